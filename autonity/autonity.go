@@ -230,7 +230,8 @@ func (c *AutonityContract) Proposer(header *types.Header, _ *state.StateDB, heig
 }
 
 // electProposer is a part of consensus, that it elect proposer from parent header's committee list which was returned
-// from autonity contract stable ordered by voting power in evm context.
+// from autonity contract stable ordered by voting power in evm context. Members of equal voting power are told apart
+// by their position in this list, which is part of the parent header hence the same on every node.
 func (c *AutonityContract) electProposer(parentHeader *types.Header, height uint64, round int64) common.Address {
 	seed := big.NewInt(constants.MaxRound)
 	totalVotingPower := big.NewInt(0)
@@ -10,6 +10,7 @@ import (
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/core/types"
 	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/rlp"
 	"github.com/stretchr/testify/require"
 )
 
@@ -30,6 +31,23 @@ func TestElectProposer(t *testing.T) {
 		}
 	})
 
+	t.Run("Proposer election with same stake should be the same on every node", func(t *testing.T) {
+		committee := generateCommittee(samePowers)
+		parentHeader := newBlockHeader(height, committee)
+		parentHeader.MixDigest = types.BFTDigest
+		// every node decodes the parent header received from the network
+		enc, err := rlp.EncodeToBytes(parentHeader)
+		require.NoError(t, err)
+		var decoded types.Header
+		require.NoError(t, rlp.DecodeBytes(enc, &decoded))
+		other := &AutonityContract{}
+		for h := uint64(0); h < uint64(100); h++ {
+			for r := int64(0); r <= int64(3); r++ {
+				require.Equal(t, ac.electProposer(parentHeader, h, r), other.electProposer(&decoded, h, r))
+			}
+		}
+	})
+
 	t.Run("Proposer selection, print and compare the scheduling rate with same stake", func(t *testing.T) {
 		committee := generateCommittee(samePowers)
 		parentHeader := newBlockHeader(height, committee)
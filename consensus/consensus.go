@@ -169,6 +169,38 @@ type BFT interface {
 	Start(ctx context.Context) error
 }
 
+// SyncAware is implemented by the engines which need to know when the node is catching up with the chain.
+type SyncAware interface {
+	// SetSyncing is called when the downloader starts and stops syncing the chain.
+	SetSyncing(syncing bool)
+}
+
+// ProposalCircuitBreaker is implemented by the engines which stop proposing once their own proposals repeatedly
+// failed verification, most likely because of a local fault.
+type ProposalCircuitBreaker interface {
+	// SetProposalCircuitBreakerThreshold sets the number of consecutive failures halting the proposing, zero
+	// disables the circuit breaker.
+	SetProposalCircuitBreakerThreshold(threshold int)
+	// ProposingHalted returns true once the circuit breaker tripped.
+	ProposingHalted() bool
+	// ResetProposalCircuitBreaker resumes proposing after the circuit breaker tripped.
+	ResetProposalCircuitBreaker()
+}
+
+// CandidateRequester is implemented by the engines which can ask for a candidate block rather than waiting for
+// the block producer to push one.
+type CandidateRequester interface {
+	// SetCandidateRequestHandler sets the function called with the height a candidate block is needed for.
+	SetCandidateRequestHandler(handler func(height uint64))
+}
+
+// ResyncRequester is implemented by the engines which can detect that the node fell behind and ask for the chain
+// to be synced again.
+type ResyncRequester interface {
+	// SetResyncHandler sets the function called when the engine asks for a resync.
+	SetResyncHandler(handler func())
+}
+
 type Syncer interface {
 	SyncPeer(address common.Address)
 
@@ -31,6 +31,10 @@ var (
 	// to the current node.
 	ErrFutureTimestampBlock = errors.New("block in the future")
 
+	// ErrTimestampRegression is returned when a block's timestamp is not greater than its
+	// parent's one.
+	ErrTimestampRegression = errors.New("timestamp not greater than parent's")
+
 	// ErrInvalidNumber is returned if a block's number doesn't equal its parent's
 	// plus one.
 	ErrInvalidNumber = errors.New("invalid block number")
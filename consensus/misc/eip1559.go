@@ -17,6 +17,7 @@
 package misc
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 
@@ -26,6 +27,10 @@ import (
 	"github.com/autonity/autonity/params"
 )
 
+// ErrInvalidBaseFee is returned if the base fee of a header doesn't match the one
+// computed from its parent.
+var ErrInvalidBaseFee = errors.New("invalid baseFee")
+
 type BaseFeeGetter interface {
 	MinBaseFee() *big.Int
 }
@@ -50,7 +55,7 @@ func VerifyEip1559Header(config *params.ChainConfig, feeGetter BaseFeeGetter, pa
 	if feeGetter != nil {
 		expectedBaseFee := CalcBaseFee(config, parent, feeGetter)
 		if header.BaseFee.Cmp(expectedBaseFee) != 0 {
-			return fmt.Errorf("invalid baseFee: have %s, want %s, parentBaseFee %s, parentGasUsed %d",
+			return fmt.Errorf("%w: have %s, want %s, parentBaseFee %s, parentGasUsed %d", ErrInvalidBaseFee,
 				expectedBaseFee, header.BaseFee, parent.BaseFee, parent.GasUsed)
 		}
 	}
@@ -78,3 +78,20 @@ func (api *API) GetCommitteeEnodes() []string {
 func (api *API) GetCoreState() interfaces.CoreState {
 	return api.tendermint.CoreState()
 }
+
+// Get a snapshot of tendermint's consensus state and vote tallies
+func (api *API) GetConsensusSnapshot() *interfaces.ConsensusSnapshot {
+	return api.tendermint.ConsensusSnapshot()
+}
+
+// PrivateAdminAPI is the operator facing RPC API to act on the BFT engine, it is only
+// exposed on the admin namespace.
+type PrivateAdminAPI struct {
+	tendermint *Backend
+}
+
+// ForceRoundChange moves the consensus engine to the next round of the current height.
+// It is a last resort tool to recover from a stuck round, see core.ForceRoundChange for the risks.
+func (api *PrivateAdminAPI) ForceRoundChange() {
+	api.tendermint.ForceRoundChange()
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/ecdsa"
 	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
@@ -88,6 +89,8 @@ type Backend struct {
 
 	// the channels for tendermint engine notifications
 	commitCh          chan<- *types.Block
+	candidateRequest  func(height uint64)
+	resyncRequest     func()
 	proposedBlockHash common.Hash
 	coreStarted       bool
 	core              interfaces.Core
@@ -301,6 +304,25 @@ func (sb *Backend) VerifyProposal(proposal *types.Block) (time.Duration, error)
 	return 0, err
 }
 
+// PrefetchProposal implements tendermint.Backend.PrefetchProposal. As the block import does for the followup
+// blocks, the proposal transactions are run on a throwaway copy of the parent state so that the verification
+// finds the state it accesses in the caches.
+func (sb *Backend) PrefetchProposal(proposal *types.Block) func() {
+	var interrupt uint32
+	stop := func() { atomic.StoreUint32(&interrupt, 1) }
+
+	parent := sb.blockchain.GetHeader(proposal.ParentHash(), proposal.NumberU64()-1)
+	if parent == nil {
+		return stop
+	}
+	throwaway, err := sb.blockchain.StateAt(parent.Root)
+	if err != nil {
+		return stop
+	}
+	go sb.blockchain.Prefetcher().Prefetch(proposal, throwaway, *sb.vmConfig, &interrupt)
+	return stop
+}
+
 // Sign implements tendermint.Backend.Sign
 func (sb *Backend) Sign(data common.Hash) ([]byte, common.Address) {
 	ret, err := crypto.Sign(data[:], sb.privateKey)
@@ -331,6 +353,82 @@ func (sb *Backend) CoreState() interfaces.CoreState {
 	return sb.core.CoreState()
 }
 
+func (sb *Backend) ConsensusSnapshot() *interfaces.ConsensusSnapshot {
+	return sb.core.Snapshot()
+}
+
+func (sb *Backend) ForceRoundChange() {
+	sb.core.ForceRoundChange()
+}
+
+// SetSyncing implements consensus.SyncAware, the current height proposals are deferred while syncing.
+func (sb *Backend) SetSyncing(syncing bool) {
+	sb.core.SetSyncing(syncing)
+}
+
+// SetProposalCircuitBreakerThreshold implements consensus.ProposalCircuitBreaker.
+func (sb *Backend) SetProposalCircuitBreakerThreshold(threshold int) {
+	sb.core.SetProposalCircuitBreakerThreshold(threshold)
+}
+
+// ProposingHalted implements consensus.ProposalCircuitBreaker.
+func (sb *Backend) ProposingHalted() bool {
+	return sb.core.ProposingHalted()
+}
+
+// ResetProposalCircuitBreaker implements consensus.ProposalCircuitBreaker.
+func (sb *Backend) ResetProposalCircuitBreaker() {
+	sb.core.ResetProposalCircuitBreaker()
+}
+
+// SetCandidateRequestHandler implements consensus.CandidateRequester, the handler is called when the node is
+// proposer without any candidate block.
+func (sb *Backend) SetCandidateRequestHandler(handler func(height uint64)) {
+	sb.coreMu.Lock()
+	defer sb.coreMu.Unlock()
+	sb.candidateRequest = handler
+}
+
+// RequestCandidateBlock implements tendermint.Backend.RequestCandidateBlock
+func (sb *Backend) RequestCandidateBlock(height uint64) {
+	sb.coreMu.RLock()
+	handler := sb.candidateRequest
+	sb.coreMu.RUnlock()
+	if handler != nil {
+		handler(height)
+	}
+}
+
+// SetResyncHandler implements consensus.ResyncRequester, the handler is called when consensus stalled despite
+// receiving proposals.
+func (sb *Backend) SetResyncHandler(handler func()) {
+	sb.coreMu.Lock()
+	defer sb.coreMu.Unlock()
+	sb.resyncRequest = handler
+}
+
+// RequestResync implements tendermint.Backend.RequestResync
+func (sb *Backend) RequestResync() {
+	sb.coreMu.RLock()
+	handler := sb.resyncRequest
+	sb.coreMu.RUnlock()
+	if handler != nil {
+		handler()
+	}
+}
+
+// SetProposalBroadcast sets how the proposals are propagated to the committee, the full proposal is sent to
+// every member by default.
+func (sb *Backend) SetProposalBroadcast(strategy interfaces.ProposalBroadcastStrategy, fanout int) {
+	sb.gossiper.SetProposalBroadcast(strategy, fanout)
+}
+
+// SetVoteBroadcast sets how the prevotes and precommits are propagated to the committee, every vote is sent to
+// every member by default.
+func (sb *Backend) SetVoteBroadcast(strategy interfaces.VoteBroadcastStrategy) {
+	sb.gossiper.SetVoteBroadcast(strategy)
+}
+
 // CommitteeEnodes retrieve the list of validators enodes for the current block
 func (sb *Backend) CommitteeEnodes() []string {
 	db, err := sb.blockchain.State()
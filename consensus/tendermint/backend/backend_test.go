@@ -26,15 +26,19 @@ import (
 	tdmcore "github.com/autonity/autonity/consensus/tendermint/core"
 	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/consensus/tendermint/events"
 	"github.com/autonity/autonity/core"
 	"github.com/autonity/autonity/core/rawdb"
 	"github.com/autonity/autonity/core/types"
 	"github.com/autonity/autonity/core/vm"
 	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/eth/protocols/eth"
 	"github.com/autonity/autonity/event"
 	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/p2p"
 	"github.com/autonity/autonity/p2p/enode"
 	"github.com/autonity/autonity/params"
+	"github.com/autonity/autonity/rlp"
 )
 
 var (
@@ -163,6 +167,355 @@ func TestGossip(t *testing.T) {
 	}
 }
 
+// loopbackPeer delivers the messages sent to it straight to the handler of the target backend.
+type loopbackPeer struct {
+	from    common.Address
+	to      *Backend
+	codes   chan uint64
+	version uint
+}
+
+func (p *loopbackPeer) Send(msgcode uint64, data interface{}) error {
+	size, r, err := rlp.EncodeToReader(data)
+	if err != nil {
+		return err
+	}
+	return p.deliver(p2p.Msg{Code: msgcode, Size: uint32(size), Payload: r})
+}
+
+func (p *loopbackPeer) SendRaw(msgcode uint64, data []byte) error {
+	return p.deliver(p2p.Msg{Code: msgcode, Size: uint32(len(data)), Payload: bytes.NewReader(data)})
+}
+
+func (p *loopbackPeer) Version() uint {
+	return p.version
+}
+
+func (p *loopbackPeer) deliver(msg p2p.Msg) error {
+	p.codes <- msg.Code
+	_, err := p.to.HandleMsg(p.from, msg, nil)
+	return err
+}
+
+// newLoopbackBackends returns backends of the committee members connected to each other through loopback peers,
+// along with the codes of the messages sent by each of them.
+func newLoopbackBackends(t *testing.T, committee types.Committee) (map[common.Address]*Backend, map[common.Address]chan uint64) {
+	return newVersionedLoopbackBackends(t, committee, nil)
+}
+
+// newVersionedLoopbackBackends is like newLoopbackBackends, the members connect with the given protocol versions,
+// eth.ETH67 if left out.
+func newVersionedLoopbackBackends(t *testing.T, committee types.Committee, versions map[common.Address]uint) (map[common.Address]*Backend, map[common.Address]chan uint64) {
+	ctrl := gomock.NewController(t)
+	backends := make(map[common.Address]*Backend)
+	for _, member := range committee {
+		knownMessages, err := lru.NewARC(inmemoryMessages)
+		require.NoError(t, err)
+		recentMessages, err := lru.NewARC(inmemoryPeers)
+		require.NoError(t, err)
+		logger := log.New("backend", "test", "id", member.Address)
+		backends[member.Address] = &Backend{
+			address:        member.Address,
+			coreStarted:    true,
+			currentBlock:   func() *types.Block { return types.NewBlockWithHeader(&types.Header{Number: common.Big0}) },
+			eventMux:       event.NewTypeMuxSilent(nil, logger),
+			knownMessages:  knownMessages,
+			recentMessages: recentMessages,
+			gossiper:       NewGossiper(recentMessages, knownMessages, member.Address, logger, make(chan struct{})),
+			logger:         logger,
+		}
+	}
+	codes := make(map[common.Address]chan uint64)
+	for addr, b := range backends {
+		codes[addr] = make(chan uint64, 64)
+		from := addr
+		broadcaster := consensus.NewMockBroadcaster(ctrl)
+		broadcaster.EXPECT().FindPeers(gomock.Any()).AnyTimes().DoAndReturn(func(targets map[common.Address]struct{}) map[common.Address]ethereum.Peer {
+			peers := make(map[common.Address]ethereum.Peer)
+			for target := range targets {
+				version, ok := versions[target]
+				if !ok {
+					version = eth.ETH67
+				}
+				peers[target] = &loopbackPeer{from: from, to: backends[target], codes: codes[from], version: version}
+			}
+			return peers
+		})
+		b.SetBroadcaster(broadcaster)
+	}
+	return backends, codes
+}
+
+func TestProposalAnnounce(t *testing.T) {
+	header := newTestHeader(3)
+	committee := header.Committee
+
+	// the proposer is committee[0], the full proposal is pushed to one member and announced to the other one
+	backends, codes := newLoopbackBackends(t, committee)
+	proposer := backends[committee[0].Address]
+	proposer.SetProposalBroadcast(interfaces.AnnounceProposalBroadcast, 1)
+	subs := make([]*event.TypeMuxSubscription, 0, 2)
+	for _, member := range committee[1:] {
+		subs = append(subs, backends[member.Address].eventMux.Subscribe(events.MessageEvent{}))
+	}
+
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)})
+	proposal := message.NewPropose(0, 1, -1, block, testSigner)
+	proposer.Gossip(committee, proposal)
+
+	for _, sub := range subs {
+		select {
+		case ev := <-sub.Chan():
+			received, ok := ev.Data.(events.MessageEvent).Message.(*message.Propose)
+			require.True(t, ok)
+			require.Equal(t, proposal.Hash(), received.Hash())
+			require.Equal(t, block.Hash(), received.Block().Hash())
+		case <-time.After(2 * time.Second):
+			t.Fatal("proposal not delivered")
+		}
+	}
+
+	// the proposer sent the full proposal twice, once pushed and once requested, and a single announcement
+	sent := make(map[uint64]int)
+	for len(codes[committee[0].Address]) > 0 {
+		sent[<-codes[committee[0].Address]]++
+	}
+	require.Equal(t, map[uint64]int{ProposeNetworkMsg: 2, ProposalAnnounceNetworkMsg: 1}, sent)
+	requests := len(codes[committee[1].Address]) + len(codes[committee[2].Address])
+	require.Equal(t, 1, requests)
+}
+
+func TestProposalAnnounceLegacyPeers(t *testing.T) {
+	header := newTestHeader(3)
+	committee := header.Committee
+
+	// the members on the previous protocol version can't pull the proposal, it is pushed to them in full
+	versions := map[common.Address]uint{committee[1].Address: eth.ETH66, committee[2].Address: eth.ETH66}
+	backends, codes := newVersionedLoopbackBackends(t, committee, versions)
+	proposer := backends[committee[0].Address]
+	proposer.SetProposalBroadcast(interfaces.AnnounceProposalBroadcast, 1)
+
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)})
+	proposal := message.NewPropose(0, 1, -1, block, testSigner)
+	proposer.Gossip(committee, proposal)
+
+	sent := make(map[uint64]int)
+	for i := 0; i < 2; i++ {
+		select {
+		case code := <-codes[committee[0].Address]:
+			sent[code]++
+		case <-time.After(2 * time.Second):
+			t.Fatal("proposal not sent")
+		}
+	}
+	require.Equal(t, map[uint64]int{ProposeNetworkMsg: 2}, sent)
+}
+
+func TestProposalAnnounceUnansweredRequest(t *testing.T) {
+	defer func(timeout time.Duration) { proposalRequestTimeout = timeout }(proposalRequestTimeout)
+	proposalRequestTimeout = 50 * time.Millisecond
+	header := newTestHeader(3)
+	committee := header.Committee
+	backends, codes := newLoopbackBackends(t, committee)
+	listener, silent, proposer := backends[committee[0].Address], committee[1].Address, committee[2].Address
+	sub := listener.eventMux.Subscribe(events.MessageEvent{})
+
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)})
+	proposal := message.NewPropose(0, 1, -1, block, testSigner)
+	backends[proposer].gossiper.(*Gossiper).proposals.Add(proposal.Hash(), proposal.Payload())
+
+	// the first announcer doesn't serve the proposal, the listener turns to the next one after the timeout
+	listener.gossiper.HandleProposalAnnounce(silent, proposal.Hash())
+	listener.gossiper.HandleProposalAnnounce(proposer, proposal.Hash())
+	select {
+	case ev := <-sub.Chan():
+		received, ok := ev.Data.(events.MessageEvent).Message.(*message.Propose)
+		require.True(t, ok)
+		require.Equal(t, proposal.Hash(), received.Hash())
+	case <-time.After(2 * time.Second):
+		t.Fatal("proposal not delivered")
+	}
+	require.Equal(t, ProposalRequestNetworkMsg, <-codes[committee[0].Address])
+	require.Equal(t, ProposalRequestNetworkMsg, <-codes[committee[0].Address])
+
+	// once received, the proposal isn't requested anymore
+	time.Sleep(2 * proposalRequestTimeout)
+	listener.gossiper.HandleProposalAnnounce(silent, proposal.Hash())
+	require.Len(t, codes[committee[0].Address], 0)
+}
+
+func TestVoteAnnounce(t *testing.T) {
+	committee := make(types.Committee, 4)
+	keys := make(map[common.Address]*ecdsa.PrivateKey)
+	for i := range committee {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		address := crypto.PubkeyToAddress(key.PublicKey)
+		keys[address] = key
+		committee[i] = types.CommitteeMember{Address: address, VotingPower: big.NewInt(int64(i + 1))}
+	}
+	inCommittee := func(address common.Address) *types.CommitteeMember {
+		for i := range committee {
+			if committee[i].Address == address {
+				return &committee[i]
+			}
+		}
+		return nil
+	}
+	backends, codes := newLoopbackBackends(t, committee)
+	gossiper := backends[committee[0].Address]
+	gossiper.SetVoteBroadcast(interfaces.CompactVoteBroadcast)
+	subs := make(map[common.Address]*event.TypeMuxSubscription)
+	for _, member := range committee[1:] {
+		subs[member.Address] = backends[member.Address].eventMux.Subscribe(events.MessageEvent{})
+	}
+
+	// committee[0] gossips the prevotes of the first three members for the same value, announcing the voters only
+	value := common.HexToHash("0x01")
+	announced := new(big.Int)
+	for _, member := range committee[:3] {
+		key := keys[member.Address]
+		signer := func(hash common.Hash) ([]byte, common.Address) {
+			out, _ := crypto.Sign(hash[:], key)
+			return out, crypto.PubkeyToAddress(key.PublicKey)
+		}
+		gossiper.Gossip(committee, message.NewPrevote(0, 1, value, signer).MustVerify(inCommittee))
+		announced.Add(announced, member.VotingPower)
+	}
+
+	// every member pulls each announced vote once and gets the full voting power of the voters
+	for addr, sub := range subs {
+		power := new(big.Int)
+		for i := 0; i < 3; i++ {
+			select {
+			case ev := <-sub.Chan():
+				received, ok := ev.Data.(events.MessageEvent).Message.(*message.Prevote)
+				require.True(t, ok)
+				require.NoError(t, received.Validate(inCommittee))
+				require.Equal(t, value, received.Value())
+				power.Add(power, received.Power())
+			case <-time.After(2 * time.Second):
+				t.Fatal("vote not delivered")
+			}
+		}
+		require.Equal(t, announced, power)
+		require.LessOrEqual(t, len(codes[addr]), 3)
+		for len(codes[addr]) > 0 {
+			require.Equal(t, VoteRequestNetworkMsg, <-codes[addr])
+		}
+	}
+
+	// each vote was sent once to each member, along with an announcement per gossiped vote
+	sent := make(map[uint64]int)
+	for i := 0; i < 18; i++ {
+		select {
+		case code := <-codes[committee[0].Address]:
+			sent[code]++
+		case <-time.After(2 * time.Second):
+			t.Fatalf("messages missing, sent %v", sent)
+		}
+	}
+	require.Equal(t, map[uint64]int{PrevoteNetworkMsg: 9, VoteAnnounceNetworkMsg: 9}, sent)
+}
+
+func TestVoteAnnounceLegacyPeers(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	voter := crypto.PubkeyToAddress(key.PublicKey)
+	committee := newTestHeader(3).Committee
+	committee[0].Address = voter
+
+	// the members on the previous protocol version can't pull the votes, they are pushed to them in full
+	versions := map[common.Address]uint{committee[1].Address: eth.ETH66, committee[2].Address: eth.ETH66}
+	backends, codes := newVersionedLoopbackBackends(t, committee, versions)
+	gossiper := backends[voter]
+	gossiper.SetVoteBroadcast(interfaces.CompactVoteBroadcast)
+
+	signer := func(hash common.Hash) ([]byte, common.Address) {
+		out, _ := crypto.Sign(hash[:], key)
+		return out, voter
+	}
+	prevote := message.NewPrevote(0, 1, common.HexToHash("0x01"), signer).MustVerify(func(common.Address) *types.CommitteeMember { return &committee[0] })
+	gossiper.Gossip(committee, prevote)
+
+	sent := make(map[uint64]int)
+	for i := 0; i < 2; i++ {
+		select {
+		case code := <-codes[voter]:
+			sent[code]++
+		case <-time.After(2 * time.Second):
+			t.Fatal("vote not sent")
+		}
+	}
+	require.Equal(t, map[uint64]int{PrevoteNetworkMsg: 2}, sent)
+
+	// an announcement reaching a member through a legacy peer isn't answered with a request
+	votes := gossiper.gossiper.(*Gossiper).addVote(committee, prevote)
+	backends[committee[1].Address].gossiper.HandleVoteAnnounce(committee[2].Address, votes)
+	require.Len(t, codes[committee[1].Address], 0)
+}
+
+func TestVoteAnnounceUnansweredRequest(t *testing.T) {
+	defer func(timeout time.Duration) { voteRequestTimeout = timeout }(voteRequestTimeout)
+	voteRequestTimeout = 50 * time.Millisecond
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	voter := crypto.PubkeyToAddress(key.PublicKey)
+	committee := newTestHeader(3).Committee
+	committee[2].Address = voter
+	backends, codes := newLoopbackBackends(t, committee)
+	listener, silent, announcer := backends[committee[0].Address], committee[1].Address, backends[voter]
+	sub := listener.eventMux.Subscribe(events.MessageEvent{})
+
+	signer := func(hash common.Hash) ([]byte, common.Address) {
+		out, _ := crypto.Sign(hash[:], key)
+		return out, voter
+	}
+	prevote := message.NewPrevote(0, 1, common.HexToHash("0x01"), signer).MustVerify(func(common.Address) *types.CommitteeMember { return &committee[2] })
+	votes := announcer.gossiper.(*Gossiper).addVote(committee, prevote)
+
+	// the first announcer doesn't serve the vote, it isn't requested again until the timeout
+	listener.gossiper.HandleVoteAnnounce(silent, votes)
+	listener.gossiper.HandleVoteAnnounce(voter, votes)
+	require.Equal(t, VoteRequestNetworkMsg, <-codes[committee[0].Address])
+	require.Len(t, codes[committee[0].Address], 0)
+
+	time.Sleep(voteRequestTimeout)
+	listener.gossiper.HandleVoteAnnounce(voter, votes)
+	select {
+	case ev := <-sub.Chan():
+		received, ok := ev.Data.(events.MessageEvent).Message.(*message.Prevote)
+		require.True(t, ok)
+		require.Equal(t, prevote.Hash(), received.Hash())
+	case <-time.After(2 * time.Second):
+		t.Fatal("vote not delivered")
+	}
+	require.Equal(t, VoteRequestNetworkMsg, <-codes[committee[0].Address])
+}
+
+func TestVoteAnnounceHeights(t *testing.T) {
+	committee := newTestHeader(2).Committee
+	backends, codes := newLoopbackBackends(t, committee)
+	listener := backends[committee[0].Address]
+	announcer := &loopbackPeer{from: committee[1].Address, to: listener, codes: codes[committee[1].Address]}
+
+	// the chain head is the genesis, only the votes of the next heights are pulled
+	for height, pulled := range map[uint64]bool{0: false, 1: true, voteAnnounceHeights: true, voteAnnounceHeights + 1: false, math.MaxUint64: false} {
+		votes := &message.CompactVotes{Code: message.PrevoteCode, Height: height, Value: common.HexToHash("0x01")}
+		votes.Add(1)
+		sets := listener.gossiper.(*Gossiper).votes.Len()
+		require.NoError(t, announcer.Send(VoteAnnounceNetworkMsg, votes))
+		<-codes[committee[1].Address]
+		if pulled {
+			require.Equal(t, sets+1, listener.gossiper.(*Gossiper).votes.Len(), "height %d", height)
+			require.Equal(t, VoteRequestNetworkMsg, <-codes[committee[0].Address])
+		} else {
+			require.Equal(t, sets, listener.gossiper.(*Gossiper).votes.Len(), "height %d", height)
+			require.Len(t, codes[committee[0].Address], 0, "height %d", height)
+		}
+	}
+}
+
 func TestVerifyProposal(t *testing.T) {
 	blockchain, backend := newBlockChain(1)
 	blocks := make([]*types.Block, 5)
@@ -209,6 +562,39 @@ func TestVerifyProposal(t *testing.T) {
 	}
 
 }
+
+// BenchmarkVerifyProposal compares the verification with and without the state prefetch. The test chain lives in
+// memory where the state is always warm, the gain only shows on a cold disk database.
+func BenchmarkVerifyProposal(b *testing.B) {
+	blockchain, backend := newBlockChain(1)
+	log.Root().SetHandler(log.DiscardHandler())
+	block, err := makeBlockWithoutSeal(blockchain, backend, blockchain.Genesis())
+	if err != nil {
+		b.Fatalf("could not create block, err=%s", err)
+	}
+	header := block.Header()
+	seal, _ := backend.Sign(types.SigHash(header))
+	if err := types.WriteSeal(header, seal); err != nil {
+		b.Fatalf("could not write seal, err=%s", err)
+	}
+	block = block.WithSeal(header)
+
+	for _, prefetch := range []bool{false, true} {
+		b.Run(fmt.Sprintf("prefetch=%v", prefetch), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				stop := func() {}
+				if prefetch {
+					stop = backend.PrefetchProposal(block)
+				}
+				if _, err := backend.VerifyProposal(block); err != nil {
+					b.Fatalf("could not verify block, err=%s", err)
+				}
+				stop()
+			}
+		})
+	}
+}
+
 func TestResetPeerCache(t *testing.T) {
 	addr := common.HexToAddress("0x01234567890")
 	msgCache, err := lru.NewARC(inmemoryMessages)
@@ -19,6 +19,7 @@ import (
 	"github.com/autonity/autonity/consensus/tendermint/core/constants"
 	"github.com/autonity/autonity/consensus/tendermint/events"
 	"github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/rawdb"
 	"github.com/autonity/autonity/core/state"
 	"github.com/autonity/autonity/core/types"
 	"github.com/autonity/autonity/event"
@@ -31,6 +32,8 @@ const (
 	inmemorySnapshots = 128 // Number of recent vote snapshots to keep in memory
 	inmemoryPeers     = 40
 	inmemoryMessages  = 1024
+	inmemoryProposals = 16  // Number of recently gossiped proposals served on request
+	inmemoryVoteSets  = 128 // Number of recently gossiped sets of votes for a value served on request
 )
 
 // ErrStartedEngine is returned if the engine is already started
@@ -55,7 +58,7 @@ var (
 	// errInvalidUncleHash is returned if a block contains an non-empty uncle list.
 	errInvalidUncleHash = errors.New("non empty uncle hash")
 	// errInvalidTimestamp is returned if the timestamp of a block is lower than the previous block's timestamp + the minimum block period.
-	errInvalidTimestamp = errors.New("invalid timestamp")
+	errInvalidTimestamp = fmt.Errorf("invalid timestamp: %w", consensus.ErrTimestampRegression)
 	// errInvalidRound is returned if the round exceed maximum round number.
 	errInvalidRound = errors.New("invalid round")
 )
@@ -65,6 +68,8 @@ var (
 	nilUncleHash                  = types.CalcUncleHash(nil) // Always Keccak256(RLP([])) as uncles are meaningless outside of PoW.
 	emptyNonce                    = types.BlockNonce{}
 	now                           = time.Now
+	proposalRequestTimeout        = 500 * time.Millisecond // time an announcer has to serve a requested proposal before the next one is asked
+	voteRequestTimeout            = 500 * time.Millisecond // time an announcer has to serve the requested votes before they are asked again
 )
 
 // Author retrieves the Ethereum address of the account that minted the given
@@ -424,6 +429,22 @@ func (sb *Backend) SetProposedBlockHash(hash common.Hash) {
 	sb.proposedBlockHash = hash
 }
 
+// LastProposal returns the last proposal sent by the node, as recorded in the chain database.
+func (sb *Backend) LastProposal() *rawdb.LastProposal {
+	if sb.blockchain == nil {
+		return nil
+	}
+	return rawdb.ReadLastProposal(sb.blockchain.Database())
+}
+
+// SaveProposal records the proposal about to be sent in the chain database.
+func (sb *Backend) SaveProposal(proposal *rawdb.LastProposal) {
+	if sb.blockchain == nil {
+		return
+	}
+	rawdb.WriteLastProposal(sb.blockchain.Database(), proposal)
+}
+
 // AddSeal update timestamp and signature of the block based on its number of transactions
 func (sb *Backend) AddSeal(block *types.Block) (*types.Block, error) {
 	header := block.Header()
@@ -445,6 +466,10 @@ func (sb *Backend) APIs(chain consensus.ChainReader) []rpc.API {
 		Version:   "1.0",
 		Service:   &API{chain: chain, tendermint: sb, getCommittee: getCommittee},
 		Public:    true,
+	}, {
+		Namespace: "admin",
+		Version:   "1.0",
+		Service:   &PrivateAdminAPI{tendermint: sb},
 	}}
 }
 
@@ -1,16 +1,21 @@
 package backend
 
 import (
+	"math"
 	"math/big"
+	"sync"
 	"time"
 
 	lru "github.com/hashicorp/golang-lru"
 
+	ethereum "github.com/autonity/autonity"
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/consensus"
 	"github.com/autonity/autonity/consensus/tendermint/bft"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
 	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/eth/protocols/eth"
 	"github.com/autonity/autonity/log"
 )
 
@@ -21,15 +26,31 @@ type Gossiper struct {
 	broadcaster    consensus.Broadcaster
 	logger         log.Logger
 	stopped        chan struct{}
+
+	proposalStrategy   interfaces.ProposalBroadcastStrategy
+	proposalFanout     int
+	proposals          *lru.Cache // the payloads of the recently gossiped proposals, served on request
+	requestsMu         sync.Mutex // protects the proposal requests in the requestedProposals cache
+	requestedProposals *lru.Cache // the pulling of the announced proposals, by hash
+
+	voteStrategy interfaces.VoteBroadcastStrategy
+	votesMu      sync.Mutex // protects the vote sets in the votes cache
+	votes        *lru.Cache // the recently gossiped and requested votes, by type, round and value
 }
 
 func NewGossiper(recentMessages *lru.ARCCache, knownMessages *lru.ARCCache, address common.Address, logger log.Logger, stopped chan struct{}) *Gossiper {
+	proposals, _ := lru.New(inmemoryProposals)
+	requestedProposals, _ := lru.New(inmemoryProposals)
+	votes, _ := lru.New(inmemoryVoteSets)
 	return &Gossiper{
-		recentMessages: recentMessages,
-		knownMessages:  knownMessages,
-		address:        address,
-		logger:         logger,
-		stopped:        stopped,
+		recentMessages:     recentMessages,
+		knownMessages:      knownMessages,
+		address:            address,
+		logger:             logger,
+		stopped:            stopped,
+		proposals:          proposals,
+		requestedProposals: requestedProposals,
+		votes:              votes,
 	}
 }
 
@@ -53,9 +74,28 @@ func (g *Gossiper) Address() common.Address {
 	return g.address
 }
 
-func (g *Gossiper) Gossip(committee types.Committee, message message.Msg) {
-	hash := message.Hash()
+// SetProposalBroadcast sets the proposal broadcast strategy, it must be called before the engine starts.
+func (g *Gossiper) SetProposalBroadcast(strategy interfaces.ProposalBroadcastStrategy, fanout int) {
+	g.proposalStrategy = strategy
+	g.proposalFanout = fanout
+}
+
+// SetVoteBroadcast sets the vote broadcast strategy, it must be called before the engine starts.
+func (g *Gossiper) SetVoteBroadcast(strategy interfaces.VoteBroadcastStrategy) {
+	g.voteStrategy = strategy
+}
+
+func (g *Gossiper) Gossip(committee types.Committee, msg message.Msg) {
+	hash := msg.Hash()
 	g.knownMessages.Add(hash, true)
+	announce := g.proposalStrategy == interfaces.AnnounceProposalBroadcast && msg.Code() == message.ProposalCode
+	if announce {
+		g.proposals.Add(hash, msg.Payload())
+	}
+	var votes *message.CompactVotes
+	if g.voteStrategy == interfaces.CompactVoteBroadcast && isVote(msg) {
+		votes = g.addVote(committee, msg)
+	}
 	targets := make(map[common.Address]struct{})
 	for _, val := range committee {
 		if val.Address != g.address {
@@ -64,6 +104,8 @@ func (g *Gossiper) Gossip(committee types.Committee, message message.Msg) {
 	}
 	if g.broadcaster != nil && len(targets) > 0 {
 		ps := g.broadcaster.FindPeers(targets)
+		// the map iteration order being random, so are the peers receiving the full proposal
+		fanout := g.fullProposalPeers(len(ps))
 		for addr, p := range ps {
 			ms, ok := g.recentMessages.Get(addr)
 			var m *lru.ARCCache
@@ -80,11 +122,129 @@ func (g *Gossiper) Gossip(committee types.Committee, message message.Msg) {
 			m.Add(hash, true)
 			g.recentMessages.Add(addr, m)
 
-			go p.SendRaw(NetworkCodes[message.Code()], message.Payload()) //nolint
+			if announce && fanout <= 0 && pullsMessages(p) {
+				go p.Send(ProposalAnnounceNetworkMsg, hash) //nolint
+				continue
+			}
+			if votes != nil && pullsMessages(p) {
+				go p.Send(VoteAnnounceNetworkMsg, votes) //nolint
+				continue
+			}
+			fanout--
+			go p.SendRaw(NetworkCodes[msg.Code()], msg.Payload()) //nolint
+		}
+	}
+}
+
+// fullProposalPeers returns the number of peers receiving the full proposal when announcing.
+func (g *Gossiper) fullProposalPeers(peers int) int {
+	if g.proposalFanout > 0 {
+		return g.proposalFanout
+	}
+	return int(math.Ceil(math.Sqrt(float64(peers))))
+}
+
+// pullsMessages returns whether the peer runs a protocol version with the announce and request messages, the
+// messages are pushed in full to the other peers.
+func pullsMessages(p ethereum.Peer) bool {
+	return p.Version() >= eth.ETH67
+}
+
+// proposalRequest tracks the pulling of an announced proposal, it is requested from a single announcer at a time.
+type proposalRequest struct {
+	announcers []common.Address // the announcers to request the proposal from next, in order of announcement
+	pending    bool             // a request waits for an answer, until proposalRequestTimeout
+}
+
+func (g *Gossiper) HandleProposalAnnounce(sender common.Address, hash common.Hash) {
+	g.markKnown(sender, hash)
+	if _, ok := g.knownMessages.Get(hash); ok {
+		return
+	}
+	g.requestsMu.Lock()
+	defer g.requestsMu.Unlock()
+	var req *proposalRequest
+	if r, ok := g.requestedProposals.Get(hash); ok {
+		req = r.(*proposalRequest)
+	} else {
+		req = new(proposalRequest)
+		g.requestedProposals.Add(hash, req)
+	}
+	// an announcer which doesn't serve the proposal can't hold it back, the next one is asked after a timeout
+	if len(req.announcers) < inmemoryPeers {
+		req.announcers = append(req.announcers, sender)
+	}
+	if !req.pending {
+		g.requestProposal(hash, req)
+	}
+}
+
+// requestProposal requests the proposal from its next announcer, and from the following one if it isn't received
+// in time. It must be called with requestsMu held.
+func (g *Gossiper) requestProposal(hash common.Hash, req *proposalRequest) {
+	req.pending = false
+	for len(req.announcers) > 0 {
+		announcer := req.announcers[0]
+		req.announcers = req.announcers[1:]
+		if p := g.findPeer(announcer); p != nil {
+			g.logger.Debug("Requesting announced proposal", "from", announcer, "hash", hash)
+			go p.Send(ProposalRequestNetworkMsg, hash) //nolint
+			req.pending = true
+			time.AfterFunc(proposalRequestTimeout, func() { g.retryProposalRequest(hash, req) })
+			return
 		}
 	}
 }
 
+// retryProposalRequest requests the proposal from the next announcer, unless it was received meanwhile.
+func (g *Gossiper) retryProposalRequest(hash common.Hash, req *proposalRequest) {
+	select {
+	case <-g.stopped:
+		return
+	default:
+	}
+	if _, ok := g.knownMessages.Get(hash); ok {
+		return
+	}
+	g.requestsMu.Lock()
+	defer g.requestsMu.Unlock()
+	if r, ok := g.requestedProposals.Get(hash); !ok || r.(*proposalRequest) != req {
+		return
+	}
+	g.requestProposal(hash, req)
+}
+
+func (g *Gossiper) HandleProposalRequest(sender common.Address, hash common.Hash) {
+	payload, ok := g.proposals.Get(hash)
+	if !ok {
+		g.logger.Debug("Requested proposal unknown", "from", sender, "hash", hash)
+		return
+	}
+	if p := g.findPeer(sender); p != nil {
+		g.markKnown(sender, hash)
+		go p.SendRaw(ProposeNetworkMsg, payload.([]byte)) //nolint
+	}
+}
+
+// markKnown records that the peer knows the given message.
+func (g *Gossiper) markKnown(addr common.Address, hash common.Hash) {
+	var m *lru.ARCCache
+	if ms, ok := g.recentMessages.Get(addr); ok {
+		m, _ = ms.(*lru.ARCCache)
+	} else {
+		m, _ = lru.NewARC(inmemoryMessages)
+		g.recentMessages.Add(addr, m)
+	}
+	m.Add(hash, true)
+}
+
+func (g *Gossiper) findPeer(addr common.Address) ethereum.Peer {
+	if g.broadcaster == nil {
+		return nil
+	}
+	return g.broadcaster.FindPeers(map[common.Address]struct{}{addr: {}})[addr]
+}
+
 func (g *Gossiper) AskSync(header *types.Header) {
 
 	targets := make(map[common.Address]struct{})
@@ -21,8 +21,23 @@ const (
 	PrecommitNetworkMsg      uint64 = 0x13
 	SyncNetworkMsg           uint64 = 0x14
 	AccountabilityNetworkMsg uint64 = 0x15
+	// ProposalAnnounceNetworkMsg and ProposalRequestNetworkMsg carry a proposal hash, they are used to pull
+	// the full proposal on demand when the proposals are announced.
+	ProposalAnnounceNetworkMsg uint64 = 0x16
+	ProposalRequestNetworkMsg  uint64 = 0x17
+	// VoteAnnounceNetworkMsg and VoteRequestNetworkMsg carry a compact set of votes, they are used to pull
+	// the individual votes on demand when the votes are announced.
+	VoteAnnounceNetworkMsg uint64 = 0x18
+	VoteRequestNetworkMsg  uint64 = 0x19
 )
 
+// maxCompactVotersSize bounds the size of the voters bitmap of the vote announcements and requests, in bytes.
+const maxCompactVotersSize = 1024
+
+// voteAnnounceHeights is the number of heights past the chain head whose announced votes are pulled. The
+// announcements for the other heights are ignored, they would evict the vote sets of the heights being decided.
+const voteAnnounceHeights = 2
+
 type UnhandledMsg struct {
 	addr common.Address
 	msg  p2p.Msg
@@ -38,7 +53,8 @@ var (
 	}
 )
 
-// Protocol implements consensus.Handler.Protocol
+// Protocol implements consensus.Handler.Protocol, the announce and request messages are left out as they are only
+// available from the eth.ETH67 protocol version, see pullsMessages.
 func (sb *Backend) Protocol() (protocolName string, extraMsgCodes uint64) {
 	return "tendermint", 5 //nolint
 }
@@ -62,7 +78,7 @@ func (sb *Backend) HandleUnhandledMsgs(ctx context.Context) {
 
 // HandleMsg implements consensus.Handler.HandleMsg
 func (sb *Backend) HandleMsg(addr common.Address, msg p2p.Msg, errCh chan<- error) (bool, error) {
-	if msg.Code < ProposeNetworkMsg || msg.Code > AccountabilityNetworkMsg {
+	if msg.Code < ProposeNetworkMsg || msg.Code > VoteRequestNetworkMsg {
 		return false, nil
 	}
 
@@ -97,6 +113,38 @@ func (sb *Backend) HandleMsg(addr common.Address, msg p2p.Msg, errCh chan<- erro
 		// post the off chain accountability msg to the event handler, let the event handler to handle DoS attack vectors.
 		sb.logger.Debug("Received Accountability Msg", "from", addr)
 		go sb.Post(events.AccountabilityEvent{Sender: addr, Payload: data, ErrCh: errCh})
+	case ProposalAnnounceNetworkMsg, ProposalRequestNetworkMsg:
+		if !sb.coreStarted {
+			return true, nil // we return nil as we don't want to shut down the connection if core is stopped
+		}
+		var hash common.Hash
+		if err := msg.Decode(&hash); err != nil {
+			return true, errDecodeFailed
+		}
+		if msg.Code == ProposalAnnounceNetworkMsg {
+			sb.gossiper.HandleProposalAnnounce(addr, hash)
+		} else {
+			sb.gossiper.HandleProposalRequest(addr, hash)
+		}
+	case VoteAnnounceNetworkMsg, VoteRequestNetworkMsg:
+		if !sb.coreStarted {
+			return true, nil // we return nil as we don't want to shut down the connection if core is stopped
+		}
+		votes := new(message.CompactVotes)
+		if err := msg.Decode(votes); err != nil {
+			return true, errDecodeFailed
+		}
+		if (votes.Code != message.PrevoteCode && votes.Code != message.PrecommitCode) || len(votes.Voters) > maxCompactVotersSize {
+			return true, errDecodeFailed
+		}
+		if msg.Code == VoteAnnounceNetworkMsg {
+			if head := sb.currentBlock().NumberU64(); votes.Height <= head || votes.Height > head+voteAnnounceHeights {
+				return true, nil
+			}
+			sb.gossiper.HandleVoteAnnounce(addr, votes)
+		} else {
+			sb.gossiper.HandleVoteRequest(addr, votes)
+		}
 	default:
 		return false, nil
 	}
@@ -108,7 +108,7 @@ func TestProtocol(t *testing.T) {
 		t.Fatalf("expected 'tendermint', got %v", name)
 	}
 	if code != 5 {
-		t.Fatalf("expected 2, got %v", code)
+		t.Fatalf("expected 5, got %v", code)
 	}
 }
 
@@ -0,0 +1,124 @@
+package backend
+
+import (
+	"time"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/types"
+)
+
+// voteKey identifies the votes of the same type cast at a round for a value.
+type voteKey struct {
+	code   uint8
+	height uint64
+	round  uint64
+	value  common.Hash
+}
+
+func compactVotesKey(votes *message.CompactVotes) voteKey {
+	return voteKey{code: votes.Code, height: votes.Height, round: votes.Round, value: votes.Value}
+}
+
+type gossipedVote struct {
+	hash    common.Hash
+	payload []byte
+}
+
+// gossipedVotes tracks the votes for a value known locally and the ones requested from the announcing peers.
+type gossipedVotes struct {
+	known     *message.CompactVotes
+	requested map[int]time.Time    // when the votes not known yet were last requested, by committee index
+	votes     map[int]gossipedVote // the payloads of the known votes, served on request, by committee index
+}
+
+func isVote(msg message.Msg) bool {
+	return msg.Code() == message.PrevoteCode || msg.Code() == message.PrecommitCode
+}
+
+// voteSet returns the votes tracked for the given key, it must be called with votesMu held.
+func (g *Gossiper) voteSet(key voteKey) *gossipedVotes {
+	if set, ok := g.votes.Get(key); ok {
+		return set.(*gossipedVotes)
+	}
+	set := &gossipedVotes{
+		known:     &message.CompactVotes{Code: key.code, Height: key.height, Round: key.round, Value: key.value},
+		requested: make(map[int]time.Time),
+		votes:     make(map[int]gossipedVote),
+	}
+	g.votes.Add(key, set)
+	return set
+}
+
+// addVote records the vote as known and returns the announcement of all the votes known for its value. Nil is
+// returned if the voter isn't part of the committee, the vote is then sent in full.
+func (g *Gossiper) addVote(committee types.Committee, msg message.Msg) *message.CompactVotes {
+	index := -1
+	for i, member := range committee {
+		if member.Address == msg.Sender() {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil
+	}
+	g.votesMu.Lock()
+	defer g.votesMu.Unlock()
+	set := g.voteSet(voteKey{code: msg.Code(), height: msg.H(), round: uint64(msg.R()), value: msg.Value()})
+	set.known.Add(index)
+	delete(set.requested, index)
+	set.votes[index] = gossipedVote{hash: msg.Hash(), payload: msg.Payload()}
+	// the announcement is sent asynchronously, it gets its own bitmap
+	announcement := *set.known
+	announcement.Voters = append([]byte(nil), set.known.Voters...)
+	return &announcement
+}
+
+func (g *Gossiper) HandleVoteAnnounce(sender common.Address, votes *message.CompactVotes) {
+	missing := &message.CompactVotes{Code: votes.Code, Height: votes.Height, Round: votes.Round, Value: votes.Value}
+	g.votesMu.Lock()
+	set := g.voteSet(compactVotesKey(votes))
+	// request each vote from a single announcer at a time, a later announcer is asked if it wasn't served in time
+	requestedAt := now()
+	for _, index := range votes.Indexes() {
+		if set.known.Has(index) {
+			continue
+		}
+		if last, ok := set.requested[index]; !ok || requestedAt.Sub(last) >= voteRequestTimeout {
+			missing.Add(index)
+			set.requested[index] = requestedAt
+		}
+	}
+	g.votesMu.Unlock()
+
+	if missing.Count() == 0 {
+		return
+	}
+	if p := g.findPeer(sender); p != nil && pullsMessages(p) {
+		g.logger.Debug("Requesting announced votes", "from", sender, "code", votes.Code, "height", votes.Height, "round", votes.Round, "value", votes.Value, "votes", missing.Count())
+		go p.Send(VoteRequestNetworkMsg, missing) //nolint
+	}
+}
+
+func (g *Gossiper) HandleVoteRequest(sender common.Address, votes *message.CompactVotes) {
+	g.votesMu.Lock()
+	var requested []gossipedVote
+	if set, ok := g.votes.Get(compactVotesKey(votes)); ok {
+		for _, index := range votes.Indexes() {
+			if vote, ok := set.(*gossipedVotes).votes[index]; ok {
+				requested = append(requested, vote)
+			}
+		}
+	}
+	g.votesMu.Unlock()
+
+	p := g.findPeer(sender)
+	if p == nil {
+		return
+	}
+	for _, vote := range requested {
+		g.markKnown(sender, vote.hash)
+		go p.SendRaw(NetworkCodes[votes.Code], vote.payload) //nolint
+	}
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+)
+
+// absentVotersHeights is the number of most recent committed heights whose absent voters are remembered.
+const absentVotersHeights = 256
+
+// absentVoters records, for the recently committed heights, the committee members which sent neither a prevote nor
+// a precommit in the committed round. It is written by the main loop and read by the API.
+type absentVoters struct {
+	mu      sync.RWMutex
+	heights map[uint64][]common.Address
+	last    uint64 // last committed height recorded
+}
+
+// recordAbsentVoters remembers the committee members which didn't vote in the round committing the given height, it
+// must be called from the main loop.
+func (c *Core) recordAbsentVoters(height uint64, messages *message.RoundMessages) {
+	voted := make(map[common.Address]struct{})
+	for _, vote := range append(messages.AllPrevotes(), messages.AllPrecommits()...) {
+		voted[vote.Sender()] = struct{}{}
+	}
+	var absent []common.Address
+	for _, member := range c.CommitteeSet().Committee() {
+		if _, ok := voted[member.Address]; !ok {
+			absent = append(absent, member.Address)
+		}
+	}
+
+	c.absent.mu.Lock()
+	defer c.absent.mu.Unlock()
+	if c.absent.heights == nil {
+		c.absent.heights = make(map[uint64][]common.Address)
+	}
+	c.absent.heights[height] = absent
+	if height > c.absent.last {
+		c.absent.last = height
+	}
+	for h := range c.absent.heights {
+		if h+absentVotersHeights <= c.absent.last {
+			delete(c.absent.heights, h)
+		}
+	}
+}
+
+// AbsentVoters returns the committee members which sent neither a prevote nor a precommit in the committed round of
+// at least one of the last window committed heights, sorted by address. Only the heights committed since the engine
+// started, and at most the last 256 ones, are known.
+func (c *Core) AbsentVoters(window uint64) []common.Address {
+	c.absent.mu.RLock()
+	defer c.absent.mu.RUnlock()
+	seen := make(map[common.Address]struct{})
+	var absent []common.Address
+	for h := c.absent.last; h > 0 && c.absent.last-h < window && c.absent.last-h < absentVotersHeights; h-- {
+		for _, addr := range c.absent.heights[h] {
+			if _, ok := seen[addr]; !ok {
+				seen[addr] = struct{}{}
+				absent = append(absent, addr)
+			}
+		}
+	}
+	sort.Slice(absent, func(i, j int) bool { return bytes.Compare(absent[i][:], absent[j][:]) < 0 })
+	return absent
+}
@@ -144,3 +144,92 @@ func (c *Core) processBacklog() {
 		}
 	}
 }
+
+// backlogSummary counts the messages buffered at the current height.
+type backlogSummary struct {
+	rounds map[int64]int
+	types  map[string]int
+}
+
+func (s backlogSummary) add(msg message.Msg) {
+	s.rounds[msg.R()]++
+	s.types[messageTypeName(msg.Code())]++
+}
+
+type backlogSummaryRequestEvent struct {
+	summaryCh chan backlogSummary
+}
+
+// BacklogSummary returns the number of messages buffered at the current height, per round: the future round messages
+// of the backlog, the old round proposals kept unverified until they get committed, and the proposals waiting for
+// their timestamp or for their verification to be retried. A node accumulating messages is likely stuck behind the
+// others. The messages of future heights are not included.
+func (c *Core) BacklogSummary() map[int64]int {
+	return c.requestBacklogSummary().rounds
+}
+
+// BacklogTypeSummary returns the number of messages buffered at the current height, per message type.
+func (c *Core) BacklogTypeSummary() map[string]int {
+	return c.requestBacklogSummary().types
+}
+
+// requestBacklogSummary has the summary produced by the main loop, which owns the backlog.
+func (c *Core) requestBacklogSummary() backlogSummary {
+	e := backlogSummaryRequestEvent{
+		summaryCh: make(chan backlogSummary),
+	}
+	go c.SendEvent(e)
+	return <-e.summaryCh
+}
+
+func (c *Core) handleBacklogSummaryRequest(e backlogSummaryRequestEvent) {
+	e.summaryCh <- c.backlogSummary()
+	close(e.summaryCh)
+}
+
+// backlogSummary must be called from the main loop.
+func (c *Core) backlogSummary() backlogSummary {
+	summary := backlogSummary{
+		rounds: make(map[int64]int),
+		types:  make(map[string]int),
+	}
+	for _, backlog := range c.backlogs {
+		for _, msg := range backlog {
+			summary.add(msg)
+		}
+	}
+	// the proposals of the past rounds we handled in time were verified
+	for _, round := range c.messages.GetRounds() {
+		if round >= c.Round() {
+			continue
+		}
+		roundMessages, _ := c.messages.Get(round)
+		if proposal := roundMessages.Proposal(); proposal != nil && !roundMessages.IsProposalVerified() {
+			summary.add(proposal)
+		}
+	}
+	if c.futureProposal != nil {
+		summary.add(c.futureProposal)
+	}
+	for _, retry := range c.verificationRetries {
+		if retry.timer != nil {
+			summary.add(retry.proposal)
+		}
+	}
+	return summary
+}
+
+func messageTypeName(code uint8) string {
+	switch code {
+	case message.ProposalCode:
+		return "proposal"
+	case message.PrevoteCode:
+		return "prevote"
+	case message.PrecommitCode:
+		return "precommit"
+	case message.LightProposalCode:
+		return "lightProposal"
+	default:
+		return "unknown"
+	}
+}
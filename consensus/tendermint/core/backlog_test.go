@@ -8,9 +8,11 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb/pkg/deep"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
 	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus"
 	"github.com/autonity/autonity/consensus/tendermint/core/constants"
 	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
@@ -204,6 +206,53 @@ func TestStoreBacklog(t *testing.T) {
 	})
 }
 
+func TestBacklogSummary(t *testing.T) {
+	backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+	c := &Core{
+		logger:   log.New("backend", "test", "id", 0),
+		address:  common.HexToAddress("0x1234567890"),
+		backend:  backendMock,
+		backlogs: make(map[common.Address][]message.Msg),
+		messages: message.NewMap(),
+		round:    2,
+		height:   big.NewInt(1),
+	}
+	c.SetClock(newFakeClock())
+	// stands for the main loop
+	backendMock.EXPECT().Post(gomock.Any()).AnyTimes().Do(func(ev any) {
+		c.handleBacklogSummaryRequest(ev.(backlogSummaryRequestEvent))
+	})
+	require.Empty(t, c.BacklogSummary())
+
+	newBlock := func(time uint64) *types.Block {
+		return types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), Time: time})
+	}
+	proposer := common.HexToAddress("0x0987654321")
+	voter := common.HexToAddress("0x0987654322")
+	c.storeBacklog(message.NewPropose(3, 1, -1, newBlock(0), defaultSigner), proposer)
+	c.storeBacklog(message.NewPrevote(3, 1, common.Hash{}, defaultSigner), voter)
+	c.storeBacklog(message.NewPrecommit(4, 1, common.Hash{}, defaultSigner), voter)
+
+	require.Equal(t, map[int64]int{3: 2, 4: 1}, c.BacklogSummary())
+	require.Equal(t, map[string]int{"proposal": 1, "prevote": 1, "precommit": 1}, c.BacklogTypeSummary())
+
+	// an old round proposal is kept unverified until it gets committed, unlike the one handled in its round
+	c.messages.GetOrCreate(0).SetProposal(message.NewPropose(0, 1, -1, newBlock(1), defaultSigner), true)
+	c.messages.GetOrCreate(1).SetProposal(message.NewPropose(1, 1, -1, newBlock(2), defaultSigner), false)
+	// so are the proposals waiting for their timestamp or for their verification to be retried
+	p := &Proposer{c}
+	p.scheduleFutureProposal(message.NewPropose(2, 1, -1, newBlock(3), defaultSigner), time.Minute)
+	c.SetProposalVerificationRetries(1, time.Second)
+	require.True(t, p.scheduleVerificationRetry(message.NewPropose(2, 1, -1, newBlock(4), defaultSigner), consensus.ErrPrunedAncestor))
+
+	require.Equal(t, map[int64]int{1: 1, 2: 2, 3: 2, 4: 1}, c.BacklogSummary())
+	require.Equal(t, map[string]int{"proposal": 4, "prevote": 1, "precommit": 1}, c.BacklogTypeSummary())
+
+	p.StopFutureProposalTimer()
+	c.stopVerificationRetries()
+	require.Equal(t, map[int64]int{1: 1, 3: 2, 4: 1}, c.BacklogSummary())
+}
+
 func TestProcessBacklog(t *testing.T) {
 	t.Run("valid proposal received", func(t *testing.T) {
 
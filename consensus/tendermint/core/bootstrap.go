@@ -0,0 +1,38 @@
+package core
+
+import (
+	"time"
+
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+)
+
+// bootstrapWait returns how long the first height still has to wait for the genesis time. A fresh network may be
+// launched with a genesis timestamp in the future while the first block must be timestamped after it, the proposals
+// are then future ones until that time. Without waiting, the rounds of the first height would time out one after the
+// other until the genesis time.
+func (c *Core) bootstrapWait() time.Duration {
+	if height := c.Height(); height == nil || height.Uint64() != 1 {
+		return 0
+	}
+	genesis := c.LastHeader()
+	if genesis == nil {
+		return 0
+	}
+	wait := time.Unix(int64(genesis.Time)+1, 0).Sub(c.Clock().Now())
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// checkGenesisParent refuses a proposal of the first height which doesn't build on our genesis block. The nodes of
+// the committee then disagree on the genesis, which is a configuration issue rather than a faulty proposer.
+func (c *Core) checkGenesisParent(proposal *message.Propose) error {
+	genesis := c.LastHeader()
+	if proposal.H() != 1 || genesis == nil || proposal.Block().ParentHash() == genesis.Hash() {
+		return nil
+	}
+	c.logger.Error("Proposal not built on our genesis block, check the genesis configuration", "proposer", proposal.Sender(), "parent", proposal.Block().ParentHash(), "genesis", genesis.Hash())
+	return constants.ErrGenesisMismatch
+}
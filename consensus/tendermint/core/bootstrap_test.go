@@ -0,0 +1,109 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus"
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/log"
+)
+
+func TestFirstHeightBootstrap(t *testing.T) {
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	proposer := committeeSet.GetProposer(0).Address
+	var me common.Address
+	for _, member := range committeeSet.Committee() {
+		if member.Address != proposer {
+			me = member.Address
+			break
+		}
+	}
+
+	// a fresh network launched with a genesis time ahead of the clock
+	newCore := func(backend interfaces.Backend) (*Core, *fakeClock, *types.Header) {
+		clock := newFakeClock()
+		genesis := &types.Header{Number: common.Big0, Time: uint64(clock.Now().Add(10 * time.Second).Unix())}
+		messages := message.NewMap()
+		c := &Core{
+			address:          me,
+			backend:          backend,
+			messages:         messages,
+			curRoundMessages: messages.GetOrCreate(0),
+			logger:           log.Root(),
+			height:           common.Big1,
+			lastHeader:       genesis,
+			lockedRound:      -1,
+			validRound:       -1,
+			proposeTimeout:   NewTimeout(Propose, log.Root()),
+			committee:        committeeSet,
+		}
+		c.SetClock(clock)
+		c.SetDefaultHandlers()
+		return c, clock, genesis
+	}
+
+	t.Run("propose timeout covers the wait for the genesis time", func(t *testing.T) {
+		c, clock, _ := newCore(interfaces.NewMockBackend(gomock.NewController(t)))
+		require.Equal(t, 11*time.Second, c.bootstrapWait())
+		require.Equal(t, InitialProposeTimeout+11*time.Second, c.timeoutPropose(0))
+
+		clock.Advance(20 * time.Second)
+		require.Equal(t, time.Duration(0), c.bootstrapWait())
+		require.Equal(t, InitialProposeTimeout, c.timeoutPropose(0))
+
+		// the later heights never wait
+		c.height = big.NewInt(2)
+		c.lastHeader = &types.Header{Number: common.Big1, Time: uint64(clock.Now().Add(time.Hour).Unix())}
+		require.Equal(t, time.Duration(0), c.bootstrapWait())
+	})
+
+	t.Run("first proposal handled and committed", func(t *testing.T) {
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		c, clock, genesis := newCore(backendMock)
+		block := types.NewBlockWithHeader(&types.Header{Number: common.Big1, ParentHash: genesis.Hash(), Time: genesis.Time + 1})
+		proposal := message.NewPropose(0, 1, -1, block, makeSigner(keys[proposer], proposer)).MustVerify(stubVerifier)
+		for _, member := range committeeSet.Committee()[:3] {
+			c.curRoundMessages.AddPrecommit(message.NewPrecommit(0, 1, block.Hash(), makeSigner(keys[member.Address], member.Address)).MustVerify(stubVerifier))
+		}
+
+		// the first block is timestamped after the genesis, it is a future one until then
+		wait := time.Unix(int64(block.Time()), 0).Sub(clock.Now())
+		backendMock.EXPECT().VerifyProposal(block).Return(wait, consensus.ErrFutureTimestampBlock)
+		require.ErrorIs(t, c.proposer.HandleProposal(context.Background(), proposal), consensus.ErrFutureTimestampBlock)
+
+		var event futureProposalEvent
+		backendMock.EXPECT().Post(gomock.Any()).Do(func(ev any) { event = ev.(futureProposalEvent) })
+		clock.Advance(wait)
+		require.Equal(t, proposal, event.proposal)
+
+		backendMock.EXPECT().VerifyProposal(block).Return(time.Duration(0), nil)
+		backendMock.EXPECT().Commit(block, int64(0), gomock.Any()).Return(nil)
+		backendMock.EXPECT().Gossip(gomock.Any(), proposal)
+		c.handleFutureProposal(context.Background(), event)
+		require.Equal(t, PrecommitDone, c.step)
+	})
+
+	t.Run("proposal on another genesis refused", func(t *testing.T) {
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		c, _, _ := newCore(backendMock)
+		block := types.NewBlockWithHeader(&types.Header{Number: common.Big1, ParentHash: common.HexToHash("0x01")})
+		proposal := message.NewPropose(0, 1, -1, block, makeSigner(keys[proposer], proposer)).MustVerify(stubVerifier)
+
+		// nil prevoted without verification
+		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(keys[me], me))
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any())
+		err := c.proposer.HandleProposal(context.Background(), proposal)
+		require.ErrorIs(t, err, constants.ErrGenesisMismatch)
+		require.Equal(t, Prevote, c.step)
+	})
+}
@@ -0,0 +1,34 @@
+package core
+
+import "time"
+
+// Clock abstracts the time source used by the consensus engine, so that time dependent
+// behaviours such as timeouts and future proposals can be driven deterministically in tests.
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a scheduled function execution created by a Clock.
+type Timer interface {
+	Stop() bool
+}
+
+// realClock is the default Clock, backed by the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// orRealClock returns the given clock, or the real clock if nil.
+func orRealClock(clock Clock) Clock {
+	if clock == nil {
+		return realClock{}
+	}
+	return clock
+}
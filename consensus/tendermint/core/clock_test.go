@@ -0,0 +1,195 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/autonity/autonity/consensus"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/log"
+)
+
+// fakeClock is a Clock whose time only moves forward when advanced, firing the due timers synchronously.
+type fakeClock struct {
+	sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock *fakeClock
+	at    time.Time
+	f     func()
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(1_000_000, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.Lock()
+	defer c.Unlock()
+	t := &fakeTimer{clock: c, at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.Lock()
+	c.now = c.now.Add(d)
+	var due, pending []*fakeTimer
+	for _, t := range c.timers {
+		if !t.at.After(c.now) {
+			due = append(due, t)
+		} else {
+			pending = append(pending, t)
+		}
+	}
+	c.timers = pending
+	c.Unlock()
+	for _, t := range due {
+		t.f()
+	}
+}
+
+// hasTimers returns true if some timers are pending.
+func (c *fakeClock) hasTimers() bool {
+	c.Lock()
+	defer c.Unlock()
+	return len(c.timers) > 0
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.Lock()
+	defer t.clock.Unlock()
+	for i, pending := range t.clock.timers {
+		if pending == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func TestFakeClockFutureProposal(t *testing.T) {
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	addr := committeeSet.Committee()[0].Address // round 3 - height 1 proposer
+	height := uint64(1)
+	round := int64(3)
+	signer := makeSigner(keys[addr], addr)
+	const delay = time.Minute
+
+	newCore := func(backend interfaces.Backend, clock Clock) (*Core, *message.Propose) {
+		block := types.NewBlockWithHeader(&types.Header{
+			Number: new(big.Int).SetUint64(height),
+		})
+		messageMap := message.NewMap()
+		curRoundMessages := messageMap.GetOrCreate(round)
+		c := &Core{
+			address:          addr,
+			backend:          backend,
+			messages:         messageMap,
+			curRoundMessages: curRoundMessages,
+			logger:           log.Root(),
+			proposeTimeout:   NewTimeout(Propose, log.Root()),
+			committee:        committeeSet,
+			round:            round,
+			height:           new(big.Int).SetUint64(height),
+		}
+		c.SetClock(clock)
+		c.SetDefaultHandlers()
+		return c, message.NewPropose(round, height, 1, block, signer).MustVerify(stubVerifier)
+	}
+
+	t.Run("future proposal event posted once the clock reaches the proposal time", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		clock := newFakeClock()
+		backendMock := interfaces.NewMockBackend(ctrl)
+		c, proposal := newCore(backendMock, clock)
+		backendMock.EXPECT().VerifyProposal(gomock.Any()).Return(delay, consensus.ErrFutureTimestampBlock)
+
+		require.ErrorIs(t, c.proposer.HandleProposal(context.Background(), proposal), consensus.ErrFutureTimestampBlock)
+
+		// strict mock, any early post fails the test
+		clock.Advance(delay - time.Second)
+		backendMock.EXPECT().Post(futureProposalEvent{proposal: proposal, generation: 1}).Times(1)
+		clock.Advance(time.Second)
+	})
+
+	t.Run("stopped future proposal timer never fires", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		clock := newFakeClock()
+		backendMock := interfaces.NewMockBackend(ctrl)
+		c, proposal := newCore(backendMock, clock)
+		backendMock.EXPECT().VerifyProposal(gomock.Any()).Return(delay, consensus.ErrFutureTimestampBlock)
+
+		require.ErrorIs(t, c.proposer.HandleProposal(context.Background(), proposal), consensus.ErrFutureTimestampBlock)
+		c.proposer.StopFutureProposalTimer()
+		clock.Advance(2 * delay)
+	})
+
+	t.Run("event of a timer stopped while firing is dropped", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		clock := newFakeClock()
+		backendMock := interfaces.NewMockBackend(ctrl)
+		c, proposal := newCore(backendMock, clock)
+		backendMock.EXPECT().VerifyProposal(gomock.Any()).Return(delay, consensus.ErrFutureTimestampBlock)
+		require.ErrorIs(t, c.proposer.HandleProposal(context.Background(), proposal), consensus.ErrFutureTimestampBlock)
+
+		// the stop races the callback, whichever wins the proposal isn't handled again
+		var (
+			mu     sync.Mutex
+			posted []futureProposalEvent
+		)
+		backendMock.EXPECT().Post(gomock.Any()).MaxTimes(1).Do(func(ev any) {
+			mu.Lock()
+			defer mu.Unlock()
+			posted = append(posted, ev.(futureProposalEvent))
+		})
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			clock.Advance(delay)
+		}()
+		go func() {
+			defer wg.Done()
+			c.proposer.StopFutureProposalTimer()
+		}()
+		wg.Wait()
+
+		// strict mock, handling the proposal again would verify and gossip it
+		for _, ev := range posted {
+			c.handleFutureProposal(context.Background(), ev)
+		}
+	})
+}
+
+func TestFakeClockTimeout(t *testing.T) {
+	clock := newFakeClock()
+	tm := NewTimeout(Propose, log.Root())
+	tm.Clock = clock
+
+	fired := false
+	tm.ScheduleTimeout(time.Second, 0, big.NewInt(1), func(_ int64, _ *big.Int) { fired = true })
+	require.Equal(t, clock.Now(), tm.Start)
+
+	clock.Advance(time.Second - time.Nanosecond)
+	require.False(t, fired)
+	clock.Advance(time.Nanosecond)
+	require.True(t, fired)
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"sync"
+
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/event"
+)
+
+// commitFeed delivers the committed blocks with their round metadata. As for the proposal outcomes, a delivery never
+// blocks the consensus loop: a subscriber which isn't ready to receive misses the commit.
+type commitFeed struct {
+	mu          sync.Mutex
+	subscribers []chan<- interfaces.CommitInfo
+}
+
+func (f *commitFeed) subscribe(ch chan<- interfaces.CommitInfo) event.Subscription {
+	f.mu.Lock()
+	f.subscribers = append(f.subscribers, ch)
+	f.mu.Unlock()
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		for i, sub := range f.subscribers {
+			if sub == ch {
+				f.subscribers = append(f.subscribers[:i], f.subscribers[i+1:]...)
+				break
+			}
+		}
+		return nil
+	})
+}
+
+func (f *commitFeed) send(info interfaces.CommitInfo) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, ch := range f.subscribers {
+		select {
+		case ch <- info:
+		default:
+			CommitDroppedMeter.Mark(1)
+		}
+	}
+}
+
+// SubscribeCommit registers a subscription notified of every block committed by the engine, with the round
+// metadata not available from the chain head events. The commits are dropped for a subscriber whose channel is full,
+// it should be buffered.
+func (c *Core) SubscribeCommit(ch chan<- interfaces.CommitInfo) event.Subscription {
+	return c.commits.subscribe(ch)
+}
@@ -0,0 +1,37 @@
+package core
+
+import (
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/types"
+)
+
+// committedCacheHeights is the number of most recent heights whose committed block is remembered to drop the
+// replayed proposals.
+const committedCacheHeights = 16
+
+// rememberCommitted records the block committed at its height and forgets the ones too old to be replayed
+// realistically, it must be called from the main loop.
+func (c *Core) rememberCommitted(block *types.Block) {
+	if c.committed == nil {
+		c.committed = make(map[uint64]common.Hash)
+	}
+	number := block.NumberU64()
+	c.committed[number] = block.Hash()
+	for h := range c.committed {
+		if h+committedCacheHeights <= number {
+			delete(c.committed, h)
+		}
+	}
+}
+
+// isCommittedProposal returns true if the message is a proposal for a block already committed at its height,
+// typically delayed by the network. Such messages are dropped before reaching the round messages.
+func (c *Core) isCommittedProposal(msg message.Msg) bool {
+	proposal, ok := msg.(*message.Propose)
+	if !ok {
+		return false
+	}
+	hash, ok := c.committed[proposal.H()]
+	return ok && proposal.Block() != nil && hash == proposal.Block().Hash()
+}
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/log"
+)
+
+func TestCommittedProposalDropped(t *testing.T) {
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	proposer := committeeSet.Committee()[0].Address
+	signer := makeSigner(keys[proposer], proposer)
+	committedBlock := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(5)})
+
+	newCore := func(t *testing.T) *Core {
+		// strict backend and no message store, any interaction fails the test
+		c := &Core{
+			backend:   interfaces.NewMockBackend(gomock.NewController(t)),
+			logger:    log.Root(),
+			committee: committeeSet,
+			height:    big.NewInt(6),
+		}
+		c.SetDefaultHandlers()
+		c.rememberCommitted(committedBlock)
+		return c
+	}
+
+	t.Run("replayed proposal for a committed block dropped", func(t *testing.T) {
+		c := newCore(t)
+		proposal := message.NewPropose(0, 5, -1, committedBlock, signer).MustVerify(stubVerifier)
+		require.ErrorIs(t, c.handleMsg(context.Background(), proposal), constants.ErrCommittedProposal)
+		require.ErrorIs(t, c.handleValidMsg(context.Background(), proposal), constants.ErrCommittedProposal)
+		require.False(t, shouldDisconnectSender(constants.ErrCommittedProposal))
+	})
+
+	t.Run("other proposal at a committed height goes through the regular checks", func(t *testing.T) {
+		c := newCore(t)
+		other := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(5), GasLimit: 1})
+		proposal := message.NewPropose(0, 5, -1, other, signer).MustVerify(stubVerifier)
+		require.ErrorIs(t, c.handleMsg(context.Background(), proposal), constants.ErrOldHeightMessage)
+	})
+
+	t.Run("old heights pruned", func(t *testing.T) {
+		c := newCore(t)
+		for n := int64(6); n < 6+committedCacheHeights; n++ {
+			c.rememberCommitted(types.NewBlockWithHeader(&types.Header{Number: big.NewInt(n)}))
+		}
+		require.Len(t, c.committed, committedCacheHeights)
+		require.NotContains(t, c.committed, uint64(5))
+		proposal := message.NewPropose(0, 5, -1, committedBlock, signer).MustVerify(stubVerifier)
+		require.False(t, c.isCommittedProposal(proposal))
+	})
+}
@@ -22,6 +22,7 @@ type RoundRobinCommittee struct {
 	members           types.Committee
 	lastBlockProposer common.Address
 	totalPower        *big.Int
+	quorum            *big.Int                        // cached as the members never change
 	allProposers      map[int64]types.CommitteeMember // cached computed values
 	roundRobinOffset  int64
 	mu                sync.RWMutex // members doesn't need to be protected as it is read-only
@@ -41,7 +42,7 @@ func NewRoundRobinSet(members types.Committee, lastBlockProposer common.Address)
 		allProposers:      make(map[int64]types.CommitteeMember),
 	}
 
-	// sort validator
+	// sort validator, by address so that members of equal power are ordered the same way on every node
 	sort.Sort(committee.members)
 
 	// calculate total power
@@ -49,6 +50,8 @@ func NewRoundRobinSet(members types.Committee, lastBlockProposer common.Address)
 		committee.totalPower.Add(committee.totalPower, m.VotingPower)
 	}
 
+	committee.quorum = bft.Quorum(committee.totalPower)
+
 	// calculate offset for round robin selection of next proposer
 	committee.roundRobinOffset = getMemberIndex(committee.members, lastBlockProposer)
 	if len(members) > 1 {
@@ -103,7 +106,7 @@ func (set *RoundRobinCommittee) SetLastHeader(_ *types.Header) {
 }
 
 func (set *RoundRobinCommittee) Quorum() *big.Int {
-	return bft.Quorum(set.totalPower)
+	return set.quorum
 }
 
 func (set *RoundRobinCommittee) F() *big.Int {
@@ -135,15 +138,18 @@ type WeightedRandomSamplingCommittee struct {
 	autonityContract       *autonity.ProtocolContracts
 	previousBlockStateRoot common.Hash
 	cachedProposer         map[int64]types.CommitteeMember
+	quorum                 *big.Int // cached for the committee of previousHeader
 }
 
 func NewWeightedRandomSamplingCommittee(previousBlock *types.Block, autonityContract *autonity.ProtocolContracts, bc *ethcore.BlockChain) *WeightedRandomSamplingCommittee {
+	header := previousBlock.Header()
 	return &WeightedRandomSamplingCommittee{
-		previousHeader:         previousBlock.Header(),
+		previousHeader:         header,
 		bc:                     bc,
 		autonityContract:       autonityContract,
 		previousBlockStateRoot: previousBlock.Root(),
 		cachedProposer:         make(map[int64]types.CommitteeMember),
+		quorum:                 bft.Quorum(header.TotalVotingPower()),
 	}
 }
 
@@ -156,6 +162,7 @@ func (w *WeightedRandomSamplingCommittee) SetLastHeader(header *types.Header) {
 	w.previousHeader = header
 	w.previousBlockStateRoot = header.Root
 	w.cachedProposer = make(map[int64]types.CommitteeMember)
+	w.quorum = bft.Quorum(header.TotalVotingPower())
 }
 
 // Get validator by index
@@ -202,7 +209,7 @@ func (w *WeightedRandomSamplingCommittee) GetProposer(round int64) types.Committ
 
 // Get the optimal quorum size
 func (w *WeightedRandomSamplingCommittee) Quorum() *big.Int {
-	return bft.Quorum(w.previousHeader.TotalVotingPower())
+	return w.quorum
 }
 
 func (w *WeightedRandomSamplingCommittee) F() *big.Int {
@@ -14,6 +14,7 @@ import (
 
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/consensus"
+	"github.com/autonity/autonity/consensus/tendermint/bft"
 	"github.com/autonity/autonity/core/types"
 	"github.com/autonity/autonity/crypto"
 )
@@ -300,6 +301,48 @@ func TestSet_QandF(t *testing.T) {
 
 }
 
+func TestQuorumCache(t *testing.T) {
+	t.Run("round robin committee", func(t *testing.T) {
+		members := createTestCommitteeMembers(t, 7, 100)
+		set, err := NewRoundRobinSet(members, members[0].Address)
+		assertNilError(t, err)
+
+		require.Equal(t, bft.Quorum(members.TotalVotingPower()), set.Quorum())
+		require.Same(t, set.Quorum(), set.Quorum())
+	})
+
+	t.Run("weighted random sampling committee refreshed on committee change", func(t *testing.T) {
+		header := &types.Header{Number: big.NewInt(1), Committee: createTestCommitteeMembers(t, 7, 100)}
+		set := NewWeightedRandomSamplingCommittee(types.NewBlockWithHeader(header), nil, nil)
+		require.Equal(t, bft.Quorum(header.TotalVotingPower()), set.Quorum())
+		require.Same(t, set.Quorum(), set.Quorum())
+
+		next := &types.Header{Number: big.NewInt(2), Committee: createTestCommitteeMembers(t, 10, 1000)}
+		set.SetLastHeader(next)
+		require.Equal(t, bft.Quorum(next.TotalVotingPower()), set.Quorum())
+		require.Equal(t, uint64(667), set.Quorum().Uint64())
+	})
+}
+
+func BenchmarkQuorum(b *testing.B) {
+	members := createTestCommitteeMembers(b, maxSize, 1_000_000)
+	set, err := NewRoundRobinSet(members, members[0].Address)
+	require.NoError(b, err)
+
+	b.Run("recomputed", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			bft.Quorum(members.TotalVotingPower())
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			set.Quorum()
+		}
+	})
+}
+
 func assertNilError(t *testing.T, got error) {
 	t.Helper()
 	if got != nil {
@@ -315,7 +358,7 @@ func assertError(t *testing.T, want, got error) {
 }
 
 // totalPower >= n
-func createTestCommitteeMembers(t *testing.T, n, totalPower int64) types.Committee {
+func createTestCommitteeMembers(t testing.TB, n, totalPower int64) types.Committee {
 	t.Helper()
 	var committee types.Committee
 
@@ -365,3 +408,21 @@ func getTotalPowerDistribution(p, n int64) (int64, int64) {
 func genRandUint64(min, max int) int64 {
 	return int64(rand.Intn(max-min+1) + min)
 }
+
+func TestSet_GetProposerEqualPower(t *testing.T) {
+	// every member has the same power, each node may learn them in a different order
+	members := createTestCommitteeMembers(t, 7, 7)
+	lastBlockProposer := members[3].Address
+	reference, err := NewRoundRobinSet(copyMembers(members), lastBlockProposer)
+	require.NoError(t, err)
+
+	for node := 0; node < 10; node++ {
+		shuffled := copyMembers(members)
+		rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		set, err := NewRoundRobinSet(shuffled, lastBlockProposer)
+		require.NoError(t, err)
+		for round := int64(0); round < 3*int64(len(members)); round++ {
+			require.Equal(t, reference.GetProposer(round), set.GetProposer(round), "proposer mismatch at round %d", round)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"math/big"
+
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
+	"github.com/autonity/autonity/core/types"
+)
+
+// pinnedCommittee is the committee taken at the start of the current height. The committee can't change within a
+// height, a change means a bug, e.g. at an epoch boundary, and the quorum would be computed against the wrong set.
+type pinnedCommittee struct {
+	members  types.Committee
+	quorum   *big.Int
+	reported bool // whether the change was already reported at this height
+	// checked is the version of the committee set last compared to the pinned one, and changed the outcome. The
+	// comparison is only done again once the set is replaced, not for every message.
+	checked uint64
+	changed bool
+}
+
+// pinCommittee takes the committee of the height starting.
+func (c *Core) pinCommittee() {
+	if c.committee == nil {
+		c.pinned = nil
+		return
+	}
+	members := c.committee.Committee()
+	pinned := &pinnedCommittee{
+		members: make(types.Committee, len(members)),
+		quorum:  new(big.Int).Set(c.committee.Quorum()),
+		checked: c.committeeVersion.Load(),
+	}
+	for i, member := range members {
+		pinned.members[i] = types.CommitteeMember{Address: member.Address, VotingPower: new(big.Int).Set(member.VotingPower)}
+	}
+	c.pinned = pinned
+}
+
+// checkPinnedCommittee returns an error if the committee differs from the one pinned at the start of the height,
+// nothing must be done with the changed set. The change is logged and metered once per height.
+func (c *Core) checkPinnedCommittee() error {
+	if c.pinned == nil || c.committee == nil {
+		return nil
+	}
+	if version := c.committeeVersion.Load(); version != c.pinned.checked {
+		c.pinned.checked = version
+		c.pinned.changed = !c.pinned.matches(c.committee.Committee(), c.committee.Quorum())
+	}
+	if !c.pinned.changed {
+		return nil
+	}
+	if !c.pinned.reported {
+		c.pinned.reported = true
+		CommitteeChangeMeter.Mark(1)
+		c.logger.Error("Committee changed within the height, refusing to act on it",
+			"height", c.Height(),
+			"round", c.Round(),
+			"pinned", len(c.pinned.members),
+			"pinnedQuorum", c.pinned.quorum,
+			"current", len(c.committee.Committee()),
+			"currentQuorum", c.committee.Quorum(),
+		)
+	}
+	return constants.ErrCommitteeChanged
+}
+
+func (p *pinnedCommittee) matches(members types.Committee, quorum *big.Int) bool {
+	if len(members) != len(p.members) || quorum.Cmp(p.quorum) != 0 {
+		return false
+	}
+	for i, member := range members {
+		if member.Address != p.members[i].Address || member.VotingPower.Cmp(p.members[i].VotingPower) != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -12,6 +12,8 @@ var (
 	// ErrOldHeightMessage is returned when the received message's view is earlier
 	// than curRoundMessages view.
 	ErrOldHeightMessage = errors.New("old height message")
+	// ErrCommittedProposal is returned when the proposal of a block already committed at its height is received.
+	ErrCommittedProposal = errors.New("proposal for an already committed block")
 	// ErrOldRoundMessage message is returned when message is of the same Height but form a smaller round
 	ErrOldRoundMessage = errors.New("same height but old round message")
 	// ErrFutureRoundMessage message is returned when message is of the same Height but form a newer round
@@ -37,4 +39,42 @@ var (
 	ErrNilPrecommitSent = errors.New("timer expired and nil precommit sent")
 	// ErrMovedToNewRound is returned when timer could be stopped in time
 	ErrMovedToNewRound = errors.New("timer expired and new round started")
+	// ErrProposalVerificationTimeout is returned when the proposal verification didn't complete in time.
+	ErrProposalVerificationTimeout = errors.New("proposal verification timed out")
+	// ErrProposalVerificationRetry is returned when a proposal which failed the verification is scheduled to be
+	// verified again.
+	ErrProposalVerificationRetry = errors.New("proposal verification to be retried")
+	// ErrBlacklistedProposer is returned when a proposal is skipped because its proposer already sent
+	// too many invalid proposals at the current height.
+	ErrBlacklistedProposer = errors.New("proposer blacklisted for sending invalid proposals")
+	// ErrFarFutureRoundProposal is returned when a proposal is dropped for a round too far ahead of the current one.
+	ErrFarFutureRoundProposal = errors.New("proposal round too far ahead")
+	// ErrProposalDeferredSyncing is returned when a proposal is deferred because the node is syncing the chain.
+	ErrProposalDeferredSyncing = errors.New("proposal deferred while syncing")
+	// ErrInvalidValidRound is returned when the valid round of a proposal is neither nil (-1) nor a round before
+	// the proposal one.
+	ErrInvalidValidRound = errors.New("proposal valid round not before its round")
+	// ErrNilProposalBlock is returned when a proposal carries no block.
+	ErrNilProposalBlock = errors.New("proposal without block")
+	// ErrInvalidTimeoutConfig is returned when the configured step timeouts are not sane.
+	ErrInvalidTimeoutConfig = errors.New("invalid timeout configuration")
+	// ErrCommitteeChanged is returned when the committee changed since the start of the current height, the
+	// messages are then refused until the next height.
+	ErrCommitteeChanged = errors.New("committee changed within the height")
+	// ErrGenesisMismatch is returned when a proposal of the first height doesn't build on our genesis block.
+	ErrGenesisMismatch = errors.New("proposal not built on our genesis block")
+	// ErrInvalidMinParticipation is returned when the minimum participation isn't a percentage.
+	ErrInvalidMinParticipation = errors.New("invalid minimum participation")
+	// ErrNonCanonicalParent is returned when a proposal doesn't build on our canonical block at the previous height.
+	ErrNonCanonicalParent = errors.New("proposal not built on our canonical chain")
+	// ErrProposalSigningTimeout is returned when the proposal signer didn't sign our proposal in time.
+	ErrProposalSigningTimeout = errors.New("proposal signing timed out")
+	// ErrProposalSignerMismatch is returned when the proposal signer signed with another key than the node's one.
+	ErrProposalSignerMismatch = errors.New("proposal signed by another address")
+	// ErrForceCommitRefused is returned when a forced commit is refused as it isn't backed by a quorum of precommits
+	// for a verified proposal of the current height.
+	ErrForceCommitRefused = errors.New("force commit refused")
+	// ErrProposalViewOutOfRange is returned when a proposal round is beyond the maximum round or its height isn't
+	// adjacent to the current one.
+	ErrProposalViewOutOfRange = errors.New("proposal height or round out of range")
 )
@@ -0,0 +1,68 @@
+package constants
+
+import "errors"
+
+// ProposalErrorKind classifies the failures to handle a proposal.
+type ProposalErrorKind uint8
+
+const (
+	// NotProposer is for proposals not sent by the proposer of their round.
+	NotProposer ProposalErrorKind = iota + 1
+	// OldRound is for proposals of a past round of the current height.
+	OldRound
+	// FutureTimestamp is for proposals whose block timestamp is ahead of the local clock.
+	FutureTimestamp
+	// VerificationFailed is for proposals whose block failed the verification.
+	VerificationFailed
+	// InvalidValidRound is for proposals whose valid round is not a round before their own.
+	InvalidValidRound
+	// NilBlock is for proposals carrying no block.
+	NilBlock
+)
+
+func (k ProposalErrorKind) String() string {
+	switch k {
+	case NotProposer:
+		return "not proposer"
+	case OldRound:
+		return "old round"
+	case FutureTimestamp:
+		return "future timestamp"
+	case VerificationFailed:
+		return "verification failed"
+	case InvalidValidRound:
+		return "invalid valid round"
+	case NilBlock:
+		return "nil block"
+	default:
+		return "unknown"
+	}
+}
+
+// ProposalError is returned when a proposal couldn't be handled, it wraps the underlying error so that
+// errors.Is keeps matching it.
+type ProposalError struct {
+	Kind ProposalErrorKind
+	Err  error
+}
+
+func NewProposalError(kind ProposalErrorKind, err error) *ProposalError {
+	return &ProposalError{Kind: kind, Err: err}
+}
+
+func (e *ProposalError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ProposalError) Unwrap() error {
+	return e.Err
+}
+
+// ProposalErrorKindOf returns the kind of the proposal error in the chain of err, if any.
+func ProposalErrorKindOf(err error) (ProposalErrorKind, bool) {
+	var proposalErr *ProposalError
+	if errors.As(err, &proposalErr) {
+		return proposalErr.Kind, true
+	}
+	return 0, false
+}
@@ -2,8 +2,10 @@ package core
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/autonity/autonity/autonity"
@@ -42,7 +44,18 @@ func New(backend interfaces.Backend, services *interfaces.Services) *Core {
 		newHeight:              time.Now(),
 		newRound:               time.Now(),
 		stepChange:             time.Now(),
+
+		proposalVerificationTimeout:    DefaultProposalVerificationTimeout,
+		proposalVerificationRetries:    DefaultProposalVerificationRetries,
+		proposalVerificationRetryDelay: DefaultProposalVerificationRetryDelay,
+		proposalBaseFeeRetries:         DefaultProposalBaseFeeRetries,
+		proposerBlacklistThreshold:     DefaultProposerBlacklistThreshold,
+		maxProposalRoundsAhead:         DefaultMaxProposalRoundsAhead,
+		resyncThreshold:                DefaultResyncThreshold,
+		proposalSigningTimeout:         DefaultProposalSigningTimeout,
+		invalidProposals:               make(map[common.Address]int),
 	}
+	c.proposalCircuitBreakerThreshold.Store(DefaultProposalCircuitBreakerThreshold)
 	c.SetDefaultHandlers()
 	if services != nil {
 		c.broadcaster = services.Broadcaster(c)
@@ -73,8 +86,17 @@ type Core struct {
 	committedSub        *event.TypeMuxSubscription
 	timeoutEventSub     *event.TypeMuxSubscription
 	syncEventSub        *event.TypeMuxSubscription
-	futureProposalTimer *time.Timer
-	stopped             chan struct{}
+	futureProposalTimer Timer
+	futureProposal      *message.Propose // the proposal futureProposalTimer hands back to the main loop
+	// futureProposalGeneration is bumped when the future proposal timer stops, it is accessed atomically.
+	futureProposalGeneration uint64
+	// proposalBroadcastTimer delays the broadcast of our proposal, see SetProposalBroadcastJitter.
+	proposalBroadcastTimer  Timer
+	proposalBroadcastJitter time.Duration
+	stopped                 chan struct{}
+
+	// clock is the time source of the engine, the real clock is used if nil.
+	clock Clock
 
 	backlogs             map[common.Address][]message.Msg
 	backlogUntrusted     map[uint64][]message.Msg
@@ -91,7 +113,9 @@ type Core struct {
 	round      int64
 	committee  interfaces.Committee
 	lastHeader *types.Header
-	// height, round, committeeSet and lastHeader are the ONLY guarded fields.
+	// proposalMu guards sentProposal, SendProposal may be called outside the main thread.
+	proposalMu sync.Mutex
+	// height, round, committeeSet and lastHeader are guarded by stateMu, sentProposal by proposalMu.
 	// everything else MUST be accessed only by the main thread.
 	step                  Step
 	stepChange            time.Time
@@ -110,6 +134,107 @@ type Core struct {
 	proposeTimeout   *Timeout
 	prevoteTimeout   *Timeout
 	precommitTimeout *Timeout
+	// timeouts configures the step timeout durations, the defaults are used if nil.
+	timeouts *TimeoutConfig
+	// timeoutsReloadSequence is bumped by every runtime reload of the timeouts, see ReloadTimeouts.
+	timeoutsReloadSequence atomic.Uint64
+	// roundTimeoutStrategy defines how the step timeouts widen with the rounds, linearly by default.
+	roundTimeoutStrategy RoundTimeoutStrategy
+
+	// proposalVerificationTimeout bounds the time spent verifying a single proposal, zero disables it.
+	proposalVerificationTimeout time.Duration
+	// abandonedVerifications holds the verifications left running after timing out, by block hash, each closed once
+	// it completes. The same block isn't verified again meanwhile so that it doesn't pile up concurrent executions.
+	abandonedVerifications map[common.Hash]chan struct{}
+
+	// verifications failing because of a momentarily unavailable state are retried up to
+	// proposalVerificationRetries times, zero disables the retries.
+	proposalVerificationRetries    int
+	proposalVerificationRetryDelay time.Duration
+	// a failed verification is attempted once more after proposalVerificationGrace before prevoting nil, zero
+	// disables the grace period.
+	proposalVerificationGrace time.Duration
+	// a verification failing on the base fee alone is attempted again up to proposalBaseFeeRetries times, giving a
+	// head transition the time to complete, zero disables the retries.
+	proposalBaseFeeRetries int
+	// verificationRetries accounts for the attempts of the proposals verified again at the current height, by block
+	// and round. The retries are timer driven not to hold the main loop.
+	verificationRetries         map[retryKey]*verificationRetry
+	verificationRetryGeneration uint64
+
+	// proposalPrefetch warms the state caches for the execution of the proposals while they are verified.
+	proposalPrefetch bool
+
+	// proposalSigner signs our proposals in place of the backend if set, bounded by proposalSigningTimeout, zero
+	// disabling the timeout.
+	proposalSigner         ProposalSigner
+	proposalSigningTimeout time.Duration
+
+	// proposals for a round more than maxProposalRoundsAhead rounds ahead of the current one are dropped instead of
+	// being backlogged, zero disables the ceiling.
+	maxProposalRoundsAhead int64
+
+	// proposing is halted after proposalCircuitBreakerThreshold consecutive failures to verify our own proposals,
+	// zero disables the circuit breaker. The breaker is configured and reset outside of the consensus goroutine.
+	proposalCircuitBreakerThreshold atomic.Int64
+	selfProposalFailures            atomic.Int64
+	proposingHalted                 atomic.Bool
+
+	// proposals from members which sent proposerBlacklistThreshold invalid proposals at the current height are
+	// nil prevoted without verification, zero disables the blacklist.
+	proposerBlacklistThreshold int
+	invalidProposals           map[common.Address]int
+
+	// a resync is requested once resyncThreshold rounds of the current height received a proposal without
+	// committing, zero disables the resync. See resync.go.
+	resyncThreshold int64
+	stalledRounds   int64
+
+	// a block is committed once the precommits of its round, for any value or nil, reach minParticipation percent
+	// of the total voting power, zero disables it. See participation.go.
+	minParticipation uint64
+
+	// firstPrecommits holds when the first precommit of each round of the current height was received.
+	firstPrecommits map[int64]time.Time
+
+	// pinned is the committee taken at the start of the current height, see committee_pin.go.
+	pinned *pinnedCommittee
+	// committeeVersion is bumped whenever the committee set is replaced, the pinned committee is only compared to a
+	// new one.
+	committeeVersion atomic.Uint64
+
+	// verifiedBlocks holds the hashes of the blocks successfully verified at the current height.
+	verifiedBlocks map[common.Hash]struct{}
+
+	// proposers caches the proposers of the first rounds of the current height, see proposer_schedule.go.
+	proposers *proposerSchedule
+
+	// proposerOverrides forces the proposer of the given rounds, it can only be set in builds with the
+	// tendermint_unsafe tag, see proposer_override.go.
+	proposerOverrides map[int64]common.Address
+	// roundTimeoutsDisabled stops the step timeouts from being scheduled, it can only be set in builds with the
+	// tendermint_unsafe tag, see round_timeouts.go.
+	roundTimeoutsDisabled bool
+
+	// committed maps the most recent heights to their committed block hash.
+	committed map[uint64]common.Hash
+	// lastCommit is the unix nano time of the last successful commit, or of the engine start if none. It is read
+	// outside of the consensus goroutine.
+	lastCommit atomic.Int64
+	// absent holds the committee members which didn't vote in the recently committed rounds, see AbsentVoters.
+	absent absentVoters
+	// proposerTimeouts counts the propose timeouts per expected proposer, see ProposerTimeouts.
+	proposerTimeouts proposerTimeouts
+	// commits notifies the subscribers of every block committed by the engine.
+	commits commitFeed
+	// proposalOutcomes notifies the subscribers of the outcome of every proposal handled.
+	proposalOutcomes proposalOutcomeFeed
+	// lockChanges notifies the subscribers of every change of the locked round and value.
+	lockChanges lockChangeFeed
+
+	// while the node syncs the chain, the current height proposals are deferred until the sync completes.
+	syncing      atomic.Bool
+	syncDeferred []*message.Propose
 
 	futureRoundChange map[int64]map[common.Address]*big.Int
 
@@ -149,6 +274,7 @@ func (c *Core) Committee() interfaces.Committee {
 
 func (c *Core) SetCommittee(committee interfaces.Committee) {
 	c.committee = committee
+	c.committeeVersion.Add(1)
 }
 
 func (c *Core) Step() Step {
@@ -164,10 +290,14 @@ func (c *Core) Messages() *message.Map {
 }
 
 func (c *Core) SentProposal() bool {
+	c.proposalMu.Lock()
+	defer c.proposalMu.Unlock()
 	return c.sentProposal
 }
 
 func (c *Core) SetSentProposal(sentProposal bool) {
+	c.proposalMu.Lock()
+	defer c.proposalMu.Unlock()
 	c.sentProposal = sentProposal
 }
 
@@ -239,6 +369,120 @@ func (c *Core) PrecommitTimeout() *Timeout {
 	return c.precommitTimeout
 }
 
+// ProposalVerificationTimeout returns the maximum duration allowed for a proposal verification.
+func (c *Core) ProposalVerificationTimeout() time.Duration {
+	return c.proposalVerificationTimeout
+}
+
+// SetProposalVerificationTimeout sets the maximum duration allowed for a proposal verification.
+// A proposal which is not verified in time is considered invalid. Zero disables the timeout.
+func (c *Core) SetProposalVerificationTimeout(timeout time.Duration) {
+	c.proposalVerificationTimeout = timeout
+}
+
+// SetProposalVerificationRetries sets how many times a proposal verification failing because of a momentarily
+// unavailable state is retried, and the delay in between, before the proposal is considered invalid. Zero disables
+// the retries.
+func (c *Core) SetProposalVerificationRetries(retries int, delay time.Duration) {
+	c.proposalVerificationRetries = retries
+	c.proposalVerificationRetryDelay = delay
+}
+
+// SetProposalVerificationGrace sets the grace period after which a failed proposal verification is attempted once
+// more before prevoting nil, if the propose timeout leaves room for it. The failure may be due to a dependency
+// missing momentarily on a flaky network. Zero disables it.
+func (c *Core) SetProposalVerificationGrace(grace time.Duration) {
+	c.proposalVerificationGrace = grace
+}
+
+// SetProposalBaseFeeRetries sets how many times a proposal verification failing on the base fee alone is attempted
+// again, the base fee being computed from a parent view which may still be updating during a head transition. Each
+// retry waits for the proposal verification retry delay. Zero disables the retries.
+func (c *Core) SetProposalBaseFeeRetries(retries int) {
+	c.proposalBaseFeeRetries = retries
+}
+
+// SetProposalPrefetch enables the prefetching of the state accessed by the proposals, run concurrently with their
+// verification to reduce its latency.
+func (c *Core) SetProposalPrefetch(enabled bool) {
+	c.proposalPrefetch = enabled
+}
+
+// Timeouts returns the durations of the step timeouts.
+func (c *Core) Timeouts() TimeoutConfig {
+	if c.timeouts == nil {
+		return DefaultTimeoutConfig()
+	}
+	return *c.timeouts
+}
+
+// SetTimeouts sets the durations of the step timeouts, higher latency networks need longer ones to keep deciding
+// in round 0. The configuration is rejected if not sane, it must be set before the engine is started, see
+// ReloadTimeouts afterwards.
+func (c *Core) SetTimeouts(tc TimeoutConfig) error {
+	if err := tc.Validate(); err != nil {
+		return err
+	}
+	c.timeouts = &tc
+	return nil
+}
+
+// SetRoundTimeoutStrategy selects how the step timeouts widen as the round number grows, linearly or exponentially
+// to recover faster from persistent faults. It must be set before the engine is started.
+func (c *Core) SetRoundTimeoutStrategy(strategy RoundTimeoutStrategy) error {
+	if strategy != LinearRoundTimeouts && strategy != ExponentialRoundTimeouts {
+		return fmt.Errorf("%w: round timeout strategy %v", constants.ErrInvalidTimeoutConfig, strategy)
+	}
+	c.roundTimeoutStrategy = strategy
+	return nil
+}
+
+// SetProposerBlacklistThreshold sets the number of invalid proposals after which the proposals of a committee
+// member are nil prevoted without verification for the rest of the height. Zero disables the blacklist.
+func (c *Core) SetProposerBlacklistThreshold(threshold int) {
+	c.proposerBlacklistThreshold = threshold
+}
+
+// SetMaxProposalRoundsAhead sets how many rounds ahead of the current one a proposal can be to be backlogged, the
+// proposals further ahead are dropped. Zero disables the ceiling.
+func (c *Core) SetMaxProposalRoundsAhead(rounds int64) {
+	c.maxProposalRoundsAhead = rounds
+}
+
+// Clock returns the time source used by the engine.
+func (c *Core) Clock() Clock {
+	return orRealClock(c.clock)
+}
+
+// SetClock replaces the time source used by the engine and its step timeouts. It is meant to be
+// used by tests to drive time deterministically and must be called before the engine is started.
+func (c *Core) SetClock(clock Clock) {
+	c.clock = clock
+	for _, t := range []*Timeout{c.proposeTimeout, c.prevoteTimeout, c.precommitTimeout} {
+		if t != nil {
+			t.Clock = clock
+		}
+	}
+}
+
+// SetProposalCircuitBreakerThreshold sets the number of consecutive failures to verify our own proposals after
+// which the node stops proposing. Zero disables the circuit breaker.
+func (c *Core) SetProposalCircuitBreakerThreshold(threshold int) {
+	c.proposalCircuitBreakerThreshold.Store(int64(threshold))
+}
+
+// ProposingHalted returns true if the node stopped proposing because its own proposals repeatedly failed verification.
+func (c *Core) ProposingHalted() bool {
+	return c.proposingHalted.Load()
+}
+
+// ResetProposalCircuitBreaker resumes proposing after the circuit breaker tripped, once the operator fixed the
+// underlying fault.
+func (c *Core) ResetProposalCircuitBreaker() {
+	c.selfProposalFailures.Store(0)
+	c.proposingHalted.Store(false)
+}
+
 func (c *Core) FutureRoundChange() map[int64]map[common.Address]*big.Int {
 	return c.futureRoundChange
 }
@@ -254,7 +498,7 @@ func (c *Core) Broadcaster() interfaces.Broadcaster {
 func (c *Core) Commit(round int64, messages *message.RoundMessages) {
 	c.SetStep(PrecommitDone)
 	// for metrics
-	start := time.Now()
+	start := c.Clock().Now()
 	proposal := messages.Proposal()
 	if proposal == nil {
 		// Should never happen really.
@@ -274,11 +518,36 @@ func (c *Core) Commit(round int64, messages *message.RoundMessages) {
 		return
 	}
 
+	c.recordQuorum(round, messages, proposalHash)
+	c.recordAbsentVoters(proposal.H(), messages)
+	now := c.Clock().Now()
+	c.lastCommit.Store(now.UnixNano())
+	LastCommitGauge.Update(now.Unix())
+	c.commits.send(interfaces.CommitInfo{
+		Hash:       proposalHash,
+		Height:     proposal.H(),
+		Round:      round,
+		Prevotes:   len(messages.AllPrevotes()),
+		Precommits: len(messages.AllPrecommits()),
+	})
 	if metrics.Enabled {
-		now := time.Now()
 		CommitTimer.Update(now.Sub(start))
 		CommitBg.Add(now.Sub(start).Nanoseconds())
+		// the commit of an older round proposal comes after a round change
+		if proposal.Sender() != c.address && round == c.Round() {
+			ProposalConversionCommitMeter.Mark(1)
+		}
+	}
+}
+
+// TimeSinceLastCommit returns the time elapsed since the engine last committed a block, or since it was started if
+// it didn't commit any yet. A value growing well beyond the block period denotes a stuck or partitioned node.
+func (c *Core) TimeSinceLastCommit() time.Duration {
+	last := c.lastCommit.Load()
+	if last == 0 {
+		return 0
 	}
+	return c.Clock().Now().Sub(time.Unix(0, last))
 }
 
 // Metric collecton of round change and height change.
@@ -299,6 +568,14 @@ func (c *Core) StartRound(ctx context.Context, round int64) {
 	}
 
 	c.measureHeightRoundMetrics(round)
+	c.checkStalled(round)
+	// a delayed proposal of the previous round is obsolete
+	c.stopProposalBroadcastTimer()
+	// so is a future proposal or a verification retry of the previous height
+	if round == 0 {
+		c.proposer.StopFutureProposalTimer()
+		c.stopVerificationRetries()
+	}
 	// Set initial FSM state
 	c.setInitialState(round)
 	// c.setStep(propose) will process the pending unmined blocks sent by the backed.Seal() and set c.lastestPendingRequest
@@ -322,8 +599,14 @@ func (c *Core) StartRound(ctx context.Context, round int64) {
 		newValue, ok := c.pendingCandidateBlocks[c.Height().Uint64()]
 		if ok {
 			c.proposer.SendProposal(ctx, newValue)
+			return
 		}
-	} else {
+		// at round 0 the block producer is already building on the new head, on later rounds the candidate
+		// went missing and is requested rather than waiting for the next one to be pushed.
+		if round > 0 {
+			c.backend.RequestCandidateBlock(c.Height().Uint64())
+		}
+	} else if c.roundTimeoutsEnabled() {
 		timeoutDuration := c.timeoutPropose(round)
 		c.proposeTimeout.ScheduleTimeout(timeoutDuration, round, c.Height(), c.onTimeoutPropose)
 		c.logger.Debug("Scheduled Propose Timeout", "Timeout Duration", timeoutDuration)
@@ -336,17 +619,22 @@ func (c *Core) setInitialState(r int64) {
 		lastBlockMined := c.backend.HeadBlock()
 		c.setHeight(new(big.Int).Add(lastBlockMined.Number(), common.Big1))
 		lastHeader := lastBlockMined.Header()
+		c.rememberCommitted(lastBlockMined)
 		c.committee.SetLastHeader(lastHeader)
 		c.setLastHeader(lastHeader)
-		c.lockedRound = -1
-		c.lockedValue = nil
+		c.pinCommittee()
+		c.scheduleProposers()
+		c.setLock(-1, nil)
 		c.validRound = -1
 		c.validValue = nil
 		c.messages.Reset()
 		c.futureRoundChange = make(map[int64]map[common.Address]*big.Int)
+		c.invalidProposals = make(map[common.Address]int)
+		c.firstPrecommits = make(map[int64]time.Time)
+		c.verifiedBlocks = make(map[common.Hash]struct{})
 		// update height duration timer
 		if metrics.Enabled {
-			now := time.Now()
+			now := c.Clock().Now()
 			HeightTimer.Update(now.Sub(c.newHeight))
 			HeightBg.Add(now.Sub(c.newHeight).Nanoseconds())
 			c.newHeight = now
@@ -357,7 +645,7 @@ func (c *Core) setInitialState(r int64) {
 	c.prevoteTimeout.Reset(Prevote)
 	c.precommitTimeout.Reset(Precommit)
 	c.curRoundMessages = c.messages.GetOrCreate(r)
-	c.sentProposal = false
+	c.SetSentProposal(false)
 	c.sentPrevote = false
 	c.sentPrecommit = false
 	c.setValidRoundAndValue = false
@@ -365,7 +653,7 @@ func (c *Core) setInitialState(r int64) {
 
 	// update round duration timer
 	if metrics.Enabled {
-		now := time.Now()
+		now := c.Clock().Now()
 		RoundTimer.Update(now.Sub(c.newRound))
 		RoundBg.Add(now.Sub(c.newRound).Nanoseconds())
 		c.newRound = now
@@ -383,7 +671,7 @@ func (c *Core) setInitialState(r int64) {
 	}
 */
 func (c *Core) SetStep(step Step) {
-	now := time.Now()
+	now := c.Clock().Now()
 	if metrics.Enabled {
 		switch {
 		// "standard" tendermint transitions
@@ -447,6 +735,7 @@ func (c *Core) setCommitteeSet(set interfaces.Committee) {
 	c.stateMu.Lock()
 	defer c.stateMu.Unlock()
 	c.committee = set
+	c.committeeVersion.Add(1)
 }
 
 func (c *Core) setLastHeader(lastHeader *types.Header) {
@@ -470,7 +759,7 @@ func (c *Core) Height() *big.Int {
 func (c *Core) CommitteeSet() interfaces.Committee {
 	c.stateMu.RLock()
 	defer c.stateMu.RUnlock()
-	return c.committee
+	return c.withProposerOverrides(c.committee)
 }
 
 func (c *Core) LastHeader() *types.Header {
@@ -483,6 +772,36 @@ func (c *Core) CurrentHeightMessages() []message.Msg {
 	return c.messages.All()
 }
 
+// PrecommitPowerFor returns the precommit voting power accumulated for the given value at the given height and round.
+// Only the current height messages are kept by Core, a zero power is returned for any other height.
+func (c *Core) PrecommitPowerFor(height uint64, round int64, hash common.Hash) *big.Int {
+	if c.Height() == nil || c.Height().Uint64() != height {
+		return new(big.Int)
+	}
+	roundMessages, ok := c.messages.Get(round)
+	if !ok {
+		return new(big.Int)
+	}
+	return roundMessages.PrecommitsPower(hash)
+}
+
+// bestPrecommitPower returns the value having gathered the highest non-nil precommit power in the given round
+// alongside with this power. It is used to report how close the committee came to commit a block.
+func (c *Core) bestPrecommitPower(round int64) (common.Hash, *big.Int) {
+	roundMessages := c.messages.GetOrCreate(round)
+	bestValue, bestPower := common.Hash{}, new(big.Int)
+	for _, msg := range roundMessages.AllPrecommits() {
+		value := msg.Value()
+		if value == (common.Hash{}) {
+			continue
+		}
+		if power := roundMessages.PrecommitsPower(value); power.Cmp(bestPower) > 0 {
+			bestValue, bestPower = value, power
+		}
+	}
+	return bestValue, bestPower
+}
+
 func (c *Core) Backend() interfaces.Backend {
 	return c.backend
 }
@@ -491,11 +810,11 @@ func (c *Core) Logger() log.Logger {
 }
 
 func (c *Core) IsFromProposer(round int64, address common.Address) bool {
-	return c.CommitteeSet().GetProposer(round).Address == address
+	return c.proposerOf(round).Address == address
 }
 
 func (c *Core) IsProposer() bool {
-	return c.CommitteeSet().GetProposer(c.Round()).Address == c.address
+	return c.proposerOf(c.Round()).Address == c.address
 }
 
 func (c *Core) BroadcastAll(msg message.Msg) {
@@ -1,13 +1,19 @@
 package core
 
 import (
+	"errors"
 	"math/big"
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 
 	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/types"
 	"github.com/autonity/autonity/log"
 	"github.com/autonity/autonity/metrics"
 )
@@ -134,3 +140,192 @@ func TestCore_Setters(t *testing.T) {
 		require.Equal(t, prevBlock.Header(), c.LastHeader())
 	})
 }
+
+func TestCore_PrecommitPowerFor(t *testing.T) {
+	committee, _ := GenerateCommittee(4)
+	height := uint64(5)
+	round := int64(1)
+	valueA, valueB := common.Hash{0xa}, common.Hash{0xb}
+	messages := message.NewMap()
+	c := &Core{
+		logger:   log.New("Core", "test", "id", 0),
+		messages: messages,
+		round:    round,
+		height:   new(big.Int).SetUint64(height),
+	}
+	votes := []common.Hash{valueA, valueB, valueA, {}}
+	for i, v := range votes {
+		messages.GetOrCreate(round).AddPrecommit(message.NewFakePrecommit(message.Fake{
+			FakeSender: committee[i].Address,
+			FakeRound:  round,
+			FakeHeight: height,
+			FakeValue:  v,
+			FakePower:  big.NewInt(int64(i + 1)),
+		}))
+	}
+
+	require.Equal(t, big.NewInt(4), c.PrecommitPowerFor(height, round, valueA))
+	require.Equal(t, big.NewInt(2), c.PrecommitPowerFor(height, round, valueB))
+	require.Equal(t, big.NewInt(4), c.PrecommitPowerFor(height, round, common.Hash{}))
+	require.Equal(t, new(big.Int), c.PrecommitPowerFor(height, round+1, valueA))
+	require.Equal(t, new(big.Int), c.PrecommitPowerFor(height+1, round, valueA))
+	// querying a round doesn't create its state
+	require.Equal(t, []int64{round}, messages.GetRounds())
+
+	bestValue, bestPower := c.bestPrecommitPower(round)
+	require.Equal(t, valueA, bestValue)
+	require.Equal(t, big.NewInt(4), bestPower)
+}
+
+func TestCore_TimeSinceLastCommit(t *testing.T) {
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	proposer := committeeSet.Committee()[3].Address
+	clock := newFakeClock()
+
+	backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+	c := &Core{
+		logger:    log.Root(),
+		backend:   backendMock,
+		committee: committeeSet,
+		clock:     clock,
+	}
+	require.Equal(t, time.Duration(0), c.TimeSinceLastCommit())
+
+	// the engine start is the reference until the first commit
+	c.lastCommit.Store(clock.Now().UnixNano())
+	clock.Advance(3 * time.Second)
+	require.Equal(t, 3*time.Second, c.TimeSinceLastCommit())
+	clock.Advance(2 * time.Second)
+	require.Equal(t, 5*time.Second, c.TimeSinceLastCommit())
+
+	block := types.NewBlockWithHeader(&types.Header{Number: common.Big1})
+	roundMessages := message.NewMap().GetOrCreate(0)
+	roundMessages.SetProposal(message.NewPropose(0, 1, -1, block, makeSigner(keys[proposer], proposer)), true)
+
+	// a failed commit doesn't reset the value
+	backendMock.EXPECT().Commit(block, int64(0), gomock.Any()).Return(errors.New("commit failed"))
+	c.Commit(0, roundMessages)
+	require.Equal(t, 5*time.Second, c.TimeSinceLastCommit())
+
+	backendMock.EXPECT().Commit(block, int64(0), gomock.Any()).Return(nil)
+	c.Commit(0, roundMessages)
+	require.Equal(t, time.Duration(0), c.TimeSinceLastCommit())
+	clock.Advance(time.Second)
+	require.Equal(t, time.Second, c.TimeSinceLastCommit())
+}
+
+func TestCore_SubscribeCommit(t *testing.T) {
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	members := committeeSet.Committee()
+	height := uint64(5)
+	block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height)})
+
+	backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+	c := &Core{
+		logger:    log.Root(),
+		backend:   backendMock,
+		committee: committeeSet,
+		messages:  message.NewMap(),
+		clock:     newFakeClock(),
+	}
+	commits := make(chan interfaces.CommitInfo, 1)
+	sub := c.SubscribeCommit(commits)
+	defer sub.Unsubscribe()
+
+	// the first two rounds end up precommitting nil, the block is decided in the third one
+	for r := int64(0); r < 2; r++ {
+		roundMessages := c.messages.GetOrCreate(r)
+		for _, m := range members[:3] {
+			roundMessages.AddPrevote(message.NewPrevote(r, height, common.Hash{}, makeSigner(keys[m.Address], m.Address)).MustVerify(stubVerifier))
+			roundMessages.AddPrecommit(message.NewPrecommit(r, height, common.Hash{}, makeSigner(keys[m.Address], m.Address)).MustVerify(stubVerifier))
+		}
+	}
+	proposer := members[0].Address
+	roundMessages := c.messages.GetOrCreate(2)
+	roundMessages.SetProposal(message.NewPropose(2, height, -1, block, makeSigner(keys[proposer], proposer)).MustVerify(stubVerifier), true)
+	for _, m := range members {
+		roundMessages.AddPrevote(message.NewPrevote(2, height, block.Hash(), makeSigner(keys[m.Address], m.Address)).MustVerify(stubVerifier))
+	}
+	for _, m := range members[:3] {
+		roundMessages.AddPrecommit(message.NewPrecommit(2, height, block.Hash(), makeSigner(keys[m.Address], m.Address)).MustVerify(stubVerifier))
+	}
+
+	// nothing is notified on a failed commit
+	backendMock.EXPECT().Commit(block, int64(2), gomock.Any()).Return(errors.New("commit failed"))
+	c.Commit(2, roundMessages)
+	select {
+	case info := <-commits:
+		t.Fatalf("unexpected commit notification %+v", info)
+	default:
+	}
+
+	// a subscriber not ready to receive doesn't hold the consensus loop, it misses the commit
+	enableTestMeters(t, &CommitDroppedMeter)
+	blocked := make(chan interfaces.CommitInfo)
+	blockedSub := c.SubscribeCommit(blocked)
+	defer blockedSub.Unsubscribe()
+
+	backendMock.EXPECT().Commit(block, int64(2), gomock.Any()).Return(nil)
+	c.Commit(2, roundMessages)
+	require.Equal(t, int64(1), CommitDroppedMeter.Count())
+	select {
+	case info := <-commits:
+		require.Equal(t, interfaces.CommitInfo{
+			Hash:       block.Hash(),
+			Height:     height,
+			Round:      2,
+			Prevotes:   4,
+			Precommits: 3,
+		}, info)
+	case <-time.After(time.Second):
+		t.Fatal("commit not notified")
+	}
+}
+
+func TestCore_AbsentVoters(t *testing.T) {
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	members := committeeSet.Committee()
+	backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+	c := &Core{
+		logger:    log.Root(),
+		backend:   backendMock,
+		committee: committeeSet,
+		messages:  message.NewMap(),
+		clock:     newFakeClock(),
+	}
+	require.Empty(t, c.AbsentVoters(10))
+
+	// commits the given height with the prevotes and precommits of the given members
+	commit := func(height uint64, prevoters, precommitters []types.CommitteeMember) {
+		block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height)})
+		roundMessages := message.NewRoundMessages()
+		proposer := members[0].Address
+		roundMessages.SetProposal(message.NewPropose(0, height, -1, block, makeSigner(keys[proposer], proposer)), true)
+		for _, m := range prevoters {
+			roundMessages.AddPrevote(message.NewPrevote(0, height, block.Hash(), makeSigner(keys[m.Address], m.Address)).MustVerify(stubVerifier))
+		}
+		for _, m := range precommitters {
+			roundMessages.AddPrecommit(message.NewPrecommit(0, height, block.Hash(), makeSigner(keys[m.Address], m.Address)).MustVerify(stubVerifier))
+		}
+		backendMock.EXPECT().Commit(block, int64(0), gomock.Any()).Return(nil)
+		c.Commit(0, roundMessages)
+	}
+	// the last member doesn't vote at height 1, the third one only precommits at height 2
+	commit(1, members[:3], members[:3])
+	commit(2, members[:2], members)
+	// everybody votes at height 3, the second member only prevotes
+	commit(3, members, []types.CommitteeMember{members[0], members[2], members[3]})
+
+	require.Empty(t, c.AbsentVoters(0))
+	require.Empty(t, c.AbsentVoters(2))
+	require.Equal(t, []common.Address{members[3].Address}, c.AbsentVoters(3))
+	require.Equal(t, []common.Address{members[3].Address}, c.AbsentVoters(100))
+
+	// a failed commit isn't recorded
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(4)})
+	roundMessages := message.NewRoundMessages()
+	roundMessages.SetProposal(message.NewPropose(0, 4, -1, block, makeSigner(keys[members[0].Address], members[0].Address)), true)
+	backendMock.EXPECT().Commit(block, int64(0), gomock.Any()).Return(errors.New("commit failed"))
+	c.Commit(0, roundMessages)
+	require.Empty(t, c.AbsentVoters(2))
+}
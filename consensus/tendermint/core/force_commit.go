@@ -0,0 +1,65 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
+)
+
+type forceCommitRequestEvent struct {
+	height uint64
+	round  int64
+	hash   common.Hash
+	errCh  chan error
+}
+
+// ForceCommit commits the proposal of the given round of the current height, for recovery when a quorum of
+// precommits was received for it but the block wasn't committed automatically. The commit goes through the normal
+// path and is refused unless the proposal was verified and a quorum of precommits for it is held, so that it can
+// never commit a block the committee didn't decide. It must not be called from the main loop.
+func (c *Core) ForceCommit(height uint64, round int64, hash common.Hash) error {
+	e := forceCommitRequestEvent{
+		height: height,
+		round:  round,
+		hash:   hash,
+		errCh:  make(chan error, 1),
+	}
+	go c.SendEvent(e)
+	return <-e.errCh
+}
+
+func (c *Core) handleForceCommitRequest(e forceCommitRequestEvent) {
+	e.errCh <- c.forceCommit(e.height, e.round, e.hash)
+}
+
+// forceCommit must be called from the main loop.
+func (c *Core) forceCommit(height uint64, round int64, hash common.Hash) error {
+	if height != c.Height().Uint64() {
+		return fmt.Errorf("%w: height %d, current height %d", constants.ErrForceCommitRefused, height, c.Height().Uint64())
+	}
+	if c.step == PrecommitDone {
+		return fmt.Errorf("%w: height %d already committed", constants.ErrForceCommitRefused, height)
+	}
+	if round < 0 || round > c.Round() {
+		return fmt.Errorf("%w: round %d, current round %d", constants.ErrForceCommitRefused, round, c.Round())
+	}
+	roundMessages := c.messages.GetOrCreate(round)
+	proposal := roundMessages.Proposal()
+	if proposal == nil || proposal.Block().Hash() != hash {
+		return fmt.Errorf("%w: no proposal %v at round %d", constants.ErrForceCommitRefused, hash, round)
+	}
+	if !roundMessages.IsProposalVerified() {
+		return fmt.Errorf("%w: proposal %v not verified", constants.ErrForceCommitRefused, hash)
+	}
+	power, quorum := roundMessages.PrecommitsPower(hash), c.CommitteeSet().Quorum()
+	if power.Cmp(quorum) < 0 {
+		return fmt.Errorf("%w: precommits power %v below quorum %v", constants.ErrForceCommitRefused, power, quorum)
+	}
+	if !c.hasMinParticipation(roundMessages) {
+		return fmt.Errorf("%w: minimum participation not reached", constants.ErrForceCommitRefused)
+	}
+	c.logger.Warn("Force committing a proposal", "height", height, "round", round, "hash", hash)
+	c.Commit(round, roundMessages)
+	return nil
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/log"
+)
+
+func TestForceCommit(t *testing.T) {
+	const round = int64(1)
+	height := big.NewInt(4)
+	committeeSet, keys := NewTestCommitteeSetWithKeys(7)
+	proposer := committeeSet.GetProposer(round).Address
+	me := committeeSet.Committee()[0].Address
+
+	// newCore returns a core at the precommit step of the round, holding the given number of precommits for the
+	// verified proposal of the round.
+	newCore := func(t *testing.T, precommits int) (*Core, *interfaces.MockBackend, *types.Block) {
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		backendMock.EXPECT().Address().AnyTimes().Return(me)
+		backendMock.EXPECT().Logger().AnyTimes().Return(log.Root())
+
+		c := New(backendMock, nil)
+		c.setCommitteeSet(committeeSet)
+		c.setHeight(height)
+		c.setRound(round)
+		c.SetStep(Precommit)
+		c.curRoundMessages = c.messages.GetOrCreate(round)
+
+		block := types.NewBlockWithHeader(&types.Header{Number: height})
+		proposal := message.NewPropose(round, height.Uint64(), -1, block, makeSigner(keys[proposer], proposer))
+		c.curRoundMessages.SetProposal(proposal.MustVerify(stubVerifier), true)
+		for _, m := range committeeSet.Committee()[:precommits] {
+			precommit := message.NewPrecommit(round, height.Uint64(), block.Hash(), makeSigner(keys[m.Address], m.Address))
+			c.curRoundMessages.AddPrecommit(precommit.MustVerify(stubVerifier))
+		}
+		return c, backendMock, block
+	}
+
+	t.Run("proposal committed with a quorum of precommits", func(t *testing.T) {
+		c, backendMock, block := newCore(t, 5)
+		backendMock.EXPECT().Commit(block, round, gomock.Len(5)).Return(nil)
+		// the request is handled from the main loop
+		backendMock.EXPECT().Post(gomock.Any()).Do(func(ev any) {
+			c.handleForceCommitRequest(ev.(forceCommitRequestEvent))
+		})
+
+		require.NoError(t, c.ForceCommit(height.Uint64(), round, block.Hash()))
+		require.Equal(t, PrecommitDone, c.Step())
+	})
+
+	t.Run("refused without a quorum of precommits", func(t *testing.T) {
+		c, _, block := newCore(t, 4)
+		require.ErrorIs(t, c.forceCommit(height.Uint64(), round, block.Hash()), constants.ErrForceCommitRefused)
+		require.Equal(t, Precommit, c.Step())
+	})
+
+	t.Run("refused for another value than the proposal", func(t *testing.T) {
+		c, _, _ := newCore(t, 5)
+		require.ErrorIs(t, c.forceCommit(height.Uint64(), round, common.HexToHash("0x01")), constants.ErrForceCommitRefused)
+	})
+
+	t.Run("refused for another height or a future round", func(t *testing.T) {
+		c, _, block := newCore(t, 5)
+		require.ErrorIs(t, c.forceCommit(height.Uint64()+1, round, block.Hash()), constants.ErrForceCommitRefused)
+		require.ErrorIs(t, c.forceCommit(height.Uint64(), round+1, block.Hash()), constants.ErrForceCommitRefused)
+	})
+
+	t.Run("refused for an unverified proposal", func(t *testing.T) {
+		c, _, block := newCore(t, 5)
+		c.curRoundMessages.SetProposal(c.curRoundMessages.Proposal(), false)
+		require.ErrorIs(t, c.forceCommit(height.Uint64(), round, block.Hash()), constants.ErrForceCommitRefused)
+	})
+
+	t.Run("refused once the height is committed", func(t *testing.T) {
+		c, _, block := newCore(t, 5)
+		c.SetStep(PrecommitDone)
+		require.ErrorIs(t, c.forceCommit(height.Uint64(), round, block.Hash()), constants.ErrForceCommitRefused)
+	})
+}
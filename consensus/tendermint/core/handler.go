@@ -26,6 +26,7 @@ func (c *Core) Start(ctx context.Context, contract *autonity.ProtocolContracts)
 		c.protocolContracts,
 		c.backend.BlockChain())
 	c.setCommitteeSet(committeeSet)
+	c.lastCommit.Store(c.Clock().Now().UnixNano())
 	ctx, c.cancel = context.WithCancel(ctx)
 	c.subscribeEvents()
 	// Tendermint Finite State Machine discrete event loop
@@ -44,11 +45,13 @@ func (c *Core) Stop() {
 	c.cancel()
 
 	c.proposer.StopFutureProposalTimer()
+	c.stopProposalBroadcastTimer()
 	c.unsubscribeEvents()
 
 	// Ensure all event handling go routines exit
 	<-c.stopped
 	<-c.stopped
+	c.stopVerificationRetries()
 }
 
 func (c *Core) subscribeEvents() {
@@ -56,7 +59,15 @@ func (c *Core) subscribeEvents() {
 		events.MessageEvent{},
 		backlogMessageEvent{},
 		backlogUntrustedMessageEvent{},
-		StateRequestEvent{})
+		StateRequestEvent{},
+		SnapshotRequestEvent{},
+		backlogSummaryRequestEvent{},
+		forceCommitRequestEvent{},
+		syncDoneEvent{},
+		timeoutsReloadEvent{},
+		proposalBroadcastEvent{},
+		futureProposalEvent{},
+		proposalRetryEvent{})
 	c.candidateBlockSub = c.backend.Subscribe(events.NewCandidateBlockEvent{})
 	c.timeoutEventSub = c.backend.Subscribe(TimeoutEvent{})
 	c.committedSub = c.backend.Subscribe(events.CommitEvent{})
@@ -78,6 +89,8 @@ func shouldDisconnectSender(err error) bool {
 		fallthrough
 	case errors.Is(err, constants.ErrOldHeightMessage):
 		fallthrough
+	case errors.Is(err, constants.ErrCommittedProposal):
+		fallthrough
 	case errors.Is(err, constants.ErrOldRoundMessage):
 		fallthrough
 	case errors.Is(err, constants.ErrFutureRoundMessage):
@@ -89,6 +102,22 @@ func shouldDisconnectSender(err error) bool {
 	case errors.Is(err, constants.ErrNilPrecommitSent):
 		fallthrough
 	case errors.Is(err, constants.ErrMovedToNewRound):
+		fallthrough
+	case errors.Is(err, constants.ErrProposalVerificationTimeout):
+		// a slow verification may be due to our own node, do not blame the sender.
+		fallthrough
+	case errors.Is(err, constants.ErrProposalVerificationRetry):
+		fallthrough
+	case errors.Is(err, constants.ErrBlacklistedProposer):
+		// the proposal was not verified, it may be valid.
+		fallthrough
+	case errors.Is(err, constants.ErrProposalDeferredSyncing):
+		fallthrough
+	case errors.Is(err, constants.ErrFarFutureRoundProposal):
+		// we may be the one lagging behind.
+		return false
+	case errors.Is(err, constants.ErrCommitteeChanged):
+		// the fault is ours, not the sender's.
 		return false
 	case errors.Is(err, ErrValidatorJailed):
 		// this one is tricky. Ideally yes, we want to disconnect the sender but we can't
@@ -147,6 +176,22 @@ eventLoop:
 			case StateRequestEvent:
 				// Process Tendermint state dump request.
 				c.handleStateDump(e)
+			case SnapshotRequestEvent:
+				c.handleSnapshotRequest(e)
+			case backlogSummaryRequestEvent:
+				c.handleBacklogSummaryRequest(e)
+			case forceCommitRequestEvent:
+				c.handleForceCommitRequest(e)
+			case syncDoneEvent:
+				c.handleSyncDone()
+			case timeoutsReloadEvent:
+				c.handleTimeoutsReloadEvent(e)
+			case proposalBroadcastEvent:
+				c.handleProposalBroadcast(e.proposal)
+			case futureProposalEvent:
+				c.handleFutureProposal(ctx, e)
+			case proposalRetryEvent:
+				c.handleProposalRetry(ctx, e)
 			}
 		case ev, ok := <-c.timeoutEventSub.Chan():
 			if !ok {
@@ -239,6 +284,9 @@ func (c *Core) SendEvent(ev any) {
 
 // handleMsg assume msg has already been decoded
 func (c *Core) handleMsg(ctx context.Context, msg message.Msg) error {
+	if c.isCommittedProposal(msg) {
+		return constants.ErrCommittedProposal // No gossip
+	}
 	msgHeight := new(big.Int).SetUint64(msg.H())
 	if msgHeight.Cmp(c.Height()) > 0 {
 		// Future height message. Skip processing and put it in the untrusted backlog buffer.
@@ -281,6 +329,12 @@ func (c *Core) handleFutureRoundMsg(ctx context.Context, msg message.Msg, sender
 }
 
 func (c *Core) handleValidMsg(ctx context.Context, msg message.Msg) error {
+	if c.isCommittedProposal(msg) {
+		return constants.ErrCommittedProposal
+	}
+	if err := c.checkPinnedCommittee(); err != nil {
+		return err
+	}
 	logger := c.logger.New("from", msg.Sender())
 
 	// Store the message if it's a future message
@@ -220,3 +220,111 @@ func TestCoreStopDoesntPanic(t *testing.T) {
 
 	c.Stop()
 }
+
+func TestCommitteeChangeWithinHeight(t *testing.T) {
+	enableTestMeters(t, &CommitteeChangeMeter)
+
+	committeeSet, keysMap := NewTestCommitteeSetWithKeys(4)
+	header := types.Header{Committee: committeeSet.Committee(), Number: common.Big1}
+	sender, _ := committeeSet.GetByIndex(1)
+	signer := makeSigner(keysMap[sender.Address], sender.Address)
+
+	var errorLogs []*log.Record
+	logger := log.New("backend", "test", "id", 0)
+	logger.SetHandler(log.FuncHandler(func(r *log.Record) error {
+		if r.Lvl == log.LvlError {
+			errorLogs = append(errorLogs, r)
+		}
+		return nil
+	}))
+	messageMap := message.NewMap()
+	c := &Core{
+		logger:            logger,
+		backlogs:          make(map[common.Address][]message.Msg),
+		round:             0,
+		height:            big.NewInt(2),
+		step:              Prevote,
+		futureRoundChange: make(map[int64]map[common.Address]*big.Int),
+		messages:          messageMap,
+		curRoundMessages:  messageMap.GetOrCreate(0),
+		committee:         committeeSet,
+		proposeTimeout:    NewTimeout(Propose, logger),
+		prevoteTimeout:    NewTimeout(Prevote, logger),
+		precommitTimeout:  NewTimeout(Precommit, logger),
+	}
+	c.SetDefaultHandlers()
+	c.pinCommittee()
+
+	prevote := message.NewPrevote(0, 2, common.BytesToHash([]byte{0x1}), signer)
+	prevote.Validate(header.CommitteeMember)
+	if err := c.handleValidMsg(context.Background(), prevote); err != nil {
+		t.Fatalf("unexpected error with the pinned committee: %v", err)
+	}
+
+	// the committee changes in the middle of the height
+	changed, _ := NewTestCommitteeSetWithKeys(5)
+	c.setCommitteeSet(changed)
+	for i := 0; i < 2; i++ {
+		precommit := message.NewPrecommit(0, 2, common.BytesToHash([]byte{0x1}), signer)
+		precommit.Validate(header.CommitteeMember)
+		if err := c.handleValidMsg(context.Background(), precommit); !errors.Is(err, constants.ErrCommitteeChanged) {
+			t.Fatalf("error mismatch: have %v, want %v", err, constants.ErrCommitteeChanged)
+		}
+	}
+	if power := c.curRoundMessages.PrecommitsTotalPower(); power.Sign() != 0 {
+		t.Fatalf("precommit handled with the changed committee, power %v", power)
+	}
+	if shouldDisconnectSender(constants.ErrCommitteeChanged) {
+		t.Fatal("sender disconnected for our committee change")
+	}
+	// the change is reported once per height
+	if have := CommitteeChangeMeter.Count(); have != 1 {
+		t.Fatalf("committee change meter mismatch: have %d, want 1", have)
+	}
+	if len(errorLogs) != 1 {
+		t.Fatalf("error logs mismatch: have %d, want 1", len(errorLogs))
+	}
+
+	// a new pin is taken at the next height
+	c.pinCommittee()
+	if err := c.checkPinnedCommittee(); err != nil {
+		t.Fatalf("unexpected error after pinning the new committee: %v", err)
+	}
+}
+
+// membersReadCommittee counts the reads of the members of the wrapped committee.
+type membersReadCommittee struct {
+	countedCommittee
+	reads int
+}
+
+func (c *membersReadCommittee) Committee() types.Committee {
+	c.reads++
+	return c.countedCommittee.Committee()
+}
+
+func TestPinnedCommitteeComparedOnReplacement(t *testing.T) {
+	committeeSet, _ := NewTestCommitteeSetWithKeys(4)
+	c := &Core{logger: log.Root()}
+	c.setCommitteeSet(&membersReadCommittee{countedCommittee: committeeSet})
+	c.pinCommittee()
+
+	// the members aren't compared for every message, only once the set is replaced
+	for _, size := range []int{4, 5} {
+		counting := c.committee.(*membersReadCommittee)
+		if err := c.checkPinnedCommittee(); (err != nil) != (size != 4) {
+			t.Fatalf("unexpected check outcome with %d members: %v", size, err)
+		}
+		reads := counting.reads
+		for i := 0; i < 3; i++ {
+			if err := c.checkPinnedCommittee(); (err != nil) != (size != 4) {
+				t.Fatalf("unexpected check outcome with %d members: %v", size, err)
+			}
+		}
+		if counting.reads != reads {
+			t.Fatalf("committee compared again: have %d reads, want %d", counting.reads, reads)
+		}
+		changed, _ := NewTestCommitteeSetWithKeys(5)
+		c.setCommitteeSet(&membersReadCommittee{countedCommittee: changed})
+	}
+}
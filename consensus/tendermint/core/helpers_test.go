@@ -15,6 +15,7 @@ import (
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
 	"github.com/autonity/autonity/core/types"
 	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/metrics"
 )
 
 func makeSigner(key *ecdsa.PrivateKey, addr common.Address) message.Signer {
@@ -122,3 +123,56 @@ func TestOverQuorumVotes(t *testing.T) {
 		require.Nil(t, overQuorumVotes)
 	})
 }
+
+// enableTestTimers turns metrics collection on and replaces the given package timers with fresh
+// ones for the duration of the test, since the registered ones are no-op when created with metrics disabled.
+func enableTestTimers(t *testing.T, timers ...*metrics.Timer) {
+	enabled := metrics.Enabled
+	metrics.Enabled = true
+	originals := make([]metrics.Timer, len(timers))
+	for i, timer := range timers {
+		originals[i] = *timer
+		*timer = metrics.NewTimer()
+	}
+	t.Cleanup(func() {
+		metrics.Enabled = enabled
+		for i, timer := range timers {
+			*timer = originals[i]
+		}
+	})
+}
+
+// enableTestHistograms is the histogram counterpart of enableTestTimers.
+func enableTestHistograms(t *testing.T, histograms ...*metrics.Histogram) {
+	enabled := metrics.Enabled
+	metrics.Enabled = true
+	originals := make([]metrics.Histogram, len(histograms))
+	for i, histogram := range histograms {
+		originals[i] = *histogram
+		*histogram = metrics.NewHistogram(metrics.NewUniformSample(100))
+	}
+	t.Cleanup(func() {
+		metrics.Enabled = enabled
+		for i, histogram := range histograms {
+			*histogram = originals[i]
+		}
+	})
+}
+
+// enableTestMeters is the meter counterpart of enableTestTimers.
+func enableTestMeters(t *testing.T, meters ...*metrics.Meter) {
+	enabled := metrics.Enabled
+	metrics.Enabled = true
+	originals := make([]metrics.Meter, len(meters))
+	for i, meter := range meters {
+		originals[i] = *meter
+		*meter = metrics.NewMeter()
+	}
+	t.Cleanup(func() {
+		metrics.Enabled = enabled
+		for i, meter := range meters {
+			(*meter).Stop()
+			*meter = originals[i]
+		}
+	})
+}
@@ -22,7 +22,7 @@ type Committee interface {
 	// SetLastHeader Update with lastest block header
 	SetLastHeader(block *types.Header)
 
-	// Quorum Get the optimal quorum size
+	// Quorum Get the optimal quorum size, the value is cached per committee and must not be modified
 	Quorum() *big.Int
 
 	// F Get the maximum number of faulty nodes
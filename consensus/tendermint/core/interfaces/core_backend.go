@@ -10,6 +10,7 @@ import (
 	"github.com/autonity/autonity/accounts/abi"
 	"github.com/autonity/autonity/common"
 	ethcore "github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/rawdb"
 	"github.com/autonity/autonity/core/types"
 	"github.com/autonity/autonity/event"
 	"github.com/autonity/autonity/log"
@@ -43,8 +44,20 @@ type Backend interface {
 	// HeadBlock retrieves latest committed proposal and the address of proposer
 	HeadBlock() *types.Block
 
+	// LastProposal returns the last proposal sent by the node, it is persisted to survive restarts.
+	LastProposal() *rawdb.LastProposal
+
 	Post(ev any)
 
+	// RequestCandidateBlock asks the block producer for a candidate block for the given height.
+	RequestCandidateBlock(height uint64)
+
+	// RequestResync asks the downloader to sync the chain with the peers.
+	RequestResync()
+
+	// SaveProposal persists the proposal about to be sent.
+	SaveProposal(proposal *rawdb.LastProposal)
+
 	// SetProposedBlockHash is a setter for the proposed block hash
 	SetProposedBlockHash(hash common.Hash)
 
@@ -59,6 +72,10 @@ type Backend interface {
 	// the time difference of the proposal and current time is also returned.
 	VerifyProposal(*types.Block) (time.Duration, error)
 
+	// PrefetchProposal warms the state caches in the background for the execution of the proposal, until the
+	// returned function is called.
+	PrefetchProposal(*types.Block) func()
+
 	// Returns the main blockchain object.
 	BlockChain() *ethcore.BlockChain
 
@@ -84,6 +101,12 @@ type Core interface {
 	Stop()
 	CurrentHeightMessages() []message.Msg
 	CoreState() CoreState
+	Snapshot() *ConsensusSnapshot
+	ForceRoundChange()
+	SetSyncing(syncing bool)
+	SetProposalCircuitBreakerThreshold(threshold int)
+	ProposingHalted() bool
+	ResetProposalCircuitBreaker()
 	Broadcaster() Broadcaster
 	Proposer() Proposer
 	Prevoter() Prevoter
@@ -18,6 +18,7 @@ import (
 	common "github.com/autonity/autonity/common"
 	message "github.com/autonity/autonity/consensus/tendermint/core/message"
 	core "github.com/autonity/autonity/core"
+	rawdb "github.com/autonity/autonity/core/rawdb"
 	types "github.com/autonity/autonity/core/types"
 	event "github.com/autonity/autonity/event"
 	log "github.com/autonity/autonity/log"
@@ -222,6 +223,20 @@ func (mr *MockBackendMockRecorder) KnownMsgHash() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KnownMsgHash", reflect.TypeOf((*MockBackend)(nil).KnownMsgHash))
 }
 
+// LastProposal mocks base method.
+func (m *MockBackend) LastProposal() *rawdb.LastProposal {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastProposal")
+	ret0, _ := ret[0].(*rawdb.LastProposal)
+	return ret0
+}
+
+// LastProposal indicates an expected call of LastProposal.
+func (mr *MockBackendMockRecorder) LastProposal() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastProposal", reflect.TypeOf((*MockBackend)(nil).LastProposal))
+}
+
 // Logger mocks base method.
 func (m *MockBackend) Logger() log.Logger {
 	m.ctrl.T.Helper()
@@ -248,6 +263,20 @@ func (mr *MockBackendMockRecorder) Post(ev any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Post", reflect.TypeOf((*MockBackend)(nil).Post), ev)
 }
 
+// PrefetchProposal mocks base method.
+func (m *MockBackend) PrefetchProposal(arg0 *types.Block) func() {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrefetchProposal", arg0)
+	ret0, _ := ret[0].(func())
+	return ret0
+}
+
+// PrefetchProposal indicates an expected call of PrefetchProposal.
+func (mr *MockBackendMockRecorder) PrefetchProposal(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrefetchProposal", reflect.TypeOf((*MockBackend)(nil).PrefetchProposal), arg0)
+}
+
 // RemoveMessageFromLocalCache mocks base method.
 func (m *MockBackend) RemoveMessageFromLocalCache(message message.Msg) {
 	m.ctrl.T.Helper()
@@ -260,6 +289,42 @@ func (mr *MockBackendMockRecorder) RemoveMessageFromLocalCache(message any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMessageFromLocalCache", reflect.TypeOf((*MockBackend)(nil).RemoveMessageFromLocalCache), message)
 }
 
+// RequestCandidateBlock mocks base method.
+func (m *MockBackend) RequestCandidateBlock(height uint64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RequestCandidateBlock", height)
+}
+
+// RequestCandidateBlock indicates an expected call of RequestCandidateBlock.
+func (mr *MockBackendMockRecorder) RequestCandidateBlock(height any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestCandidateBlock", reflect.TypeOf((*MockBackend)(nil).RequestCandidateBlock), height)
+}
+
+// RequestResync mocks base method.
+func (m *MockBackend) RequestResync() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RequestResync")
+}
+
+// RequestResync indicates an expected call of RequestResync.
+func (mr *MockBackendMockRecorder) RequestResync() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestResync", reflect.TypeOf((*MockBackend)(nil).RequestResync))
+}
+
+// SaveProposal mocks base method.
+func (m *MockBackend) SaveProposal(proposal *rawdb.LastProposal) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SaveProposal", proposal)
+}
+
+// SaveProposal indicates an expected call of SaveProposal.
+func (mr *MockBackendMockRecorder) SaveProposal(proposal any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveProposal", reflect.TypeOf((*MockBackend)(nil).SaveProposal), proposal)
+}
+
 // SetBlockchain mocks base method.
 func (m *MockBackend) SetBlockchain(bc *core.BlockChain) {
 	m.ctrl.T.Helper()
@@ -395,6 +460,82 @@ func (mr *MockCoreMockRecorder) CoreState() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CoreState", reflect.TypeOf((*MockCore)(nil).CoreState))
 }
 
+// ForceRoundChange mocks base method.
+func (m *MockCore) ForceRoundChange() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ForceRoundChange")
+}
+
+// ForceRoundChange indicates an expected call of ForceRoundChange.
+func (mr *MockCoreMockRecorder) ForceRoundChange() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForceRoundChange", reflect.TypeOf((*MockCore)(nil).ForceRoundChange))
+}
+
+// ProposingHalted mocks base method.
+func (m *MockCore) ProposingHalted() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProposingHalted")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// ProposingHalted indicates an expected call of ProposingHalted.
+func (mr *MockCoreMockRecorder) ProposingHalted() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProposingHalted", reflect.TypeOf((*MockCore)(nil).ProposingHalted))
+}
+
+// ResetProposalCircuitBreaker mocks base method.
+func (m *MockCore) ResetProposalCircuitBreaker() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ResetProposalCircuitBreaker")
+}
+
+// ResetProposalCircuitBreaker indicates an expected call of ResetProposalCircuitBreaker.
+func (mr *MockCoreMockRecorder) ResetProposalCircuitBreaker() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetProposalCircuitBreaker", reflect.TypeOf((*MockCore)(nil).ResetProposalCircuitBreaker))
+}
+
+// SetProposalCircuitBreakerThreshold mocks base method.
+func (m *MockCore) SetProposalCircuitBreakerThreshold(threshold int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetProposalCircuitBreakerThreshold", threshold)
+}
+
+// SetProposalCircuitBreakerThreshold indicates an expected call of SetProposalCircuitBreakerThreshold.
+func (mr *MockCoreMockRecorder) SetProposalCircuitBreakerThreshold(threshold any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProposalCircuitBreakerThreshold", reflect.TypeOf((*MockCore)(nil).SetProposalCircuitBreakerThreshold), threshold)
+}
+
+// SetSyncing mocks base method.
+func (m *MockCore) SetSyncing(syncing bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetSyncing", syncing)
+}
+
+// SetSyncing indicates an expected call of SetSyncing.
+func (mr *MockCoreMockRecorder) SetSyncing(syncing any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSyncing", reflect.TypeOf((*MockCore)(nil).SetSyncing), syncing)
+}
+
+// Snapshot mocks base method.
+func (m *MockCore) Snapshot() *ConsensusSnapshot {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshot")
+	ret0, _ := ret[0].(*ConsensusSnapshot)
+	return ret0
+}
+
+// Snapshot indicates an expected call of Snapshot.
+func (mr *MockCoreMockRecorder) Snapshot() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshot", reflect.TypeOf((*MockCore)(nil).Snapshot))
+}
+
 // CurrentHeightMessages mocks base method.
 func (m *MockCore) CurrentHeightMessages() []message.Msg {
 	m.ctrl.T.Helper()
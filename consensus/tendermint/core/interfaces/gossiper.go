@@ -8,9 +8,44 @@ import (
 	lru "github.com/hashicorp/golang-lru"
 )
 
+// ProposalBroadcastStrategy selects how the proposals are propagated to the committee.
+type ProposalBroadcastStrategy uint8
+
+const (
+	// FullProposalBroadcast sends the full proposal to every committee member.
+	FullProposalBroadcast ProposalBroadcastStrategy = iota
+	// AnnounceProposalBroadcast sends the full proposal to a subset of the committee only and a compact announcement
+	// to the other members, which request the full proposal if they don't know it yet.
+	AnnounceProposalBroadcast
+)
+
+// VoteBroadcastStrategy selects how the prevotes and precommits are propagated to the committee.
+type VoteBroadcastStrategy uint8
+
+const (
+	// FullVoteBroadcast sends every vote to every committee member.
+	FullVoteBroadcast VoteBroadcastStrategy = iota
+	// CompactVoteBroadcast announces the votes known for a value as a bitmap of their voters, the committee members
+	// request the individual votes they are missing.
+	CompactVoteBroadcast
+)
+
 type Gossiper interface {
 	Gossip(committee types.Committee, message message.Msg)
 	AskSync(header *types.Header)
+	// SetProposalBroadcast sets the proposal broadcast strategy, fanout is the number of peers receiving the full
+	// proposal when announcing, the square root of the number of peers if zero.
+	SetProposalBroadcast(strategy ProposalBroadcastStrategy, fanout int)
+	// HandleProposalAnnounce requests the announced proposal from the sender if it isn't known yet.
+	HandleProposalAnnounce(sender common.Address, hash common.Hash)
+	// HandleProposalRequest sends the requested proposal to the sender if it was recently gossiped.
+	HandleProposalRequest(sender common.Address, hash common.Hash)
+	// SetVoteBroadcast sets the vote broadcast strategy.
+	SetVoteBroadcast(strategy VoteBroadcastStrategy)
+	// HandleVoteAnnounce requests from the sender the announced votes which aren't known yet.
+	HandleVoteAnnounce(sender common.Address, votes *message.CompactVotes)
+	// HandleVoteRequest sends the requested votes to the sender among the ones recently gossiped.
+	HandleVoteRequest(sender common.Address, votes *message.CompactVotes)
 	SetBroadcaster(broadcaster consensus.Broadcaster)
 	Broadcaster() consensus.Broadcaster
 	RecentMessages() *lru.ARCCache
@@ -94,6 +94,54 @@ func (mr *MockGossiperMockRecorder) Gossip(committee, message any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Gossip", reflect.TypeOf((*MockGossiper)(nil).Gossip), committee, message)
 }
 
+// HandleProposalAnnounce mocks base method.
+func (m *MockGossiper) HandleProposalAnnounce(sender common.Address, hash common.Hash) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "HandleProposalAnnounce", sender, hash)
+}
+
+// HandleProposalAnnounce indicates an expected call of HandleProposalAnnounce.
+func (mr *MockGossiperMockRecorder) HandleProposalAnnounce(sender, hash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleProposalAnnounce", reflect.TypeOf((*MockGossiper)(nil).HandleProposalAnnounce), sender, hash)
+}
+
+// HandleProposalRequest mocks base method.
+func (m *MockGossiper) HandleProposalRequest(sender common.Address, hash common.Hash) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "HandleProposalRequest", sender, hash)
+}
+
+// HandleProposalRequest indicates an expected call of HandleProposalRequest.
+func (mr *MockGossiperMockRecorder) HandleProposalRequest(sender, hash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleProposalRequest", reflect.TypeOf((*MockGossiper)(nil).HandleProposalRequest), sender, hash)
+}
+
+// HandleVoteAnnounce mocks base method.
+func (m *MockGossiper) HandleVoteAnnounce(sender common.Address, votes *message.CompactVotes) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "HandleVoteAnnounce", sender, votes)
+}
+
+// HandleVoteAnnounce indicates an expected call of HandleVoteAnnounce.
+func (mr *MockGossiperMockRecorder) HandleVoteAnnounce(sender, votes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleVoteAnnounce", reflect.TypeOf((*MockGossiper)(nil).HandleVoteAnnounce), sender, votes)
+}
+
+// HandleVoteRequest mocks base method.
+func (m *MockGossiper) HandleVoteRequest(sender common.Address, votes *message.CompactVotes) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "HandleVoteRequest", sender, votes)
+}
+
+// HandleVoteRequest indicates an expected call of HandleVoteRequest.
+func (mr *MockGossiperMockRecorder) HandleVoteRequest(sender, votes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleVoteRequest", reflect.TypeOf((*MockGossiper)(nil).HandleVoteRequest), sender, votes)
+}
+
 // KnownMessages mocks base method.
 func (m *MockGossiper) KnownMessages() *lru.ARCCache {
 	m.ctrl.T.Helper()
@@ -133,3 +181,27 @@ func (mr *MockGossiperMockRecorder) SetBroadcaster(broadcaster any) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBroadcaster", reflect.TypeOf((*MockGossiper)(nil).SetBroadcaster), broadcaster)
 }
+
+// SetProposalBroadcast mocks base method.
+func (m *MockGossiper) SetProposalBroadcast(strategy ProposalBroadcastStrategy, fanout int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetProposalBroadcast", strategy, fanout)
+}
+
+// SetProposalBroadcast indicates an expected call of SetProposalBroadcast.
+func (mr *MockGossiperMockRecorder) SetProposalBroadcast(strategy, fanout any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProposalBroadcast", reflect.TypeOf((*MockGossiper)(nil).SetProposalBroadcast), strategy, fanout)
+}
+
+// SetVoteBroadcast mocks base method.
+func (m *MockGossiper) SetVoteBroadcast(strategy VoteBroadcastStrategy) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetVoteBroadcast", strategy)
+}
+
+// SetVoteBroadcast indicates an expected call of SetVoteBroadcast.
+func (mr *MockGossiperMockRecorder) SetVoteBroadcast(strategy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVoteBroadcast", reflect.TypeOf((*MockGossiper)(nil).SetVoteBroadcast), strategy)
+}
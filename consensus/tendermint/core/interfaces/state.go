@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
 	"github.com/autonity/autonity/core/types"
 	"math/big"
@@ -59,6 +60,7 @@ type CoreState struct {
 	SentPrevote           bool
 	SentPrecommit         bool
 	SetValidRoundAndValue bool
+	ProposingHalted       bool
 
 	// timer state
 	BlockPeriod           uint64
@@ -75,3 +77,70 @@ type CoreState struct {
 	// Known msg of gossip.
 	KnownMsgHash []common.Hash
 }
+
+// ConsensusSnapshot is a serializable view of the consensus state at a given instant, deep copied from
+// the engine so that it can be retained by external observers.
+type ConsensusSnapshot struct {
+	Height      *big.Int        `json:"height"`
+	Round       int64           `json:"round"`
+	Step        string          `json:"step"`
+	LockedRound int64           `json:"lockedRound"`
+	LockedValue *common.Hash    `json:"lockedValue"`
+	ValidRound  int64           `json:"validRound"`
+	ValidValue  *common.Hash    `json:"validValue"`
+	Committee   types.Committee `json:"committee"`
+	Rounds      []RoundSnapshot `json:"rounds"`
+}
+
+// RoundSnapshot holds the proposal and the vote tallies received for a round of the current height.
+type RoundSnapshot struct {
+	Round      int64        `json:"round"`
+	Proposal   *common.Hash `json:"proposal"`
+	Prevotes   []VoteTally  `json:"prevotes"`
+	Precommits []VoteTally  `json:"precommits"`
+}
+
+// VoteTally is the voting power accumulated by a value, nil votes are tallied under the empty hash.
+type VoteTally struct {
+	Value  common.Hash      `json:"value"`
+	Power  *big.Int         `json:"power"`
+	Voters []common.Address `json:"voters"`
+}
+
+// CommitInfo describes a block committed by the engine, alongside with the round it was decided in. The round is also
+// the number of round changes the height went through before the decision.
+type CommitInfo struct {
+	Hash       common.Hash `json:"hash"`
+	Height     uint64      `json:"height"`
+	Round      int64       `json:"round"`
+	Prevotes   int         `json:"prevotes"`   // prevotes received in the committing round, for any value
+	Precommits int         `json:"precommits"` // precommits received in the committing round, for any value
+}
+
+// ProposalOutcome is the result of the handling of a proposal. The proposals rejected are classified by Kind, which
+// is left to zero for the ones accepted and for the ones which couldn't be evaluated yet, such as future round
+// proposals, Err telling them apart.
+type ProposalOutcome struct {
+	Proposer common.Address
+	Height   uint64
+	Round    int64
+	Value    common.Hash
+	Kind     constants.ProposalErrorKind
+	Err      error
+}
+
+// LockChange is a transition of the locked round and value of the engine, the rounds being -1 and the values the empty
+// hash while unlocked. Locking happens upon a quorum of prevotes for the current round proposal and unlocking upon a
+// new height.
+type LockChange struct {
+	Height   uint64
+	OldRound int64
+	OldValue common.Hash
+	NewRound int64
+	NewValue common.Hash
+}
+
+// Accepted returns whether the proposal was accepted.
+func (o ProposalOutcome) Accepted() bool {
+	return o.Err == nil
+}
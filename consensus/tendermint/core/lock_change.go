@@ -0,0 +1,89 @@
+package core
+
+import (
+	"sync"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/event"
+)
+
+// lockChangeFeed delivers the changes of the locked round and value. As for the proposal outcomes, a delivery never
+// blocks the consensus loop: a subscriber which isn't ready to receive misses the change.
+type lockChangeFeed struct {
+	mu          sync.Mutex
+	subscribers []chan<- interfaces.LockChange
+}
+
+func (f *lockChangeFeed) subscribe(ch chan<- interfaces.LockChange) event.Subscription {
+	f.mu.Lock()
+	f.subscribers = append(f.subscribers, ch)
+	f.mu.Unlock()
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		for i, sub := range f.subscribers {
+			if sub == ch {
+				f.subscribers = append(f.subscribers[:i], f.subscribers[i+1:]...)
+				break
+			}
+		}
+		return nil
+	})
+}
+
+func (f *lockChangeFeed) active() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.subscribers) > 0
+}
+
+func (f *lockChangeFeed) send(change interfaces.LockChange) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, ch := range f.subscribers {
+		select {
+		case ch <- change:
+		default:
+			LockDroppedMeter.Mark(1)
+		}
+	}
+}
+
+// SubscribeLockChange registers a subscription notified of every change of the locked round and value, to follow the
+// lock transitions of lines 36-41 of the algorithm when validating the safety of the consensus. The changes are
+// dropped for a subscriber whose channel is full, it should be buffered.
+func (c *Core) SubscribeLockChange(ch chan<- interfaces.LockChange) event.Subscription {
+	return c.lockChanges.subscribe(ch)
+}
+
+// setLock sets the locked round and value, notifying the subscribers if they changed. The values are only hashed when
+// there are subscribers.
+func (c *Core) setLock(round int64, value *types.Block) {
+	oldRound, oldValue := c.lockedRound, c.lockedValue
+	c.lockedRound = round
+	c.lockedValue = value
+	if !c.lockChanges.active() {
+		return
+	}
+	oldHash, newHash := lockedHash(oldValue), lockedHash(value)
+	if oldRound == round && oldHash == newHash {
+		return
+	}
+	c.lockChanges.send(interfaces.LockChange{
+		Height:   c.Height().Uint64(),
+		OldRound: oldRound,
+		OldValue: oldHash,
+		NewRound: round,
+		NewValue: newHash,
+	})
+}
+
+func lockedHash(value *types.Block) common.Hash {
+	if value == nil {
+		return common.Hash{}
+	}
+	return value.Hash()
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/log"
+)
+
+func TestSubscribeLockChange(t *testing.T) {
+	enableTestMeters(t, &LockDroppedMeter)
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	member := committeeSet.Committee()[0]
+	signer := makeSigner(keys[member.Address], member.Address)
+	height := big.NewInt(3)
+
+	backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+	backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(signer).AnyTimes()
+	backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any()).AnyTimes()
+
+	messages := message.NewMap()
+	c := &Core{
+		address:        member.Address,
+		backend:        backendMock,
+		messages:       messages,
+		logger:         log.Root(),
+		prevoteTimeout: NewTimeout(Prevote, log.Root()),
+		committee:      committeeSet,
+		height:         height,
+		lockedRound:    -1,
+		validRound:     -1,
+	}
+	c.SetDefaultHandlers()
+
+	changes := make(chan interfaces.LockChange, 1)
+	sub := c.SubscribeLockChange(changes)
+	defer sub.Unsubscribe()
+	// a subscriber which isn't ready to receive doesn't block the consensus loop
+	slow := c.SubscribeLockChange(make(chan interfaces.LockChange))
+	defer slow.Unsubscribe()
+
+	// lockRound gets a quorum of prevotes for the proposal of the given round while at the prevote step
+	lockRound := func(round int64, block *types.Block) {
+		c.setRound(round)
+		c.SetStep(Prevote)
+		c.setValidRoundAndValue = false
+		c.curRoundMessages = messages.GetOrCreate(round)
+		c.curRoundMessages.SetProposal(message.NewPropose(round, height.Uint64(), -1, block, signer), true)
+		prevote := message.NewPrevote(round, height.Uint64(), block.Hash(), signer).MustVerify(stubVerifierWithPower(3))
+		require.NoError(t, c.prevoter.HandlePrevote(context.Background(), prevote))
+		require.Equal(t, Precommit, c.Step())
+	}
+
+	block := types.NewBlockWithHeader(&types.Header{Number: height})
+	lockRound(1, block)
+	require.Equal(t, interfaces.LockChange{
+		Height:   height.Uint64(),
+		OldRound: -1,
+		NewRound: 1,
+		NewValue: block.Hash(),
+	}, <-changes)
+	require.Equal(t, int64(1), LockDroppedMeter.Count())
+
+	// locking another value on a later round is notified with the previous lock
+	other := types.NewBlockWithHeader(&types.Header{Number: height, GasLimit: 1})
+	lockRound(2, other)
+	require.Equal(t, interfaces.LockChange{
+		Height:   height.Uint64(),
+		OldRound: 1,
+		OldValue: block.Hash(),
+		NewRound: 2,
+		NewValue: other.Hash(),
+	}, <-changes)
+
+	// setting the same lock again isn't a change
+	c.setLock(2, other)
+	select {
+	case change := <-changes:
+		t.Fatalf("unexpected lock change %+v", change)
+	default:
+	}
+
+	// unlocking is notified too
+	c.setLock(-1, nil)
+	require.Equal(t, interfaces.LockChange{
+		Height:   height.Uint64(),
+		OldRound: 2,
+		OldValue: other.Hash(),
+		NewRound: -1,
+		NewValue: common.Hash{},
+	}, <-changes)
+	require.Equal(t, int64(3), LockDroppedMeter.Count())
+}
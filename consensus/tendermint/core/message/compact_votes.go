@@ -0,0 +1,73 @@
+package message
+
+import (
+	"errors"
+	"math/big"
+	"math/bits"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/types"
+)
+
+var errVoterOutOfCommittee = errors.New("voter index beyond the committee size")
+
+// CompactVotes designates the votes of the same type cast at a round for a value by their voters, as a bitmap of
+// their indexes in the committee. It is used to announce and request votes without carrying their signatures.
+type CompactVotes struct {
+	Code   uint8
+	Height uint64
+	Round  uint64
+	Value  common.Hash
+	Voters []byte
+}
+
+// NewCompactVotes returns an empty set of the votes for the given value, code being PrevoteCode or PrecommitCode.
+func NewCompactVotes(code uint8, height uint64, round int64, value common.Hash) *CompactVotes {
+	return &CompactVotes{Code: code, Height: height, Round: uint64(round), Value: value}
+}
+
+// Add marks the committee member of the given index as voter.
+func (v *CompactVotes) Add(index int) {
+	for len(v.Voters) <= index/8 {
+		v.Voters = append(v.Voters, 0)
+	}
+	v.Voters[index/8] |= 1 << (index % 8)
+}
+
+// Has returns true if the committee member of the given index is a voter.
+func (v *CompactVotes) Has(index int) bool {
+	return index/8 < len(v.Voters) && v.Voters[index/8]&(1<<(index%8)) != 0
+}
+
+// Indexes returns the committee indexes of the voters, in ascending order.
+func (v *CompactVotes) Indexes() []int {
+	indexes := make([]int, 0, v.Count())
+	for i, b := range v.Voters {
+		for ; b != 0; b &= b - 1 {
+			indexes = append(indexes, i*8+bits.TrailingZeros8(b))
+		}
+	}
+	return indexes
+}
+
+// Count returns the number of voters.
+func (v *CompactVotes) Count() int {
+	count := 0
+	for _, b := range v.Voters {
+		count += bits.OnesCount8(b)
+	}
+	return count
+}
+
+// Power returns the voting power of the voters in the given committee, the one of the votes height. An error is
+// returned if a voter isn't part of the committee.
+func (v *CompactVotes) Power(committee types.Committee) (*big.Int, error) {
+	power := new(big.Int)
+	for _, index := range v.Indexes() {
+		if index >= len(committee) {
+			return nil, errVoterOutOfCommittee
+		}
+		power.Add(power, committee[index].VotingPower)
+	}
+	return power, nil
+}
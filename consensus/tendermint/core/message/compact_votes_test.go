@@ -0,0 +1,66 @@
+package message
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/rlp"
+)
+
+func TestCompactVotes(t *testing.T) {
+	committee := make(types.Committee, 20)
+	for i := range committee {
+		committee[i] = types.CommitteeMember{
+			Address:     common.BigToAddress(big.NewInt(int64(i + 1))),
+			VotingPower: big.NewInt(int64(10 * (i + 1))),
+		}
+	}
+	value := common.HexToHash("0xabcd")
+
+	votes := NewCompactVotes(PrecommitCode, 9, 2, value)
+	voters := []int{0, 3, 8, 17, 19}
+	want := new(big.Int)
+	for _, i := range voters {
+		votes.Add(i)
+		want.Add(want, committee[i].VotingPower)
+	}
+	votes.Add(8) // voters are counted once
+
+	t.Run("power reconstructed from the decoded form", func(t *testing.T) {
+		payload, err := rlp.EncodeToBytes(votes)
+		require.NoError(t, err)
+		// the bitmap is the only per voter data sent
+		assert.Len(t, votes.Voters, 3)
+
+		decoded := new(CompactVotes)
+		require.NoError(t, rlp.DecodeBytes(payload, decoded))
+		assert.Equal(t, votes, decoded)
+		assert.Equal(t, len(voters), decoded.Count())
+		assert.Equal(t, voters, decoded.Indexes())
+		assert.True(t, decoded.Has(17))
+		assert.False(t, decoded.Has(1))
+		assert.False(t, decoded.Has(100))
+
+		power, err := decoded.Power(committee)
+		require.NoError(t, err)
+		assert.Equal(t, want, power)
+	})
+
+	t.Run("voters beyond the committee refused", func(t *testing.T) {
+		_, err := votes.Power(committee[:10])
+		assert.ErrorIs(t, err, errVoterOutOfCommittee)
+	})
+
+	t.Run("no voters", func(t *testing.T) {
+		empty := NewCompactVotes(PrevoteCode, 9, 2, common.Hash{})
+		power, err := empty.Power(committee)
+		require.NoError(t, err)
+		assert.Equal(t, 0, power.Sign())
+		assert.Empty(t, empty.Indexes())
+	})
+}
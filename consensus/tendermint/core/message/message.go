@@ -619,6 +619,24 @@ func NewFakePrevote(f Fake) *Prevote {
 	}
 }
 
+// NewFakePropose returns a proposal of the given valid round and block, which may be nil.
+func NewFakePropose(f Fake, validRound int64, block *types.Block) *Propose {
+	return &Propose{
+		block:      block,
+		validRound: validRound,
+		base: base{
+			round:     f.FakeRound,
+			height:    f.FakeHeight,
+			signature: f.FakeSignature,
+			payload:   f.FakePayload,
+			power:     f.FakePower,
+			sender:    f.FakeSender,
+			hash:      f.FakeHash,
+			verified:  true,
+		},
+	}
+}
+
 func NewFakePrecommit(f Fake) *Precommit {
 	return &Precommit{
 		value: f.FakeValue,
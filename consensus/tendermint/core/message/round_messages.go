@@ -36,6 +36,14 @@ func (s *Map) GetOrCreate(round int64) *RoundMessages {
 	return state
 }
 
+// Get returns the messages of the given round, if any were received, without creating its state otherwise.
+func (s *Map) Get(round int64) (*RoundMessages, bool) {
+	s.RLock()
+	defer s.RUnlock()
+	state, ok := s.internal[round]
+	return state, ok
+}
+
 func (s *Map) All() []Msg {
 	s.RLock()
 	defer s.RUnlock()
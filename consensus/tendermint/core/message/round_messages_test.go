@@ -27,6 +27,17 @@ func TestGetOrCreate(t *testing.T) {
 	assert.Equal(t, rm1, messages.GetOrCreate(1))
 }
 
+func TestGet(t *testing.T) {
+	messages := NewMap()
+	_, ok := messages.Get(0)
+	assert.False(t, ok)
+	assert.Equal(t, 0, len(messages.internal))
+	rm0 := messages.GetOrCreate(0)
+	got, ok := messages.Get(0)
+	assert.True(t, ok)
+	assert.Equal(t, rm0, got)
+}
+
 func TestGetMessages(t *testing.T) {
 	messages := NewMap()
 
@@ -38,7 +38,49 @@ var (
 	ProposalSentTimer     = metrics.NewRegisteredTimer("tendermint/proposal/sent", nil)     // time between round start and proposal sent
 	ProposalReceivedTimer = metrics.NewRegisteredTimer("tendermint/proposal/received", nil) // time between round start and proposal received
 	ProposalVerifiedTimer = metrics.NewRegisteredTimer("tendermint/proposal/verified", nil) // time to verify proposal
-	CommitTimer           = metrics.NewRegisteredTimer("tendermint/commit", nil)            // time between round start and commit (--> block queued for insertion)
+
+	PrevoteSentTimer       = metrics.NewRegisteredTimer("tendermint/prevote/sent", nil)       // time between round start and prevote sent
+	PrevoteReceivedTimer   = metrics.NewRegisteredTimer("tendermint/prevote/received", nil)   // time between round start and prevote received
+	PrecommitSentTimer     = metrics.NewRegisteredTimer("tendermint/precommit/sent", nil)     // time between round start and precommit sent
+	PrecommitReceivedTimer = metrics.NewRegisteredTimer("tendermint/precommit/received", nil) // time between round start and precommit received
+	CommitTimer            = metrics.NewRegisteredTimer("tendermint/commit", nil)             // time between round start and commit (--> block queued for insertion)
+
+	LastCommitGauge = metrics.NewRegisteredGauge("tendermint/commit/last", nil) // unix time in seconds of the last successful commit, for liveness alerting
+
+	QuorumLatencyTimer     = metrics.NewRegisteredTimer("tendermint/quorum/latency", nil)                                             // time between the first precommit received in the round and the commit
+	QuorumSurplusHistogram = metrics.NewRegisteredHistogram("tendermint/quorum/surplus", nil, metrics.NewExpDecaySample(1028, 0.015)) // precommits for the committed value beyond the fewest forming a quorum
+
+	ProposalSizeHistogram             = metrics.NewRegisteredHistogram("tendermint/proposal/size", nil, metrics.NewExpDecaySample(1028, 0.015))              // RLP size in bytes of the verified proposals
+	ProposalVerificationCostHistogram = metrics.NewRegisteredHistogram("tendermint/proposal/verification/cost", nil, metrics.NewExpDecaySample(1028, 0.015)) // proposal verification time in microseconds per KiB, high values hint at blocks expensive to verify for their size
+
+	ProposalVerificationTimeoutMeter = metrics.NewRegisteredMeter("tendermint/proposal/verification/timeout", nil) // proposals whose verification timed out
+	ProposalVerificationRetryMeter   = metrics.NewRegisteredMeter("tendermint/proposal/verification/retry", nil)   // proposal verifications retried after a transient failure
+	ProposalVerificationGraceMeter   = metrics.NewRegisteredMeter("tendermint/proposal/verification/grace", nil)   // proposal verifications attempted again after the grace period
+	ProposalBaseFeeRetryMeter        = metrics.NewRegisteredMeter("tendermint/proposal/verification/basefee", nil) // proposal verifications retried after a base fee mismatch during a head transition
+	ProposalCircuitBreakerMeter      = metrics.NewRegisteredMeter("tendermint/proposal/circuitbreaker", nil)       // times proposing got halted after repeated self-proposal verification failures
+	ProposalBlacklistedMeter         = metrics.NewRegisteredMeter("tendermint/proposal/blacklisted", nil)          // proposals skipped because their proposer is blacklisted
+	ProposalTimestampRegressionMeter = metrics.NewRegisteredMeter("tendermint/proposal/timestamp/regression", nil) // proposals rejected for a timestamp not greater than their parent's
+	ProposalEquivocationMeter        = metrics.NewRegisteredMeter("tendermint/proposal/equivocation", nil)         // own proposals refused for conflicting with an earlier one
+	ProposalNonCanonicalParentMeter  = metrics.NewRegisteredMeter("tendermint/proposal/noncanonical", nil)         // proposals nil prevoted for not building on our canonical chain
+	ProposalFutureRoundDroppedMeter  = metrics.NewRegisteredMeter("tendermint/proposal/futureround/dropped", nil)  // proposals dropped for a round too far ahead
+	ProposalStaleDroppedMeter        = metrics.NewRegisteredMeter("tendermint/proposal/stale/dropped", nil)        // own proposals not broadcast as the round moved on
+	ProposalSigningFailedMeter       = metrics.NewRegisteredMeter("tendermint/proposal/signing/failed", nil)       // own proposals dropped as the proposal signer failed or timed out
+	ProposalOutcomeDroppedMeter      = metrics.NewRegisteredMeter("tendermint/proposal/outcome/dropped", nil)      // proposal outcomes missed by slow subscribers
+	ProposalInProposeMeter           = metrics.NewRegisteredMeter("tendermint/proposal/step/propose", nil)         // current round proposals received in the propose step
+	ProposalInPrevoteMeter           = metrics.NewRegisteredMeter("tendermint/proposal/step/prevote", nil)         // current round proposals received after prevoting
+	ProposalInPrecommitMeter         = metrics.NewRegisteredMeter("tendermint/proposal/step/precommit", nil)       // current round proposals received after precommitting
+	ProposalConversionVerifiedMeter  = metrics.NewRegisteredMeter("tendermint/proposal/conversion/verified", nil)  // current round proposals of other validators verified successfully
+	ProposalConversionCommitMeter    = metrics.NewRegisteredMeter("tendermint/proposal/conversion/commit", nil)    // proposals of other validators committed in the round they were proposed, a falling ratio to the verified ones hints at disagreement
+
+	PrevoteNilSentMeter   = metrics.NewRegisteredMeter("tendermint/prevote/sent/nil", nil)   // nil prevotes sent, a rising rate hints at proposer or network issues
+	PrevoteValueSentMeter = metrics.NewRegisteredMeter("tendermint/prevote/sent/value", nil) // prevotes sent for a proposed value
+
+	CommitteeChangeMeter = metrics.NewRegisteredMeter("tendermint/committee/change", nil) // committee changes detected within a height
+	ResyncRequestMeter   = metrics.NewRegisteredMeter("tendermint/resync/request", nil)   // chain resyncs requested as consensus stalled despite receiving proposals
+	CommitDeferredMeter  = metrics.NewRegisteredMeter("tendermint/commit/deferred", nil)  // commits deferred as the precommits didn't reach the minimum participation
+	ProposerTimeoutMeter = metrics.NewRegisteredMeter("tendermint/proposer/timeout", nil) // rounds timed out at the propose step, also counted per expected proposer under tendermint/proposer/timeout/<address>
+	LockDroppedMeter     = metrics.NewRegisteredMeter("tendermint/lock/dropped", nil)     // lock changes missed by slow subscribers
+	CommitDroppedMeter   = metrics.NewRegisteredMeter("tendermint/commit/dropped", nil)   // commit notifications missed by slow subscribers
 
 	// Instant metrics
 
@@ -57,5 +99,10 @@ var (
 	ProposalSentBg     = metrics.NewRegisteredBufferedGauge("tendermint/proposal/sent.bg", nil)     // time between round start and proposal sent
 	ProposalReceivedBg = metrics.NewRegisteredBufferedGauge("tendermint/proposal/received.bg", nil) // time between round start and proposal received
 	ProposalVerifiedBg = metrics.NewRegisteredBufferedGauge("tendermint/proposal/verified.bg", nil) // time to verify proposal
-	CommitBg           = metrics.NewRegisteredBufferedGauge("tendermint/commit.bg", nil)            // time between round start and commit (--> block queued for insertion)
+
+	PrevoteSentBg       = metrics.NewRegisteredBufferedGauge("tendermint/prevote/sent.bg", nil)       // time between round start and prevote sent
+	PrevoteReceivedBg   = metrics.NewRegisteredBufferedGauge("tendermint/prevote/received.bg", nil)   // time between round start and prevote received
+	PrecommitSentBg     = metrics.NewRegisteredBufferedGauge("tendermint/precommit/sent.bg", nil)     // time between round start and precommit sent
+	PrecommitReceivedBg = metrics.NewRegisteredBufferedGauge("tendermint/precommit/received.bg", nil) // time between round start and precommit received
+	CommitBg            = metrics.NewRegisteredBufferedGauge("tendermint/commit.bg", nil)             // time between round start and commit (--> block queued for insertion)
 )
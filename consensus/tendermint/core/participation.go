@@ -0,0 +1,43 @@
+package core
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+)
+
+// SetMinParticipation sets the percentage of the total voting power which must have precommitted in the round of a
+// block, for any value or nil, for the block to be committed. A quorum of precommits for the block is still
+// required, the setting can only delay commits until more precommits are received, or the round times out, to avoid
+// committing on a barely live network. Zero disables it.
+func (c *Core) SetMinParticipation(percent uint64) error {
+	if percent > 100 {
+		return fmt.Errorf("%w: %d%%", constants.ErrInvalidMinParticipation, percent)
+	}
+	c.minParticipation = percent
+	return nil
+}
+
+// hasMinParticipation returns true if the precommits of the round reach the minimum participation.
+func (c *Core) hasMinParticipation(messages *message.RoundMessages) bool {
+	if c.minParticipation == 0 {
+		return true
+	}
+	participation := new(big.Int).Mul(messages.PrecommitsTotalPower(), big.NewInt(100))
+	required := new(big.Int).Mul(c.CommitteeSet().Committee().TotalVotingPower(), new(big.Int).SetUint64(c.minParticipation))
+	return participation.Cmp(required) >= 0
+}
+
+// canCommit returns true if the precommits of the round reach the minimum participation, to be checked along the
+// quorum in the upon-conditions leading to a commit. Otherwise the commit is deferred and the round goes on as if
+// there was no quorum.
+func (c *Core) canCommit(round int64, messages *message.RoundMessages) bool {
+	if c.hasMinParticipation(messages) {
+		return true
+	}
+	CommitDeferredMeter.Mark(1)
+	c.logger.Info("Commit deferred until the minimum participation is reached", "round", round, "participation", messages.PrecommitsTotalPower(), "minParticipation", c.minParticipation)
+	return false
+}
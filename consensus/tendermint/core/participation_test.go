@@ -0,0 +1,131 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/log"
+)
+
+func TestMinParticipation(t *testing.T) {
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	me := committeeSet.Committee()[1].Address
+	proposer := committeeSet.GetProposer(2).Address
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)})
+
+	proposal := message.NewPropose(2, 1, -1, block, makeSigner(keys[proposer], proposer)).MustVerify(stubVerifier)
+
+	newCore := func(t *testing.T, minParticipation uint64) (*Core, *interfaces.MockBackend) {
+		enableTestMeters(t, &CommitDeferredMeter)
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		messages := message.NewMap()
+		c := &Core{
+			address:          me,
+			logger:           log.Root(),
+			backend:          backendMock,
+			messages:         messages,
+			curRoundMessages: messages.GetOrCreate(2),
+			round:            2,
+			height:           big.NewInt(1),
+			committee:        committeeSet,
+			step:             Precommit,
+			lockedRound:      -1,
+			validRound:       -1,
+			proposeTimeout:   NewTimeout(Propose, log.Root()),
+			precommitTimeout: NewTimeout(Precommit, log.Root()),
+		}
+		c.SetDefaultHandlers()
+		require.NoError(t, c.SetMinParticipation(minParticipation))
+		t.Cleanup(func() {
+			_ = c.precommitTimeout.StopTimer()
+		})
+		return c, backendMock
+	}
+	precommit := func(i int, value common.Hash) *message.Precommit {
+		member := committeeSet.Committee()[i].Address
+		return message.NewPrecommit(2, 1, value, makeSigner(keys[member], member)).MustVerify(stubVerifier)
+	}
+
+	t.Run("participation at the threshold, block committed", func(t *testing.T) {
+		c, backendMock := newCore(t, 75)
+		c.curRoundMessages.SetProposal(proposal, true)
+		backendMock.EXPECT().Commit(block, int64(2), gomock.Any())
+		for i := 0; i < 3; i++ {
+			require.NoError(t, c.precommiter.HandlePrecommit(context.Background(), precommit(i, block.Hash())))
+		}
+		require.Equal(t, PrecommitDone, c.step)
+		require.Equal(t, int64(0), CommitDeferredMeter.Count())
+	})
+
+	t.Run("participation below the threshold, commit deferred", func(t *testing.T) {
+		c, backendMock := newCore(t, 100)
+		c.curRoundMessages.SetProposal(proposal, true)
+		for i := 0; i < 3; i++ {
+			require.NoError(t, c.precommiter.HandlePrecommit(context.Background(), precommit(i, block.Hash())))
+		}
+		// the quorum is reached but not the participation, the round keeps going
+		require.Equal(t, Precommit, c.step)
+		require.True(t, c.precommitTimeout.TimerStarted())
+		require.NoError(t, c.precommitTimeout.StopTimer())
+		require.Equal(t, int64(1), CommitDeferredMeter.Count())
+
+		// a late nil precommit completes the participation
+		backendMock.EXPECT().Commit(block, int64(2), gomock.Any())
+		require.NoError(t, c.precommiter.HandlePrecommit(context.Background(), precommit(3, common.Hash{})))
+		require.Equal(t, PrecommitDone, c.step)
+	})
+
+	t.Run("proposal received after a quorum of precommits, commit deferred", func(t *testing.T) {
+		c, backendMock := newCore(t, 100)
+		for i := 0; i < 3; i++ {
+			require.NoError(t, c.precommiter.HandlePrecommit(context.Background(), precommit(i, block.Hash())))
+		}
+		require.True(t, c.precommitTimeout.TimerStarted())
+
+		// the proposal is handled like any other, the precommit timeout keeps running
+		backendMock.EXPECT().VerifyProposal(block)
+		require.NoError(t, c.proposer.HandleProposal(context.Background(), proposal))
+		require.Equal(t, proposal, c.curRoundMessages.Proposal())
+		require.Equal(t, Precommit, c.step)
+		require.True(t, c.precommitTimeout.TimerStarted())
+		require.Equal(t, int64(1), CommitDeferredMeter.Count())
+
+		backendMock.EXPECT().Commit(block, int64(2), gomock.Any())
+		require.NoError(t, c.precommiter.HandlePrecommit(context.Background(), precommit(3, common.Hash{})))
+		require.Equal(t, PrecommitDone, c.step)
+	})
+
+	t.Run("old round quorum of precommits, commit deferred", func(t *testing.T) {
+		c, backendMock := newCore(t, 100)
+		oldRoundMessages := c.messages.GetOrCreate(2)
+		oldRoundMessages.SetProposal(proposal, false)
+		c.curRoundMessages = c.messages.GetOrCreate(3)
+		c.round = 3
+		for i := 0; i < 3; i++ {
+			err := c.precommiter.HandlePrecommit(context.Background(), precommit(i, block.Hash()))
+			require.ErrorIs(t, err, constants.ErrOldRoundMessage)
+		}
+		require.Equal(t, Precommit, c.step)
+		require.Equal(t, int64(1), CommitDeferredMeter.Count())
+
+		backendMock.EXPECT().VerifyProposal(block)
+		backendMock.EXPECT().Commit(block, int64(2), gomock.Any())
+		require.NoError(t, c.precommiter.HandlePrecommit(context.Background(), precommit(3, common.Hash{})))
+		require.Equal(t, PrecommitDone, c.step)
+	})
+
+	t.Run("participation above 100% refused", func(t *testing.T) {
+		c, _ := newCore(t, 0)
+		require.ErrorIs(t, c.SetMinParticipation(101), constants.ErrInvalidMinParticipation)
+		require.Equal(t, uint64(0), c.minParticipation)
+	})
+}
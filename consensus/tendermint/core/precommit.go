@@ -8,6 +8,7 @@ import (
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/consensus/tendermint/core/constants"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/metrics"
 )
 
 type Precommiter struct {
@@ -27,6 +28,11 @@ func (c *Precommiter) SendPrecommit(ctx context.Context, isNil bool) {
 	precommit := message.NewPrecommit(c.Round(), c.Height().Uint64(), value, c.backend.Sign)
 	c.LogPrecommitMessageEvent("Precommit sent", precommit, c.address.String(), "broadcast")
 	c.sentPrecommit = true
+	if metrics.Enabled {
+		now := c.Clock().Now()
+		PrecommitSentTimer.Update(now.Sub(c.newRound))
+		PrecommitSentBg.Add(now.Sub(c.newRound).Nanoseconds())
+	}
 	c.Broadcaster().Broadcast(precommit)
 }
 
@@ -37,8 +43,9 @@ func (c *Precommiter) HandlePrecommit(ctx context.Context, precommit *message.Pr
 			// in this old round.
 			roundMessages := c.messages.GetOrCreate(precommit.R())
 			roundMessages.AddPrecommit(precommit)
+			c.recordPrecommitArrival(precommit.R())
 			oldRoundProposal := roundMessages.Proposal()
-			if oldRoundProposal != nil && roundMessages.PrecommitsPower(oldRoundProposal.Block().Hash()).Cmp(c.CommitteeSet().Quorum()) >= 0 {
+			if oldRoundProposal != nil && roundMessages.PrecommitsPower(oldRoundProposal.Block().Hash()).Cmp(c.CommitteeSet().Quorum()) >= 0 && c.canCommit(precommit.R(), roundMessages) {
 				c.logger.Info("Quorum on a old round proposal", "round", precommit.R())
 				if !roundMessages.IsProposalVerified() {
 					if _, err2 := c.backend.VerifyProposal(roundMessages.Proposal().Block()); err2 != nil {
@@ -46,7 +53,7 @@ func (c *Precommiter) HandlePrecommit(ctx context.Context, precommit *message.Pr
 						panic("Fatal Safety Error: Quorum on unverifiable proposal")
 					}
 				}
-				c.Commit(precommit.R(), c.curRoundMessages)
+				c.Commit(precommit.R(), roundMessages)
 				return nil
 			}
 		}
@@ -58,8 +65,15 @@ func (c *Precommiter) HandlePrecommit(ctx context.Context, precommit *message.Pr
 	curProposalHash := c.curRoundMessages.ProposalHash()
 	// We don't care about which step we are in to accept a precommit, since it has the highest importance
 	c.curRoundMessages.AddPrecommit(precommit)
+	c.recordPrecommitArrival(precommit.R())
+	// received a current round precommit
+	if metrics.Enabled {
+		now := c.Clock().Now()
+		PrecommitReceivedTimer.Update(now.Sub(c.newRound))
+		PrecommitReceivedBg.Add(now.Sub(c.newRound).Nanoseconds())
+	}
 	c.LogPrecommitMessageEvent("MessageEvent(Precommit): Received", precommit, precommit.Sender().String(), c.address.String())
-	if curProposalHash != (common.Hash{}) && c.curRoundMessages.PrecommitsPower(curProposalHash).Cmp(c.CommitteeSet().Quorum()) >= 0 {
+	if curProposalHash != (common.Hash{}) && c.curRoundMessages.PrecommitsPower(curProposalHash).Cmp(c.CommitteeSet().Quorum()) >= 0 && c.canCommit(c.Round(), c.curRoundMessages) {
 		if err := c.precommitTimeout.StopTimer(); err != nil {
 			return err
 		}
@@ -73,7 +87,7 @@ func (c *Precommiter) HandlePrecommit(ctx context.Context, precommit *message.Pr
 		}
 
 		// Line 47 in Algorithm 1 of The latest gossip on BFT consensus
-	} else if !c.precommitTimeout.TimerStarted() && c.curRoundMessages.PrecommitsTotalPower().Cmp(c.CommitteeSet().Quorum()) >= 0 {
+	} else if !c.precommitTimeout.TimerStarted() && c.curRoundMessages.PrecommitsTotalPower().Cmp(c.CommitteeSet().Quorum()) >= 0 && c.roundTimeoutsEnabled() {
 		timeoutDuration := c.timeoutPrecommit(c.Round())
 		c.precommitTimeout.ScheduleTimeout(timeoutDuration, c.Round(), c.Height(), c.onTimeoutPrecommit)
 		c.logger.Debug("Scheduled Precommit Timeout", "Timeout Duration", timeoutDuration)
@@ -105,7 +119,7 @@ func (c *Precommiter) LogPrecommitMessageEvent(message string, precommit *messag
 		"msgRound", precommit.R(),
 		"currentStep", c.step,
 		"isProposer", c.IsProposer(),
-		"currentProposer", c.CommitteeSet().GetProposer(c.Round()),
+		"currentProposer", c.proposerOf(c.Round()),
 		"isNilMsg", precommit.Value() == common.Hash{},
 		"hash", precommit.Value(),
 		"type", "Precommit",
@@ -366,3 +366,123 @@ func TestHandleCommit(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestPrecommitMetrics(t *testing.T) {
+	enableTestTimers(t, &PrecommitSentTimer, &PrecommitReceivedTimer)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	member := committeeSet.Committee()[1]
+	messages := message.NewMap()
+	curRoundMessages := messages.GetOrCreate(2)
+	backendMock := interfaces.NewMockBackend(ctrl)
+	backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(keys[member.Address], member.Address))
+	backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any())
+	logger := log.New("backend", "test", "id", 0)
+	c := &Core{
+		address:          member.Address,
+		logger:           logger,
+		backend:          backendMock,
+		messages:         messages,
+		curRoundMessages: curRoundMessages,
+		round:            2,
+		height:           big.NewInt(3),
+		committee:        committeeSet,
+		step:             Precommit,
+		precommitTimeout: NewTimeout(Precommit, logger),
+		newRound:         time.Now(),
+	}
+	c.SetDefaultHandlers()
+
+	c.precommiter.SendPrecommit(context.Background(), true)
+	require.Equal(t, int64(1), PrecommitSentTimer.Count())
+
+	sender := committeeSet.Committee()[0]
+	precommit := message.NewPrecommit(2, 3, common.Hash{}, makeSigner(keys[sender.Address], sender.Address)).MustVerify(stubVerifier)
+	require.NoError(t, c.precommiter.HandlePrecommit(context.Background(), precommit))
+	require.Equal(t, int64(1), PrecommitReceivedTimer.Count())
+}
+
+func TestQuorumMetrics(t *testing.T) {
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	me := committeeSet.Committee()[1].Address
+	proposer := committeeSet.GetProposer(2).Address
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)})
+
+	newCore := func(t *testing.T) (*Core, *interfaces.MockBackend, *fakeClock, *message.Propose) {
+		enableTestTimers(t, &QuorumLatencyTimer)
+		enableTestHistograms(t, &QuorumSurplusHistogram)
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		clock := newFakeClock()
+		messages := message.NewMap()
+		c := &Core{
+			address:          me,
+			logger:           log.Root(),
+			backend:          backendMock,
+			messages:         messages,
+			curRoundMessages: messages.GetOrCreate(2),
+			round:            2,
+			height:           big.NewInt(1),
+			committee:        committeeSet,
+			step:             Precommit,
+			lockedRound:      -1,
+			validRound:       -1,
+			proposeTimeout:   NewTimeout(Propose, log.Root()),
+			precommitTimeout: NewTimeout(Precommit, log.Root()),
+		}
+		c.SetClock(clock)
+		c.SetDefaultHandlers()
+		proposal := message.NewPropose(2, 1, -1, block, makeSigner(keys[proposer], proposer)).MustVerify(stubVerifier)
+		return c, backendMock, clock, proposal
+	}
+	precommit := func(i int) *message.Precommit {
+		member := committeeSet.Committee()[i].Address
+		return message.NewPrecommit(2, 1, block.Hash(), makeSigner(keys[member], member)).MustVerify(stubVerifier)
+	}
+
+	t.Run("quorum formed by the precommits", func(t *testing.T) {
+		c, backendMock, clock, proposal := newCore(t)
+		c.curRoundMessages.SetProposal(proposal, true)
+		backendMock.EXPECT().Commit(gomock.Any(), int64(2), gomock.Any())
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, c.precommiter.HandlePrecommit(context.Background(), precommit(i)))
+			clock.Advance(100 * time.Millisecond)
+		}
+		require.Equal(t, int64(1), QuorumLatencyTimer.Count())
+		require.Equal(t, int64(200*time.Millisecond), QuorumLatencyTimer.Max())
+		require.Equal(t, int64(1), QuorumSurplusHistogram.Count())
+		require.Equal(t, int64(0), QuorumSurplusHistogram.Max())
+	})
+
+	t.Run("quorum completed by a late proposal", func(t *testing.T) {
+		c, backendMock, clock, proposal := newCore(t)
+		for i := 0; i < 4; i++ {
+			require.NoError(t, c.precommiter.HandlePrecommit(context.Background(), precommit(i)))
+		}
+		// short of the precommit timeout started on the quorum
+		clock.Advance(500 * time.Millisecond)
+		backendMock.EXPECT().VerifyProposal(gomock.Any())
+		backendMock.EXPECT().Commit(gomock.Any(), int64(2), gomock.Any())
+
+		require.NoError(t, c.proposer.HandleProposal(context.Background(), proposal))
+		require.Equal(t, int64(1), QuorumLatencyTimer.Count())
+		require.Equal(t, int64(500*time.Millisecond), QuorumLatencyTimer.Max())
+		// all four precommits were in, one more than the quorum of three
+		require.Equal(t, int64(1), QuorumSurplusHistogram.Max())
+	})
+}
+
+func TestSurplusPrecommits(t *testing.T) {
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	var precommits []*message.Precommit
+	for _, member := range committeeSet.Committee() {
+		precommits = append(precommits, message.NewPrecommit(0, 1, common.Hash{1}, makeSigner(keys[member.Address], member.Address)).MustVerify(stubVerifier))
+	}
+	quorum := committeeSet.Quorum()
+	require.Equal(t, 1, surplusPrecommits(precommits, quorum))
+	require.Equal(t, 0, surplusPrecommits(precommits[:3], quorum))
+	// short of the quorum
+	require.Equal(t, 0, surplusPrecommits(precommits[:2], quorum))
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/consensus/tendermint/core/constants"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/metrics"
 )
 
 type Prevoter struct {
@@ -26,6 +27,16 @@ func (c *Prevoter) SendPrevote(ctx context.Context, isNil bool) {
 	prevote := message.NewPrevote(c.Round(), c.Height().Uint64(), value, c.backend.Sign)
 	c.LogPrevoteMessageEvent("MessageEvent(Prevote): Sent", prevote, c.address.String(), "broadcast")
 	c.sentPrevote = true
+	if metrics.Enabled {
+		now := c.Clock().Now()
+		PrevoteSentTimer.Update(now.Sub(c.newRound))
+		PrevoteSentBg.Add(now.Sub(c.newRound).Nanoseconds())
+		if isNil {
+			PrevoteNilSentMeter.Mark(1)
+		} else {
+			PrevoteValueSentMeter.Mark(1)
+		}
+	}
 	c.Broadcaster().Broadcast(prevote)
 }
 
@@ -37,20 +48,8 @@ func (c *Prevoter) HandlePrevote(ctx context.Context, prevote *message.Prevote)
 			oldRoundMessages := c.messages.GetOrCreate(prevote.R())
 			oldRoundMessages.AddPrevote(prevote)
 
-			// Line 28 in Algorithm 1 of The latest gossip on BFT consensus.
-			if c.step == Propose {
-				// ProposalBlock would be nil if node haven't received the proposal yet.
-				if proposal := c.curRoundMessages.Proposal(); proposal != nil {
-					vr := proposal.ValidRound()
-					h := proposal.Block().Hash()
-					rs := c.messages.GetOrCreate(vr)
-
-					if vr >= 0 && vr < c.Round() && rs.PrevotesPower(h).Cmp(c.CommitteeSet().Quorum()) >= 0 {
-						c.SendPrevote(ctx, !(c.lockedRound <= vr || h == c.lockedValue.Hash()))
-						c.SetStep(Prevote)
-						return nil
-					}
-				}
+			if c.prevoteOnValidRoundQuorum(ctx) {
+				return nil
 			}
 		}
 		return err
@@ -62,6 +61,13 @@ func (c *Prevoter) HandlePrevote(ctx context.Context, prevote *message.Prevote)
 	// votes from other nodes.
 	c.curRoundMessages.AddPrevote(prevote)
 
+	// received a current round prevote
+	if metrics.Enabled {
+		now := c.Clock().Now()
+		PrevoteReceivedTimer.Update(now.Sub(c.newRound))
+		PrevoteReceivedBg.Add(now.Sub(c.newRound).Nanoseconds())
+	}
+
 	c.LogPrevoteMessageEvent("MessageEvent(Prevote): Received", prevote, prevote.Sender().String(), c.address.String())
 
 	// Now we can add the preVote to our current round state
@@ -76,8 +82,7 @@ func (c *Prevoter) HandlePrevote(ctx context.Context, prevote *message.Prevote)
 			c.logger.Debug("Stopped Scheduled Prevote Timeout")
 
 			if c.step == Prevote {
-				c.lockedValue = curProposal.Block()
-				c.lockedRound = c.Round()
+				c.setLock(c.Round(), curProposal.Block())
 				c.precommiter.SendPrecommit(ctx, false)
 				c.SetStep(Precommit)
 			}
@@ -93,7 +98,7 @@ func (c *Prevoter) HandlePrevote(ctx context.Context, prevote *message.Prevote)
 			c.precommiter.SendPrecommit(ctx, true)
 			c.SetStep(Precommit)
 			// Line 34 in Algorithm 1 of The latest gossip on BFT consensus
-		} else if c.step == Prevote && !c.prevoteTimeout.TimerStarted() && !c.sentPrecommit && c.curRoundMessages.PrevotesTotalPower().Cmp(c.CommitteeSet().Quorum()) >= 0 {
+		} else if c.step == Prevote && !c.prevoteTimeout.TimerStarted() && !c.sentPrecommit && c.curRoundMessages.PrevotesTotalPower().Cmp(c.CommitteeSet().Quorum()) >= 0 && c.roundTimeoutsEnabled() {
 			timeoutDuration := c.timeoutPrevote(c.Round())
 			c.prevoteTimeout.ScheduleTimeout(timeoutDuration, c.Round(), c.Height(), c.onTimeoutPrevote)
 			c.logger.Debug("Scheduled Prevote Timeout", "Timeout Duration", timeoutDuration)
@@ -103,6 +108,28 @@ func (c *Prevoter) HandlePrevote(ctx context.Context, prevote *message.Prevote)
 	return nil
 }
 
+// prevoteOnValidRoundQuorum evaluates the line 28 in Algorithm 1 of The latest gossip on BFT consensus for the
+// current round proposal, it returns true if the prevote got sent.
+func (c *Core) prevoteOnValidRoundQuorum(ctx context.Context) bool {
+	if c.step != Propose {
+		return false
+	}
+	// ProposalBlock would be nil if node haven't received the proposal yet.
+	proposal := c.curRoundMessages.Proposal()
+	if proposal == nil {
+		return false
+	}
+	vr := proposal.ValidRound()
+	h := proposal.Block().Hash()
+	rs := c.messages.GetOrCreate(vr)
+	if vr >= 0 && vr < c.Round() && rs.PrevotesPower(h).Cmp(c.CommitteeSet().Quorum()) >= 0 {
+		c.prevoter.SendPrevote(ctx, !(c.lockedRound <= vr || h == c.lockedValue.Hash()))
+		c.SetStep(Prevote)
+		return true
+	}
+	return false
+}
+
 func (c *Prevoter) LogPrevoteMessageEvent(message string, prevote *message.Prevote, from, to string) {
 	currentProposalHash := c.curRoundMessages.ProposalHash()
 	c.logger.Debug(message,
@@ -114,7 +141,7 @@ func (c *Prevoter) LogPrevoteMessageEvent(message string, prevote *message.Prevo
 		"msgRound", prevote.R(),
 		"currentStep", c.step,
 		"isProposer", c.IsProposer(),
-		"currentProposer", c.CommitteeSet().GetProposer(c.Round()),
+		"currentProposer", c.proposerOf(c.Round()),
 		"isNilMsg", prevote.Value() == common.Hash{},
 		"value", prevote.Value(),
 		"type", "Prevote",
@@ -6,7 +6,9 @@ import (
 	"math/big"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
 	"github.com/autonity/autonity/common"
@@ -293,3 +295,40 @@ func TestHandlePrevote(t *testing.T) {
 		})
 	*/
 }
+
+func TestPrevoteMetrics(t *testing.T) {
+	enableTestTimers(t, &PrevoteSentTimer, &PrevoteReceivedTimer)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	member := committeeSet.Committee()[1]
+	messages := message.NewMap()
+	curRoundMessages := messages.GetOrCreate(2)
+	backendMock := interfaces.NewMockBackend(ctrl)
+	backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(keys[member.Address], member.Address))
+	backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any())
+	logger := log.New("backend", "test", "id", 0)
+	c := &Core{
+		address:          member.Address,
+		logger:           logger,
+		backend:          backendMock,
+		messages:         messages,
+		curRoundMessages: curRoundMessages,
+		round:            2,
+		height:           big.NewInt(3),
+		committee:        committeeSet,
+		step:             Prevote,
+		prevoteTimeout:   NewTimeout(Prevote, logger),
+		newRound:         time.Now(),
+	}
+	c.SetDefaultHandlers()
+
+	c.prevoter.SendPrevote(context.Background(), true)
+	require.Equal(t, int64(1), PrevoteSentTimer.Count())
+
+	sender := committeeSet.Committee()[0]
+	prevote := message.NewPrevote(2, 3, common.Hash{}, makeSigner(keys[sender.Address], sender.Address)).MustVerify(stubVerifier)
+	require.NoError(t, c.prevoter.HandlePrevote(context.Background(), prevote))
+	require.Equal(t, int64(1), PrevoteReceivedTimer.Count())
+}
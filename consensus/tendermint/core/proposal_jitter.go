@@ -0,0 +1,73 @@
+package core
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/metrics"
+)
+
+// proposalBroadcastEvent hands a proposal whose broadcast was delayed back to the main loop.
+type proposalBroadcastEvent struct {
+	proposal *message.Propose
+}
+
+// SetProposalBroadcastJitter sets the maximum random delay before broadcasting our proposals, so that the proposal
+// traffic of large committees is spread in time. The delay is capped to leave at least half of the propose timeout
+// to the other members to receive and verify the proposal. Zero disables the jitter.
+func (c *Core) SetProposalBroadcastJitter(jitter time.Duration) {
+	c.proposalBroadcastJitter = jitter
+}
+
+// proposalBroadcastDelay returns the random delay before broadcasting our proposal of the given round.
+func (c *Core) proposalBroadcastDelay(round int64) time.Duration {
+	if c.proposalBroadcastJitter <= 0 {
+		return 0
+	}
+	bound := c.timeoutPropose(round)/2 - c.Clock().Now().Sub(c.newRound)
+	if bound > c.proposalBroadcastJitter {
+		bound = c.proposalBroadcastJitter
+	}
+	if bound <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(bound) + 1))
+}
+
+// scheduleProposalBroadcast broadcasts the proposal after the given delay, from the main loop.
+func (c *Core) scheduleProposalBroadcast(proposal *message.Propose, delay time.Duration) {
+	c.logger.Debug("Delaying proposal broadcast", "round", proposal.R(), "delay", delay)
+	c.stopProposalBroadcastTimer()
+	c.proposalBroadcastTimer = c.Clock().AfterFunc(delay, func() {
+		c.SendEvent(proposalBroadcastEvent{proposal: proposal})
+	})
+}
+
+// handleProposalBroadcast broadcasts a delayed proposal, unless the round moved on meanwhile.
+func (c *Core) handleProposalBroadcast(proposal *message.Propose) {
+	c.broadcastProposal(proposal)
+}
+
+// broadcastProposal broadcasts our proposal if its round is still the current one. The round may have changed while
+// the proposal was built or delayed, an obsolete proposal is dropped.
+func (c *Core) broadcastProposal(proposal *message.Propose) {
+	if proposal.H() != c.Height().Uint64() || proposal.R() != c.Round() {
+		ProposalStaleDroppedMeter.Mark(1)
+		c.logger.Debug("Dropping proposal of a past round", "height", proposal.H(), "round", proposal.R(), "currentHeight", c.Height(), "currentRound", c.Round())
+		return
+	}
+	if metrics.Enabled {
+		now := c.Clock().Now()
+		ProposalSentTimer.Update(now.Sub(c.newRound))
+		ProposalSentBg.Add(now.Sub(c.newRound).Nanoseconds())
+	}
+	c.proposer.LogProposalMessageEvent("MessageEvent(Proposal): Sent", proposal, c.address.String(), "broadcast")
+	c.Broadcaster().Broadcast(proposal)
+}
+
+func (c *Core) stopProposalBroadcastTimer() {
+	if c.proposalBroadcastTimer != nil {
+		c.proposalBroadcastTimer.Stop()
+	}
+}
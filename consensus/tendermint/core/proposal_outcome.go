@@ -0,0 +1,72 @@
+package core
+
+import (
+	"sync"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/event"
+)
+
+// proposalOutcomeFeed delivers the outcome of the proposals handled. Unlike event.Feed, a delivery never blocks the
+// consensus loop: a subscriber which isn't ready to receive misses the outcome.
+type proposalOutcomeFeed struct {
+	mu          sync.Mutex
+	subscribers []chan<- interfaces.ProposalOutcome
+}
+
+func (f *proposalOutcomeFeed) subscribe(ch chan<- interfaces.ProposalOutcome) event.Subscription {
+	f.mu.Lock()
+	f.subscribers = append(f.subscribers, ch)
+	f.mu.Unlock()
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		for i, sub := range f.subscribers {
+			if sub == ch {
+				f.subscribers = append(f.subscribers[:i], f.subscribers[i+1:]...)
+				break
+			}
+		}
+		return nil
+	})
+}
+
+func (f *proposalOutcomeFeed) send(outcome interfaces.ProposalOutcome) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, ch := range f.subscribers {
+		select {
+		case ch <- outcome:
+		default:
+			ProposalOutcomeDroppedMeter.Mark(1)
+		}
+	}
+}
+
+// SubscribeProposalOutcome registers a subscription notified of the outcome of every proposal handled, accepted or
+// rejected with the reason why, to score the committee members. The outcomes are dropped for a subscriber whose
+// channel is full, it should be buffered.
+func (c *Core) SubscribeProposalOutcome(ch chan<- interfaces.ProposalOutcome) event.Subscription {
+	return c.proposalOutcomes.subscribe(ch)
+}
+
+// notifyProposalOutcome reports the outcome of the proposal given the error returned by its handling.
+func (c *Core) notifyProposalOutcome(proposal *message.Propose, err error) {
+	kind, _ := constants.ProposalErrorKindOf(err)
+	var value common.Hash
+	if proposal.Block() != nil {
+		value = proposal.Block().Hash()
+	}
+	c.proposalOutcomes.send(interfaces.ProposalOutcome{
+		Proposer: proposal.Sender(),
+		Height:   proposal.H(),
+		Round:    proposal.R(),
+		Value:    value,
+		Kind:     kind,
+		Err:      err,
+	})
+}
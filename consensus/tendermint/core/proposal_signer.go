@@ -0,0 +1,78 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+)
+
+// ProposalSigner signs the proposals of the node in place of the backend key, to plug in a remote signer or an HSM.
+// The context is cancelled once the signing timeout expires.
+type ProposalSigner interface {
+	SignProposal(ctx context.Context, hash common.Hash) (signature []byte, address common.Address, err error)
+}
+
+// SetProposalSigner sets the signer of the proposals and the maximum duration allowed for the signing, the proposal
+// being dropped if it isn't signed in time. A nil signer restores the signing by the backend. Zero disables the
+// timeout.
+func (c *Core) SetProposalSigner(signer ProposalSigner, timeout time.Duration) {
+	c.proposalSigner = signer
+	c.proposalSigningTimeout = timeout
+}
+
+// proposalSignerFunc returns the message signer of a proposal, which records the failure of the signing in err.
+func (c *Proposer) proposalSignerFunc(ctx context.Context, err *error) message.Signer {
+	return func(hash common.Hash) ([]byte, common.Address) {
+		var (
+			signature []byte
+			address   common.Address
+		)
+		signature, address, *err = c.signProposal(ctx, hash)
+		return signature, address
+	}
+}
+
+// signProposal signs the proposal hash with the configured signer, bounded by the signing timeout. If the timeout
+// expires the signing is left running in the background so that the consensus goroutine is never stalled by a slow
+// remote signer.
+func (c *Proposer) signProposal(ctx context.Context, hash common.Hash) ([]byte, common.Address, error) {
+	signer := c.proposalSigner
+	if signer == nil {
+		signature, address := c.backend.Sign(hash)
+		return signature, address, nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if c.proposalSigningTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.proposalSigningTimeout)
+		defer cancel()
+	}
+	type signingResult struct {
+		signature []byte
+		address   common.Address
+		err       error
+	}
+	resultCh := make(chan signingResult, 1)
+	go func() {
+		signature, address, err := signer.SignProposal(ctx, hash)
+		resultCh <- signingResult{signature: signature, address: address, err: err}
+	}()
+	select {
+	case result := <-resultCh:
+		if result.err != nil {
+			return nil, common.Address{}, result.err
+		}
+		if result.address != c.address {
+			return nil, common.Address{}, fmt.Errorf("%w: signed by %v", constants.ErrProposalSignerMismatch, result.address)
+		}
+		return result.signature, result.address, nil
+	case <-ctx.Done():
+		return nil, common.Address{}, fmt.Errorf("%w: %v", constants.ErrProposalSigningTimeout, ctx.Err())
+	}
+}
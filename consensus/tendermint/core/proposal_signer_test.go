@@ -0,0 +1,126 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/committee"
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/log"
+)
+
+// testProposalSigner adapts a function to the ProposalSigner interface.
+type testProposalSigner func(ctx context.Context, hash common.Hash) ([]byte, common.Address, error)
+
+func (f testProposalSigner) SignProposal(ctx context.Context, hash common.Hash) ([]byte, common.Address, error) {
+	return f(ctx, hash)
+}
+
+func TestProposalSigner(t *testing.T) {
+	proposerKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	proposer := crypto.PubkeyToAddress(proposerKey.PublicKey)
+	testCommittee := types.Committee{types.CommitteeMember{Address: proposer, VotingPower: big.NewInt(1)}}
+	valSet, err := committee.NewRoundRobinSet(testCommittee, proposer)
+	require.NoError(t, err)
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)})
+
+	newCore := func(t *testing.T) (*Core, *interfaces.MockBackend) {
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		backendMock.EXPECT().LastProposal()
+		backendMock.EXPECT().SaveProposal(gomock.Any())
+		messages := message.NewMap()
+		c := &Core{
+			address:          proposer,
+			backend:          backendMock,
+			messages:         messages,
+			curRoundMessages: messages.GetOrCreate(0),
+			logger:           log.Root(),
+			height:           big.NewInt(1),
+			validRound:       -1,
+			committee:        valSet,
+		}
+		c.SetDefaultHandlers()
+		return c, backendMock
+	}
+
+	t.Run("remote signer signs the proposal", func(t *testing.T) {
+		c, backendMock := newCore(t)
+		signs := 0
+		c.SetProposalSigner(testProposalSigner(func(_ context.Context, hash common.Hash) ([]byte, common.Address, error) {
+			signs++
+			signature, address := makeSigner(proposerKey, proposer)(hash)
+			return signature, address, nil
+		}), time.Second)
+
+		var sent message.Msg
+		backendMock.EXPECT().SetProposedBlockHash(block.Hash())
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any()).Do(func(_ types.Committee, msg message.Msg) { sent = msg })
+		c.proposer.SendProposal(context.Background(), block)
+
+		require.Equal(t, 1, signs)
+		require.NotNil(t, sent)
+		require.NoError(t, sent.Validate(stubVerifier))
+		require.Equal(t, proposer, sent.Sender())
+	})
+
+	t.Run("slow remote signer aborts the proposal", func(t *testing.T) {
+		enableTestMeters(t, &ProposalSigningFailedMeter)
+		c, _ := newCore(t)
+		cancelled := make(chan struct{})
+		c.SetProposalSigner(testProposalSigner(func(ctx context.Context, _ common.Hash) ([]byte, common.Address, error) {
+			<-ctx.Done()
+			close(cancelled)
+			return nil, common.Address{}, ctx.Err()
+		}), 50*time.Millisecond)
+
+		// neither the proposed block hash is set nor the proposal broadcast
+		start := time.Now()
+		c.proposer.SendProposal(context.Background(), block)
+		require.Less(t, time.Since(start), time.Second)
+		require.Equal(t, int64(1), ProposalSigningFailedMeter.Count())
+		<-cancelled
+
+		// a single proposal is ever attempted for the round
+		c.proposer.SendProposal(context.Background(), block)
+		require.Equal(t, int64(1), ProposalSigningFailedMeter.Count())
+	})
+
+	t.Run("signature of another key is refused", func(t *testing.T) {
+		enableTestMeters(t, &ProposalSigningFailedMeter)
+		otherKey, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		other := crypto.PubkeyToAddress(otherKey.PublicKey)
+		c, _ := newCore(t)
+		c.SetProposalSigner(testProposalSigner(func(_ context.Context, hash common.Hash) ([]byte, common.Address, error) {
+			signature, address := makeSigner(otherKey, other)(hash)
+			return signature, address, nil
+		}), time.Second)
+
+		_, _, err = c.proposer.(*Proposer).signProposal(context.Background(), block.Hash())
+		require.ErrorIs(t, err, constants.ErrProposalSignerMismatch)
+
+		// the proposal is dropped
+		c.proposer.SendProposal(context.Background(), block)
+		require.Equal(t, int64(1), ProposalSigningFailedMeter.Count())
+	})
+
+	t.Run("backend signs without remote signer", func(t *testing.T) {
+		c, backendMock := newCore(t)
+		c.SetProposalSigner(nil, time.Second)
+		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(proposerKey, proposer))
+		backendMock.EXPECT().SetProposedBlockHash(block.Hash())
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any())
+		c.proposer.SendProposal(context.Background(), block)
+	})
+}
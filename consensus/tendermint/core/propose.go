@@ -3,39 +3,120 @@ package core
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/consensus"
+	"github.com/autonity/autonity/consensus/misc"
 	"github.com/autonity/autonity/consensus/tendermint/core/constants"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/rawdb"
 	"github.com/autonity/autonity/core/types"
 	"github.com/autonity/autonity/metrics"
+	"github.com/autonity/autonity/trie"
 )
 
 type Proposer struct {
 	*Core
 }
 
-func (c *Proposer) SendProposal(_ context.Context, block *types.Block) {
-	// If I'm the proposer and I have the same height with the proposal
-	if c.Height().Cmp(block.Number()) == 0 && c.IsProposer() && !c.sentProposal {
-		proposal := message.NewPropose(c.Round(), c.Height().Uint64(), c.validRound, block, c.backend.Sign)
-		c.sentProposal = true
-		c.backend.SetProposedBlockHash(block.Hash())
-		if metrics.Enabled {
-			now := time.Now()
-			ProposalSentTimer.Update(now.Sub(c.newRound))
-			ProposalSentBg.Add(now.Sub(c.newRound).Nanoseconds())
-		}
-		c.LogProposalMessageEvent("MessageEvent(Proposal): Sent", proposal, c.address.String(), "broadcast")
-		c.Broadcaster().Broadcast(proposal)
+func (c *Proposer) SendProposal(ctx context.Context, block *types.Block) {
+	if c.ProposingHalted() {
+		c.logger.Error("Proposing halted after repeated failures to verify our own proposals", "height", c.Height(), "round", c.Round())
+		return
+	}
+	if !c.claimProposal(block) {
+		return
+	}
+	if c.isSelfEquivocation(block) {
+		return
 	}
+	c.backend.SaveProposal(&rawdb.LastProposal{Height: c.Height().Uint64(), Round: uint64(c.Round()), Hash: block.Hash()})
+	var signErr error
+	proposal := message.NewPropose(c.Round(), c.Height().Uint64(), c.validRound, block, c.proposalSignerFunc(ctx, &signErr))
+	if signErr != nil {
+		ProposalSigningFailedMeter.Mark(1)
+		c.logger.Error("Failed to sign our proposal", "height", c.Height(), "round", c.Round(), "hash", block.Hash(), "err", signErr)
+		return
+	}
+	c.backend.SetProposedBlockHash(block.Hash())
+	if delay := c.proposalBroadcastDelay(c.Round()); delay > 0 {
+		c.scheduleProposalBroadcast(proposal, delay)
+		return
+	}
+	c.broadcastProposal(proposal)
 }
 
+// claimProposal returns true if we are the proposer of the current round, at the height of the block, and didn't
+// send a proposal yet. The check and the marking of the proposal as sent are atomic, a single one of concurrent
+// calls is ever granted the round.
+func (c *Proposer) claimProposal(block *types.Block) bool {
+	c.proposalMu.Lock()
+	defer c.proposalMu.Unlock()
+	if c.Height().Cmp(block.Number()) != 0 || !c.IsProposer() || c.sentProposal {
+		return false
+	}
+	c.sentProposal = true
+	return true
+}
+
+// isSelfEquivocation returns true if we already proposed a different block at the current height and round, which
+// happens if our consensus state got reset by a restart. Sending the new proposal would get us slashed.
+func (c *Proposer) isSelfEquivocation(block *types.Block) bool {
+	last := c.backend.LastProposal()
+	if last == nil || last.Height != c.Height().Uint64() || last.Round != uint64(c.Round()) || last.Hash == block.Hash() {
+		return false
+	}
+	ProposalEquivocationMeter.Mark(1)
+	c.logger.Error("Refusing to send a proposal conflicting with an earlier one", "height", c.Height(), "round", c.Round(), "previous", last.Hash, "hash", block.Hash())
+	return true
+}
+
+// HandleProposal handles a proposal and reports its outcome to the subscribers.
 func (c *Proposer) HandleProposal(ctx context.Context, proposal *message.Propose) error {
+	err := c.handleProposal(ctx, proposal)
+	c.notifyProposalOutcome(proposal, err)
+	return err
+}
+
+func (c *Proposer) handleProposal(ctx context.Context, proposal *message.Propose) error {
+	// Refuse a view out of any sane range before anything is looked up or allocated for it, whatever the path the
+	// proposal came from. Only the current height and the adjacent ones are handled below.
+	if err := c.checkProposalView(proposal); err != nil {
+		c.logger.Warn("Rejecting proposal with out of range view", "height", proposal.H(), "round", proposal.R(), "sender", proposal.Sender())
+		return err
+	}
+	// A proposal without block is a protocol violation, the decoding refuses it but it must never reach the code
+	// below. As for any invalid proposal, we prevote nil if it's the one we are waiting for.
+	if proposal.Block() == nil {
+		c.logger.Warn("Rejecting proposal without block", "height", proposal.H(), "round", proposal.R(), "sender", proposal.Sender())
+		if proposal.H() == c.Height().Uint64() && proposal.R() == c.Round() && c.step == Propose && c.IsFromProposer(c.Round(), proposal.Sender()) {
+			if timeoutErr := c.proposeTimeout.StopTimer(); timeoutErr != nil {
+				return timeoutErr
+			}
+			c.prevoter.SendPrevote(ctx, true)
+			c.SetStep(Prevote)
+		}
+		return constants.NewProposalError(constants.NilBlock, constants.ErrNilProposalBlock)
+	}
+	// The valid round must precede the proposal round (L28 reads it as a past round), a proposal breaking it
+	// is malformed whatever our view. The fault detector accounts for it as a WrongValidRound misbehavior.
+	if vr := proposal.ValidRound(); vr < -1 || vr >= proposal.R() {
+		c.logger.Warn("Rejecting proposal with invalid valid round", "round", proposal.R(), "validRound", vr, "sender", proposal.Sender())
+		return constants.NewProposalError(constants.InvalidValidRound, constants.ErrInvalidValidRound)
+	}
 	// Ensure we have the same view with the Proposal message
 	if err := c.checkMessageStep(proposal.R(), proposal.H(), Propose); err != nil {
+		// Buffering proposals for any future round would let a byzantine proposer bloat the backlog.
+		if errors.Is(err, constants.ErrFutureRoundMessage) && c.maxProposalRoundsAhead > 0 && proposal.R()-c.Round() > c.maxProposalRoundsAhead {
+			// forget the proposal so that it can be received again once we catch up with its round
+			c.backend.RemoveMessageFromLocalCache(proposal)
+			ProposalFutureRoundDroppedMeter.Mark(1)
+			c.logger.Debug("Dropping far future round proposal", "round", proposal.R(), "current", c.Round())
+			return constants.ErrFarFutureRoundProposal
+		}
 		// If it's a future round proposal, the only upon condition
 		// that can be triggered is L49, but this requires more than F future round messages
 		// meaning that a future roundchange will happen before, as such, pushing the
@@ -45,23 +126,31 @@ func (c *Proposer) HandleProposal(ctx context.Context, proposal *message.Propose
 			// if we already have a proposal then it must be different than the current one
 			// it can't happen unless someone's byzantine.
 			if roundMessages.Proposal() != nil {
-				return err // do not gossip, TODO: accountability
+				return constants.NewProposalError(constants.OldRound, err) // do not gossip, TODO: accountability
 			}
 
 			if !c.IsFromProposer(proposal.R(), proposal.Sender()) {
 				c.logger.Warn("Ignoring proposal from non-proposer")
-				return constants.ErrNotFromProposer
+				return constants.NewProposalError(constants.NotProposer, constants.ErrNotFromProposer)
 			}
-			// We do not verify the proposal in this case.
-			roundMessages.SetProposal(proposal, false)
-			if roundMessages.PrecommitsPower(proposal.Block().Hash()).Cmp(c.CommitteeSet().Quorum()) >= 0 {
-				if _, err2 := c.backend.VerifyProposal(proposal.Block()); err2 != nil {
-					return err2
+			// We do not verify the proposal in this case, unless it gets committed or becomes our valid value.
+			if roundMessages.PrecommitsPower(proposal.Block().Hash()).Cmp(c.CommitteeSet().Quorum()) >= 0 && c.canCommit(proposal.R(), roundMessages) {
+				// the block may have already been verified when proposed again in a later round
+				if !c.isVerifiedBlock(proposal.Block().Hash()) {
+					if err2 := c.verifyOldRoundProposal(proposal, roundMessages); err2 != nil {
+						return err2
+					}
 				}
+				roundMessages.SetProposal(proposal, false)
 				c.logger.Debug("Committing old round proposal")
 				c.Commit(proposal.R(), roundMessages)
 				return nil
 			}
+			if proposal.R() == c.Round()-1 && roundMessages.PrevotesPower(proposal.Block().Hash()).Cmp(c.CommitteeSet().Quorum()) >= 0 {
+				return c.handleBoundaryProposal(ctx, proposal, roundMessages, err)
+			}
+			roundMessages.SetProposal(proposal, false)
+			return constants.NewProposalError(constants.OldRound, err)
 		}
 		return err
 	}
@@ -69,48 +158,122 @@ func (c *Proposer) HandleProposal(ctx context.Context, proposal *message.Propose
 	// Check if the message comes from curRoundMessages proposer
 	if !c.IsFromProposer(c.Round(), proposal.Sender()) {
 		c.logger.Warn("Ignore proposal messages from non-proposer")
-		return constants.ErrNotFromProposer
+		return constants.NewProposalError(constants.NotProposer, constants.ErrNotFromProposer)
+	}
+	// a proposal verified again was already accounted for when first received
+	retried := c.isRetriedProposal(proposal)
+	if !retried {
+		c.measureProposalStep()
+	}
+
+	// our state may be stale while syncing, the proposal is evaluated once the sync completes
+	if c.Syncing() {
+		c.deferProposal(proposal)
+		return constants.ErrProposalDeferredSyncing
+	}
+
+	// skip the verification of proposals from a member which already sent too many invalid ones at this height
+	if c.isBlacklistedProposer(proposal.Sender()) {
+		ProposalBlacklistedMeter.Mark(1)
+		// once we left the propose step we already prevoted, another prevote would be an equivocation
+		if c.step == Propose {
+			if timeoutErr := c.proposeTimeout.StopTimer(); timeoutErr != nil {
+				return timeoutErr
+			}
+			c.prevoter.SendPrevote(ctx, true)
+			c.SetStep(Prevote)
+			c.logger.Info("Nil prevote for blacklisted proposer", "proposer", proposal.Sender(), "invalid", c.invalidProposals[proposal.Sender()])
+		}
+		return constants.ErrBlacklistedProposer
+	}
+
+	// the proposal must extend our chain, the first height having no committed block but the genesis to build on
+	if err := c.checkParent(proposal); err != nil {
+		// once we left the propose step we already prevoted, another prevote would be an equivocation
+		if c.step == Propose {
+			if timeoutErr := c.proposeTimeout.StopTimer(); timeoutErr != nil {
+				return timeoutErr
+			}
+			c.prevoter.SendPrevote(ctx, true)
+			c.SetStep(Prevote)
+		}
+		return constants.NewProposalError(constants.VerificationFailed, err)
 	}
 
 	// received a current round proposal
-	if metrics.Enabled {
-		now := time.Now()
+	if metrics.Enabled && !retried {
+		now := c.Clock().Now()
 		ProposalReceivedTimer.Update(now.Sub(c.newRound))
 		ProposalReceivedBg.Add(now.Sub(c.newRound).Nanoseconds())
 	}
 
-	// Verify the proposal we received
-	start := time.Now()
-	duration, err := c.backend.VerifyProposal(proposal.Block()) // youssef: can we skip the verification for our own proposal?
+	// Verify the proposal we received, warming the state caches alongside if enabled
+	stopPrefetch := func() {}
+	if c.proposalPrefetch {
+		stopPrefetch = c.backend.PrefetchProposal(proposal.Block())
+	}
+	start := c.Clock().Now()
+	duration, err := c.verifyProposalOnce(proposal.Block()) // youssef: can we skip the verification for our own proposal?
+	stopPrefetch()
 
 	if metrics.Enabled {
-		now := time.Now()
+		now := c.Clock().Now()
 		ProposalVerifiedTimer.Update(now.Sub(start))
 		ProposalVerifiedBg.Add(now.Sub(start).Nanoseconds())
+		recordProposalCost(proposal.Block(), now.Sub(start))
+	}
+
+	// the propose timeout keeps running while the verification is retried
+	if err != nil && c.scheduleVerificationRetry(proposal, err) {
+		return constants.ErrProposalVerificationRetry
+	}
+
+	if proposal.Sender() == c.address {
+		c.recordSelfProposalVerification(err)
 	}
 
 	if err != nil {
-		if timeoutErr := c.proposeTimeout.StopTimer(); timeoutErr != nil {
-			return timeoutErr
-		}
 		// if it's a future block, we will handle it again after the duration
 		// TODO: implement wiggle time / median time
 		if errors.Is(err, consensus.ErrFutureTimestampBlock) {
-			c.StopFutureProposalTimer()
-			c.futureProposalTimer = time.AfterFunc(duration, func() {
-				c.SendEvent(backlogMessageEvent{
-					msg: proposal,
-				})
-			})
-			return err
+			if timeoutErr := c.proposeTimeout.StopTimer(); timeoutErr != nil {
+				return timeoutErr
+			}
+			c.scheduleFutureProposal(proposal, duration)
+			return constants.NewProposalError(constants.FutureTimestamp, err)
+		}
+		// timestamp regressions are told apart to diagnose clock drifts across the committee.
+		if errors.Is(err, consensus.ErrTimestampRegression) {
+			ProposalTimestampRegressionMeter.Mark(1)
+			var parentTime uint64
+			if lastHeader := c.LastHeader(); lastHeader != nil {
+				parentTime = lastHeader.Time
+			}
+			c.logger.Warn("Proposal timestamp regression", "proposer", proposal.Sender(), "time", proposal.Block().Time(), "parentTime", parentTime)
+		}
+		// a slow verification may be due to our own node, do not blame the proposer. Our own failures are
+		// accounted for by the circuit breaker instead, we must not blacklist ourselves.
+		if !errors.Is(err, constants.ErrProposalVerificationTimeout) && proposal.Sender() != c.address {
+			c.recordInvalidProposal(proposal.Sender())
 		}
-		c.prevoter.SendPrevote(ctx, true)
-		// do not to accept another proposal in current round
-		c.SetStep(Prevote)
-
 		c.logger.Warn("Failed to verify proposal", "err", err, "duration", duration)
 
-		return err
+		// the propose timeout may have expired while the verification was retried, we already prevoted then
+		if c.step == Propose {
+			if timeoutErr := c.proposeTimeout.StopTimer(); timeoutErr != nil {
+				return timeoutErr
+			}
+			c.prevoter.SendPrevote(ctx, true)
+			// do not to accept another proposal in current round
+			c.SetStep(Prevote)
+		}
+
+		return constants.NewProposalError(constants.VerificationFailed, err)
+	}
+
+	c.rememberVerifiedBlock(proposal.Block().Hash())
+	if metrics.Enabled && proposal.Sender() != c.address {
+		ProposalConversionVerifiedMeter.Mark(1)
 	}
 
 	// Set the proposal for the current round
@@ -119,7 +282,7 @@ func (c *Proposer) HandleProposal(ctx context.Context, proposal *message.Propose
 
 	//l49: Check if we have a quorum of precommits for this proposal
 	hash := proposal.Block().Hash()
-	if c.curRoundMessages.PrecommitsPower(hash).Cmp(c.CommitteeSet().Quorum()) >= 0 {
+	if c.curRoundMessages.PrecommitsPower(hash).Cmp(c.CommitteeSet().Quorum()) >= 0 && c.canCommit(proposal.R(), c.curRoundMessages) {
 		c.Commit(proposal.R(), c.curRoundMessages)
 		return nil
 	}
@@ -150,6 +313,275 @@ func (c *Proposer) HandleProposal(ctx context.Context, proposal *message.Propose
 	return nil
 }
 
+// handleBoundaryProposal handles a proposal of the previous round holding a quorum of prevotes, typically the one
+// we were waiting for when the round changed. Rather than keeping it unverified until a quorum of precommits shows
+// up, it is verified right away to become our valid value, which the current round can then build upon.
+func (c *Proposer) handleBoundaryProposal(ctx context.Context, proposal *message.Propose, roundMessages *message.RoundMessages, err error) error {
+	block := proposal.Block()
+	if !c.isVerifiedBlock(block.Hash()) {
+		if err2 := c.verifyOldRoundProposal(proposal, roundMessages); err2 != nil {
+			return err2
+		}
+		c.rememberVerifiedBlock(block.Hash())
+	}
+	roundMessages.SetProposal(proposal, true)
+	if proposal.R() > c.validRound {
+		c.validValue = block
+		c.validRound = proposal.R()
+	}
+	c.logger.Debug("Previous round proposal with a quorum of prevotes", "round", proposal.R(), "hash", block.Hash())
+	c.prevoteOnValidRoundQuorum(ctx)
+	return constants.NewProposalError(constants.OldRound, err)
+}
+
+// checkParent refuses a proposal which doesn't extend our chain.
+func (c *Core) checkParent(proposal *message.Propose) error {
+	if err := c.checkGenesisParent(proposal); err != nil {
+		return err
+	}
+	return c.checkCanonicalParent(proposal)
+}
+
+// checkCanonicalParent refuses a proposal whose parent isn't our canonical block at the previous height. The parent
+// may still be known, an abandoned block after a reorg for instance, and pass the verification while prevoting for it
+// would support the extension of a fork.
+func (c *Core) checkCanonicalParent(proposal *message.Propose) error {
+	lastHeader := c.LastHeader()
+	if proposal.H() <= 1 || lastHeader == nil || lastHeader.Number.Uint64() != proposal.H()-1 {
+		return nil
+	}
+	if parent := proposal.Block().ParentHash(); parent != lastHeader.Hash() {
+		ProposalNonCanonicalParentMeter.Mark(1)
+		c.logger.Warn("Proposal not built on our canonical chain", "proposer", proposal.Sender(), "parent", parent, "canonical", lastHeader.Hash())
+		return constants.ErrNonCanonicalParent
+	}
+	return nil
+}
+
+// measureProposalStep accounts for the step we are in when receiving the proposal of the current round. A high rate
+// of proposals received after leaving Propose tells our progress is consistently off the proposers'.
+func (c *Core) measureProposalStep() {
+	if !metrics.Enabled {
+		return
+	}
+	switch c.step {
+	case Propose:
+		ProposalInProposeMeter.Mark(1)
+	case Prevote:
+		ProposalInPrevoteMeter.Mark(1)
+	default:
+		ProposalInPrecommitMeter.Mark(1)
+	}
+}
+
+// verifyOldRoundProposal verifies a proposal of a past round, which is kept unverified if it fails for good.
+func (c *Proposer) verifyOldRoundProposal(proposal *message.Propose, roundMessages *message.RoundMessages) error {
+	if _, err := c.verifyProposalOnce(proposal.Block()); err != nil {
+		if c.scheduleVerificationRetry(proposal, err) {
+			return constants.ErrProposalVerificationRetry
+		}
+		roundMessages.SetProposal(proposal, false)
+		return constants.NewProposalError(constants.VerificationFailed, err)
+	}
+	return nil
+}
+
+// verificationRetry accounts for the verification attempts of the proposal of a block at a round, and holds the timer
+// of the next one.
+type verificationRetry struct {
+	proposal   *message.Propose
+	transient  int
+	baseFee    int
+	grace      bool
+	timer      Timer
+	generation uint64 // generation of the scheduled attempt, telling apart the events of a replaced timer
+}
+
+// retryKey identifies the proposal of a block at a round, each proposal being retried independently.
+type retryKey struct {
+	hash  common.Hash
+	round int64
+}
+
+func proposalRetryKey(proposal *message.Propose) retryKey {
+	return retryKey{hash: proposal.Block().Hash(), round: proposal.R()}
+}
+
+// scheduleVerificationRetry schedules the proposal to be handled again if its verification failure is worth another
+// attempt, and returns whether it did. A momentarily unavailable state, or a base fee computed from a parent view
+// still being updated during a head transition, is retried after the retry delay. Any other failure of a current
+// round proposal is attempted once more after the grace period, with its own retries, if the propose timeout leaves
+// room for it. The retries are timer driven, the main loop keeps handling the other messages meanwhile.
+func (c *Proposer) scheduleVerificationRetry(proposal *message.Propose, err error) bool {
+	block := proposal.Block()
+	key := proposalRetryKey(proposal)
+	retry, ok := c.verificationRetries[key]
+	if !ok {
+		if c.verificationRetries == nil {
+			c.verificationRetries = make(map[retryKey]*verificationRetry)
+		}
+		retry = new(verificationRetry)
+		c.verificationRetries[key] = retry
+	}
+	var delay time.Duration
+	switch {
+	case isTransientVerificationError(err) && retry.transient < c.proposalVerificationRetries:
+		retry.transient++
+		ProposalVerificationRetryMeter.Mark(1)
+		c.logger.Debug("Retrying proposal verification", "hash", block.Hash(), "retry", retry.transient, "err", err)
+		delay = c.proposalVerificationRetryDelay
+	case errors.Is(err, misc.ErrInvalidBaseFee) && retry.baseFee < c.proposalBaseFeeRetries:
+		retry.baseFee++
+		ProposalBaseFeeRetryMeter.Mark(1)
+		c.logger.Debug("Retrying proposal verification after a base fee mismatch", "hash", block.Hash(), "retry", retry.baseFee, "err", err)
+		delay = c.proposalVerificationRetryDelay
+	case !retry.grace && c.inVerificationGrace(proposal, err):
+		retry.transient, retry.baseFee, retry.grace = 0, 0, true
+		ProposalVerificationGraceMeter.Mark(1)
+		c.logger.Debug("Verifying proposal again after the grace period", "hash", block.Hash(), "grace", c.proposalVerificationGrace, "err", err)
+		delay = c.proposalVerificationGrace
+	default:
+		return false
+	}
+	if retry.timer != nil {
+		retry.timer.Stop()
+	}
+	retry.proposal = proposal
+	c.verificationRetryGeneration++
+	generation := c.verificationRetryGeneration
+	retry.generation = generation
+	retry.timer = c.Clock().AfterFunc(delay, func() {
+		c.SendEvent(proposalRetryEvent{proposal: proposal, generation: generation})
+	})
+	return true
+}
+
+// isRetriedProposal returns true if the proposal failed a verification attempt already.
+func (c *Core) isRetriedProposal(proposal *message.Propose) bool {
+	_, ok := c.verificationRetries[proposalRetryKey(proposal)]
+	return ok
+}
+
+// stopVerificationRetries stops the timers of the scheduled verification retries and forgets the attempts made.
+func (c *Core) stopVerificationRetries() {
+	for _, retry := range c.verificationRetries {
+		if retry.timer != nil {
+			retry.timer.Stop()
+		}
+	}
+	c.verificationRetries = nil
+}
+
+// inVerificationGrace returns true if the failed verification of the proposal can be attempted again after the grace
+// period. A future proposal is handled again later anyway, and a verification which timed out would likely do so
+// again.
+func (c *Proposer) inVerificationGrace(proposal *message.Propose, err error) bool {
+	if c.proposalVerificationGrace <= 0 || proposal.R() != c.Round() ||
+		errors.Is(err, consensus.ErrFutureTimestampBlock) || errors.Is(err, constants.ErrProposalVerificationTimeout) {
+		return false
+	}
+	remaining := c.timeoutPropose(c.Round()) - c.Clock().Now().Sub(c.newRound)
+	return remaining > c.proposalVerificationGrace
+}
+
+// isTransientVerificationError returns true if the verification failed because the parent state is momentarily
+// unavailable, e.g. during pruning, rather than because of the proposal itself.
+func isTransientVerificationError(err error) bool {
+	var missing *trie.MissingNodeError
+	return errors.Is(err, consensus.ErrPrunedAncestor) || errors.As(err, &missing)
+}
+
+// verifyProposalOnce runs the backend proposal verification, bounded by the configured verification timeout. A
+// verification which timed out keeps running in the background as the backend can't be interrupted, the same block
+// is then refused as timed out until it completes, the other proposals are verified as usual.
+func (c *Proposer) verifyProposalOnce(block *types.Block) (time.Duration, error) {
+	if c.proposalVerificationTimeout <= 0 {
+		return c.backend.VerifyProposal(block)
+	}
+	for hash, done := range c.abandonedVerifications {
+		select {
+		case <-done:
+			delete(c.abandonedVerifications, hash)
+		default:
+		}
+	}
+	if _, ok := c.abandonedVerifications[block.Hash()]; ok {
+		ProposalVerificationTimeoutMeter.Mark(1)
+		c.logger.Warn("Proposal verification refused while its timed out one is still running", "hash", block.Hash())
+		return 0, fmt.Errorf("%w: previous verification still running", constants.ErrProposalVerificationTimeout)
+	}
+	type verificationResult struct {
+		duration time.Duration
+		err      error
+	}
+	resultCh := make(chan verificationResult, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		duration, err := c.backend.VerifyProposal(block)
+		resultCh <- verificationResult{duration: duration, err: err}
+	}()
+	timeout := make(chan struct{})
+	timer := c.Clock().AfterFunc(c.proposalVerificationTimeout, func() { close(timeout) })
+	defer timer.Stop()
+	select {
+	case result := <-resultCh:
+		return result.duration, result.err
+	case <-timeout:
+		if c.abandonedVerifications == nil {
+			c.abandonedVerifications = make(map[common.Hash]chan struct{})
+		}
+		c.abandonedVerifications[block.Hash()] = done
+		ProposalVerificationTimeoutMeter.Mark(1)
+		c.logger.Warn("Proposal verification timed out", "hash", block.Hash(), "timeout", c.proposalVerificationTimeout)
+		return 0, constants.ErrProposalVerificationTimeout
+	}
+}
+
+// rememberVerifiedBlock records a block successfully verified at the current height.
+func (c *Proposer) rememberVerifiedBlock(hash common.Hash) {
+	if c.verifiedBlocks == nil {
+		c.verifiedBlocks = make(map[common.Hash]struct{})
+	}
+	c.verifiedBlocks[hash] = struct{}{}
+}
+
+// isVerifiedBlock returns true if the block was already successfully verified at the current height.
+func (c *Proposer) isVerifiedBlock(hash common.Hash) bool {
+	_, ok := c.verifiedBlocks[hash]
+	return ok
+}
+
+// recordSelfProposalVerification tracks the outcome of our own proposals verification and trips the circuit breaker
+// once too many consecutive ones failed: this most likely denotes a local fault, e.g. a corrupted state database,
+// and we should stop broadcasting blocks nobody can accept.
+func (c *Proposer) recordSelfProposalVerification(err error) {
+	if err == nil || errors.Is(err, consensus.ErrFutureTimestampBlock) {
+		c.selfProposalFailures.Store(0)
+		return
+	}
+	failures := c.selfProposalFailures.Add(1)
+	if threshold := c.proposalCircuitBreakerThreshold.Load(); threshold > 0 && failures >= threshold && c.proposingHalted.CompareAndSwap(false, true) {
+		ProposalCircuitBreakerMeter.Mark(1)
+		c.logger.Error("⚠️ Own proposals repeatedly failed verification, proposing halted", "failures", failures, "err", err)
+	}
+}
+
+// recordInvalidProposal accounts for an invalid proposal from the given proposer at the current height.
+func (c *Proposer) recordInvalidProposal(proposer common.Address) {
+	if c.invalidProposals == nil {
+		c.invalidProposals = make(map[common.Address]int)
+	}
+	c.invalidProposals[proposer]++
+	if c.invalidProposals[proposer] == c.proposerBlacklistThreshold {
+		c.logger.Warn("Blacklisting proposer until next height", "proposer", proposer, "invalid", c.invalidProposals[proposer])
+	}
+}
+
+func (c *Proposer) isBlacklistedProposer(proposer common.Address) bool {
+	return c.proposerBlacklistThreshold > 0 && c.invalidProposals[proposer] >= c.proposerBlacklistThreshold
+}
+
 func (c *Proposer) HandleNewCandidateBlockMsg(ctx context.Context, candidateBlock *types.Block) {
 	if candidateBlock == nil {
 		return
@@ -161,11 +593,18 @@ func (c *Proposer) HandleNewCandidateBlockMsg(ctx context.Context, candidateBloc
 		return
 	}
 
-	c.pendingCandidateBlocks[candidateBlock.NumberU64()] = candidateBlock
+	// the miner may emit several candidates for a height, a late one worse than the one buffered, e.g. an empty
+	// block, doesn't replace it.
+	if kept, ok := c.pendingCandidateBlocks[candidateBlock.NumberU64()]; ok && betterCandidate(kept, candidateBlock) {
+		c.logger.Debug("NewCandidateBlockEvent: keeping better candidateBlock", "number", number.Uint64(), "txs", kept.Transactions().Len(), "discardedTxs", candidateBlock.Transactions().Len())
+		candidateBlock = kept
+	} else {
+		c.pendingCandidateBlocks[candidateBlock.NumberU64()] = candidateBlock
+	}
 
 	// if current node is the proposer of current height and current round at step PROPOSE without available candidate
 	// block sent before, if the incoming candidate block is the one it missed, send it now.
-	if c.IsProposer() && c.step == Propose && !c.sentProposal && c.Height().Cmp(number) == 0 {
+	if c.IsProposer() && c.step == Propose && !c.SentProposal() && c.Height().Cmp(number) == 0 {
 		c.logger.Debug("NewCandidateBlockEvent: Sending proposal that was missed before", "number", number.Uint64())
 		c.proposer.SendProposal(ctx, candidateBlock)
 	}
@@ -178,10 +617,82 @@ func (c *Proposer) HandleNewCandidateBlockMsg(ctx context.Context, candidateBloc
 	}
 }
 
+// betterCandidate reports whether the candidate block a is strictly better than b, including more transactions or,
+// for as many transactions, using more gas. The receipts aren't available to compare the fees, the gas used stands
+// for the value of the transactions.
+func betterCandidate(a, b *types.Block) bool {
+	if a.Transactions().Len() != b.Transactions().Len() {
+		return a.Transactions().Len() > b.Transactions().Len()
+	}
+	return a.GasUsed() > b.GasUsed()
+}
+
+// futureProposalEvent hands a proposal with a future timestamp back to the main loop once its time came. The
+// generation tells apart the events of a stopped timer, see StopFutureProposalTimer.
+type futureProposalEvent struct {
+	proposal   *message.Propose
+	generation uint64
+}
+
+// proposalRetryEvent hands a proposal whose verification is retried back to the main loop. The generation tells
+// apart the events of a replaced or stopped retry timer.
+type proposalRetryEvent struct {
+	proposal   *message.Propose
+	generation uint64
+}
+
+// scheduleFutureProposal handles again the proposal with a future timestamp after the given delay.
+func (c *Proposer) scheduleFutureProposal(proposal *message.Propose, delay time.Duration) {
+	c.StopFutureProposalTimer()
+	c.futureProposal = proposal
+	generation := atomic.LoadUint64(&c.futureProposalGeneration)
+	c.futureProposalTimer = c.Clock().AfterFunc(delay, func() {
+		if atomic.LoadUint64(&c.futureProposalGeneration) != generation {
+			return
+		}
+		c.SendEvent(futureProposalEvent{proposal: proposal, generation: generation})
+	})
+}
+
+// StopFutureProposalTimer stops the timer of the proposal with a future timestamp. Its callback may already be
+// running and post the proposal, the event of a stopped timer is dropped by the main loop.
 func (c *Proposer) StopFutureProposalTimer() {
+	atomic.AddUint64(&c.futureProposalGeneration, 1)
 	if c.futureProposalTimer != nil {
 		c.futureProposalTimer.Stop()
 	}
+	c.futureProposal = nil
+}
+
+// handleFutureProposal handles the proposal with a future timestamp whose time came, unless its timer was stopped
+// meanwhile.
+func (c *Core) handleFutureProposal(ctx context.Context, e futureProposalEvent) {
+	if e.generation != atomic.LoadUint64(&c.futureProposalGeneration) {
+		c.logger.Debug("Dropping future proposal of a stopped timer", "height", e.proposal.H(), "round", e.proposal.R())
+		return
+	}
+	c.futureProposal = nil
+	if err := c.handleValidMsg(ctx, e.proposal); err != nil {
+		c.logger.Debug("Future proposal handling failed", "err", err)
+		return
+	}
+	c.backend.Gossip(c.CommitteeSet().Committee(), e.proposal)
+}
+
+// handleProposalRetry handles again the proposal whose verification is retried, unless its retry was replaced or
+// stopped meanwhile.
+func (c *Core) handleProposalRetry(ctx context.Context, e proposalRetryEvent) {
+	retry, ok := c.verificationRetries[proposalRetryKey(e.proposal)]
+	if !ok || retry.generation != e.generation {
+		c.logger.Debug("Dropping proposal retry of a stopped timer", "height", e.proposal.H(), "round", e.proposal.R())
+		return
+	}
+	retry.timer = nil
+	if err := c.handleValidMsg(ctx, e.proposal); err != nil {
+		c.logger.Debug("Proposal retry handling failed", "err", err)
+		return
+	}
+	c.backend.Gossip(c.CommitteeSet().Committee(), e.proposal)
 }
 
 func (c *Proposer) LogProposalMessageEvent(message string, proposal *message.Propose, from, to string) {
@@ -195,8 +706,32 @@ func (c *Proposer) LogProposalMessageEvent(message string, proposal *message.Pro
 		"msgRound", proposal.R(),
 		"currentStep", c.step,
 		"isProposer", c.IsProposer(),
-		"currentProposer", c.CommitteeSet().GetProposer(c.Round()),
+		"currentProposer", c.proposerOf(c.Round()),
 		"isNilMsg", proposal.Block().Hash() == common.Hash{},
 		"hash", proposal.Block().Hash(),
 	)
 }
+
+// recordProposalCost records the size of a verified proposal together with its verification time relative to that
+// size, so that proposals disproportionately expensive to verify stand out.
+func recordProposalCost(block *types.Block, elapsed time.Duration) {
+	size := int64(block.Size())
+	if size <= 0 {
+		return
+	}
+	ProposalSizeHistogram.Update(size)
+	ProposalVerificationCostHistogram.Update(elapsed.Microseconds() * 1024 / size)
+}
+
+// checkProposalView returns an error if the proposal round is outside of the rounds the engine can reach, or if its
+// height isn't the current one or adjacent to it.
+func (c *Proposer) checkProposalView(proposal *message.Propose) error {
+	if proposal.R() < 0 || proposal.R() > constants.MaxRound {
+		return fmt.Errorf("%w: round %d", constants.ErrProposalViewOutOfRange, proposal.R())
+	}
+	height := c.Height().Uint64()
+	if proposal.H() > height+1 || (height > 0 && proposal.H() < height-1) {
+		return fmt.Errorf("%w: height %d, current height %d", constants.ErrProposalViewOutOfRange, proposal.H(), height)
+	}
+	return nil
+}
@@ -3,8 +3,11 @@ package core
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
 	"math/big"
 	"reflect"
+	"sync"
 	"testing"
 	"time"
 
@@ -14,13 +17,18 @@ import (
 
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/consensus"
+	"github.com/autonity/autonity/consensus/misc"
 	"github.com/autonity/autonity/consensus/tendermint/core/committee"
 	"github.com/autonity/autonity/consensus/tendermint/core/constants"
 	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/rawdb"
 	"github.com/autonity/autonity/core/types"
 	"github.com/autonity/autonity/crypto"
 	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/metrics"
+	"github.com/autonity/autonity/params"
+	"github.com/autonity/autonity/trie"
 )
 
 func TestSendPropose(t *testing.T) {
@@ -52,6 +60,8 @@ func TestSendPropose(t *testing.T) {
 		}
 
 		backendMock := interfaces.NewMockBackend(ctrl)
+		backendMock.EXPECT().LastProposal()
+		backendMock.EXPECT().SaveProposal(gomock.Any())
 		backendMock.EXPECT().SetProposedBlockHash(proposal.Block().Hash())
 		backendMock.EXPECT().Sign(gomock.Any()).AnyTimes().DoAndReturn(makeSigner(proposerKey, proposer))
 		backendMock.EXPECT().Broadcast(gomock.Any(), proposal)
@@ -73,6 +83,234 @@ func TestSendPropose(t *testing.T) {
 	})
 }
 
+func TestSendProposalConcurrently(t *testing.T) {
+	proposerKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	proposer := crypto.PubkeyToAddress(proposerKey.PublicKey)
+	testCommittee := types.Committee{types.CommitteeMember{Address: proposer, VotingPower: big.NewInt(1)}}
+	valSet, err := committee.NewRoundRobinSet(testCommittee, proposer)
+	require.NoError(t, err)
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)})
+
+	for i := 0; i < 20; i++ {
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		// a single proposal is ever built and broadcast for the round
+		backendMock.EXPECT().LastProposal().Times(1)
+		backendMock.EXPECT().SaveProposal(gomock.Any()).Times(1)
+		backendMock.EXPECT().SetProposedBlockHash(block.Hash()).Times(1)
+		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(proposerKey, proposer)).Times(1)
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any()).Times(1)
+		messages := message.NewMap()
+		c := &Core{
+			address:          proposer,
+			backend:          backendMock,
+			messages:         messages,
+			curRoundMessages: messages.GetOrCreate(0),
+			logger:           log.Root(),
+			height:           big.NewInt(1),
+			validRound:       -1,
+			committee:        valSet,
+		}
+		c.SetDefaultHandlers()
+
+		var wg sync.WaitGroup
+		for j := 0; j < 2; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				c.proposer.SendProposal(context.Background(), block)
+			}()
+		}
+		wg.Wait()
+		require.True(t, c.SentProposal())
+	}
+}
+
+func TestSendProposalBroadcastJitter(t *testing.T) {
+	proposerKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	proposer := crypto.PubkeyToAddress(proposerKey.PublicKey)
+	testCommittee := types.Committee{types.CommitteeMember{Address: proposer, VotingPower: big.NewInt(1)}}
+	valSet, err := committee.NewRoundRobinSet(testCommittee, proposer)
+	require.NoError(t, err)
+	const round = int64(1)
+
+	newCore := func(backend interfaces.Backend, jitter time.Duration) (*Core, *fakeClock) {
+		clock := newFakeClock()
+		messages := message.NewMap()
+		c := &Core{
+			address:          proposer,
+			backend:          backend,
+			messages:         messages,
+			curRoundMessages: messages.GetOrCreate(round),
+			logger:           log.Root(),
+			round:            round,
+			height:           big.NewInt(1),
+			validRound:       -1,
+			committee:        valSet,
+			newRound:         clock.Now(),
+		}
+		c.SetClock(clock)
+		c.SetProposalBroadcastJitter(jitter)
+		c.SetDefaultHandlers()
+		return c, clock
+	}
+
+	t.Run("delay bounded by the jitter and the propose timeout", func(t *testing.T) {
+		c, clock := newCore(nil, 100*time.Millisecond)
+		for i := 0; i < 100; i++ {
+			require.LessOrEqual(t, c.proposalBroadcastDelay(round), 100*time.Millisecond)
+		}
+		// a jitter larger than the propose timeout is capped to half of what remains of it
+		c.SetProposalBroadcastJitter(time.Hour)
+		clock.Advance(100 * time.Millisecond)
+		bound := c.timeoutPropose(round)/2 - 100*time.Millisecond
+		for i := 0; i < 100; i++ {
+			require.LessOrEqual(t, c.proposalBroadcastDelay(round), bound)
+		}
+		// no delay once half of the propose timeout elapsed
+		clock.Advance(bound)
+		require.Equal(t, time.Duration(0), c.proposalBroadcastDelay(round))
+	})
+
+	t.Run("broadcast within the jitter window", func(t *testing.T) {
+		jitter := 200 * time.Millisecond
+		require.Less(t, jitter, InitialProposeTimeout)
+		block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)})
+		for i := 0; i < 10; i++ {
+			backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+			backendMock.EXPECT().LastProposal()
+			backendMock.EXPECT().SaveProposal(gomock.Any())
+			backendMock.EXPECT().SetProposedBlockHash(block.Hash())
+			backendMock.EXPECT().Sign(gomock.Any()).AnyTimes().DoAndReturn(makeSigner(proposerKey, proposer))
+			c, clock := newCore(backendMock, jitter)
+
+			// strict mock, the broadcast is not done right away
+			var delayed *message.Propose
+			backendMock.EXPECT().Post(gomock.Any()).MaxTimes(1).Do(func(ev any) {
+				delayed = ev.(proposalBroadcastEvent).proposal
+			})
+			c.proposer.SendProposal(context.Background(), block)
+			clock.Advance(jitter)
+			require.NotNil(t, delayed, "proposal broadcast not posted within the jitter window")
+			require.Equal(t, block.Hash(), delayed.Block().Hash())
+
+			backendMock.EXPECT().Broadcast(gomock.Any(), delayed)
+			c.handleProposalBroadcast(delayed)
+		}
+	})
+
+	t.Run("delayed proposal of a past height dropped", func(t *testing.T) {
+		c, _ := newCore(interfaces.NewMockBackend(gomock.NewController(t)), time.Second)
+		block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)})
+		proposal := message.NewPropose(round, 1, -1, block, makeSigner(proposerKey, proposer))
+		c.setHeight(big.NewInt(2))
+		// strict mock, no broadcast expected
+		c.handleProposalBroadcast(proposal)
+	})
+
+	t.Run("delayed proposal of a past round dropped", func(t *testing.T) {
+		enableTestMeters(t, &ProposalStaleDroppedMeter)
+		c, _ := newCore(interfaces.NewMockBackend(gomock.NewController(t)), time.Second)
+		block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)})
+		proposal := message.NewPropose(round, 1, -1, block, makeSigner(proposerKey, proposer))
+		c.setRound(round + 1)
+		// strict mock, no broadcast expected
+		c.handleProposalBroadcast(proposal)
+		require.Equal(t, int64(1), ProposalStaleDroppedMeter.Count())
+	})
+
+	t.Run("round change while building the proposal, no broadcast", func(t *testing.T) {
+		enableTestMeters(t, &ProposalStaleDroppedMeter)
+		block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)})
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		c, _ := newCore(backendMock, 0)
+		backendMock.EXPECT().LastProposal()
+		backendMock.EXPECT().SaveProposal(gomock.Any())
+		backendMock.EXPECT().SetProposedBlockHash(block.Hash())
+		// the round moves on while the proposal is signed
+		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(func(hash common.Hash) ([]byte, common.Address) {
+			c.setRound(round + 1)
+			return makeSigner(proposerKey, proposer)(hash)
+		})
+		// strict mock, no broadcast expected
+		c.proposer.SendProposal(context.Background(), block)
+		require.Equal(t, int64(1), ProposalStaleDroppedMeter.Count())
+	})
+}
+
+func TestSendProposalSelfEquivocation(t *testing.T) {
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	proposer := committeeSet.Committee()[0].Address // round 3 - height 1 proposer
+	height := uint64(1)
+	round := int64(3)
+	// the chain database survives the restarts
+	db := rawdb.NewMemoryDatabase()
+
+	// startCore returns a core with a fresh consensus state, as after a restart, and the proposals it broadcasts
+	startCore := func(t *testing.T) (*Core, *[]message.Msg) {
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		backendMock.EXPECT().LastProposal().AnyTimes().DoAndReturn(func() *rawdb.LastProposal {
+			return rawdb.ReadLastProposal(db)
+		})
+		backendMock.EXPECT().SaveProposal(gomock.Any()).AnyTimes().Do(func(proposal *rawdb.LastProposal) {
+			rawdb.WriteLastProposal(db, proposal)
+		})
+		backendMock.EXPECT().SetProposedBlockHash(gomock.Any()).AnyTimes()
+		backendMock.EXPECT().Sign(gomock.Any()).AnyTimes().DoAndReturn(makeSigner(keys[proposer], proposer))
+		var sent []message.Msg
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any()).AnyTimes().Do(func(_ types.Committee, msg message.Msg) {
+			sent = append(sent, msg)
+		})
+		messages := message.NewMap()
+		c := &Core{
+			address:          proposer,
+			backend:          backendMock,
+			messages:         messages,
+			curRoundMessages: messages.GetOrCreate(round),
+			logger:           log.Root(),
+			round:            round,
+			height:           new(big.Int).SetUint64(height),
+			validRound:       -1,
+			committee:        committeeSet,
+		}
+		c.SetDefaultHandlers()
+		return c, &sent
+	}
+	block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height)})
+	conflicting := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height), GasLimit: 1})
+
+	c, sent := startCore(t)
+	c.proposer.SendProposal(context.Background(), block)
+	require.Len(t, *sent, 1)
+	require.Equal(t, block.Hash(), (*sent)[0].Value())
+
+	t.Run("conflicting proposal after a restart is blocked", func(t *testing.T) {
+		enableTestMeters(t, &ProposalEquivocationMeter)
+		c, sent := startCore(t)
+		c.proposer.SendProposal(context.Background(), conflicting)
+		require.Empty(t, *sent)
+		require.True(t, c.sentProposal)
+		require.Equal(t, int64(1), ProposalEquivocationMeter.Count())
+		require.Equal(t, block.Hash(), rawdb.ReadLastProposal(db).Hash)
+	})
+
+	t.Run("same proposal after a restart is sent again", func(t *testing.T) {
+		c, sent := startCore(t)
+		c.proposer.SendProposal(context.Background(), block)
+		require.Len(t, *sent, 1)
+	})
+
+	t.Run("other block in a later round is sent", func(t *testing.T) {
+		c, sent := startCore(t)
+		c.round = round + 4 // same proposer
+		c.curRoundMessages = c.messages.GetOrCreate(c.round)
+		c.proposer.SendProposal(context.Background(), conflicting)
+		require.Len(t, *sent, 1)
+		require.Equal(t, &rawdb.LastProposal{Height: height, Round: uint64(round + 4), Hash: conflicting.Hash()}, rawdb.ReadLastProposal(db))
+	})
+}
+
 func TestHandleProposal(t *testing.T) {
 	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
 	addr := committeeSet.Committee()[0].Address // round 3 - height 1 proposer
@@ -182,8 +420,9 @@ func TestHandleProposal(t *testing.T) {
 		proposal := message.NewPropose(round, height, 1, block, signer).MustVerify(stubVerifier)
 		backendMock := interfaces.NewMockBackend(ctrl)
 		backendMock.EXPECT().VerifyProposal(gomock.Any()).Return(eventPostingDelay, consensus.ErrFutureTimestampBlock)
-		event := backlogMessageEvent{
-			msg: proposal,
+		event := futureProposalEvent{
+			proposal:   proposal,
+			generation: 1,
 		}
 		backendMock.EXPECT().Post(event).Times(1)
 		c := &Core{
@@ -207,6 +446,217 @@ func TestHandleProposal(t *testing.T) {
 		<-time.NewTimer(2 * eventPostingDelay).C
 	})
 
+	t.Run("proposal verification times out, nil prevote sent", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		block := types.NewBlockWithHeader(&types.Header{
+			Number: new(big.Int).SetUint64(height),
+		})
+		messageMap := message.NewMap()
+		curRoundMessages := messageMap.GetOrCreate(round)
+		proposal := message.NewPropose(round, height, 1, block, signer).MustVerify(stubVerifier)
+		backendMock := interfaces.NewMockBackend(ctrl)
+		backendMock.EXPECT().VerifyProposal(gomock.Any()).DoAndReturn(func(_ *types.Block) (time.Duration, error) {
+			time.Sleep(time.Second)
+			return 0, nil
+		})
+		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(signer)
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any()).Times(1).Do(func(_ types.Committee, msg message.Msg) {
+			require.Equal(t, message.PrevoteCode, msg.Code())
+			require.Equal(t, common.Hash{}, msg.Value())
+		})
+		c := &Core{
+			address:                     addr,
+			backend:                     backendMock,
+			messages:                    messageMap,
+			curRoundMessages:            curRoundMessages,
+			logger:                      log.Root(),
+			proposeTimeout:              NewTimeout(Propose, log.Root()),
+			committee:                   committeeSet,
+			round:                       round,
+			height:                      new(big.Int).SetUint64(height),
+			proposalVerificationTimeout: 10 * time.Millisecond,
+		}
+		c.SetDefaultHandlers()
+		start := time.Now()
+		err := c.proposer.HandleProposal(context.Background(), proposal)
+		require.ErrorIs(t, err, constants.ErrProposalVerificationTimeout)
+		require.Less(t, time.Since(start), time.Second)
+		require.Equal(t, Prevote, c.step)
+		require.Nil(t, curRoundMessages.Proposal())
+	})
+
+	t.Run("own proposals repeatedly fail verification, circuit breaker trips", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		block := types.NewBlockWithHeader(&types.Header{
+			Number: new(big.Int).SetUint64(height),
+		})
+		messageMap := message.NewMap()
+		curRoundMessages := messageMap.GetOrCreate(round)
+		proposal := message.NewPropose(round, height, 1, block, signer).MustVerify(stubVerifier)
+		backendMock := interfaces.NewMockBackend(ctrl)
+		backendMock.EXPECT().VerifyProposal(gomock.Any()).Times(3).Return(time.Duration(0), errors.New("corrupted state"))
+		backendMock.EXPECT().Sign(gomock.Any()).AnyTimes().DoAndReturn(signer)
+		// only the nil prevotes are broadcast, no proposal once the breaker tripped
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any()).Times(3).Do(func(_ types.Committee, msg message.Msg) {
+			require.Equal(t, message.PrevoteCode, msg.Code())
+		})
+		c := &Core{
+			address:          addr,
+			backend:          backendMock,
+			messages:         messageMap,
+			curRoundMessages: curRoundMessages,
+			logger:           log.Root(),
+			proposeTimeout:   NewTimeout(Propose, log.Root()),
+			committee:        committeeSet,
+			round:            round,
+			height:           new(big.Int).SetUint64(height),
+		}
+		c.SetProposalCircuitBreakerThreshold(3)
+		c.SetDefaultHandlers()
+		for i := 0; i < 3; i++ {
+			require.False(t, c.ProposingHalted())
+			c.SetStep(Propose)
+			require.Error(t, c.proposer.HandleProposal(context.Background(), proposal))
+		}
+		require.True(t, c.ProposingHalted())
+
+		c.proposer.SendProposal(context.Background(), block)
+		require.False(t, c.sentProposal)
+
+		c.ResetProposalCircuitBreaker()
+		require.False(t, c.ProposingHalted())
+	})
+
+	t.Run("proposer repeatedly sends invalid proposals, blacklisted until next height", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		block := types.NewBlockWithHeader(&types.Header{
+			Number: new(big.Int).SetUint64(height),
+		})
+		messageMap := message.NewMap()
+		curRoundMessages := messageMap.GetOrCreate(round)
+		proposal := message.NewPropose(round, height, 1, block, signer).MustVerify(stubVerifier)
+		backendMock := interfaces.NewMockBackend(ctrl)
+		// the third proposal must not be verified
+		backendMock.EXPECT().VerifyProposal(gomock.Any()).Times(2).Return(time.Duration(0), errors.New("invalid block"))
+		backendMock.EXPECT().Sign(gomock.Any()).AnyTimes().DoAndReturn(signer)
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any()).Times(3).Do(func(_ types.Committee, msg message.Msg) {
+			require.Equal(t, message.PrevoteCode, msg.Code())
+			require.Equal(t, common.Hash{}, msg.Value())
+		})
+		c := &Core{
+			address:          committeeSet.Committee()[1].Address,
+			backend:          backendMock,
+			messages:         messageMap,
+			curRoundMessages: curRoundMessages,
+			logger:           log.Root(),
+			proposeTimeout:   NewTimeout(Propose, log.Root()),
+			prevoteTimeout:   NewTimeout(Prevote, log.Root()),
+			precommitTimeout: NewTimeout(Precommit, log.Root()),
+			committee:        committeeSet,
+			round:            round,
+			height:           new(big.Int).SetUint64(height),
+		}
+		c.SetProposerBlacklistThreshold(2)
+		c.SetDefaultHandlers()
+		for i := 0; i < 2; i++ {
+			c.SetStep(Propose)
+			err := c.proposer.HandleProposal(context.Background(), proposal)
+			require.Error(t, err)
+			require.NotErrorIs(t, err, constants.ErrBlacklistedProposer)
+		}
+		c.SetStep(Propose)
+		err := c.proposer.HandleProposal(context.Background(), proposal)
+		require.ErrorIs(t, err, constants.ErrBlacklistedProposer)
+		require.Equal(t, Prevote, c.step)
+		require.False(t, shouldDisconnectSender(err))
+
+		// after prevoting, the proposals of the blacklisted proposer are dropped without another prevote
+		for _, step := range []Step{Prevote, Precommit} {
+			c.SetStep(step)
+			err = c.proposer.HandleProposal(context.Background(), proposal)
+			require.ErrorIs(t, err, constants.ErrBlacklistedProposer)
+			require.Equal(t, step, c.step)
+		}
+
+		// the blacklist is cleared on height change
+		backendMock.EXPECT().HeadBlock().Return(types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height)}))
+		c.setInitialState(0)
+		require.False(t, c.proposer.(*Proposer).isBlacklistedProposer(addr))
+	})
+
+	t.Run("own invalid proposals, never blacklisted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		block := types.NewBlockWithHeader(&types.Header{
+			Number: new(big.Int).SetUint64(height),
+		})
+		messageMap := message.NewMap()
+		curRoundMessages := messageMap.GetOrCreate(round)
+		proposal := message.NewPropose(round, height, 1, block, signer).MustVerify(stubVerifier)
+		backendMock := interfaces.NewMockBackend(ctrl)
+		backendMock.EXPECT().VerifyProposal(gomock.Any()).Times(2).Return(time.Duration(0), errors.New("invalid block"))
+		backendMock.EXPECT().Sign(gomock.Any()).AnyTimes().DoAndReturn(signer)
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any()).Times(2)
+		c := &Core{
+			address:          addr,
+			backend:          backendMock,
+			messages:         messageMap,
+			curRoundMessages: curRoundMessages,
+			logger:           log.Root(),
+			proposeTimeout:   NewTimeout(Propose, log.Root()),
+			prevoteTimeout:   NewTimeout(Prevote, log.Root()),
+			precommitTimeout: NewTimeout(Precommit, log.Root()),
+			committee:        committeeSet,
+			round:            round,
+			height:           new(big.Int).SetUint64(height),
+		}
+		c.SetProposerBlacklistThreshold(1)
+		c.SetDefaultHandlers()
+		for i := 0; i < 2; i++ {
+			c.SetStep(Propose)
+			err := c.proposer.HandleProposal(context.Background(), proposal)
+			require.Error(t, err)
+			require.NotErrorIs(t, err, constants.ErrBlacklistedProposer)
+		}
+		require.False(t, c.proposer.(*Proposer).isBlacklistedProposer(addr))
+	})
+
+	t.Run("proposal timestamp not greater than parent's, regression classified", func(t *testing.T) {
+		enableTestMeters(t, &ProposalTimestampRegressionMeter)
+		ctrl := gomock.NewController(t)
+		parent := &types.Header{Number: new(big.Int).SetUint64(height - 1), Time: 1000}
+		block := types.NewBlockWithHeader(&types.Header{
+			Number:     new(big.Int).SetUint64(height),
+			ParentHash: parent.Hash(),
+			Time:       parent.Time,
+		})
+		messageMap := message.NewMap()
+		curRoundMessages := messageMap.GetOrCreate(round)
+		proposal := message.NewPropose(round, height, 1, block, signer).MustVerify(stubVerifier)
+		backendMock := interfaces.NewMockBackend(ctrl)
+		backendMock.EXPECT().VerifyProposal(gomock.Any()).Return(time.Duration(0), fmt.Errorf("invalid timestamp: %w", consensus.ErrTimestampRegression))
+		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(signer)
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any()).Do(func(_ types.Committee, msg message.Msg) {
+			require.Equal(t, message.PrevoteCode, msg.Code())
+			require.Equal(t, common.Hash{}, msg.Value())
+		})
+		c := &Core{
+			address:          addr,
+			backend:          backendMock,
+			messages:         messageMap,
+			curRoundMessages: curRoundMessages,
+			logger:           log.Root(),
+			proposeTimeout:   NewTimeout(Propose, log.Root()),
+			committee:        committeeSet,
+			round:            round,
+			height:           new(big.Int).SetUint64(height),
+			lastHeader:       parent,
+		}
+		c.SetDefaultHandlers()
+		err := c.proposer.HandleProposal(context.Background(), proposal)
+		require.ErrorIs(t, err, consensus.ErrTimestampRegression)
+		require.Equal(t, int64(1), ProposalTimestampRegressionMeter.Count())
+	})
+
 	t.Run("valid proposal given, no error returned", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)})
@@ -254,7 +704,7 @@ func TestHandleProposal(t *testing.T) {
 		messages := message.NewMap()
 		curRoundMessages := messages.GetOrCreate(2)
 
-		proposal := message.NewPropose(2, 1, 2, proposalBlock, makeSigner(keys[proposer.Address], proposer.Address)).MustVerify(stubVerifier)
+		proposal := message.NewPropose(2, 1, -1, proposalBlock, makeSigner(keys[proposer.Address], proposer.Address)).MustVerify(stubVerifier)
 
 		assert.NoError(t, err)
 
@@ -380,40 +830,462 @@ func TestHandleProposal(t *testing.T) {
 	})
 }
 
-func TestHandleNewCandidateBlockMsg(t *testing.T) {
-	t.Run("invalid block send by miner", func(t *testing.T) {
+func TestProposalVerificationTimeout(t *testing.T) {
+	slow := types.NewBlockWithHeader(&types.Header{Number: common.Big1})
+	other := types.NewBlockWithHeader(&types.Header{Number: common.Big1, Extra: []byte("other")})
+	release := make(chan struct{})
+	backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+	// the slow block is verified until released, a second time once it completed
+	backendMock.EXPECT().VerifyProposal(slow).Times(2).DoAndReturn(func(_ *types.Block) (time.Duration, error) {
+		<-release
+		return 0, nil
+	})
+	backendMock.EXPECT().VerifyProposal(other).Return(time.Duration(0), nil)
+	clock := newFakeClock()
+	c := &Core{
+		backend:                     backendMock,
+		logger:                      log.Root(),
+		proposalVerificationTimeout: time.Second,
+	}
+	c.SetClock(clock)
+	proposer := &Proposer{c}
+
+	// the timeout fires once the clock reaches it
+	go func() {
+		for !clock.hasTimers() {
+			time.Sleep(time.Millisecond)
+		}
+		clock.Advance(time.Second)
+	}()
+	_, err := proposer.verifyProposalOnce(slow)
+	require.ErrorIs(t, err, constants.ErrProposalVerificationTimeout)
+
+	// the timed out verification is still running, the same block isn't verified again but the other ones are
+	_, err = proposer.verifyProposalOnce(slow)
+	require.ErrorIs(t, err, constants.ErrProposalVerificationTimeout)
+	_, err = proposer.verifyProposalOnce(other)
+	require.NoError(t, err)
+
+	close(release)
+	<-c.abandonedVerifications[slow.Hash()]
+	_, err = proposer.verifyProposalOnce(slow)
+	require.NoError(t, err)
+	require.Empty(t, c.abandonedVerifications)
+}
+
+func TestHandleProposalPrevoteMeters(t *testing.T) {
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	proposer := committeeSet.Committee()[0].Address // round 3 - height 1 proposer
+	me := committeeSet.Committee()[1].Address
+	height := uint64(1)
+	round := int64(3)
+
+	handleProposal := func(t *testing.T, verifyErr error) {
+		ctrl := gomock.NewController(t)
+		messages := message.NewMap()
+		block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height)})
+		proposal := message.NewPropose(round, height, -1, block, makeSigner(keys[proposer], proposer)).MustVerify(stubVerifier)
+		backendMock := interfaces.NewMockBackend(ctrl)
+		backendMock.EXPECT().VerifyProposal(proposal.Block()).Return(time.Duration(0), verifyErr)
+		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(keys[me], me))
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any())
 		c := &Core{
-			pendingCandidateBlocks: make(map[uint64]*types.Block),
+			address:          me,
+			backend:          backendMock,
+			messages:         messages,
+			curRoundMessages: messages.GetOrCreate(round),
+			logger:           log.Root(),
+			round:            round,
+			height:           new(big.Int).SetUint64(height),
+			step:             Propose,
+			lockedRound:      -1,
+			validRound:       -1,
+			proposeTimeout:   NewTimeout(Propose, log.Root()),
+			committee:        committeeSet,
 		}
 		c.SetDefaultHandlers()
-		c.proposer.HandleNewCandidateBlockMsg(context.Background(), nil)
-		require.Equal(t, 0, len(c.pendingCandidateBlocks))
+		require.ErrorIs(t, c.proposer.HandleProposal(context.Background(), proposal), verifyErr)
+		require.True(t, c.sentPrevote)
+	}
+
+	t.Run("invalid proposal, nil prevote counted", func(t *testing.T) {
+		enableTestMeters(t, &PrevoteNilSentMeter, &PrevoteValueSentMeter)
+		handleProposal(t, consensus.ErrInvalidNumber)
+		require.Equal(t, int64(1), PrevoteNilSentMeter.Count())
+		require.Equal(t, int64(0), PrevoteValueSentMeter.Count())
 	})
 
-	t.Run("discarding old height candidate blocks", func(t *testing.T) {
+	t.Run("valid proposal, value prevote counted", func(t *testing.T) {
+		enableTestMeters(t, &PrevoteNilSentMeter, &PrevoteValueSentMeter)
+		handleProposal(t, nil)
+		require.Equal(t, int64(0), PrevoteNilSentMeter.Count())
+		require.Equal(t, int64(1), PrevoteValueSentMeter.Count())
+	})
+}
 
-		oldHeightCandidate := types.NewBlockWithHeader(&types.Header{
-			Number: big.NewInt(10),
-		})
+func TestHandleProposalErrorKinds(t *testing.T) {
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	proposer := committeeSet.Committee()[0].Address // round 3 - height 1 proposer
+	me := committeeSet.Committee()[1].Address
+	height := uint64(1)
+	round := int64(3)
 
+	newCore := func(backend interfaces.Backend, curRound int64) *Core {
+		messages := message.NewMap()
 		c := &Core{
-			logger:                 log.New("backend", "test", "id", 0),
-			height:                 big.NewInt(11),
-			pendingCandidateBlocks: make(map[uint64]*types.Block),
+			address:          me,
+			backend:          backend,
+			messages:         messages,
+			curRoundMessages: messages.GetOrCreate(curRound),
+			logger:           log.Root(),
+			round:            curRound,
+			height:           new(big.Int).SetUint64(height),
+			step:             Propose,
+			lockedRound:      -1,
+			validRound:       -1,
+			proposeTimeout:   NewTimeout(Propose, log.Root()),
+			committee:        committeeSet,
 		}
 		c.SetDefaultHandlers()
-		c.proposer.HandleNewCandidateBlockMsg(context.Background(), oldHeightCandidate)
-		require.Equal(t, 0, len(c.pendingCandidateBlocks))
+		return c
+	}
+	newProposal := func(sender common.Address) *message.Propose {
+		block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height)})
+		return message.NewPropose(round, height, -1, block, makeSigner(keys[sender], sender)).MustVerify(stubVerifier)
+	}
+	requireKind := func(t *testing.T, err error, kind constants.ProposalErrorKind, wrapped error) {
+		have, ok := constants.ProposalErrorKindOf(err)
+		require.True(t, ok, "not a proposal error: %v", err)
+		require.Equal(t, kind, have)
+		require.ErrorIs(t, err, wrapped)
+	}
+
+	t.Run("proposal from non-proposer", func(t *testing.T) {
+		c := newCore(nil, round)
+		err := c.proposer.HandleProposal(context.Background(), newProposal(me))
+		requireKind(t, err, constants.NotProposer, constants.ErrNotFromProposer)
 	})
 
-	t.Run("candidate block is the one missed, proposal is broadcast", func(t *testing.T) {
-		ctrl := gomock.NewController(t)
-		committeeSet, keys := NewTestCommitteeSetWithKeys(1)
-		proposer, _ := committeeSet.GetByIndex(0)
-		proposerKey := keys[proposer.Address]
-		height := new(big.Int).SetUint64(1)
+	t.Run("old round proposal", func(t *testing.T) {
+		c := newCore(nil, round+1)
+		err := c.proposer.HandleProposal(context.Background(), newProposal(proposer))
+		requireKind(t, err, constants.OldRound, constants.ErrOldRoundMessage)
+	})
 
-		messages := message.NewMap()
+	t.Run("future timestamp proposal", func(t *testing.T) {
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		backendMock.EXPECT().VerifyProposal(gomock.Any()).Return(time.Hour, consensus.ErrFutureTimestampBlock)
+		c := newCore(backendMock, round)
+		defer c.proposer.StopFutureProposalTimer()
+		err := c.proposer.HandleProposal(context.Background(), newProposal(proposer))
+		requireKind(t, err, constants.FutureTimestamp, consensus.ErrFutureTimestampBlock)
+	})
+
+	t.Run("proposal failing verification", func(t *testing.T) {
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		backendMock.EXPECT().VerifyProposal(gomock.Any()).Return(time.Duration(0), consensus.ErrInvalidNumber)
+		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(keys[me], me))
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any())
+		c := newCore(backendMock, round)
+		err := c.proposer.HandleProposal(context.Background(), newProposal(proposer))
+		requireKind(t, err, constants.VerificationFailed, consensus.ErrInvalidNumber)
+	})
+
+	t.Run("proposal with invalid valid round", func(t *testing.T) {
+		for _, vr := range []int64{round, round + 1} {
+			c := newCore(nil, round)
+			block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height)})
+			proposal := message.NewPropose(round, height, vr, block, makeSigner(keys[proposer], proposer)).MustVerify(stubVerifier)
+			err := c.proposer.HandleProposal(context.Background(), proposal)
+			requireKind(t, err, constants.InvalidValidRound, constants.ErrInvalidValidRound)
+			require.Nil(t, c.curRoundMessages.Proposal())
+			require.True(t, shouldDisconnectSender(err))
+		}
+	})
+
+	t.Run("errors unrelated to the proposal are not wrapped", func(t *testing.T) {
+		c := newCore(nil, round-1)
+		err := c.proposer.HandleProposal(context.Background(), newProposal(proposer))
+		require.ErrorIs(t, err, constants.ErrFutureRoundMessage)
+		_, ok := constants.ProposalErrorKindOf(err)
+		require.False(t, ok)
+	})
+}
+
+func TestHandleNilBlockProposal(t *testing.T) {
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	proposer := committeeSet.Committee()[0].Address // round 3 - height 1 proposer
+	me := committeeSet.Committee()[1].Address
+	height := uint64(1)
+	round := int64(3)
+
+	newCore := func(backend interfaces.Backend) *Core {
+		messages := message.NewMap()
+		c := &Core{
+			address:          me,
+			backend:          backend,
+			messages:         messages,
+			curRoundMessages: messages.GetOrCreate(round),
+			logger:           log.Root(),
+			round:            round,
+			height:           new(big.Int).SetUint64(height),
+			step:             Propose,
+			lockedRound:      -1,
+			validRound:       -1,
+			proposeTimeout:   NewTimeout(Propose, log.Root()),
+			committee:        committeeSet,
+			committed:        map[uint64]common.Hash{height: common.HexToHash("0x01")},
+		}
+		c.SetDefaultHandlers()
+		return c
+	}
+	newProposal := func(round int64, sender common.Address) *message.Propose {
+		return message.NewFakePropose(message.Fake{FakeRound: round, FakeHeight: height, FakeSender: sender, FakePower: common.Big1}, -1, nil)
+	}
+
+	t.Run("current proposal without block, nil prevote sent", func(t *testing.T) {
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		var prevote message.Msg
+		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(keys[me], me))
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any()).Do(func(_ types.Committee, msg message.Msg) { prevote = msg })
+		c := newCore(backendMock)
+		outcomes := make(chan interfaces.ProposalOutcome, 1)
+		sub := c.SubscribeProposalOutcome(outcomes)
+		defer sub.Unsubscribe()
+
+		err := c.handleValidMsg(context.Background(), newProposal(round, proposer))
+		require.ErrorIs(t, err, constants.ErrNilProposalBlock)
+		kind, ok := constants.ProposalErrorKindOf(err)
+		require.True(t, ok)
+		require.Equal(t, constants.NilBlock, kind)
+		require.IsType(t, &message.Prevote{}, prevote)
+		require.Equal(t, common.Hash{}, prevote.Value())
+		require.Equal(t, Prevote, c.step)
+		require.Equal(t, constants.NilBlock, (<-outcomes).Kind)
+		require.True(t, shouldDisconnectSender(err))
+	})
+
+	t.Run("other proposal without block, rejected without prevote", func(t *testing.T) {
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		c := newCore(backendMock)
+
+		err := c.handleValidMsg(context.Background(), newProposal(round, me))
+		require.ErrorIs(t, err, constants.ErrNilProposalBlock)
+		err = c.handleValidMsg(context.Background(), newProposal(round+1, proposer))
+		require.ErrorIs(t, err, constants.ErrNilProposalBlock)
+		require.Equal(t, Propose, c.step)
+	})
+}
+
+func TestOldRoundCommitSkipsVerifiedBlock(t *testing.T) {
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	me := committeeSet.Committee()[0].Address
+	height := uint64(1)
+	block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height)})
+	proposalAt := func(round, validRound int64) *message.Propose {
+		proposer := committeeSet.GetProposer(round).Address
+		return message.NewPropose(round, height, validRound, block, makeSigner(keys[proposer], proposer)).MustVerify(stubVerifier)
+	}
+
+	ctrl := gomock.NewController(t)
+	backendMock := interfaces.NewMockBackend(ctrl)
+	messages := message.NewMap()
+	c := &Core{
+		address:          me,
+		backend:          backendMock,
+		messages:         messages,
+		curRoundMessages: messages.GetOrCreate(1),
+		logger:           log.Root(),
+		round:            1,
+		height:           new(big.Int).SetUint64(height),
+		step:             Propose,
+		lockedRound:      -1,
+		validRound:       -1,
+		proposeTimeout:   NewTimeout(Propose, log.Root()),
+		precommitTimeout: NewTimeout(Precommit, log.Root()),
+		committee:        committeeSet,
+	}
+	c.SetDefaultHandlers()
+	defer c.proposeTimeout.StopTimer()   // nolint: errcheck
+	defer c.precommitTimeout.StopTimer() // nolint: errcheck
+
+	// the block is verified once when first proposed in the current round
+	backendMock.EXPECT().VerifyProposal(block).Times(1)
+	backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(keys[me], me))
+	backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any())
+	require.NoError(t, c.proposer.HandleProposal(context.Background(), proposalAt(1, -1)))
+	require.Contains(t, c.verifiedBlocks, block.Hash())
+
+	// the same block gets re-proposed and committed in round 2 while we moved to round 3
+	c.round = 3
+	c.curRoundMessages = messages.GetOrCreate(3)
+	c.step = Propose
+	for i := 0; i < 3; i++ {
+		val, _ := committeeSet.GetByIndex(i)
+		messages.GetOrCreate(2).AddPrecommit(message.NewPrecommit(2, height, block.Hash(), makeSigner(keys[val.Address], val.Address)).MustVerify(stubVerifier))
+	}
+	backendMock.EXPECT().Commit(gomock.Any(), int64(2), gomock.Any()).Times(1).Do(func(committedBlock *types.Block, _ int64, _ [][]byte) {
+		require.Equal(t, block.Hash(), committedBlock.Hash())
+	})
+	require.NoError(t, c.proposer.HandleProposal(context.Background(), proposalAt(2, 1)))
+
+	// the cache doesn't outlive the height
+	backendMock.EXPECT().HeadBlock().Return(block)
+	c.prevoteTimeout = NewTimeout(Prevote, log.Root())
+	c.setInitialState(0)
+	require.NotContains(t, c.verifiedBlocks, block.Hash())
+}
+
+func TestOldRoundCommitVerificationRetry(t *testing.T) {
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	me := committeeSet.Committee()[0].Address
+	height := uint64(1)
+	round := int64(2)
+	delay := time.Millisecond
+	block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height)})
+	proposer := committeeSet.GetProposer(round).Address
+	proposal := message.NewPropose(round, height, -1, block, makeSigner(keys[proposer], proposer)).MustVerify(stubVerifier)
+
+	backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+	messages := message.NewMap()
+	c := &Core{
+		address:                        me,
+		backend:                        backendMock,
+		messages:                       messages,
+		curRoundMessages:               messages.GetOrCreate(3),
+		logger:                         log.Root(),
+		round:                          3,
+		height:                         new(big.Int).SetUint64(height),
+		step:                           Propose,
+		lockedRound:                    -1,
+		validRound:                     -1,
+		proposeTimeout:                 NewTimeout(Propose, log.Root()),
+		precommitTimeout:               NewTimeout(Precommit, log.Root()),
+		committee:                      committeeSet,
+		proposalVerificationRetries:    1,
+		proposalVerificationRetryDelay: delay,
+	}
+	clock := newFakeClock()
+	c.SetClock(clock)
+	c.SetDefaultHandlers()
+	defer c.precommitTimeout.StopTimer() // nolint: errcheck
+	for i := 0; i < 3; i++ {
+		val, _ := committeeSet.GetByIndex(i)
+		messages.GetOrCreate(round).AddPrecommit(message.NewPrecommit(round, height, block.Hash(), makeSigner(keys[val.Address], val.Address)).MustVerify(stubVerifier))
+	}
+
+	// the proposal isn't recorded while its verification is retried, so that it can be handled again
+	backendMock.EXPECT().VerifyProposal(block).Return(time.Duration(0), consensus.ErrPrunedAncestor)
+	require.ErrorIs(t, c.proposer.HandleProposal(context.Background(), proposal), constants.ErrProposalVerificationRetry)
+	require.Nil(t, messages.GetOrCreate(round).Proposal())
+	expectProposalRetry(t, backendMock, clock, proposal, delay)
+
+	backendMock.EXPECT().VerifyProposal(block).Return(time.Duration(0), nil)
+	backendMock.EXPECT().Commit(gomock.Any(), round, gomock.Any()).Times(1)
+	require.NoError(t, c.proposer.HandleProposal(context.Background(), proposal))
+}
+
+func TestHandleBoundaryRoundProposal(t *testing.T) {
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	proposer := committeeSet.Committee()[0].Address // round 3 - height 1 proposer
+	me := committeeSet.Committee()[1].Address
+	height := uint64(1)
+	round := int64(3)
+	block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height)})
+	proposal := message.NewPropose(round, height, -1, block, makeSigner(keys[proposer], proposer)).MustVerify(stubVerifier)
+	precommit := func(i int) *message.Precommit {
+		val, _ := committeeSet.GetByIndex(i)
+		return message.NewPrecommit(round, height, block.Hash(), makeSigner(keys[val.Address], val.Address)).MustVerify(stubVerifier)
+	}
+
+	// the round changed just before the proposal got received
+	newCore := func(backend interfaces.Backend, prevotes int) *Core {
+		messages := message.NewMap()
+		for i := 0; i < prevotes; i++ {
+			val, _ := committeeSet.GetByIndex(i)
+			messages.GetOrCreate(round).AddPrevote(message.NewPrevote(round, height, block.Hash(), makeSigner(keys[val.Address], val.Address)).MustVerify(stubVerifier))
+		}
+		for i := 0; i < 2; i++ {
+			messages.GetOrCreate(round).AddPrecommit(precommit(i))
+		}
+		c := &Core{
+			address:          me,
+			backend:          backend,
+			messages:         messages,
+			curRoundMessages: messages.GetOrCreate(round + 1),
+			logger:           log.Root(),
+			round:            round + 1,
+			height:           new(big.Int).SetUint64(height),
+			step:             Propose,
+			lockedRound:      -1,
+			validRound:       -1,
+			proposeTimeout:   NewTimeout(Propose, log.Root()),
+			precommitTimeout: NewTimeout(Precommit, log.Root()),
+			committee:        committeeSet,
+		}
+		c.SetDefaultHandlers()
+		return c
+	}
+
+	t.Run("quorum of prevotes, valid value set and committed on the last precommit", func(t *testing.T) {
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		backendMock.EXPECT().VerifyProposal(block).Times(1)
+		c := newCore(backendMock, 3)
+		err := c.proposer.HandleProposal(context.Background(), proposal)
+		require.ErrorIs(t, err, constants.ErrOldRoundMessage)
+		require.True(t, c.messages.GetOrCreate(round).IsProposalVerified())
+		require.Equal(t, round, c.validRound)
+		require.Equal(t, block.Hash(), c.validValue.Hash())
+
+		backendMock.EXPECT().Commit(gomock.Any(), round, gomock.Any()).Times(1).Do(func(committedBlock *types.Block, _ int64, _ [][]byte) {
+			require.Equal(t, block.Hash(), committedBlock.Hash())
+		})
+		require.NoError(t, c.precommiter.HandlePrecommit(context.Background(), precommit(2)))
+	})
+
+	t.Run("no quorum of prevotes, left unverified", func(t *testing.T) {
+		c := newCore(interfaces.NewMockBackend(gomock.NewController(t)), 2)
+		err := c.proposer.HandleProposal(context.Background(), proposal)
+		require.ErrorIs(t, err, constants.ErrOldRoundMessage)
+		require.False(t, c.messages.GetOrCreate(round).IsProposalVerified())
+		require.Equal(t, int64(-1), c.validRound)
+	})
+}
+
+func TestHandleNewCandidateBlockMsg(t *testing.T) {
+	t.Run("invalid block send by miner", func(t *testing.T) {
+		c := &Core{
+			pendingCandidateBlocks: make(map[uint64]*types.Block),
+		}
+		c.SetDefaultHandlers()
+		c.proposer.HandleNewCandidateBlockMsg(context.Background(), nil)
+		require.Equal(t, 0, len(c.pendingCandidateBlocks))
+	})
+
+	t.Run("discarding old height candidate blocks", func(t *testing.T) {
+
+		oldHeightCandidate := types.NewBlockWithHeader(&types.Header{
+			Number: big.NewInt(10),
+		})
+
+		c := &Core{
+			logger:                 log.New("backend", "test", "id", 0),
+			height:                 big.NewInt(11),
+			pendingCandidateBlocks: make(map[uint64]*types.Block),
+		}
+		c.SetDefaultHandlers()
+		c.proposer.HandleNewCandidateBlockMsg(context.Background(), oldHeightCandidate)
+		require.Equal(t, 0, len(c.pendingCandidateBlocks))
+	})
+
+	t.Run("candidate block is the one missed, proposal is broadcast", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		committeeSet, keys := NewTestCommitteeSetWithKeys(1)
+		proposer, _ := committeeSet.GetByIndex(0)
+		proposerKey := keys[proposer.Address]
+		height := new(big.Int).SetUint64(1)
+
+		messages := message.NewMap()
 		preBlock := types.NewBlockWithHeader(&types.Header{
 			Number: big.NewInt(0),
 		})
@@ -425,6 +1297,8 @@ func TestHandleNewCandidateBlockMsg(t *testing.T) {
 		proposal := generateBlockProposal(1, height, validRound, false, makeSigner(proposerKey, proposer.Address))
 
 		backendMock := interfaces.NewMockBackend(ctrl)
+		backendMock.EXPECT().LastProposal()
+		backendMock.EXPECT().SaveProposal(gomock.Any())
 		backendMock.EXPECT().SetProposedBlockHash(proposal.Block().Hash())
 		backendMock.EXPECT().Broadcast(gomock.Any(), proposal)
 		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(proposerKey, proposer.Address))
@@ -447,4 +1321,870 @@ func TestHandleNewCandidateBlockMsg(t *testing.T) {
 		require.Equal(t, 1, len(c.pendingCandidateBlocks))
 		require.Equal(t, uint64(1), c.pendingCandidateBlocks[uint64(1)].Number().Uint64())
 	})
+
+	t.Run("late worse candidate doesn't replace the buffered one", func(t *testing.T) {
+		committeeSet, _ := NewTestCommitteeSetWithKeys(2)
+		c := &Core{
+			logger:                 log.New("backend", "test", "id", 0),
+			height:                 big.NewInt(11),
+			committee:              committeeSet,
+			pendingCandidateBlocks: make(map[uint64]*types.Block),
+		}
+		c.SetDefaultHandlers()
+
+		header := &types.Header{Number: big.NewInt(11), GasUsed: params.TxGas}
+		tx := types.NewTransaction(0, common.Address{}, big.NewInt(1), params.TxGas, big.NewInt(1), nil)
+		full := types.NewBlockWithHeader(header).WithBody([]*types.Transaction{tx}, nil)
+		empty := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(11), Time: 1})
+
+		c.proposer.HandleNewCandidateBlockMsg(context.Background(), full)
+		c.proposer.HandleNewCandidateBlockMsg(context.Background(), empty)
+		require.Equal(t, full.Hash(), c.pendingCandidateBlocks[11].Hash())
+
+		// an as good candidate is more recent, it replaces the buffered one
+		header.Time = 2
+		newer := types.NewBlockWithHeader(header).WithBody([]*types.Transaction{tx}, nil)
+		c.proposer.HandleNewCandidateBlockMsg(context.Background(), newer)
+		require.Equal(t, newer.Hash(), c.pendingCandidateBlocks[11].Hash())
+	})
+}
+
+// expectProposalRetry advances the clock by the given delay and expects the proposal to be posted back to the main
+// loop then, not earlier.
+func expectProposalRetry(t *testing.T, backendMock *interfaces.MockBackend, clock *fakeClock, proposal *message.Propose, delay time.Duration) {
+	clock.Advance(delay - time.Nanosecond)
+	var posted proposalRetryEvent
+	backendMock.EXPECT().Post(gomock.Any()).Do(func(ev any) { posted = ev.(proposalRetryEvent) })
+	clock.Advance(time.Nanosecond)
+	require.Equal(t, proposal, posted.proposal)
+}
+
+func TestProposalVerificationRetries(t *testing.T) {
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	proposer := committeeSet.Committee()[0].Address // round 3 - height 1 proposer
+	me := committeeSet.Committee()[1].Address
+	height := uint64(1)
+	round := int64(3)
+	delay := time.Millisecond
+
+	newCore := func(backend interfaces.Backend, clock *fakeClock) *Core {
+		messages := message.NewMap()
+		c := &Core{
+			address:                        me,
+			backend:                        backend,
+			messages:                       messages,
+			curRoundMessages:               messages.GetOrCreate(round),
+			logger:                         log.Root(),
+			round:                          round,
+			height:                         new(big.Int).SetUint64(height),
+			step:                           Propose,
+			lockedRound:                    -1,
+			validRound:                     -1,
+			proposeTimeout:                 NewTimeout(Propose, log.Root()),
+			committee:                      committeeSet,
+			proposalVerificationRetries:    3,
+			proposalVerificationRetryDelay: delay,
+		}
+		c.SetClock(clock)
+		c.SetDefaultHandlers()
+		return c
+	}
+	block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height)})
+	proposal := message.NewPropose(round, height, -1, block, makeSigner(keys[proposer], proposer)).MustVerify(stubVerifier)
+	t.Run("transient failure eventually verified", func(t *testing.T) {
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		transient := &trie.MissingNodeError{NodeHash: common.HexToHash("0x01")}
+		gomock.InOrder(
+			backendMock.EXPECT().VerifyProposal(block).Return(time.Duration(0), transient).Times(2),
+			backendMock.EXPECT().VerifyProposal(block).Return(time.Duration(0), nil),
+		)
+		var prevote message.Msg
+		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(keys[me], me))
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any()).Do(func(_ types.Committee, msg message.Msg) { prevote = msg })
+		clock := newFakeClock()
+		c := newCore(backendMock, clock)
+
+		// the main loop isn't held while waiting for the retries
+		for i := 0; i < 2; i++ {
+			require.ErrorIs(t, c.proposer.HandleProposal(context.Background(), proposal), constants.ErrProposalVerificationRetry)
+			require.Equal(t, Propose, c.step)
+			expectProposalRetry(t, backendMock, clock, proposal, delay)
+		}
+		require.NoError(t, c.proposer.HandleProposal(context.Background(), proposal))
+		require.IsType(t, &message.Prevote{}, prevote)
+		require.Equal(t, block.Hash(), prevote.Value())
+	})
+
+	t.Run("transient failure exhausting the retries", func(t *testing.T) {
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		backendMock.EXPECT().VerifyProposal(block).Return(time.Duration(0), consensus.ErrPrunedAncestor).Times(4)
+		var prevote message.Msg
+		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(keys[me], me))
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any()).Do(func(_ types.Committee, msg message.Msg) { prevote = msg })
+		clock := newFakeClock()
+		c := newCore(backendMock, clock)
+
+		for i := 0; i < 3; i++ {
+			require.ErrorIs(t, c.proposer.HandleProposal(context.Background(), proposal), constants.ErrProposalVerificationRetry)
+			expectProposalRetry(t, backendMock, clock, proposal, delay)
+		}
+		require.ErrorIs(t, c.proposer.HandleProposal(context.Background(), proposal), consensus.ErrPrunedAncestor)
+		require.Equal(t, common.Hash{}, prevote.Value())
+	})
+
+	t.Run("retried proposal failing past the propose step, no second prevote", func(t *testing.T) {
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		backendMock.EXPECT().VerifyProposal(block).Return(time.Duration(0), consensus.ErrPrunedAncestor).Times(4)
+		clock := newFakeClock()
+		c := newCore(backendMock, clock)
+
+		for i := 0; i < 3; i++ {
+			require.ErrorIs(t, c.proposer.HandleProposal(context.Background(), proposal), constants.ErrProposalVerificationRetry)
+			expectProposalRetry(t, backendMock, clock, proposal, delay)
+		}
+		// the propose timeout expired meanwhile, the nil prevote was sent on timeout
+		c.SetStep(Prevote)
+		require.ErrorIs(t, c.proposer.HandleProposal(context.Background(), proposal), consensus.ErrPrunedAncestor)
+		require.Equal(t, Prevote, c.step)
+	})
+
+	t.Run("permanent failure is not retried", func(t *testing.T) {
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		backendMock.EXPECT().VerifyProposal(block).Return(time.Duration(0), consensus.ErrInvalidNumber).Times(1)
+		var prevote message.Msg
+		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(keys[me], me))
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any()).Do(func(_ types.Committee, msg message.Msg) { prevote = msg })
+		c := newCore(backendMock, newFakeClock())
+
+		require.ErrorIs(t, c.proposer.HandleProposal(context.Background(), proposal), consensus.ErrInvalidNumber)
+		require.IsType(t, &message.Prevote{}, prevote)
+		require.Equal(t, common.Hash{}, prevote.Value())
+	})
+
+	t.Run("retried proposal measured once", func(t *testing.T) {
+		enableTestMeters(t, &ProposalInProposeMeter)
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		gomock.InOrder(
+			backendMock.EXPECT().VerifyProposal(block).Return(time.Duration(0), consensus.ErrPrunedAncestor),
+			backendMock.EXPECT().VerifyProposal(block).Return(time.Duration(0), nil),
+		)
+		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(keys[me], me))
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any())
+		clock := newFakeClock()
+		c := newCore(backendMock, clock)
+
+		require.ErrorIs(t, c.proposer.HandleProposal(context.Background(), proposal), constants.ErrProposalVerificationRetry)
+		expectProposalRetry(t, backendMock, clock, proposal, delay)
+		require.NoError(t, c.proposer.HandleProposal(context.Background(), proposal))
+		require.Equal(t, int64(1), ProposalInProposeMeter.Count())
+	})
+
+	t.Run("retries independent of each other and of a future proposal", func(t *testing.T) {
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		var posted []any
+		backendMock.EXPECT().Post(gomock.Any()).AnyTimes().Do(func(ev any) { posted = append(posted, ev) })
+		clock := newFakeClock()
+		c := newCore(backendMock, clock)
+		p := &Proposer{c}
+
+		oldRound := message.NewPropose(round-1, height, -1, block, makeSigner(keys[proposer], proposer)).MustVerify(stubVerifier)
+		future := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height), Time: 1})
+		futureProposal := message.NewPropose(round, height, -1, future, makeSigner(keys[proposer], proposer)).MustVerify(stubVerifier)
+		p.scheduleFutureProposal(futureProposal, delay)
+		require.True(t, p.scheduleVerificationRetry(proposal, consensus.ErrPrunedAncestor))
+		require.True(t, p.scheduleVerificationRetry(oldRound, consensus.ErrPrunedAncestor))
+		clock.Advance(delay)
+		require.Len(t, posted, 3)
+
+		// a retry scheduled again replaces its own previous attempt only
+		stale := posted[1].(proposalRetryEvent)
+		require.True(t, p.scheduleVerificationRetry(stale.proposal, consensus.ErrPrunedAncestor))
+		c.handleProposalRetry(context.Background(), stale)
+		require.True(t, clock.hasTimers())
+
+		// all the retries are stopped at a new height
+		c.stopVerificationRetries()
+		require.False(t, clock.hasTimers())
+		require.False(t, c.isRetriedProposal(proposal))
+	})
+}
+
+func TestProposalVerificationGrace(t *testing.T) {
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	proposer := committeeSet.Committee()[0].Address // round 3 - height 1 proposer
+	me := committeeSet.Committee()[1].Address
+	height := uint64(1)
+	round := int64(3)
+	grace := time.Millisecond
+	block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height)})
+	proposal := message.NewPropose(round, height, -1, block, makeSigner(keys[proposer], proposer)).MustVerify(stubVerifier)
+
+	newCore := func(backend interfaces.Backend, clock *fakeClock) *Core {
+		messages := message.NewMap()
+		c := &Core{
+			address:                   me,
+			backend:                   backend,
+			messages:                  messages,
+			curRoundMessages:          messages.GetOrCreate(round),
+			logger:                    log.Root(),
+			round:                     round,
+			height:                    new(big.Int).SetUint64(height),
+			step:                      Propose,
+			lockedRound:               -1,
+			validRound:                -1,
+			proposeTimeout:            NewTimeout(Propose, log.Root()),
+			committee:                 committeeSet,
+			newRound:                  clock.Now(),
+			proposalVerificationGrace: grace,
+		}
+		c.SetClock(clock)
+		c.SetDefaultHandlers()
+		return c
+	}
+
+	t.Run("failure then success within the grace period", func(t *testing.T) {
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		gomock.InOrder(
+			backendMock.EXPECT().VerifyProposal(block).Return(time.Duration(0), consensus.ErrUnknownAncestor),
+			backendMock.EXPECT().VerifyProposal(block).Return(time.Duration(0), nil),
+		)
+		var prevote message.Msg
+		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(keys[me], me))
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any()).Do(func(_ types.Committee, msg message.Msg) { prevote = msg })
+		clock := newFakeClock()
+		c := newCore(backendMock, clock)
+
+		require.ErrorIs(t, c.proposer.HandleProposal(context.Background(), proposal), constants.ErrProposalVerificationRetry)
+		expectProposalRetry(t, backendMock, clock, proposal, grace)
+		require.NoError(t, c.proposer.HandleProposal(context.Background(), proposal))
+		require.IsType(t, &message.Prevote{}, prevote)
+		require.Equal(t, block.Hash(), prevote.Value())
+	})
+
+	t.Run("single attempt after the grace period", func(t *testing.T) {
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		backendMock.EXPECT().VerifyProposal(block).Return(time.Duration(0), consensus.ErrUnknownAncestor).Times(2)
+		var prevote message.Msg
+		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(keys[me], me))
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any()).Do(func(_ types.Committee, msg message.Msg) { prevote = msg })
+		clock := newFakeClock()
+		c := newCore(backendMock, clock)
+
+		require.ErrorIs(t, c.proposer.HandleProposal(context.Background(), proposal), constants.ErrProposalVerificationRetry)
+		expectProposalRetry(t, backendMock, clock, proposal, grace)
+		require.ErrorIs(t, c.proposer.HandleProposal(context.Background(), proposal), consensus.ErrUnknownAncestor)
+		require.Equal(t, common.Hash{}, prevote.Value())
+	})
+
+	t.Run("no second attempt past the propose timeout", func(t *testing.T) {
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		backendMock.EXPECT().VerifyProposal(block).Return(time.Duration(0), consensus.ErrUnknownAncestor).Times(1)
+		var prevote message.Msg
+		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(keys[me], me))
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any()).Do(func(_ types.Committee, msg message.Msg) { prevote = msg })
+		clock := newFakeClock()
+		c := newCore(backendMock, clock)
+		clock.Advance(c.timeoutPropose(round))
+
+		require.ErrorIs(t, c.proposer.HandleProposal(context.Background(), proposal), consensus.ErrUnknownAncestor)
+		require.Equal(t, common.Hash{}, prevote.Value())
+	})
+}
+
+func TestProposalStepMeters(t *testing.T) {
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	proposer := committeeSet.Committee()[0].Address // round 3 - height 1 proposer
+	me := committeeSet.Committee()[1].Address
+	height := uint64(1)
+	round := int64(3)
+	block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height)})
+	proposal := message.NewPropose(round, height, -1, block, makeSigner(keys[proposer], proposer)).MustVerify(stubVerifier)
+
+	tests := []struct {
+		step  Step
+		meter *metrics.Meter
+	}{
+		{Propose, &ProposalInProposeMeter},
+		{Prevote, &ProposalInPrevoteMeter},
+		{Precommit, &ProposalInPrecommitMeter},
+		{PrecommitDone, &ProposalInPrecommitMeter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.step.String(), func(t *testing.T) {
+			enableTestMeters(t, &ProposalInProposeMeter, &ProposalInPrevoteMeter, &ProposalInPrecommitMeter)
+			backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+			backendMock.EXPECT().VerifyProposal(block).Return(time.Duration(0), nil)
+			backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(keys[me], me)).AnyTimes()
+			backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any()).AnyTimes()
+			messages := message.NewMap()
+			c := &Core{
+				address:          me,
+				backend:          backendMock,
+				messages:         messages,
+				curRoundMessages: messages.GetOrCreate(round),
+				logger:           log.Root(),
+				round:            round,
+				height:           new(big.Int).SetUint64(height),
+				step:             tt.step,
+				lockedRound:      -1,
+				validRound:       -1,
+				proposeTimeout:   NewTimeout(Propose, log.Root()),
+				committee:        committeeSet,
+			}
+			c.SetDefaultHandlers()
+
+			require.NoError(t, c.proposer.HandleProposal(context.Background(), proposal))
+			for _, meter := range []*metrics.Meter{&ProposalInProposeMeter, &ProposalInPrevoteMeter, &ProposalInPrecommitMeter} {
+				want := int64(0)
+				if meter == tt.meter {
+					want = 1
+				}
+				require.Equal(t, want, (*meter).Count())
+			}
+		})
+	}
+}
+
+func TestProposalCostMetrics(t *testing.T) {
+	enableTestHistograms(t, &ProposalSizeHistogram, &ProposalVerificationCostHistogram)
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	proposer := committeeSet.Committee()[0].Address // round 3 - height 1 proposer
+	me := committeeSet.Committee()[1].Address
+	height := uint64(1)
+	round := int64(3)
+	block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height), Extra: make([]byte, 256*1024)})
+	proposal := message.NewPropose(round, height, -1, block, makeSigner(keys[proposer], proposer)).MustVerify(stubVerifier)
+
+	clock := newFakeClock()
+	backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+	backendMock.EXPECT().VerifyProposal(block).DoAndReturn(func(*types.Block) (time.Duration, error) {
+		clock.Advance(2 * time.Second)
+		return 2 * time.Second, nil
+	})
+	backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(keys[me], me)).AnyTimes()
+	backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any()).AnyTimes()
+	messages := message.NewMap()
+	c := &Core{
+		address:          me,
+		backend:          backendMock,
+		messages:         messages,
+		curRoundMessages: messages.GetOrCreate(round),
+		logger:           log.Root(),
+		round:            round,
+		height:           new(big.Int).SetUint64(height),
+		step:             Propose,
+		lockedRound:      -1,
+		validRound:       -1,
+		proposeTimeout:   NewTimeout(Propose, log.Root()),
+		committee:        committeeSet,
+		clock:            clock,
+	}
+	c.SetDefaultHandlers()
+
+	require.NoError(t, c.proposer.HandleProposal(context.Background(), proposal))
+	size := int64(block.Size())
+	require.Greater(t, size, int64(256*1024))
+	require.Equal(t, int64(1), ProposalSizeHistogram.Count())
+	require.Equal(t, size, ProposalSizeHistogram.Max())
+	require.Equal(t, int64(1), ProposalVerificationCostHistogram.Count())
+	require.Equal(t, (2*time.Second).Microseconds()*1024/size, ProposalVerificationCostHistogram.Max())
+}
+
+func TestProposalPrefetch(t *testing.T) {
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	proposer := committeeSet.Committee()[0].Address // round 3 - height 1 proposer
+	me := committeeSet.Committee()[1].Address
+	height := uint64(1)
+	round := int64(3)
+	block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height)})
+	proposal := message.NewPropose(round, height, -1, block, makeSigner(keys[proposer], proposer)).MustVerify(stubVerifier)
+
+	newCore := func(backend interfaces.Backend, prefetch bool) *Core {
+		messages := message.NewMap()
+		c := &Core{
+			address:          me,
+			backend:          backend,
+			messages:         messages,
+			curRoundMessages: messages.GetOrCreate(round),
+			logger:           log.Root(),
+			round:            round,
+			height:           new(big.Int).SetUint64(height),
+			step:             Propose,
+			lockedRound:      -1,
+			validRound:       -1,
+			proposeTimeout:   NewTimeout(Propose, log.Root()),
+			committee:        committeeSet,
+		}
+		c.SetDefaultHandlers()
+		c.SetProposalPrefetch(prefetch)
+		return c
+	}
+
+	t.Run("prefetch runs alongside the verification", func(t *testing.T) {
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		stopped := false
+		gomock.InOrder(
+			backendMock.EXPECT().PrefetchProposal(block).Return(func() { stopped = true }),
+			backendMock.EXPECT().VerifyProposal(block).DoAndReturn(func(*types.Block) (time.Duration, error) {
+				require.False(t, stopped, "prefetch stopped before the verification")
+				return 0, nil
+			}),
+		)
+		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(keys[me], me))
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any())
+		c := newCore(backendMock, true)
+
+		require.NoError(t, c.proposer.HandleProposal(context.Background(), proposal))
+		require.True(t, stopped, "prefetch left running after the verification")
+	})
+
+	t.Run("prefetch disabled by default", func(t *testing.T) {
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		backendMock.EXPECT().VerifyProposal(block)
+		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(keys[me], me))
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any())
+		c := newCore(backendMock, false)
+
+		require.NoError(t, c.proposer.HandleProposal(context.Background(), proposal))
+	})
+}
+
+func TestHandleFarFutureRoundProposal(t *testing.T) {
+	enableTestMeters(t, &ProposalFutureRoundDroppedMeter)
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	proposer := committeeSet.Committee()[0].Address
+	me := committeeSet.Committee()[1].Address
+	height := uint64(1)
+
+	backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+	messages := message.NewMap()
+	c := &Core{
+		address:                me,
+		backend:                backendMock,
+		messages:               messages,
+		curRoundMessages:       messages.GetOrCreate(0),
+		logger:                 log.Root(),
+		height:                 new(big.Int).SetUint64(height),
+		step:                   Propose,
+		lockedRound:            -1,
+		validRound:             -1,
+		committee:              committeeSet,
+		backlogs:               make(map[common.Address][]message.Msg),
+		futureRoundChange:      make(map[int64]map[common.Address]*big.Int),
+		maxProposalRoundsAhead: 10,
+	}
+	c.SetDefaultHandlers()
+	newProposal := func(round int64) *message.Propose {
+		block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height)})
+		return message.NewPropose(round, height, -1, block, makeSigner(keys[proposer], proposer)).MustVerify(stubVerifier)
+	}
+
+	// a proposal within the ceiling is backlogged
+	err := c.handleValidMsg(context.Background(), newProposal(10))
+	require.ErrorIs(t, err, constants.ErrFutureRoundMessage)
+	require.Len(t, c.backlogs[proposer], 1)
+	require.Contains(t, c.futureRoundChange, int64(10))
+
+	// a proposal beyond is dropped and forgotten, without disconnecting its sender
+	far := newProposal(constants.MaxRound)
+	backendMock.EXPECT().RemoveMessageFromLocalCache(far)
+	err = c.handleValidMsg(context.Background(), far)
+	require.ErrorIs(t, err, constants.ErrFarFutureRoundProposal)
+	require.False(t, shouldDisconnectSender(err))
+	require.Len(t, c.backlogs[proposer], 1)
+	require.NotContains(t, c.futureRoundChange, int64(constants.MaxRound))
+	require.Equal(t, int64(1), ProposalFutureRoundDroppedMeter.Count())
+}
+
+func TestHandleProposalOutcome(t *testing.T) {
+	enableTestMeters(t, &ProposalOutcomeDroppedMeter)
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	proposer := committeeSet.Committee()[0].Address // round 3 - height 1 proposer
+	me := committeeSet.Committee()[1].Address
+	height := uint64(1)
+	round := int64(3)
+
+	handle := func(backend interfaces.Backend, proposal *message.Propose) (interfaces.ProposalOutcome, error) {
+		messages := message.NewMap()
+		c := &Core{
+			address:          me,
+			backend:          backend,
+			messages:         messages,
+			curRoundMessages: messages.GetOrCreate(round),
+			logger:           log.Root(),
+			round:            round,
+			height:           new(big.Int).SetUint64(height),
+			lockedRound:      -1,
+			validRound:       -1,
+			proposeTimeout:   NewTimeout(Propose, log.Root()),
+			committee:        committeeSet,
+		}
+		c.SetDefaultHandlers()
+		outcomes := make(chan interfaces.ProposalOutcome, 1)
+		sub := c.SubscribeProposalOutcome(outcomes)
+		defer sub.Unsubscribe()
+		// a subscriber never reading doesn't hold the consensus back
+		slow := c.SubscribeProposalOutcome(make(chan interfaces.ProposalOutcome))
+		defer slow.Unsubscribe()
+
+		err := c.proposer.HandleProposal(context.Background(), proposal)
+		select {
+		case outcome := <-outcomes:
+			return outcome, err
+		default:
+			t.Fatal("proposal outcome not notified")
+			return interfaces.ProposalOutcome{}, nil
+		}
+	}
+	newProposal := func(sender common.Address) *message.Propose {
+		block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height)})
+		return message.NewPropose(round, height, 2, block, makeSigner(keys[sender], sender)).MustVerify(stubVerifier)
+	}
+	requireOutcome := func(t *testing.T, outcome interfaces.ProposalOutcome, proposal *message.Propose, kind constants.ProposalErrorKind) {
+		require.Equal(t, proposal.Sender(), outcome.Proposer)
+		require.Equal(t, height, outcome.Height)
+		require.Equal(t, round, outcome.Round)
+		require.Equal(t, proposal.Block().Hash(), outcome.Value)
+		require.Equal(t, kind, outcome.Kind)
+	}
+
+	t.Run("accepted proposal", func(t *testing.T) {
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		backendMock.EXPECT().VerifyProposal(gomock.Any())
+		proposal := newProposal(proposer)
+		outcome, err := handle(backendMock, proposal)
+		require.NoError(t, err)
+		require.True(t, outcome.Accepted())
+		requireOutcome(t, outcome, proposal, 0)
+	})
+
+	t.Run("proposal from non-proposer", func(t *testing.T) {
+		proposal := newProposal(me)
+		outcome, err := handle(nil, proposal)
+		require.False(t, outcome.Accepted())
+		require.Equal(t, err, outcome.Err)
+		requireOutcome(t, outcome, proposal, constants.NotProposer)
+	})
+
+	t.Run("proposal failing verification", func(t *testing.T) {
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		backendMock.EXPECT().VerifyProposal(gomock.Any()).Return(time.Duration(0), consensus.ErrInvalidNumber)
+		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(keys[me], me))
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any())
+		proposal := newProposal(proposer)
+		outcome, err := handle(backendMock, proposal)
+		require.False(t, outcome.Accepted())
+		require.ErrorIs(t, outcome.Err, consensus.ErrInvalidNumber)
+		require.Equal(t, err, outcome.Err)
+		requireOutcome(t, outcome, proposal, constants.VerificationFailed)
+	})
+
+	require.Equal(t, int64(3), ProposalOutcomeDroppedMeter.Count())
+}
+
+func TestProposalConversionMetrics(t *testing.T) {
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	me := committeeSet.GetProposer(1).Address // round 1 - height 1 proposer
+	height := uint64(1)
+
+	newCore := func(t *testing.T, round int64) (*Core, *interfaces.MockBackend) {
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(keys[me], me)).AnyTimes()
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any()).AnyTimes()
+		messages := message.NewMap()
+		c := &Core{
+			address:          me,
+			backend:          backendMock,
+			messages:         messages,
+			curRoundMessages: messages.GetOrCreate(round),
+			logger:           log.Root(),
+			round:            round,
+			height:           new(big.Int).SetUint64(height),
+			step:             Propose,
+			lockedRound:      -1,
+			validRound:       -1,
+			proposeTimeout:   NewTimeout(Propose, log.Root()),
+			committee:        committeeSet,
+		}
+		c.SetDefaultHandlers()
+		return c, backendMock
+	}
+	newProposal := func(round int64) (*message.Propose, *types.Block) {
+		proposer := committeeSet.GetProposer(round).Address
+		block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height), Extra: []byte{byte(round)}})
+		return message.NewPropose(round, height, -1, block, makeSigner(keys[proposer], proposer)).MustVerify(stubVerifier), block
+	}
+	addPrecommits := func(roundMessages *message.RoundMessages, round int64, block *types.Block) {
+		for _, member := range committeeSet.Committee()[:3] {
+			roundMessages.AddPrecommit(message.NewPrecommit(round, height, block.Hash(), makeSigner(keys[member.Address], member.Address)).MustVerify(stubVerifier))
+		}
+	}
+
+	t.Run("proposal committed in the same round", func(t *testing.T) {
+		enableTestMeters(t, &ProposalConversionVerifiedMeter, &ProposalConversionCommitMeter)
+		c, backendMock := newCore(t, 0)
+		proposal, block := newProposal(0)
+		addPrecommits(c.curRoundMessages, 0, block)
+
+		backendMock.EXPECT().VerifyProposal(block).Return(time.Duration(0), nil)
+		backendMock.EXPECT().Commit(block, int64(0), gomock.Any()).Return(nil)
+		require.NoError(t, c.proposer.HandleProposal(context.Background(), proposal))
+		require.Equal(t, int64(1), ProposalConversionVerifiedMeter.Count())
+		require.Equal(t, int64(1), ProposalConversionCommitMeter.Count())
+	})
+
+	t.Run("proposal committed after a round change", func(t *testing.T) {
+		enableTestMeters(t, &ProposalConversionVerifiedMeter, &ProposalConversionCommitMeter)
+		c, backendMock := newCore(t, 0)
+		proposal, block := newProposal(0)
+
+		// verified without quorum of precommits, the round moves on
+		backendMock.EXPECT().VerifyProposal(block).Return(time.Duration(0), nil)
+		require.NoError(t, c.proposer.HandleProposal(context.Background(), proposal))
+		require.Equal(t, int64(1), ProposalConversionVerifiedMeter.Count())
+
+		oldRoundMessages := c.curRoundMessages
+		c.setRound(1)
+		c.curRoundMessages = c.messages.GetOrCreate(1)
+		addPrecommits(oldRoundMessages, 0, block)
+		backendMock.EXPECT().Commit(block, int64(0), gomock.Any()).Return(nil)
+		c.Commit(0, oldRoundMessages)
+		require.Equal(t, int64(0), ProposalConversionCommitMeter.Count())
+	})
+
+	t.Run("own proposals are not accounted", func(t *testing.T) {
+		enableTestMeters(t, &ProposalConversionVerifiedMeter, &ProposalConversionCommitMeter)
+		c, backendMock := newCore(t, 1)
+		proposal, block := newProposal(1)
+		addPrecommits(c.curRoundMessages, 1, block)
+
+		backendMock.EXPECT().VerifyProposal(block).Return(time.Duration(0), nil)
+		backendMock.EXPECT().Commit(block, int64(1), gomock.Any()).Return(nil)
+		require.NoError(t, c.proposer.HandleProposal(context.Background(), proposal))
+		require.Equal(t, int64(0), ProposalConversionVerifiedMeter.Count())
+		require.Equal(t, int64(0), ProposalConversionCommitMeter.Count())
+	})
+}
+
+func TestHandleProposalNonCanonicalParent(t *testing.T) {
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	proposer := committeeSet.Committee()[0].Address // round 3 proposer
+	me := committeeSet.Committee()[1].Address
+	height := uint64(5)
+	round := int64(3)
+	canonical := &types.Header{Number: new(big.Int).SetUint64(height - 1), Extra: []byte("canonical")}
+	abandoned := &types.Header{Number: new(big.Int).SetUint64(height - 1), Extra: []byte("abandoned")}
+
+	newCore := func(t *testing.T) (*Core, *interfaces.MockBackend) {
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		messages := message.NewMap()
+		c := &Core{
+			address:          me,
+			backend:          backendMock,
+			messages:         messages,
+			curRoundMessages: messages.GetOrCreate(round),
+			logger:           log.Root(),
+			round:            round,
+			height:           new(big.Int).SetUint64(height),
+			step:             Propose,
+			lockedRound:      -1,
+			validRound:       -1,
+			proposeTimeout:   NewTimeout(Propose, log.Root()),
+			committee:        committeeSet,
+			lastHeader:       canonical,
+		}
+		c.SetDefaultHandlers()
+		return c, backendMock
+	}
+
+	t.Run("proposal on an abandoned parent is nil prevoted", func(t *testing.T) {
+		enableTestMeters(t, &ProposalNonCanonicalParentMeter)
+		c, backendMock := newCore(t)
+		block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height), ParentHash: abandoned.Hash()})
+		proposal := message.NewPropose(round, height, -1, block, makeSigner(keys[proposer], proposer)).MustVerify(stubVerifier)
+
+		// nil prevoted without verification
+		var prevote *message.Prevote
+		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(keys[me], me))
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any()).Do(func(_ types.Committee, msg message.Msg) { prevote = msg.(*message.Prevote) })
+		err := c.proposer.HandleProposal(context.Background(), proposal)
+		require.ErrorIs(t, err, constants.ErrNonCanonicalParent)
+		require.Equal(t, Prevote, c.step)
+		require.Equal(t, common.Hash{}, prevote.Value())
+		require.Equal(t, int64(1), ProposalNonCanonicalParentMeter.Count())
+	})
+
+	t.Run("proposal on an abandoned parent past the propose step, no second prevote", func(t *testing.T) {
+		c, _ := newCore(t)
+		block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height), ParentHash: abandoned.Hash()})
+		proposal := message.NewPropose(round, height, -1, block, makeSigner(keys[proposer], proposer)).MustVerify(stubVerifier)
+
+		// strict mock, a broadcast fails the test
+		for _, step := range []Step{Prevote, Precommit} {
+			c.SetStep(step)
+			require.ErrorIs(t, c.proposer.HandleProposal(context.Background(), proposal), constants.ErrNonCanonicalParent)
+			require.Equal(t, step, c.step)
+		}
+	})
+
+	t.Run("proposal on the canonical parent is verified", func(t *testing.T) {
+		enableTestMeters(t, &ProposalNonCanonicalParentMeter)
+		c, backendMock := newCore(t)
+		block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height), ParentHash: canonical.Hash()})
+		proposal := message.NewPropose(round, height, -1, block, makeSigner(keys[proposer], proposer)).MustVerify(stubVerifier)
+
+		var prevote *message.Prevote
+		backendMock.EXPECT().VerifyProposal(block).Return(time.Duration(0), nil)
+		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(keys[me], me))
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any()).Do(func(_ types.Committee, msg message.Msg) { prevote = msg.(*message.Prevote) })
+		require.NoError(t, c.proposer.HandleProposal(context.Background(), proposal))
+		require.Equal(t, block.Hash(), prevote.Value())
+		require.Equal(t, int64(0), ProposalNonCanonicalParentMeter.Count())
+	})
+}
+
+func TestProposalBaseFeeRetry(t *testing.T) {
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	proposer := committeeSet.Committee()[0].Address // round 3 proposer
+	me := committeeSet.Committee()[1].Address
+	height := uint64(5)
+	round := int64(3)
+	parent := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height - 1)})
+	block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height), ParentHash: parent.Hash()})
+	proposal := message.NewPropose(round, height, -1, block, makeSigner(keys[proposer], proposer)).MustVerify(stubVerifier)
+	baseFeeErr := fmt.Errorf("%w: have 1, want 2", misc.ErrInvalidBaseFee)
+
+	newCore := func(t *testing.T) (*Core, *interfaces.MockBackend, *fakeClock) {
+		enableTestMeters(t, &ProposalBaseFeeRetryMeter)
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		messages := message.NewMap()
+		c := &Core{
+			address:          me,
+			backend:          backendMock,
+			messages:         messages,
+			curRoundMessages: messages.GetOrCreate(round),
+			logger:           log.Root(),
+			round:            round,
+			height:           new(big.Int).SetUint64(height),
+			step:             Propose,
+			lockedRound:      -1,
+			validRound:       -1,
+			proposeTimeout:   NewTimeout(Propose, log.Root()),
+			committee:        committeeSet,
+		}
+		clock := newFakeClock()
+		c.SetClock(clock)
+		c.SetDefaultHandlers()
+		c.SetProposalBaseFeeRetries(2)
+		c.SetProposalVerificationRetries(0, DefaultProposalVerificationRetryDelay)
+		return c, backendMock, clock
+	}
+	expectPrevote := func(backendMock *interfaces.MockBackend, prevote **message.Prevote) {
+		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(keys[me], me))
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any()).Do(func(_ types.Committee, msg message.Msg) { *prevote = msg.(*message.Prevote) })
+	}
+
+	t.Run("base fee mismatch resolved after the head update, value prevoted", func(t *testing.T) {
+		c, backendMock, clock := newCore(t)
+		gomock.InOrder(
+			backendMock.EXPECT().VerifyProposal(block).Return(time.Duration(0), baseFeeErr),
+			backendMock.EXPECT().VerifyProposal(block).Return(time.Duration(0), nil),
+		)
+		var prevote *message.Prevote
+		expectPrevote(backendMock, &prevote)
+
+		require.ErrorIs(t, c.proposer.HandleProposal(context.Background(), proposal), constants.ErrProposalVerificationRetry)
+		expectProposalRetry(t, backendMock, clock, proposal, DefaultProposalVerificationRetryDelay)
+		require.NoError(t, c.proposer.HandleProposal(context.Background(), proposal))
+		require.Equal(t, block.Hash(), prevote.Value())
+		require.Equal(t, int64(1), ProposalBaseFeeRetryMeter.Count())
+	})
+
+	t.Run("base fee mismatch persisting, nil prevoted", func(t *testing.T) {
+		c, backendMock, clock := newCore(t)
+		backendMock.EXPECT().VerifyProposal(block).Return(time.Duration(0), baseFeeErr).Times(3)
+		var prevote *message.Prevote
+		expectPrevote(backendMock, &prevote)
+
+		for i := 0; i < 2; i++ {
+			require.ErrorIs(t, c.proposer.HandleProposal(context.Background(), proposal), constants.ErrProposalVerificationRetry)
+			expectProposalRetry(t, backendMock, clock, proposal, DefaultProposalVerificationRetryDelay)
+		}
+		err := c.proposer.HandleProposal(context.Background(), proposal)
+		require.ErrorIs(t, err, misc.ErrInvalidBaseFee)
+		require.Equal(t, common.Hash{}, prevote.Value())
+		require.Equal(t, int64(2), ProposalBaseFeeRetryMeter.Count())
+	})
+
+	t.Run("other failures not retried", func(t *testing.T) {
+		c, backendMock, _ := newCore(t)
+		backendMock.EXPECT().VerifyProposal(block).Return(time.Duration(0), errors.New("bad state root"))
+		var prevote *message.Prevote
+		expectPrevote(backendMock, &prevote)
+
+		require.Error(t, c.proposer.HandleProposal(context.Background(), proposal))
+		require.Equal(t, int64(0), ProposalBaseFeeRetryMeter.Count())
+	})
+}
+
+func TestHandleProposalOutOfRangeView(t *testing.T) {
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	proposer := committeeSet.Committee()[0].Address
+	me := committeeSet.Committee()[1].Address
+	height := uint64(10)
+	round := int64(2)
+
+	newCore := func(t *testing.T) *Core {
+		messages := message.NewMap()
+		c := &Core{
+			address:          me,
+			backend:          interfaces.NewMockBackend(gomock.NewController(t)),
+			messages:         messages,
+			curRoundMessages: messages.GetOrCreate(round),
+			logger:           log.Root(),
+			round:            round,
+			height:           new(big.Int).SetUint64(height),
+			step:             Propose,
+			lockedRound:      -1,
+			validRound:       -1,
+			proposeTimeout:   NewTimeout(Propose, log.Root()),
+			committee:        committeeSet,
+			backlogs:         make(map[common.Address][]message.Msg),
+		}
+		c.SetDefaultHandlers()
+		return c
+	}
+	newProposal := func(r int64, h uint64) *message.Propose {
+		block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(h)})
+		return message.NewPropose(r, h, -1, block, makeSigner(keys[proposer], proposer)).MustVerify(stubVerifier)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		round  int64
+		height uint64
+	}{
+		{name: "round above the maximum", round: constants.MaxRound + 1, height: height},
+		{name: "extreme round", round: math.MaxInt64, height: height},
+		{name: "negative round", round: -2, height: height},
+		{name: "far future height", round: round, height: height + 2},
+		{name: "extreme height", round: round, height: math.MaxUint64},
+		{name: "far past height", round: round, height: height - 2},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCore(t)
+			err := c.proposer.HandleProposal(context.Background(), newProposal(tt.round, tt.height))
+			require.ErrorIs(t, err, constants.ErrProposalViewOutOfRange)
+			require.True(t, shouldDisconnectSender(err))
+			// nothing got allocated nor buffered for the proposal view
+			require.Equal(t, []int64{round}, c.messages.GetRounds())
+			require.Empty(t, c.backlogs)
+		})
+	}
+
+	t.Run("adjacent heights handled as before", func(t *testing.T) {
+		c := newCore(t)
+		require.ErrorIs(t, c.proposer.HandleProposal(context.Background(), newProposal(round, height+1)), constants.ErrFutureHeightMessage)
+		require.ErrorIs(t, c.proposer.HandleProposal(context.Background(), newProposal(round, height-1)), constants.ErrOldHeightMessage)
+	})
 }
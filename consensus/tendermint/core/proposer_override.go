@@ -0,0 +1,48 @@
+//go:build tendermint_unsafe
+
+package core
+
+import (
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/core/types"
+)
+
+// SetProposerOverride forces the proposer of the given rounds, regardless of the proposer election, to
+// reproduce scenarios deterministically in tests. Passing nil restores the elected proposers. It is only
+// available in builds with the tendermint_unsafe tag and must never be used in production.
+func (c *Core) SetProposerOverride(overrides map[int64]common.Address) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	c.proposers = nil
+	c.proposerOverrides = make(map[int64]common.Address, len(overrides))
+	for round, address := range overrides {
+		c.proposerOverrides[round] = address
+	}
+}
+
+func (c *Core) withProposerOverrides(committee interfaces.Committee) interfaces.Committee {
+	if len(c.proposerOverrides) == 0 || committee == nil {
+		return committee
+	}
+	return &overriddenCommittee{electedCommittee: committee, overrides: c.proposerOverrides}
+}
+
+// electedCommittee is embedded under another name than its Committee method.
+type electedCommittee = interfaces.Committee
+
+// overriddenCommittee returns the forced proposer of the overridden rounds.
+type overriddenCommittee struct {
+	electedCommittee
+	overrides map[int64]common.Address
+}
+
+func (o *overriddenCommittee) GetProposer(round int64) types.CommitteeMember {
+	if address, ok := o.overrides[round]; ok {
+		if _, member, err := o.electedCommittee.GetByAddress(address); err == nil {
+			return member
+		}
+		return types.CommitteeMember{Address: address}
+	}
+	return o.electedCommittee.GetProposer(round)
+}
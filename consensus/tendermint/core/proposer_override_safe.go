@@ -0,0 +1,13 @@
+//go:build !tendermint_unsafe
+
+package core
+
+import (
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+)
+
+// withProposerOverrides is a no-op: the proposer can't be overridden outside of builds with the
+// tendermint_unsafe tag.
+func (c *Core) withProposerOverrides(committee interfaces.Committee) interfaces.Committee {
+	return committee
+}
@@ -0,0 +1,49 @@
+//go:build tendermint_unsafe
+
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+)
+
+// Run with: go test -tags tendermint_unsafe ./consensus/tendermint/core/
+func TestProposerOverride(t *testing.T) {
+	committeeSet, _ := NewTestCommitteeSetWithKeys(4)
+	members := committeeSet.Committee()
+	c := &Core{address: members[1].Address, committee: committeeSet}
+
+	elected := make(map[int64]bool)
+	for r := int64(0); r < 8; r++ {
+		c.setRound(r)
+		elected[r] = c.IsProposer()
+	}
+
+	// member 1 is forced as proposer of the rounds 2 and 5, member 2 of the round 3
+	c.SetProposerOverride(map[int64]common.Address{2: members[1].Address, 3: members[2].Address, 5: members[1].Address})
+	for r := int64(0); r < 8; r++ {
+		c.setRound(r)
+		switch r {
+		case 2, 5:
+			require.True(t, c.IsProposer(), "round %d", r)
+			require.True(t, c.IsFromProposer(r, members[1].Address), "round %d", r)
+			require.Equal(t, members[1].VotingPower, c.CommitteeSet().GetProposer(r).VotingPower)
+		case 3:
+			require.False(t, c.IsProposer(), "round %d", r)
+			require.Equal(t, members[2].Address, c.CommitteeSet().GetProposer(r).Address)
+		default:
+			require.Equal(t, elected[r], c.IsProposer(), "round %d", r)
+			require.Equal(t, committeeSet.GetProposer(r), c.CommitteeSet().GetProposer(r))
+		}
+	}
+
+	// the elected proposers are restored
+	c.SetProposerOverride(nil)
+	for r := int64(0); r < 8; r++ {
+		c.setRound(r)
+		require.Equal(t, elected[r], c.IsProposer(), "round %d", r)
+	}
+}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/core/types"
+)
+
+// proposerScheduleRounds is the number of rounds whose proposer is computed at the start of each height, the
+// proposers of the later rounds are computed on demand.
+const proposerScheduleRounds = 8
+
+// proposerSchedule holds the proposers of the first rounds of a height. The proposer is looked up for most messages
+// while its election may be costly, the weighted random sampling runs a contract call for instance.
+type proposerSchedule struct {
+	committee  interfaces.Committee // committee the schedule was computed with
+	lastHeader *types.Header        // header the committee was set to when the schedule was computed
+	proposers  []types.CommitteeMember
+}
+
+// scheduleProposers computes the proposers of the first rounds of the height starting, once its committee is set.
+func (c *Core) scheduleProposers() {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	c.proposers = nil
+	if c.committee == nil {
+		return
+	}
+	committee := c.withProposerOverrides(c.committee)
+	schedule := &proposerSchedule{
+		committee:  c.committee,
+		lastHeader: c.lastHeader,
+		proposers:  make([]types.CommitteeMember, proposerScheduleRounds),
+	}
+	for round := range schedule.proposers {
+		schedule.proposers[round] = committee.GetProposer(int64(round))
+	}
+	c.proposers = schedule
+}
+
+// proposerOf returns the proposer of the given round. The schedule of the height is used unless the committee was
+// rotated since it was computed, or the proposer of the round couldn't be elected then.
+func (c *Core) proposerOf(round int64) types.CommitteeMember {
+	c.stateMu.RLock()
+	schedule, committee := c.proposers, c.withProposerOverrides(c.committee)
+	valid := schedule != nil && schedule.committee == c.committee && schedule.lastHeader == c.lastHeader
+	c.stateMu.RUnlock()
+
+	if valid && round >= 0 && round < int64(len(schedule.proposers)) {
+		if proposer := schedule.proposers[round]; proposer.Address != (common.Address{}) {
+			return proposer
+		}
+	}
+	return committee.GetProposer(round)
+}
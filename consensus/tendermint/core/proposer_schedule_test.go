@@ -0,0 +1,99 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/core/types"
+)
+
+// countedCommittee is embedded under another name than its Committee method.
+type countedCommittee = interfaces.Committee
+
+// countingCommittee counts the proposer elections run on the wrapped committee.
+type countingCommittee struct {
+	countedCommittee
+	elections int
+}
+
+func (c *countingCommittee) GetProposer(round int64) types.CommitteeMember {
+	c.elections++
+	return c.countedCommittee.GetProposer(round)
+}
+
+func TestProposerSchedule(t *testing.T) {
+	newCore := func() (*Core, *countingCommittee) {
+		committeeSet, _ := NewTestCommitteeSetWithKeys(4)
+		committee := &countingCommittee{countedCommittee: committeeSet}
+		c := &Core{
+			committee:  committee,
+			lastHeader: &types.Header{Number: big.NewInt(1)},
+		}
+		c.scheduleProposers()
+		return c, committee
+	}
+
+	t.Run("cached proposers match the on demand election", func(t *testing.T) {
+		c, committee := newCore()
+		require.Equal(t, proposerScheduleRounds, committee.elections)
+		for round := int64(0); round < 3*proposerScheduleRounds; round++ {
+			require.Equal(t, committee.countedCommittee.GetProposer(round), c.proposerOf(round), "round %d", round)
+		}
+	})
+
+	t.Run("scheduled rounds don't run the election", func(t *testing.T) {
+		c, committee := newCore()
+		committee.elections = 0
+		for round := int64(0); round < proposerScheduleRounds; round++ {
+			c.proposerOf(round)
+			c.IsFromProposer(round, c.address)
+		}
+		require.Zero(t, committee.elections)
+
+		c.proposerOf(proposerScheduleRounds)
+		require.Equal(t, 1, committee.elections)
+	})
+
+	t.Run("schedule is invalidated on committee rotation", func(t *testing.T) {
+		c, _ := newCore()
+		rotated, _ := NewTestCommitteeSetWithKeys(5)
+		c.setCommitteeSet(rotated)
+		for round := int64(0); round < proposerScheduleRounds; round++ {
+			require.Equal(t, rotated.GetProposer(round), c.proposerOf(round), "round %d", round)
+		}
+
+		c.scheduleProposers()
+		require.Equal(t, rotated, c.proposers.committee)
+	})
+
+	t.Run("schedule is invalidated when the last header changes", func(t *testing.T) {
+		c, committee := newCore()
+		c.setLastHeader(&types.Header{Number: big.NewInt(2)})
+		committee.elections = 0
+		c.proposerOf(0)
+		require.Equal(t, 1, committee.elections)
+	})
+}
+
+func BenchmarkProposerOf(b *testing.B) {
+	committeeSet, _ := NewTestCommitteeSetWithKeys(21)
+	c := &Core{
+		committee:  committeeSet,
+		lastHeader: &types.Header{Number: big.NewInt(1)},
+	}
+	c.scheduleProposers()
+
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			c.proposerOf(int64(i % proposerScheduleRounds))
+		}
+	})
+	b.Run("on demand", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			c.CommitteeSet().GetProposer(int64(i % proposerScheduleRounds))
+		}
+	})
+}
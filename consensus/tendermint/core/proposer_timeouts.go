@@ -0,0 +1,45 @@
+package core
+
+import (
+	"sync"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/metrics"
+)
+
+// proposerTimeouts counts, per committee member, the rounds which timed out at the propose step while the member was
+// the expected proposer. It is written by the main loop and read by the API.
+type proposerTimeouts struct {
+	mu     sync.RWMutex
+	counts map[common.Address]uint64
+}
+
+// recordProposerTimeout attributes the propose timeout of the given round to its expected proposer, it must be called
+// from the main loop.
+func (c *Core) recordProposerTimeout(round int64) {
+	proposer := c.proposerOf(round).Address
+	c.proposerTimeouts.mu.Lock()
+	if c.proposerTimeouts.counts == nil {
+		c.proposerTimeouts.counts = make(map[common.Address]uint64)
+	}
+	c.proposerTimeouts.counts[proposer]++
+	c.proposerTimeouts.mu.Unlock()
+
+	ProposerTimeoutMeter.Mark(1)
+	if metrics.Enabled {
+		metrics.GetOrRegisterCounter("tendermint/proposer/timeout/"+proposer.Hex(), nil).Inc(1)
+	}
+}
+
+// ProposerTimeouts returns, per committee member, the number of rounds which timed out at the propose step while the
+// member was the expected proposer, since the engine started. A member accumulating timeouts is likely offline or
+// unable to build blocks in time.
+func (c *Core) ProposerTimeouts() map[common.Address]uint64 {
+	c.proposerTimeouts.mu.RLock()
+	defer c.proposerTimeouts.mu.RUnlock()
+	counts := make(map[common.Address]uint64, len(c.proposerTimeouts.counts))
+	for addr, count := range c.proposerTimeouts.counts {
+		counts[addr] = count
+	}
+	return counts
+}
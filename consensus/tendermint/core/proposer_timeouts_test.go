@@ -0,0 +1,69 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/metrics"
+)
+
+func TestProposerTimeouts(t *testing.T) {
+	enableTestMeters(t, &ProposerTimeoutMeter)
+	height := big.NewInt(5)
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	me := committeeSet.Committee()[0].Address
+
+	backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+	backendMock.EXPECT().Address().AnyTimes().Return(me)
+	backendMock.EXPECT().Logger().AnyTimes().Return(log.Root())
+	backendMock.EXPECT().Sign(gomock.Any()).AnyTimes().DoAndReturn(makeSigner(keys[me], me))
+	backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any()).AnyTimes()
+
+	c := New(backendMock, nil)
+	c.setCommitteeSet(committeeSet)
+	c.setHeight(height)
+
+	// timeoutRound times the propose step of the given round out.
+	timeoutRound := func(round int64) {
+		c.setRound(round)
+		c.SetStep(Propose)
+		c.handleTimeoutPropose(context.Background(), TimeoutEvent{RoundWhenCalled: round, HeightWhenCalled: height, Step: Propose})
+		require.Equal(t, Prevote, c.Step())
+	}
+
+	slow := committeeSet.GetProposer(1).Address
+	counter := metrics.GetOrRegisterCounter("tendermint/proposer/timeout/"+slow.Hex(), nil)
+	before := counter.Count()
+
+	// the rounds of the same proposer timing out are attributed to it
+	for round := int64(1); round < 12; round += 4 {
+		require.Equal(t, slow, committeeSet.GetProposer(round).Address)
+		timeoutRound(round)
+	}
+	require.Equal(t, map[common.Address]uint64{slow: 3}, c.ProposerTimeouts())
+	require.Equal(t, before+3, counter.Count())
+
+	// another proposer gets its own count
+	other := committeeSet.GetProposer(2).Address
+	timeoutRound(2)
+	require.Equal(t, map[common.Address]uint64{slow: 3, other: 1}, c.ProposerTimeouts())
+	require.Equal(t, int64(4), ProposerTimeoutMeter.Count())
+
+	// a stale timeout of a past round isn't counted
+	c.setRound(3)
+	c.SetStep(Propose)
+	c.handleTimeoutPropose(context.Background(), TimeoutEvent{RoundWhenCalled: 1, HeightWhenCalled: height, Step: Propose})
+	require.Equal(t, uint64(3), c.ProposerTimeouts()[slow])
+	require.Equal(t, int64(4), ProposerTimeoutMeter.Count())
+
+	// the accessor returns a copy
+	c.ProposerTimeouts()[slow] = 0
+	require.Equal(t, uint64(3), c.ProposerTimeouts()[slow])
+}
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/metrics"
+)
+
+// recordPrecommitArrival remembers when the first precommit of the round was received, it must be called from the
+// main loop.
+func (c *Core) recordPrecommitArrival(round int64) {
+	if _, ok := c.firstPrecommits[round]; ok {
+		return
+	}
+	if c.firstPrecommits == nil {
+		c.firstPrecommits = make(map[int64]time.Time)
+	}
+	c.firstPrecommits[round] = c.Clock().Now()
+}
+
+// recordQuorum reports how the precommit quorum committing the value formed: the time since the first precommit of
+// the round was received and the number of precommits beyond the fewest forming a quorum.
+func (c *Core) recordQuorum(round int64, messages *message.RoundMessages, value common.Hash) {
+	surplus := surplusPrecommits(messages.PrecommitsFor(value), c.CommitteeSet().Quorum())
+	first, ok := c.firstPrecommits[round]
+	latency := c.Clock().Now().Sub(first)
+	if !ok {
+		latency = 0
+	}
+	c.logger.Debug("Precommit quorum reached", "round", round, "latency", latency, "surplus", surplus)
+	if metrics.Enabled {
+		if ok {
+			QuorumLatencyTimer.Update(latency)
+		}
+		QuorumSurplusHistogram.Update(int64(surplus))
+	}
+}
+
+// surplusPrecommits returns the number of precommits which could be left out while keeping the quorum.
+func surplusPrecommits(precommits []*message.Precommit, quorum *big.Int) int {
+	powers := make([]*big.Int, len(precommits))
+	for i, precommit := range precommits {
+		powers[i] = precommit.Power()
+	}
+	sort.Slice(powers, func(i, j int) bool { return powers[i].Cmp(powers[j]) > 0 })
+	total := new(big.Int)
+	for i, power := range powers {
+		if total.Add(total, power).Cmp(quorum) >= 0 {
+			return len(powers) - i - 1
+		}
+	}
+	return 0
+}
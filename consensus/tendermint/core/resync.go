@@ -0,0 +1,32 @@
+package core
+
+// SetResyncThreshold sets the number of rounds of a height which received a proposal without committing after which
+// a chain resync is requested, as the node is likely on a minority fork or behind the committee. Zero disables the
+// resync.
+func (c *Core) SetResyncThreshold(rounds int64) {
+	c.resyncThreshold = rounds
+}
+
+// ForceResync asks the downloader to sync the chain with the peers, even if few of them are available.
+func (c *Core) ForceResync() {
+	ResyncRequestMeter.Mark(1)
+	c.backend.RequestResync()
+}
+
+// checkStalled counts the rounds of the current height which received a proposal without committing, and requests
+// a resync once they reach the threshold. It is called when the given round starts, before the state of the previous
+// round is reset, and must be called from the main loop.
+func (c *Core) checkStalled(round int64) {
+	if round == 0 {
+		c.stalledRounds = 0
+		return
+	}
+	if c.curRoundMessages == nil || c.curRoundMessages.Proposal() == nil {
+		return
+	}
+	c.stalledRounds++
+	if c.resyncThreshold > 0 && c.stalledRounds == c.resyncThreshold {
+		c.logger.Warn("Consensus stalled despite receiving proposals, requesting a resync", "height", c.Height(), "round", round, "stalledRounds", c.stalledRounds)
+		c.ForceResync()
+	}
+}
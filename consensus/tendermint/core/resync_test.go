@@ -0,0 +1,81 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/log"
+)
+
+func TestCheckStalled(t *testing.T) {
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	proposer := committeeSet.Committee()[0].Address
+	height := uint64(1)
+	block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height)})
+
+	newCore := func(t *testing.T, threshold int64) (*Core, *interfaces.MockBackend) {
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		c := &Core{
+			backend:         backendMock,
+			messages:        message.NewMap(),
+			logger:          log.Root(),
+			height:          new(big.Int).SetUint64(height),
+			committee:       committeeSet,
+			resyncThreshold: threshold,
+		}
+		return c, backendMock
+	}
+	// runRound simulates a round ending without commit, with or without a proposal received
+	runRound := func(c *Core, round int64, proposed bool) {
+		c.checkStalled(round)
+		c.round = round
+		c.curRoundMessages = c.messages.GetOrCreate(round)
+		if proposed {
+			proposal := message.NewPropose(round, height, -1, block, makeSigner(keys[proposer], proposer)).MustVerify(stubVerifier)
+			c.curRoundMessages.SetProposal(proposal, true)
+		}
+	}
+
+	t.Run("resync requested once after the threshold", func(t *testing.T) {
+		enableTestMeters(t, &ResyncRequestMeter)
+		c, backendMock := newCore(t, 3)
+		backendMock.EXPECT().RequestResync().Times(1)
+		for round := int64(0); round < 10; round++ {
+			runRound(c, round, true)
+		}
+		require.Equal(t, int64(9), c.stalledRounds)
+		require.Equal(t, int64(1), ResyncRequestMeter.Count())
+	})
+
+	t.Run("rounds without proposal are not counted", func(t *testing.T) {
+		c, _ := newCore(t, 4)
+		for round := int64(0); round < 10; round++ {
+			runRound(c, round, round%3 == 0)
+		}
+		require.Equal(t, int64(3), c.stalledRounds)
+	})
+
+	t.Run("new height resets the count", func(t *testing.T) {
+		c, _ := newCore(t, 3)
+		for round := int64(0); round < 3; round++ {
+			runRound(c, round, true)
+		}
+		runRound(c, 0, true)
+		runRound(c, 1, true)
+		require.Equal(t, int64(1), c.stalledRounds)
+	})
+
+	t.Run("zero threshold disables the resync", func(t *testing.T) {
+		c, _ := newCore(t, 0)
+		for round := int64(0); round < 10; round++ {
+			runRound(c, round, true)
+		}
+		require.Equal(t, int64(9), c.stalledRounds)
+	})
+}
@@ -0,0 +1,39 @@
+//go:build tendermint_unsafe
+
+package core
+
+import (
+	"fmt"
+)
+
+// DisableRoundTimeouts stops the propose, prevote and precommit timeouts from being scheduled, so that tests can
+// drive the round changes explicitly with TriggerRoundTimeout. The timeouts already running are left untouched. It
+// is only available in builds with the tendermint_unsafe tag and must never be used in production.
+func (c *Core) DisableRoundTimeouts(disabled bool) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	c.roundTimeoutsDisabled = disabled
+}
+
+// TriggerRoundTimeout fires the timeout of the given step for the current height and round, as if its timer
+// expired. It is only available in builds with the tendermint_unsafe tag.
+func (c *Core) TriggerRoundTimeout(step Step) error {
+	round, height := c.Round(), c.Height()
+	switch step {
+	case Propose:
+		c.onTimeoutPropose(round, height)
+	case Prevote:
+		c.onTimeoutPrevote(round, height)
+	case Precommit:
+		c.onTimeoutPrecommit(round, height)
+	default:
+		return fmt.Errorf("no timeout for step %v", step)
+	}
+	return nil
+}
+
+func (c *Core) roundTimeoutsEnabled() bool {
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
+	return !c.roundTimeoutsDisabled
+}
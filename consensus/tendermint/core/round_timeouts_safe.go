@@ -0,0 +1,9 @@
+//go:build !tendermint_unsafe
+
+package core
+
+// roundTimeoutsEnabled always returns true: the round timeouts can't be disabled outside of builds with the
+// tendermint_unsafe tag.
+func (c *Core) roundTimeoutsEnabled() bool {
+	return true
+}
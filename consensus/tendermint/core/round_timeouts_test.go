@@ -0,0 +1,100 @@
+//go:build tendermint_unsafe
+
+// Run with: go test -tags tendermint_unsafe ./consensus/tendermint/core/
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/log"
+)
+
+func TestDisableRoundTimeouts(t *testing.T) {
+	const round = int64(1)
+	height := big.NewInt(3)
+	committeeSet, keys := NewTestCommitteeSetWithKeys(7)
+	proposer := committeeSet.GetProposer(round).Address
+	var me common.Address
+	for _, m := range committeeSet.Committee() {
+		if m.Address != proposer {
+			me = m.Address
+			break
+		}
+	}
+
+	newCore := func(t *testing.T, step Step, disabled bool) (*Core, *interfaces.MockBackend, *message.Propose) {
+		backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+		backendMock.EXPECT().Address().AnyTimes().Return(me)
+		backendMock.EXPECT().Logger().AnyTimes().Return(log.Root())
+		backendMock.EXPECT().Sign(gomock.Any()).AnyTimes().DoAndReturn(makeSigner(keys[me], me))
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any()).AnyTimes()
+
+		c := New(backendMock, nil)
+		c.SetClock(newFakeClock())
+		c.DisableRoundTimeouts(disabled)
+		c.setCommitteeSet(committeeSet)
+		c.setHeight(height)
+		c.setRound(round)
+		c.SetStep(step)
+
+		proposal := message.NewPropose(round, height.Uint64(), -1, types.NewBlockWithHeader(&types.Header{Number: height}), makeSigner(keys[proposer], proposer))
+		c.curRoundMessages.SetProposal(proposal.MustVerify(stubVerifier), true)
+		return c, backendMock, proposal
+	}
+
+	// mixedValue returns the proposal hash for the first votes and nil for the others, so that a quorum is reached
+	// for none of the values.
+	mixedValue := func(proposal *message.Propose, i int) common.Hash {
+		if i >= 3 {
+			return common.Hash{}
+		}
+		return proposal.Block().Hash()
+	}
+
+	for _, disabled := range []bool{false, true} {
+		c, _, _ := newCore(t, PrecommitDone, disabled)
+		c.StartRound(context.Background(), round)
+		require.Equal(t, !disabled, c.proposeTimeout.TimerStarted(), "propose timeout, disabled: %v", disabled)
+
+		c, _, proposal := newCore(t, Prevote, disabled)
+		for i, m := range committeeSet.Committee()[2:] {
+			prevote := message.NewPrevote(round, height.Uint64(), mixedValue(proposal, i), makeSigner(keys[m.Address], m.Address))
+			require.NoError(t, c.prevoter.HandlePrevote(context.Background(), prevote.MustVerify(stubVerifier)))
+		}
+		require.Equal(t, !disabled, c.prevoteTimeout.TimerStarted(), "prevote timeout, disabled: %v", disabled)
+
+		c, _, proposal = newCore(t, Precommit, disabled)
+		for i, m := range committeeSet.Committee()[2:] {
+			precommit := message.NewPrecommit(round, height.Uint64(), mixedValue(proposal, i), makeSigner(keys[m.Address], m.Address))
+			require.NoError(t, c.precommiter.HandlePrecommit(context.Background(), precommit.MustVerify(stubVerifier)))
+		}
+		require.Equal(t, !disabled, c.precommitTimeout.TimerStarted(), "precommit timeout, disabled: %v", disabled)
+	}
+
+	t.Run("triggered timeout posted for the current round", func(t *testing.T) {
+		for _, step := range []Step{Propose, Prevote, Precommit} {
+			c, backendMock, _ := newCore(t, step, true)
+			var posted TimeoutEvent
+			backendMock.EXPECT().Post(gomock.Any()).Do(func(ev interface{}) { posted = ev.(TimeoutEvent) })
+
+			require.NoError(t, c.TriggerRoundTimeout(step))
+			require.Equal(t, step, posted.Step)
+			require.Equal(t, round, posted.RoundWhenCalled)
+			require.Equal(t, height.Uint64(), posted.HeightWhenCalled.Uint64())
+		}
+	})
+
+	t.Run("no timeout for the other steps", func(t *testing.T) {
+		c, _, _ := newCore(t, PrecommitDone, true)
+		require.Error(t, c.TriggerRoundTimeout(PrecommitDone))
+	})
+}
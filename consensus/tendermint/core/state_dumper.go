@@ -13,6 +13,10 @@ type StateRequestEvent struct {
 	StateChan chan interfaces.CoreState
 }
 
+type SnapshotRequestEvent struct {
+	SnapshotChan chan *interfaces.ConsensusSnapshot
+}
+
 func (c *Core) CoreState() interfaces.CoreState {
 	// send state dump request.
 	var e = StateRequestEvent{
@@ -42,15 +46,16 @@ func (c *Core) handleStateDump(e StateRequestEvent) {
 
 		// committee state
 		Committee:       c.CommitteeSet().Committee(),
-		Proposer:        c.CommitteeSet().GetProposer(c.Round()).Address,
+		Proposer:        c.proposerOf(c.Round()).Address,
 		IsProposer:      c.IsProposer(),
-		QuorumVotePower: c.CommitteeSet().Quorum(),
+		QuorumVotePower: new(big.Int).Set(c.CommitteeSet().Quorum()),
 		RoundStates:     getRoundState(c),
 		// extra state
-		SentProposal:          c.sentProposal,
+		SentProposal:          c.SentProposal(),
 		SentPrevote:           c.sentPrevote,
 		SentPrecommit:         c.sentPrecommit,
 		SetValidRoundAndValue: c.setValidRoundAndValue,
+		ProposingHalted:       c.ProposingHalted(),
 		// timer state
 		ProposeTimerStarted:   c.proposeTimeout.TimerStarted(),
 		PrevoteTimerStarted:   c.prevoteTimeout.TimerStarted(),
@@ -66,6 +71,71 @@ func (c *Core) handleStateDump(e StateRequestEvent) {
 	close(e.StateChan)
 }
 
+// Snapshot returns a deep copy of the consensus state. Like the state dump, it is produced by the main loop so that
+// the view is consistent.
+func (c *Core) Snapshot() *interfaces.ConsensusSnapshot {
+	var e = SnapshotRequestEvent{
+		SnapshotChan: make(chan *interfaces.ConsensusSnapshot),
+	}
+	go c.SendEvent(e)
+	return <-e.SnapshotChan
+}
+
+func (c *Core) handleSnapshotRequest(e SnapshotRequestEvent) {
+	e.SnapshotChan <- c.snapshot()
+	close(e.SnapshotChan)
+}
+
+// snapshot must be called from the main loop.
+func (c *Core) snapshot() *interfaces.ConsensusSnapshot {
+	committee := c.CommitteeSet().Committee()
+	committeeCopy := make(types.Committee, len(committee))
+	for i, m := range committee {
+		committeeCopy[i] = types.CommitteeMember{Address: m.Address, VotingPower: new(big.Int).Set(m.VotingPower)}
+	}
+
+	rounds := c.messages.GetRounds()
+	roundSnapshots := make([]interfaces.RoundSnapshot, 0, len(rounds))
+	for _, r := range rounds {
+		roundMessages := c.messages.GetOrCreate(r)
+		roundSnapshots = append(roundSnapshots, interfaces.RoundSnapshot{
+			Round:      r,
+			Proposal:   getProposal(c, r),
+			Prevotes:   voteTallies(roundMessages.AllPrevotes()),
+			Precommits: voteTallies(roundMessages.AllPrecommits()),
+		})
+	}
+
+	return &interfaces.ConsensusSnapshot{
+		Height:      new(big.Int).Set(c.Height()),
+		Round:       c.Round(),
+		Step:        c.step.String(),
+		LockedRound: c.lockedRound,
+		LockedValue: getHash(c.lockedValue),
+		ValidRound:  c.validRound,
+		ValidValue:  getHash(c.validValue),
+		Committee:   committeeCopy,
+		Rounds:      roundSnapshots,
+	}
+}
+
+// voteTallies aggregates the votes by value, in order of first appearance.
+func voteTallies(votes []message.Msg) []interfaces.VoteTally {
+	tallies := make([]interfaces.VoteTally, 0)
+	index := make(map[common.Hash]int)
+	for _, v := range votes {
+		i, ok := index[v.Value()]
+		if !ok {
+			i = len(tallies)
+			index[v.Value()] = i
+			tallies = append(tallies, interfaces.VoteTally{Value: v.Value(), Power: new(big.Int)})
+		}
+		tallies[i].Power.Add(tallies[i].Power, v.Power())
+		tallies[i].Voters = append(tallies[i].Voters, v.Sender())
+	}
+	return tallies
+}
+
 func getBacklogUncheckedMsgs(c *Core) []*interfaces.MsgForDump {
 	result := make([]*interfaces.MsgForDump, 0)
 	for _, ms := range c.backlogUntrusted {
@@ -143,6 +143,85 @@ func TestGetCoreState(t *testing.T) {
 	}
 }
 
+func TestGetConsensusSnapshot(t *testing.T) {
+	height := big.NewInt(int64(100) + 1)
+	prevBlock := generateBlock(new(big.Int).Sub(height, common.Big1))
+	sender := common.BytesToAddress([]byte("sender"))
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	backendMock := interfaces.NewMockBackend(ctrl)
+	backendMock.EXPECT().Address().Return(sender)
+	backendMock.EXPECT().Logger().AnyTimes().Return(log.Root())
+
+	c := New(backendMock, nil)
+
+	var rounds = []int64{0, 1}
+
+	// Prepare 2 rounds of messages, plus a nil prevote in the last round
+	proposals := make([]*message.Propose, 2)
+	proposers := make([]common.Address, 2)
+	proposals[0], proposers[0] = prepareRoundMsgs(c, rounds[0], height)
+	proposals[1], proposers[1] = prepareRoundMsgs(c, rounds[1], height)
+	nilVoter := common.BytesToAddress([]byte("nil voter"))
+	c.messages.GetOrCreate(rounds[1]).AddPrevote(message.NewFakePrevote(message.Fake{
+		FakeSender: nilVoter,
+		FakeRound:  rounds[1],
+		FakeHeight: height.Uint64(),
+		FakePower:  common.Big2,
+	}))
+
+	members := []types.CommitteeMember{{Address: proposers[0], VotingPower: big.NewInt(1)}, {Address: proposers[1], VotingPower: big.NewInt(1)}}
+	committeeSet, err := tdmcommittee.NewRoundRobinSet(members, proposers[1])
+	require.NoError(t, err)
+	setCoreState(c, height, rounds[1], Prevote, proposals[0].Block(), rounds[0], proposals[1].Block(), rounds[1], committeeSet,
+		prevBlock.Header())
+
+	var e = SnapshotRequestEvent{
+		SnapshotChan: make(chan *interfaces.ConsensusSnapshot),
+	}
+	go c.handleSnapshotRequest(e)
+	snapshot := <-e.SnapshotChan
+	assert.Equal(t, height, snapshot.Height)
+	assert.Equal(t, rounds[1], snapshot.Round)
+	assert.Equal(t, Prevote.String(), snapshot.Step)
+	assert.Equal(t, proposals[0].Value(), *snapshot.LockedValue)
+	assert.Equal(t, rounds[0], snapshot.LockedRound)
+	assert.Equal(t, proposals[1].Value(), *snapshot.ValidValue)
+	assert.Equal(t, rounds[1], snapshot.ValidRound)
+	assert.Equal(t, committeeSet.Committee().String(), snapshot.Committee.String())
+
+	require.Len(t, snapshot.Rounds, 2)
+	for _, r := range snapshot.Rounds {
+		proposal := proposals[r.Round]
+		assert.Equal(t, proposal.Value(), *r.Proposal)
+		require.Len(t, r.Precommits, 1)
+		assert.Equal(t, proposal.Value(), r.Precommits[0].Value)
+		assert.Equal(t, []common.Address{testAddr}, r.Precommits[0].Voters)
+		if r.Round == rounds[0] {
+			require.Len(t, r.Prevotes, 1)
+			continue
+		}
+		require.Len(t, r.Prevotes, 2)
+		for _, tally := range r.Prevotes {
+			if tally.Value == (common.Hash{}) {
+				assert.Equal(t, common.Big2, tally.Power)
+				assert.Equal(t, []common.Address{nilVoter}, tally.Voters)
+			} else {
+				assert.Equal(t, proposal.Value(), tally.Value)
+				assert.Equal(t, []common.Address{testAddr}, tally.Voters)
+			}
+		}
+	}
+
+	// the snapshot is a deep copy
+	snapshot.Height.SetUint64(0)
+	snapshot.Committee[0].VotingPower.SetUint64(100)
+	assert.Equal(t, big.NewInt(int64(100)+1), c.Height())
+	assert.Equal(t, big.NewInt(1), c.CommitteeSet().Committee()[0].VotingPower)
+}
+
 func randomProposal(t *testing.T) *message.Propose {
 	currentHeight := big.NewInt(int64(rand.Intn(100) + 1))
 	currentRound := int64(rand.Intn(100) + 1)
@@ -0,0 +1,48 @@
+package core
+
+import (
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+)
+
+// syncDoneEvent is posted once the node caught up with the chain.
+type syncDoneEvent struct{}
+
+// SetSyncing is called when the downloader starts and stops syncing the chain. Meanwhile, the current height
+// proposals are deferred instead of being voted on, as they would be verified against a stale state. They are
+// re-evaluated once the sync completes, provided the engine is still at their height.
+func (c *Core) SetSyncing(syncing bool) {
+	if c.syncing.Swap(syncing) && !syncing {
+		go c.SendEvent(syncDoneEvent{})
+	}
+}
+
+// Syncing returns true if the node is syncing the chain.
+func (c *Core) Syncing() bool {
+	return c.syncing.Load()
+}
+
+// deferProposal keeps the first proposal received for each round while syncing, it must be called from the main
+// loop.
+func (c *Core) deferProposal(proposal *message.Propose) {
+	for _, p := range c.syncDeferred {
+		if p.H() == proposal.H() && p.R() == proposal.R() {
+			return
+		}
+	}
+	c.logger.Debug("Deferring proposal while syncing", "height", proposal.H(), "round", proposal.R())
+	c.syncDeferred = append(c.syncDeferred, proposal)
+}
+
+// handleSyncDone re-evaluates the proposals deferred during the sync through the backlog, it must be called from
+// the main loop.
+func (c *Core) handleSyncDone() {
+	deferred := c.syncDeferred
+	c.syncDeferred = nil
+	for _, p := range deferred {
+		if p.H() != c.Height().Uint64() {
+			continue
+		}
+		c.logger.Debug("Re-evaluating proposal deferred while syncing", "height", p.H(), "round", p.R())
+		go c.SendEvent(backlogMessageEvent{msg: p})
+	}
+}
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/log"
+)
+
+func TestProposalDeferredWhileSyncing(t *testing.T) {
+	committeeSet, keys := NewTestCommitteeSetWithKeys(4)
+	addr := committeeSet.Committee()[0].Address // round 3 - height 1 proposer
+	me := committeeSet.Committee()[1].Address
+	height := uint64(1)
+	round := int64(3)
+
+	ctrl := gomock.NewController(t)
+	block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height)})
+	proposal := message.NewPropose(round, height, -1, block, makeSigner(keys[addr], addr)).MustVerify(stubVerifier)
+	messages := message.NewMap()
+	// strict mock, the proposal must not be verified while syncing
+	backendMock := interfaces.NewMockBackend(ctrl)
+	c := &Core{
+		address:          me,
+		backend:          backendMock,
+		messages:         messages,
+		curRoundMessages: messages.GetOrCreate(round),
+		logger:           log.Root(),
+		proposeTimeout:   NewTimeout(Propose, log.Root()),
+		committee:        committeeSet,
+		round:            round,
+		height:           new(big.Int).SetUint64(height),
+		step:             Propose,
+	}
+	c.SetDefaultHandlers()
+	c.SetSyncing(true)
+
+	for i := 0; i < 2; i++ {
+		require.ErrorIs(t, c.proposer.HandleProposal(context.Background(), proposal), constants.ErrProposalDeferredSyncing)
+	}
+	require.Nil(t, c.curRoundMessages.Proposal())
+	require.Equal(t, Propose, c.step)
+	require.Len(t, c.syncDeferred, 1)
+
+	// the end of the sync is notified to the main loop which re-evaluates the proposal through the backlog
+	done := make(chan struct{})
+	backendMock.EXPECT().Post(syncDoneEvent{}).Do(func(any) { done <- struct{}{} })
+	c.SetSyncing(false)
+	waitPost(t, done)
+
+	backendMock.EXPECT().Post(backlogMessageEvent{msg: proposal}).Do(func(any) { done <- struct{}{} })
+	c.handleSyncDone()
+	waitPost(t, done)
+	require.Empty(t, c.syncDeferred)
+
+	// proposals deferred at a height the node since moved past are dropped
+	c.SetSyncing(true)
+	require.ErrorIs(t, c.proposer.HandleProposal(context.Background(), proposal), constants.ErrProposalDeferredSyncing)
+	c.setHeight(new(big.Int).SetUint64(height + 1))
+	c.handleSyncDone()
+	require.Empty(t, c.syncDeferred)
+}
+
+func waitPost(t *testing.T, done <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("event not posted")
+	}
+}
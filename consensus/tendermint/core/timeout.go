@@ -2,6 +2,8 @@ package core
 
 import (
 	"context"
+	"fmt"
+	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/consensus/tendermint/core/constants"
 	"github.com/autonity/autonity/log"
 	"math/big"
@@ -16,8 +18,87 @@ const (
 	PrevoteTimeoutDelta     = 200 * time.Millisecond
 	InitialPrecommitTimeout = 500 * time.Millisecond
 	PrecommitTimeoutDelta   = 200 * time.Millisecond
+
+	// MaxStepTimeout bounds the configurable base timeouts and per round increments.
+	MaxStepTimeout = time.Minute
+
+	DefaultProposalVerificationTimeout     = time.Duration(0) // disabled, the verification runs on the main loop
+	DefaultProposalVerificationRetries     = 3
+	DefaultProposalVerificationRetryDelay  = 50 * time.Millisecond
+	DefaultProposalBaseFeeRetries          = 2
+	DefaultProposalCircuitBreakerThreshold = 5
+	DefaultProposerBlacklistThreshold      = 2
+	DefaultMaxProposalRoundsAhead          = 10
+	DefaultResyncThreshold                 = 20
+	DefaultProposalSigningTimeout          = 2 * time.Second
+)
+
+// RoundTimeoutStrategy defines how the step timeouts widen as the round number grows.
+type RoundTimeoutStrategy int
+
+const (
+	// LinearRoundTimeouts adds the step delta once per round, the default.
+	LinearRoundTimeouts RoundTimeoutStrategy = iota
+	// ExponentialRoundTimeouts doubles the increment every round, the timeout of round r being
+	// base + delta * (2^r - 1), up to MaxStepTimeout.
+	ExponentialRoundTimeouts
 )
 
+func (s RoundTimeoutStrategy) String() string {
+	switch s {
+	case LinearRoundTimeouts:
+		return "linear"
+	case ExponentialRoundTimeouts:
+		return "exponential"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
+
+// TimeoutConfig holds the duration of each step timeout at round 0 and its increment for every following round.
+// The block period is added on top of the propose timeout.
+type TimeoutConfig struct {
+	Propose        time.Duration
+	ProposeDelta   time.Duration
+	Prevote        time.Duration
+	PrevoteDelta   time.Duration
+	Precommit      time.Duration
+	PrecommitDelta time.Duration
+}
+
+// DefaultTimeoutConfig returns the step timeouts used unless configured otherwise.
+func DefaultTimeoutConfig() TimeoutConfig {
+	return TimeoutConfig{
+		Propose:        InitialProposeTimeout,
+		ProposeDelta:   ProposeTimeoutDelta,
+		Prevote:        InitialPrevoteTimeout,
+		PrevoteDelta:   PrevoteTimeoutDelta,
+		Precommit:      InitialPrecommitTimeout,
+		PrecommitDelta: PrecommitTimeoutDelta,
+	}
+}
+
+// Validate checks that the base timeouts are positive and that none of the durations exceeds MaxStepTimeout.
+func (tc TimeoutConfig) Validate() error {
+	for _, base := range []struct {
+		name  string
+		value time.Duration
+	}{{"propose", tc.Propose}, {"prevote", tc.Prevote}, {"precommit", tc.Precommit}} {
+		if base.value <= 0 || base.value > MaxStepTimeout {
+			return fmt.Errorf("%w: %s timeout %v out of (0, %v]", constants.ErrInvalidTimeoutConfig, base.name, base.value, MaxStepTimeout)
+		}
+	}
+	for _, delta := range []struct {
+		name  string
+		value time.Duration
+	}{{"propose", tc.ProposeDelta}, {"prevote", tc.PrevoteDelta}, {"precommit", tc.PrecommitDelta}} {
+		if delta.value < 0 || delta.value > MaxStepTimeout {
+			return fmt.Errorf("%w: %s timeout delta %v out of [0, %v]", constants.ErrInvalidTimeoutConfig, delta.name, delta.value, MaxStepTimeout)
+		}
+	}
+	return nil
+}
+
 type TimeoutEvent struct {
 	RoundWhenCalled  int64
 	HeightWhenCalled *big.Int
@@ -26,12 +107,14 @@ type TimeoutEvent struct {
 }
 
 type Timeout struct {
-	Timer   *time.Timer
+	Timer   Timer
 	Started bool
 	Step    Step
 	// Start will be refreshed on each new schedule, it is used for metric collection of tendermint Timeout.
 	Start  time.Time
 	Logger log.Logger
+	// Clock is the time source used to schedule the timeout, the real clock is used if nil.
+	Clock Clock
 	sync.Mutex
 }
 
@@ -49,10 +132,30 @@ func (t *Timeout) ScheduleTimeout(stepTimeout time.Duration, round int64, height
 	t.Lock()
 	defer t.Unlock()
 	t.Started = true
-	t.Start = time.Now()
-	t.Timer = time.AfterFunc(stepTimeout, func() {
+	t.Start = orRealClock(t.Clock).Now()
+	t.Timer = orRealClock(t.Clock).AfterFunc(stepTimeout, func() {
+		runAfterTimeout(round, height)
+	})
+}
+
+// Reschedule moves the running timer to the given duration, counted from its original start, so that the step
+// is neither restarted nor timed out twice. The timer fires right away if the new duration already elapsed. It
+// returns false if no timer is running, including when it already fired.
+func (t *Timeout) Reschedule(stepTimeout time.Duration, round int64, height *big.Int, runAfterTimeout func(r int64, h *big.Int)) bool {
+	t.Lock()
+	defer t.Unlock()
+	if !t.Started || !t.Timer.Stop() {
+		return false
+	}
+	clock := orRealClock(t.Clock)
+	remaining := stepTimeout - clock.Now().Sub(t.Start)
+	if remaining < 0 {
+		remaining = 0
+	}
+	t.Timer = clock.AfterFunc(remaining, func() {
 		runAfterTimeout(round, height)
 	})
+	return true
 }
 
 func (t *Timeout) TimerStarted() bool {
@@ -81,16 +184,16 @@ func (t *Timeout) StopTimer() error {
 }
 
 func (t *Timeout) MeasureMetricsOnStopTimer() {
-	now := time.Now()
+	now := orRealClock(t.Clock).Now()
 	switch t.Step {
 	case Propose:
 		ProposeTimer.Update(now.Sub(t.Start))
 		ProposeBg.Add(now.Sub(t.Start).Nanoseconds())
 	case Prevote:
-		PrevoteTimer.UpdateSince(t.Start)
+		PrevoteTimer.Update(now.Sub(t.Start))
 		PrevoteBg.Add(now.Sub(t.Start).Nanoseconds())
 	case Precommit:
-		PrecommitTimer.UpdateSince(t.Start)
+		PrecommitTimer.Update(now.Sub(t.Start))
 		PrecommitBg.Add(now.Sub(t.Start).Nanoseconds())
 	}
 }
@@ -164,10 +267,27 @@ func (c *Core) onTimeoutPrecommit(r int64, h *big.Int) {
 	c.SendEvent(msg)
 }
 
+// ForceRoundChange moves the engine to the next round of the current height, as if the precommit timeout of
+// the current round expired. It is a last resort liveness tool for operators facing a stuck round, e.g. with an
+// offline proposer and a lost timeout. The round change goes through the regular precommit timeout handling, so
+// the locked and valid values are kept and no vote of the abandoned round is ever sent again. However forcing
+// round changes on a subset of the committee only desynchronises it from the others: it delays the
+// height instead of speeding it up unless a quorum of the committee moves together.
+func (c *Core) ForceRoundChange() {
+	msg := TimeoutEvent{
+		RoundWhenCalled:  c.Round(),
+		HeightWhenCalled: c.Height(),
+		Step:             Precommit,
+	}
+	c.logger.Warn("Forcing round change", "round", msg.RoundWhenCalled, "height", msg.HeightWhenCalled)
+	c.SendEvent(msg)
+}
+
 // ///////////// Handle Timeout Functions ///////////////
 func (c *Core) handleTimeoutPropose(ctx context.Context, msg TimeoutEvent) {
 	if msg.HeightWhenCalled.Cmp(c.Height()) == 0 && msg.RoundWhenCalled == c.Round() && c.step == Propose {
 		c.logTimeoutEvent("TimeoutEvent(Propose): Received", "Propose", msg)
+		c.recordProposerTimeout(msg.RoundWhenCalled)
 		c.prevoter.SendPrevote(ctx, true)
 		c.SetStep(Prevote)
 	}
@@ -184,6 +304,15 @@ func (c *Core) handleTimeoutPrevote(ctx context.Context, msg TimeoutEvent) {
 func (c *Core) handleTimeoutPrecommit(ctx context.Context, msg TimeoutEvent) {
 	if msg.HeightWhenCalled.Cmp(c.Height()) == 0 && msg.RoundWhenCalled == c.Round() {
 		c.logTimeoutEvent("TimeoutEvent(Precommit): Received", "Precommit", msg)
+		bestValue, bestPower := c.bestPrecommitPower(msg.RoundWhenCalled)
+		c.logger.Info("Round ended without commit",
+			"height", msg.HeightWhenCalled,
+			"round", msg.RoundWhenCalled,
+			"bestValue", bestValue,
+			"bestPrecommitPower", bestPower,
+			"nilPrecommitPower", c.messages.GetOrCreate(msg.RoundWhenCalled).PrecommitsPower(common.Hash{}),
+			"quorum", c.CommitteeSet().Quorum(),
+		)
 		c.StartRound(ctx, c.Round()+1)
 	}
 }
@@ -191,15 +320,34 @@ func (c *Core) handleTimeoutPrecommit(ctx context.Context, msg TimeoutEvent) {
 // ///////////// Calculate Timeout Duration Functions ///////////////
 // The Timeout may need to be changed depending on the Step
 func (c *Core) timeoutPropose(round int64) time.Duration {
-	return InitialProposeTimeout + time.Duration(c.blockPeriod)*time.Second + time.Duration(round)*ProposeTimeoutDelta
+	tc := c.Timeouts()
+	return c.roundTimeout(tc.Propose, tc.ProposeDelta, round) + time.Duration(c.blockPeriod)*time.Second + c.bootstrapWait()
 }
 
 func (c *Core) timeoutPrevote(round int64) time.Duration {
-	return InitialPrevoteTimeout + time.Duration(round)*PrevoteTimeoutDelta
+	tc := c.Timeouts()
+	return c.roundTimeout(tc.Prevote, tc.PrevoteDelta, round)
 }
 
 func (c *Core) timeoutPrecommit(round int64) time.Duration {
-	return InitialPrecommitTimeout + time.Duration(round)*PrecommitTimeoutDelta
+	tc := c.Timeouts()
+	return c.roundTimeout(tc.Precommit, tc.PrecommitDelta, round)
+}
+
+// roundTimeout returns the step timeout of the given round according to the round timeout strategy.
+func (c *Core) roundTimeout(base, delta time.Duration, round int64) time.Duration {
+	if c.roundTimeoutStrategy != ExponentialRoundTimeouts {
+		return base + time.Duration(round)*delta
+	}
+	timeout, increment := base, delta
+	for ; round > 0 && timeout < MaxStepTimeout; round-- {
+		timeout += increment
+		increment *= 2
+	}
+	if timeout > MaxStepTimeout {
+		timeout = MaxStepTimeout
+	}
+	return timeout
 }
 
 func (c *Core) logTimeoutEvent(message string, msgType string, timeout TimeoutEvent) {
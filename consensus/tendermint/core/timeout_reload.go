@@ -0,0 +1,47 @@
+package core
+
+// timeoutsReloadEvent carries a step timeouts configuration changed at runtime to the main loop. The events are
+// posted concurrently, the sequence tells apart the one of the latest reload.
+type timeoutsReloadEvent struct {
+	config   TimeoutConfig
+	sequence uint64
+}
+
+// ReloadTimeouts changes the durations of the step timeouts while the engine is running. The configuration is
+// rejected if not sane, otherwise it is applied from the main loop: the running step timers are moved to their
+// new duration right away instead of at the next round.
+func (c *Core) ReloadTimeouts(tc TimeoutConfig) error {
+	if err := tc.Validate(); err != nil {
+		return err
+	}
+	sequence := c.timeoutsReloadSequence.Add(1)
+	go c.SendEvent(timeoutsReloadEvent{config: tc, sequence: sequence})
+	return nil
+}
+
+// handleTimeoutsReloadEvent applies the reloaded timeouts configuration unless a later reload superseded it, the
+// events may reach the main loop out of order.
+func (c *Core) handleTimeoutsReloadEvent(e timeoutsReloadEvent) {
+	if e.sequence != c.timeoutsReloadSequence.Load() {
+		c.logger.Debug("Dropping superseded timeouts reload", "sequence", e.sequence)
+		return
+	}
+	c.handleTimeoutsReload(e.config)
+}
+
+// handleTimeoutsReload sets the timeouts configuration and reschedules the running step timers, it must be called
+// from the main loop. The new durations are counted from the original start of the timers and a timer which
+// already fired is left alone, so that no step is restarted or timed out twice.
+func (c *Core) handleTimeoutsReload(tc TimeoutConfig) {
+	c.timeouts = &tc
+	round, height := c.Round(), c.Height()
+	if c.proposeTimeout.Reschedule(c.timeoutPropose(round), round, height, c.onTimeoutPropose) {
+		c.logger.Debug("Rescheduled Propose Timeout", "Timeout Duration", c.timeoutPropose(round))
+	}
+	if c.prevoteTimeout.Reschedule(c.timeoutPrevote(round), round, height, c.onTimeoutPrevote) {
+		c.logger.Debug("Rescheduled Prevote Timeout", "Timeout Duration", c.timeoutPrevote(round))
+	}
+	if c.precommitTimeout.Reschedule(c.timeoutPrecommit(round), round, height, c.onTimeoutPrecommit) {
+		c.logger.Debug("Rescheduled Precommit Timeout", "Timeout Duration", c.timeoutPrecommit(round))
+	}
+}
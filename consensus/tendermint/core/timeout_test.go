@@ -3,13 +3,16 @@ package core
 import (
 	"context"
 	"math/big"
+	"sort"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
 	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
 	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
 	"github.com/autonity/autonity/core/types"
@@ -191,6 +194,54 @@ func TestOnTimeoutPrevote(t *testing.T) {
 	engine.onTimeoutPrevote(2, big.NewInt(4))
 }
 
+func TestForceRoundChange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	committeeSet, _ := NewTestCommitteeSetWithKeys(4)
+	currentValidator, _ := committeeSet.GetByIndex(0)
+	logger := log.New("backend", "test", "id", 0)
+	messages := message.NewMap()
+	curRoundMessages := messages.GetOrCreate(1)
+	// strict mock: no vote can be broadcast
+	mockBackend := interfaces.NewMockBackend(ctrl)
+	engine := Core{
+		logger:           logger,
+		backend:          mockBackend,
+		address:          currentValidator.Address,
+		curRoundMessages: curRoundMessages,
+		messages:         messages,
+		step:             Prevote,
+		sentPrevote:      true,
+		round:            1,
+		height:           big.NewInt(2),
+		committee:        committeeSet,
+		proposeTimeout:   NewTimeout(Propose, logger),
+		prevoteTimeout:   NewTimeout(Prevote, logger),
+		precommitTimeout: NewTimeout(Precommit, logger),
+	}
+	engine.SetDefaultHandlers()
+
+	var forced TimeoutEvent
+	mockBackend.EXPECT().Post(gomock.Any()).Times(1).Do(func(ev interface{}) {
+		forced = ev.(TimeoutEvent)
+	})
+	engine.ForceRoundChange()
+	require.Equal(t, TimeoutEvent{RoundWhenCalled: 1, HeightWhenCalled: big.NewInt(2), Step: Precommit}, forced)
+
+	// the forced round change is processed by the main loop like a precommit timeout
+	mockBackend.EXPECT().Post(gomock.Any()).AnyTimes()
+	previousProposer := committeeSet.GetProposer(1).Address
+	engine.handleTimeoutPrecommit(context.Background(), forced)
+	require.Equal(t, int64(2), engine.Round())
+	require.Equal(t, big.NewInt(2), engine.Height())
+	require.Equal(t, Propose, engine.step)
+	require.NotEqual(t, previousProposer, engine.CommitteeSet().GetProposer(engine.Round()).Address)
+
+	// a stale forced change is ignored
+	engine.handleTimeoutPrecommit(context.Background(), forced)
+	require.Equal(t, int64(2), engine.Round())
+}
+
 func TestOnTimeoutPrecommit(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -221,3 +272,253 @@ func TestOnTimeoutPrecommit(t *testing.T) {
 	})
 	engine.onTimeoutPrecommit(2, big.NewInt(4))
 }
+
+func TestTimeoutConfig(t *testing.T) {
+	custom := TimeoutConfig{
+		Propose:        3 * time.Second,
+		ProposeDelta:   time.Second,
+		Prevote:        2 * time.Second,
+		PrevoteDelta:   700 * time.Millisecond,
+		Precommit:      4 * time.Second,
+		PrecommitDelta: 300 * time.Millisecond,
+	}
+	const round = int64(2)
+	height := big.NewInt(3)
+	committeeSet, keys := NewTestCommitteeSetWithKeys(7)
+	proposer := committeeSet.GetProposer(round).Address
+	var me common.Address
+	for _, m := range committeeSet.Committee() {
+		if m.Address != proposer {
+			me = m.Address
+			break
+		}
+	}
+
+	// scheduledIn returns the delay of the single timer pending on the clock.
+	scheduledIn := func(t *testing.T, clock *fakeClock) time.Duration {
+		clock.Lock()
+		defer clock.Unlock()
+		require.Len(t, clock.timers, 1)
+		return clock.timers[0].at.Sub(clock.now)
+	}
+
+	newCore := func(t *testing.T, step Step) (*Core, *fakeClock, *message.Propose) {
+		ctrl := gomock.NewController(t)
+		backendMock := interfaces.NewMockBackend(ctrl)
+		backendMock.EXPECT().Address().AnyTimes().Return(me)
+		backendMock.EXPECT().Logger().AnyTimes().Return(log.Root())
+		backendMock.EXPECT().Sign(gomock.Any()).AnyTimes().DoAndReturn(makeSigner(keys[me], me))
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any()).AnyTimes()
+
+		c := New(backendMock, nil)
+		clock := newFakeClock()
+		c.SetClock(clock)
+		require.NoError(t, c.SetTimeouts(custom))
+		c.setCommitteeSet(committeeSet)
+		c.setHeight(height)
+		c.setRound(round)
+		c.SetStep(step)
+
+		proposal := message.NewPropose(round, height.Uint64(), -1, types.NewBlockWithHeader(&types.Header{Number: height}), makeSigner(keys[proposer], proposer))
+		c.curRoundMessages.SetProposal(proposal.MustVerify(stubVerifier), true)
+		return c, clock, proposal
+	}
+
+	t.Run("default configuration used unless set", func(t *testing.T) {
+		c := &Core{}
+		require.Equal(t, DefaultTimeoutConfig(), c.Timeouts())
+		require.Equal(t, InitialPrevoteTimeout+PrevoteTimeoutDelta, c.timeoutPrevote(1))
+	})
+
+	t.Run("insane configurations rejected", func(t *testing.T) {
+		c := &Core{}
+		for _, mutate := range []func(tc *TimeoutConfig){
+			func(tc *TimeoutConfig) { tc.Propose = 0 },
+			func(tc *TimeoutConfig) { tc.Prevote = -time.Second },
+			func(tc *TimeoutConfig) { tc.Precommit = MaxStepTimeout + 1 },
+			func(tc *TimeoutConfig) { tc.ProposeDelta = -1 },
+			func(tc *TimeoutConfig) { tc.PrecommitDelta = MaxStepTimeout + 1 },
+		} {
+			tc := custom
+			mutate(&tc)
+			require.ErrorIs(t, c.SetTimeouts(tc), constants.ErrInvalidTimeoutConfig)
+			require.Equal(t, DefaultTimeoutConfig(), c.Timeouts())
+		}
+		// zero increments keep the timeouts constant across rounds
+		tc := custom
+		tc.PrevoteDelta = 0
+		require.NoError(t, c.SetTimeouts(tc))
+		require.Equal(t, tc, c.Timeouts())
+	})
+
+	t.Run("configured propose timeout scheduled at round start", func(t *testing.T) {
+		c, clock, _ := newCore(t, PrecommitDone)
+		c.StartRound(context.Background(), round)
+
+		require.True(t, c.proposeTimeout.TimerStarted())
+		want := custom.Propose + time.Duration(c.blockPeriod)*time.Second + time.Duration(round)*custom.ProposeDelta
+		require.Equal(t, want, scheduledIn(t, clock))
+	})
+
+	t.Run("configured prevote timeout scheduled on prevotes quorum for any value", func(t *testing.T) {
+		c, clock, proposal := newCore(t, Prevote)
+		// 3 prevotes for the proposal and 2 nil prevotes, a quorum in total but for none of the values
+		for i, m := range committeeSet.Committee()[2:] {
+			value := proposal.Block().Hash()
+			if i >= 3 {
+				value = common.Hash{}
+			}
+			prevote := message.NewPrevote(round, height.Uint64(), value, makeSigner(keys[m.Address], m.Address))
+			require.NoError(t, c.prevoter.HandlePrevote(context.Background(), prevote.MustVerify(stubVerifier)))
+		}
+
+		require.True(t, c.prevoteTimeout.TimerStarted())
+		require.Equal(t, custom.Prevote+time.Duration(round)*custom.PrevoteDelta, scheduledIn(t, clock))
+	})
+
+	t.Run("configured precommit timeout scheduled on precommits quorum for any value", func(t *testing.T) {
+		c, clock, proposal := newCore(t, Precommit)
+		for i, m := range committeeSet.Committee()[2:] {
+			value := proposal.Block().Hash()
+			if i >= 3 {
+				value = common.Hash{}
+			}
+			precommit := message.NewPrecommit(round, height.Uint64(), value, makeSigner(keys[m.Address], m.Address))
+			require.NoError(t, c.precommiter.HandlePrecommit(context.Background(), precommit.MustVerify(stubVerifier)))
+		}
+
+		require.True(t, c.precommitTimeout.TimerStarted())
+		require.Equal(t, custom.Precommit+time.Duration(round)*custom.PrecommitDelta, scheduledIn(t, clock))
+	})
+
+	t.Run("reloaded propose timeout applied to the running timer", func(t *testing.T) {
+		c, clock, _ := newCore(t, PrecommitDone)
+		c.StartRound(context.Background(), round)
+		clock.Advance(time.Second)
+
+		reloaded := custom
+		reloaded.Propose = 2 * custom.Propose
+		c.handleTimeoutsReload(reloaded)
+		require.Equal(t, reloaded, c.Timeouts())
+		// the time already spent in the step counts towards the new timeout
+		want := reloaded.Propose + time.Duration(c.blockPeriod)*time.Second + time.Duration(round)*custom.ProposeDelta
+		require.Equal(t, want-time.Second, scheduledIn(t, clock))
+
+		// a timeout shorter than the time already spent fires right away
+		clock.Advance(time.Duration(c.blockPeriod) * time.Second)
+		reloaded.Propose = time.Millisecond
+		reloaded.ProposeDelta = 0
+		c.handleTimeoutsReload(reloaded)
+		require.Equal(t, time.Duration(0), scheduledIn(t, clock))
+		require.False(t, c.prevoteTimeout.TimerStarted())
+		require.False(t, c.precommitTimeout.TimerStarted())
+	})
+
+	t.Run("insane reloaded configuration rejected", func(t *testing.T) {
+		c, _, _ := newCore(t, Propose)
+		reloaded := custom
+		reloaded.Prevote = 0
+		require.ErrorIs(t, c.ReloadTimeouts(reloaded), constants.ErrInvalidTimeoutConfig)
+		require.Equal(t, custom, c.Timeouts())
+	})
+}
+
+func TestTimeoutsReloadOrdering(t *testing.T) {
+	backendMock := interfaces.NewMockBackend(gomock.NewController(t))
+	backendMock.EXPECT().Address().AnyTimes()
+	backendMock.EXPECT().Logger().AnyTimes().Return(log.Root())
+	posted := make(chan timeoutsReloadEvent, 2)
+	backendMock.EXPECT().Post(gomock.Any()).Times(2).Do(func(ev any) { posted <- ev.(timeoutsReloadEvent) })
+	c := New(backendMock, nil)
+
+	first, second := DefaultTimeoutConfig(), DefaultTimeoutConfig()
+	first.Propose = 2 * first.Propose
+	second.Propose = 3 * second.Propose
+	require.NoError(t, c.ReloadTimeouts(first))
+	require.NoError(t, c.ReloadTimeouts(second))
+	events := []timeoutsReloadEvent{<-posted, <-posted}
+	sort.Slice(events, func(i, j int) bool { return events[i].sequence > events[j].sequence })
+
+	// the events of both reloads reach the main loop in the reverse order, the earlier one is dropped
+	for _, e := range events {
+		c.handleTimeoutsReloadEvent(e)
+	}
+	require.Equal(t, second, c.Timeouts())
+}
+
+func TestRoundTimeoutStrategy(t *testing.T) {
+	tc := TimeoutConfig{
+		Propose:        3 * time.Second,
+		ProposeDelta:   time.Second,
+		Prevote:        2 * time.Second,
+		PrevoteDelta:   500 * time.Millisecond,
+		Precommit:      4 * time.Second,
+		PrecommitDelta: 0,
+	}
+	newCore := func(t *testing.T, strategy RoundTimeoutStrategy) *Core {
+		c := &Core{blockPeriod: 1}
+		require.NoError(t, c.SetTimeouts(tc))
+		require.NoError(t, c.SetRoundTimeoutStrategy(strategy))
+		return c
+	}
+
+	t.Run("linear by default", func(t *testing.T) {
+		c := &Core{}
+		require.Equal(t, LinearRoundTimeouts, c.roundTimeoutStrategy)
+		require.Equal(t, InitialPrevoteTimeout+5*PrevoteTimeoutDelta, c.timeoutPrevote(5))
+	})
+
+	t.Run("linear", func(t *testing.T) {
+		c := newCore(t, LinearRoundTimeouts)
+		for round := int64(0); round < 10; round++ {
+			require.Equal(t, tc.Propose+time.Second+time.Duration(round)*tc.ProposeDelta, c.timeoutPropose(round))
+			require.Equal(t, tc.Prevote+time.Duration(round)*tc.PrevoteDelta, c.timeoutPrevote(round))
+			require.Equal(t, tc.Precommit, c.timeoutPrecommit(round))
+		}
+	})
+
+	t.Run("exponential", func(t *testing.T) {
+		c := newCore(t, ExponentialRoundTimeouts)
+		for round := int64(0); round < 5; round++ {
+			increment := time.Duration(1<<round - 1)
+			require.Equal(t, tc.Propose+time.Second+increment*tc.ProposeDelta, c.timeoutPropose(round))
+			require.Equal(t, tc.Prevote+increment*tc.PrevoteDelta, c.timeoutPrevote(round))
+			require.Equal(t, tc.Precommit, c.timeoutPrecommit(round))
+		}
+		// the step timeouts are capped, the block period still adds to the propose one
+		require.Equal(t, MaxStepTimeout+time.Second, c.timeoutPropose(10))
+		require.Equal(t, MaxStepTimeout, c.timeoutPrevote(constants.MaxRound))
+	})
+
+	t.Run("unknown strategy rejected", func(t *testing.T) {
+		c := &Core{}
+		require.ErrorIs(t, c.SetRoundTimeoutStrategy(RoundTimeoutStrategy(2)), constants.ErrInvalidTimeoutConfig)
+		require.Equal(t, LinearRoundTimeouts, c.roundTimeoutStrategy)
+	})
+}
+
+func TestTimeoutReschedule(t *testing.T) {
+	clock := newFakeClock()
+	tm := NewTimeout(Propose, log.Root())
+	tm.Clock = clock
+
+	require.False(t, tm.Reschedule(time.Second, 0, big.NewInt(1), func(_ int64, _ *big.Int) {}))
+
+	fired := 0
+	onTimeout := func(_ int64, _ *big.Int) { fired++ }
+	tm.ScheduleTimeout(time.Second, 0, big.NewInt(1), onTimeout)
+	start := tm.Start
+	clock.Advance(500 * time.Millisecond)
+	require.True(t, tm.Reschedule(2*time.Second, 0, big.NewInt(1), onTimeout))
+	require.Equal(t, start, tm.Start)
+
+	clock.Advance(time.Second)
+	require.Equal(t, 0, fired)
+	clock.Advance(500 * time.Millisecond)
+	require.Equal(t, 1, fired)
+
+	// a fired timer is not scheduled again
+	require.False(t, tm.Reschedule(3*time.Second, 0, big.NewInt(1), onTimeout))
+	clock.Advance(time.Minute)
+	require.Equal(t, 1, fired)
+}
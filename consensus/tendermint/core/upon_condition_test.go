@@ -195,11 +195,35 @@ func TestStartRound(t *testing.T) {
 			backendMock.EXPECT().HeadBlock().Return(prevBlock)
 		}
 
+		backendMock.EXPECT().LastProposal()
+		backendMock.EXPECT().SaveProposal(gomock.Any())
 		backendMock.EXPECT().SetProposedBlockHash(proposal.Block().Hash())
 		backendMock.EXPECT().Broadcast(committeeSet.Committee(), proposal)
 
 		core.StartRound(context.Background(), currentRound)
 	})
+	t.Run("client is the proposer without candidate block", func(t *testing.T) {
+		proposalHeight := big.NewInt(int64(rand.Intn(maxSize) + 1))
+		currentRound := int64(len(members))
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		backendMock := interfaces.NewMockBackend(ctrl)
+		backendMock.EXPECT().Address().Return(clientAddr)
+		backendMock.EXPECT().Logger().AnyTimes().Return(log.Root())
+
+		core := New(backendMock, nil)
+		core.committee = committeeSet
+		core.height = proposalHeight
+
+		// no proposal is sent, a candidate block is requested instead
+		backendMock.EXPECT().RequestCandidateBlock(proposalHeight.Uint64())
+
+		core.StartRound(context.Background(), currentRound)
+		assert.False(t, core.sentProposal)
+		assert.False(t, core.proposeTimeout.TimerStarted())
+	})
 	t.Run("client is the proposer and valid value is not nil", func(t *testing.T) {
 
 		proposalHeight := big.NewInt(int64(rand.Intn(maxSize) + 1))
@@ -226,6 +250,8 @@ func TestStartRound(t *testing.T) {
 		core.validRound = validR
 		core.validValue = proposal.Block()
 
+		backendMock.EXPECT().LastProposal()
+		backendMock.EXPECT().SaveProposal(gomock.Any())
 		backendMock.EXPECT().SetProposedBlockHash(proposal.Block().Hash())
 		backendMock.EXPECT().Broadcast(committeeSet.Committee(), proposal)
 
@@ -1372,6 +1398,8 @@ func TestQuorumPrecommit(t *testing.T) {
 	if newCommitteeSet.GetProposer(0).Address == clientAddr {
 		t.Log("is proposer")
 		c.pendingCandidateBlocks[nextHeight] = nextProposalMsg.Block()
+		backendMock.EXPECT().LastProposal()
+		backendMock.EXPECT().SaveProposal(gomock.Any())
 		backendMock.EXPECT().SetProposedBlockHash(nextProposalMsg.Block().Hash())
 		backendMock.EXPECT().Broadcast(committeeSet.Committee(), nextProposalMsg)
 	}
@@ -55,3 +55,17 @@ func (mr *MockPeerMockRecorder) SendRaw(msgcode, data interface{}) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendRaw", reflect.TypeOf((*MockPeer)(nil).SendRaw), msgcode, data)
 }
+
+// Version mocks base method.
+func (m *MockPeer) Version() uint {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Version")
+	ret0, _ := ret[0].(uint)
+	return ret0
+}
+
+// Version indicates an expected call of Version.
+func (mr *MockPeerMockRecorder) Version() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Version", reflect.TypeOf((*MockPeer)(nil).Version))
+}
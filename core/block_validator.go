@@ -106,7 +106,17 @@ func (v *BlockValidator) ValidateState(block *types.Block, statedb *state.StateD
 // to keep the baseline gas close to the provided target, and increase it towards
 // the target if the baseline gas is lower.
 func CalcGasLimit(parentGasLimit, desiredLimit uint64) uint64 {
-    delta := parentGasLimit/params.GasLimitBoundDivisor - 1
+    return CalcGasLimitWithDivisor(parentGasLimit, desiredLimit, params.GasLimitBoundDivisor)
+}
+
+// CalcGasLimitWithDivisor is like CalcGasLimit, but moves the gas limit toward the
+// target by at most parentGasLimit/stepDivisor per block. The divisor must not be
+// lower than params.GasLimitBoundDivisor for the result to satisfy the protocol bounds.
+func CalcGasLimitWithDivisor(parentGasLimit, desiredLimit, stepDivisor uint64) uint64 {
+    delta := parentGasLimit / stepDivisor
+    if delta > 0 {
+        delta--
+    }
     limit := parentGasLimit
     if desiredLimit < params.MinGasLimit {
         desiredLimit = params.MinGasLimit
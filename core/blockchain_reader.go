@@ -26,6 +26,7 @@ import (
     "github.com/autonity/autonity/core/state/snapshot"
     "github.com/autonity/autonity/core/types"
     "github.com/autonity/autonity/core/vm"
+    "github.com/autonity/autonity/ethdb"
     "github.com/autonity/autonity/event"
     "github.com/autonity/autonity/params"
     "github.com/autonity/autonity/rlp"
@@ -329,11 +330,22 @@ func (bc *BlockChain) Processor() Processor {
     return bc.processor
 }
 
+// Prefetcher returns the state prefetcher used to warm the caches for the blocks
+// about to be processed.
+func (bc *BlockChain) Prefetcher() Prefetcher {
+    return bc.prefetcher
+}
+
 // StateCache returns the caching database underpinning the blockchain instance.
 func (bc *BlockChain) StateCache() state.Database {
     return bc.stateCache
 }
 
+// Database returns the low level persistent database of the blockchain.
+func (bc *BlockChain) Database() ethdb.Database {
+    return bc.db
+}
+
 // GasLimit returns the gas limit of the current HEAD block.
 func (bc *BlockChain) GasLimit() uint64 {
     return bc.CurrentBlock().GasLimit()
@@ -174,3 +174,35 @@ func WriteTransitionStatus(db ethdb.KeyValueWriter, data []byte) {
 		log.Crit("Failed to store the eth2 transition status", "err", err)
 	}
 }
+
+// LastProposal is the record of the last consensus proposal sent by the local node.
+type LastProposal struct {
+	Height uint64
+	Round  uint64
+	Hash   common.Hash
+}
+
+// ReadLastProposal retrieves the last consensus proposal sent by the local node.
+func ReadLastProposal(db ethdb.KeyValueReader) *LastProposal {
+	data, _ := db.Get(lastProposalKey)
+	if len(data) == 0 {
+		return nil
+	}
+	proposal := new(LastProposal)
+	if err := rlp.DecodeBytes(data, proposal); err != nil {
+		log.Error("Invalid last proposal RLP", "err", err)
+		return nil
+	}
+	return proposal
+}
+
+// WriteLastProposal stores the last consensus proposal sent by the local node.
+func WriteLastProposal(db ethdb.KeyValueWriter, proposal *LastProposal) {
+	data, err := rlp.EncodeToBytes(proposal)
+	if err != nil {
+		log.Crit("Failed to encode last proposal", "err", err)
+	}
+	if err := db.Put(lastProposalKey, data); err != nil {
+		log.Crit("Failed to store last proposal", "err", err)
+	}
+}
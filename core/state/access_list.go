@@ -18,6 +18,7 @@ package state
 
 import (
 	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/types"
 )
 
 type accessList struct {
@@ -54,6 +55,21 @@ func newAccessList() *accessList {
 	}
 }
 
+// toAccessList converts the access list to its transaction representation.
+func (al *accessList) toAccessList() types.AccessList {
+	list := make(types.AccessList, 0, len(al.addresses))
+	for addr, idx := range al.addresses {
+		tuple := types.AccessTuple{Address: addr, StorageKeys: []common.Hash{}}
+		if idx >= 0 {
+			for slot := range al.slots[idx] {
+				tuple.StorageKeys = append(tuple.StorageKeys, slot)
+			}
+		}
+		list = append(list, tuple)
+	}
+	return list
+}
+
 // Copy creates an independent copy of an accessList.
 func (a *accessList) Copy() *accessList {
 	cp := newAccessList()
@@ -138,6 +138,21 @@ type (
 		address *common.Address
 		slot    *common.Hash
 	}
+	accessListResetChange struct {
+		prev *accessList
+	}
+
+	// Changes made by Finalise while the journal is retained.
+	finaliseChange struct {
+		account         *common.Address
+		object          *stateObject
+		prevDeleted     bool
+		prevPending     bool
+		prevDirty       bool
+		prevDestruct    bool
+		prevSnapAccount []byte
+		prevSnapStorage map[common.Hash][]byte
+	}
 )
 
 func (ch createObjectChange) revert(s *StateDB) {
@@ -267,3 +282,37 @@ func (ch accessListAddSlotChange) revert(s *StateDB) {
 func (ch accessListAddSlotChange) dirtied() *common.Address {
 	return nil
 }
+
+func (ch accessListResetChange) revert(s *StateDB) {
+	s.accessList = ch.prev
+}
+
+func (ch accessListResetChange) dirtied() *common.Address {
+	return nil
+}
+
+func (ch finaliseChange) revert(s *StateDB) {
+	ch.object.deleted = ch.prevDeleted
+	if !ch.prevPending {
+		delete(s.stateObjectsPending, *ch.account)
+	}
+	if !ch.prevDirty {
+		delete(s.stateObjectsDirty, *ch.account)
+	}
+	if s.snap == nil {
+		return
+	}
+	if !ch.prevDestruct {
+		delete(s.snapDestructs, ch.object.addrHash)
+	}
+	if ch.prevSnapAccount != nil {
+		s.snapAccounts[ch.object.addrHash] = ch.prevSnapAccount
+	}
+	if ch.prevSnapStorage != nil {
+		s.snapStorage[ch.object.addrHash] = ch.prevSnapStorage
+	}
+}
+
+func (ch finaliseChange) dirtied() *common.Address {
+	return nil
+}
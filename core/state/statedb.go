@@ -104,6 +104,7 @@ type StateDB struct {
 	journal        *journal
 	validRevisions []revision
 	nextRevisionId int
+	retainJournal  int // callers keeping the journal across Finalise, see RetainJournal
 
 	// Measurements gathered during execution for debugging purposes
 	AccountReads         time.Duration
@@ -786,6 +787,9 @@ func (s *StateDB) Finalise(deleteEmptyObjects bool) {
 			// Thus, we can safely ignore it here
 			continue
 		}
+		if s.retainJournal > 0 {
+			s.journalFinalise(addr, obj)
+		}
 		if obj.suicided || (deleteEmptyObjects && obj.empty()) {
 			obj.deleted = true
 
@@ -812,10 +816,32 @@ func (s *StateDB) Finalise(deleteEmptyObjects bool) {
 	if s.prefetcher != nil && len(addressesToPrefetch) > 0 {
 		s.prefetcher.prefetch(s.originalRoot, addressesToPrefetch)
 	}
+	if s.retainJournal > 0 {
+		s.journal.append(refundChange{prev: s.refund})
+		s.refund = 0
+		return
+	}
 	// Invalidate journal because reverting across transactions is not allowed.
 	s.clearJournalAndRefund()
 }
 
+// RetainJournal keeps the journal across Finalise until ReleaseJournal is called,
+// so that a snapshot taken beforehand can revert several transactions at once. The
+// effects of Finalise are journalled along, but not the trie updates done by
+// IntermediateRoot, which mustn't be called meanwhile. Calls nest, the journal is
+// only cleared once every caller released it.
+func (s *StateDB) RetainJournal() {
+	s.retainJournal++
+}
+
+// ReleaseJournal stops retaining the journal, clearing it like Finalise does once
+// no other caller retains it.
+func (s *StateDB) ReleaseJournal() {
+	if s.retainJournal--; s.retainJournal == 0 {
+		s.clearJournalAndRefund()
+	}
+}
+
 // IntermediateRoot computes the current root hash of the state trie.
 // It is called in between transactions to get the root hash that
 // goes into transaction receipts.
@@ -884,9 +910,29 @@ func (s *StateDB) IntermediateRoot(deleteEmptyObjects bool) common.Hash {
 func (s *StateDB) Prepare(thash common.Hash, ti int) {
 	s.thash = thash
 	s.txIndex = ti
+	if s.retainJournal > 0 {
+		s.journal.append(accessListResetChange{prev: s.accessList})
+	}
 	s.accessList = newAccessList()
 }
 
+// journalFinalise records the object state that Finalise is about to change.
+func (s *StateDB) journalFinalise(addr common.Address, obj *stateObject) {
+	ch := finaliseChange{
+		account:     &addr,
+		object:      obj,
+		prevDeleted: obj.deleted,
+	}
+	_, ch.prevPending = s.stateObjectsPending[addr]
+	_, ch.prevDirty = s.stateObjectsDirty[addr]
+	if s.snap != nil {
+		_, ch.prevDestruct = s.snapDestructs[obj.addrHash]
+		ch.prevSnapAccount = s.snapAccounts[obj.addrHash]
+		ch.prevSnapStorage = s.snapStorage[obj.addrHash]
+	}
+	s.journal.append(ch)
+}
+
 func (s *StateDB) clearJournalAndRefund() {
 	if len(s.journal.entries) > 0 {
 		s.journal = newJournal()
@@ -1044,3 +1090,9 @@ func (s *StateDB) AddressInAccessList(addr common.Address) bool {
 func (s *StateDB) SlotInAccessList(addr common.Address, slot common.Hash) (addressPresent bool, slotPresent bool) {
 	return s.accessList.Contains(addr, slot)
 }
+
+// AccessList returns the addresses and storage slots in the access list, that is the
+// state accessed by the transaction being, or last, applied.
+func (s *StateDB) AccessList() types.AccessList {
+	return s.accessList.toAccessList()
+}
@@ -695,6 +695,61 @@ func TestDeleteCreateRevert(t *testing.T) {
 	}
 }
 
+// TestRetainJournalRevert checks that a snapshot taken while the journal is
+// retained reverts several finalised transactions, leaving the same state as if
+// they had never been applied.
+func TestRetainJournalRevert(t *testing.T) {
+	state, _ := New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()), nil)
+
+	var (
+		alive   = common.BytesToAddress([]byte("alive"))
+		doomed  = common.BytesToAddress([]byte("doomed"))
+		created = common.BytesToAddress([]byte("created"))
+		slot    = common.BytesToHash([]byte("slot"))
+	)
+	state.SetBalance(alive, big.NewInt(1))
+	state.SetState(alive, slot, common.BytesToHash([]byte("before")))
+	state.SetBalance(doomed, big.NewInt(2))
+	root, _ := state.Commit(false)
+	state, _ = New(root, state.db, state.snaps)
+
+	state.SetNonce(alive, 1)
+	state.Finalise(true)
+	want := state.Copy().IntermediateRoot(true)
+
+	state.RetainJournal()
+	id := state.Snapshot()
+
+	state.Prepare(common.Hash{1}, 0)
+	state.AddSlotToAccessList(alive, slot)
+	state.SetState(alive, slot, common.BytesToHash([]byte("after")))
+	state.Suicide(doomed)
+	state.AddRefund(10)
+	state.Finalise(true)
+
+	state.Prepare(common.Hash{2}, 1)
+	state.AddSlotToAccessList(alive, slot)
+	state.SetBalance(created, big.NewInt(3))
+	state.AddBalance(alive, big.NewInt(4))
+	state.Finalise(true)
+
+	state.RevertToSnapshot(id)
+	state.ReleaseJournal()
+
+	if got := state.IntermediateRoot(true); got != want {
+		t.Fatalf("state root mismatch: have %x, want %x", got, want)
+	}
+	if state.Exist(created) {
+		t.Fatalf("reverted account still exists")
+	}
+	if state.HasSuicided(doomed) || state.GetBalance(doomed).Cmp(big.NewInt(2)) != 0 {
+		t.Fatalf("self-destruct not reverted")
+	}
+	if refund := state.GetRefund(); refund != 0 {
+		t.Fatalf("refund not reverted: have %d", refund)
+	}
+}
+
 // TestMissingTrieNodes tests that if the StateDB fails to load parts of the trie,
 // the Commit operation fails with an error
 // If we are missing trie nodes, we should not continue writing to the trie
@@ -38,6 +38,7 @@ import (
 	"github.com/autonity/autonity/core/state"
 	"github.com/autonity/autonity/core/types"
 	"github.com/autonity/autonity/internal/ethapi"
+	"github.com/autonity/autonity/miner"
 	"github.com/autonity/autonity/rlp"
 	"github.com/autonity/autonity/rpc"
 	"github.com/autonity/autonity/trie"
@@ -85,6 +86,12 @@ func (api *PublicMinerAPI) Mining() bool {
 	return api.e.IsMining()
 }
 
+// MiningStatus returns whether this node is producing blocks, and whether the consensus engine
+// halted proposing after its own proposals repeatedly failed verification.
+func (api *PublicMinerAPI) MiningStatus() miner.MiningStatus {
+	return api.e.Miner().MiningStatus()
+}
+
 // PrivateMinerAPI provides private RPC methods to control the miner.
 // These methods can be abused by external users and must be considered insecure for use by untrusted users.
 type PrivateMinerAPI struct {
@@ -139,8 +146,24 @@ func (api *PrivateMinerAPI) SetGasLimit(gasLimit hexutil.Uint64) bool {
 }
 
 // SetRecommitInterval updates the interval for miner sealing work recommitting.
-func (api *PrivateMinerAPI) SetRecommitInterval(interval int) {
-	api.e.Miner().SetRecommitInterval(time.Duration(interval) * time.Millisecond)
+func (api *PrivateMinerAPI) SetRecommitInterval(interval int) error {
+	return api.e.Miner().SetRecommitInterval(time.Duration(interval) * time.Millisecond)
+}
+
+// ResetProposalCircuitBreaker resumes proposing after the consensus engine halted it because
+// our own proposals repeatedly failed verification, once the underlying fault is fixed.
+func (api *PrivateMinerAPI) ResetProposalCircuitBreaker() {
+	api.e.Miner().ResetProposalCircuitBreaker()
+}
+
+// TxSelectionReport builds a block on the given parent, the latest head if omitted, and reports for each
+// transaction of the pool whether it was included and why not if it wasn't.
+func (api *PrivateMinerAPI) TxSelectionReport(parent *common.Hash) (*miner.SelectionReport, error) {
+	var hash common.Hash
+	if parent != nil {
+		hash = *parent
+	}
+	return api.e.Miner().TxSelectionReport(hash)
 }
 
 // PrivateAdminAPI is the collection of Ethereum full node-related APIs
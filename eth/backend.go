@@ -260,6 +260,15 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 
 	eth.miner = miner.New(eth, &config.Miner, chainConfig, eth.EventMux(), eth.engine, eth.isLocalBlock)
 	eth.miner.SetExtra(makeExtraData(config.Miner.ExtraData))
+	// Resync the chain when the consensus engine detects the node fell behind, pausing the miner meanwhile.
+	if requester, ok := eth.engine.(consensus.ResyncRequester); ok {
+		requester.SetResyncHandler(func() {
+			go func() {
+				eth.miner.Resync()
+				eth.handler.chainSync.forceSync()
+			}()
+		})
+	}
 
 	eth.APIBackend = &EthAPIBackend{stack.Config().ExtRPCEnabled(), stack.Config().AllowUnprotectedTxs, eth, nil}
 	if eth.APIBackend.allowUnprotectedTxs {
@@ -484,7 +493,7 @@ func (s *Ethereum) StartMining(threads int) error {
 		s.txPool.SetGasPrice(price)
 
 		// Configure the local mining address
-		if _, err := s.Etherbase(); err != nil {
+		if _, err := s.Etherbase(); err != nil && !s.config.Miner.AllowZeroCoinbase {
 			s.log.Error("Cannot start mining without address", "err", err)
 			return fmt.Errorf("address missing: %v", err)
 		}
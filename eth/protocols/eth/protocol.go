@@ -31,6 +31,7 @@ import (
 // Constants to match up protocol versions and messages
 const (
 	ETH66 = 66
+	ETH67 = 67 // adds the tendermint announce and request messages
 )
 
 // ProtocolName is the official short name of the `eth` protocol used during
@@ -39,12 +40,12 @@ const ProtocolName = "aut"
 
 // ProtocolVersions are the supported versions of the `eth` protocol (first
 // is primary).
-var ProtocolVersions = []uint{ETH66}
+var ProtocolVersions = []uint{ETH67, ETH66}
 
 // protocolLengths are the number of implemented message corresponding to
 // different protocol versions.
 // var protocolLengths = map[uint]uint64{ETH66: 17}
-var protocolLengths = map[uint]uint64{ETH66: 22}
+var protocolLengths = map[uint]uint64{ETH67: 26, ETH66: 22}
 
 // MaxMessageSize is the maximum cap on the size of a protocol message.
 const MaxMessageSize = 10 * 1024 * 1024
@@ -70,6 +71,10 @@ const (
 	// 0x11 reserved for tendermintMsg
 	// 0x12 reserved for tendermintSyncMsg
 	// 0x13 reserved for TendermintOffChainAccountabilityMsg
+	// 0x16 reserved for tendermintProposalAnnounceMsg, from ETH67
+	// 0x17 reserved for tendermintProposalRequestMsg, from ETH67
+	// 0x18 reserved for tendermintVoteAnnounceMsg, from ETH67
+	// 0x19 reserved for tendermintVoteRequestMsg, from ETH67
 )
 
 var (
@@ -66,6 +66,7 @@ type chainSyncer struct {
 	force       *time.Timer
 	forced      bool // true when force timer fired
 	peerEventCh chan struct{}
+	forceCh     chan struct{}
 	doneCh      chan error // non-nil when sync is running
 }
 
@@ -82,6 +83,7 @@ func newChainSyncer(handler *handler) *chainSyncer {
 	return &chainSyncer{
 		handler:     handler,
 		peerEventCh: make(chan struct{}),
+		forceCh:     make(chan struct{}),
 	}
 }
 
@@ -97,6 +99,18 @@ func (cs *chainSyncer) handlePeerEvent(peer *eth.Peer) bool {
 	}
 }
 
+// forceSync makes the syncer start a sync with the best peer as soon as one is
+// available, without waiting for the force timer. It is used when consensus
+// detects that the node fell behind.
+func (cs *chainSyncer) forceSync() bool {
+	select {
+	case cs.forceCh <- struct{}{}:
+		return true
+	case <-cs.handler.quitSync:
+		return false
+	}
+}
+
 // loop runs in its own goroutine and launches the sync when necessary.
 func (cs *chainSyncer) loop() {
 	defer cs.handler.wg.Done()
@@ -125,6 +139,8 @@ func (cs *chainSyncer) loop() {
 			cs.forced = false
 		case <-cs.force.C:
 			cs.forced = true
+		case <-cs.forceCh:
+			cs.forced = true
 
 		case <-cs.handler.quitSync:
 			// Disable all insertion on the blockchain. This needs to happen before
@@ -239,4 +239,7 @@ type Peer interface {
 	Send(msgcode uint64, data interface{}) error
 
 	SendRaw(msgcode uint64, data []byte) error
+
+	// Version returns the negotiated version of the protocol
+	Version() uint
 }
@@ -537,6 +537,10 @@ web3._extend({
 			getter: 'eth_maxPriorityFeePerGas',
 			outputFormatter: web3._extend.utils.toBigNumber
 		}),
+		new web3._extend.Property({
+			name: 'miningStatus',
+			getter: 'eth_miningStatus'
+		}),
 	]
 });
 `
@@ -587,6 +591,16 @@ web3._extend({
 			name: 'getHashrate',
 			call: 'miner_getHashrate'
 		}),
+		new web3._extend.Method({
+			name: 'txSelectionReport',
+			call: 'miner_txSelectionReport',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'resetProposalCircuitBreaker',
+			call: 'miner_resetProposalCircuitBreaker'
+		}),
 	],
 	properties: []
 });
@@ -0,0 +1,57 @@
+package miner
+
+import (
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/types"
+)
+
+// agedTransactions records the block height at which each pending transaction was
+// first seen and forgets the ones which left the pool, unless record is false. It
+// returns, per account, the transactions which must be included regardless of their
+// tip because they, or one of their successors, have been pending for at least
+// MaxPendingBlocks blocks.
+//
+// It is only called from the main loop, hence the first seen heights aren't locked.
+func (w *worker) agedTransactions(pending map[common.Address]types.Transactions, number uint64, record bool) map[common.Address]types.Transactions {
+	firstSeen := make(map[common.Hash]uint64)
+	aged := make(map[common.Address]types.Transactions)
+	for account, txs := range pending {
+		last := -1
+		for i, tx := range txs {
+			seen, ok := w.txFirstSeen[tx.Hash()]
+			if !ok {
+				seen = number
+			}
+			firstSeen[tx.Hash()] = seen
+			if number >= seen && number-seen >= w.config.MaxPendingBlocks {
+				last = i
+			}
+		}
+		// nonce ordering requires including all the predecessors of an aged transaction
+		if last >= 0 {
+			aged[account] = txs[:last+1]
+		}
+	}
+	if record {
+		w.txFirstSeen = firstSeen
+	}
+	return aged
+}
+
+// withoutPrefixes returns the pending transactions left once the given leading ones
+// of each account are removed.
+func withoutPrefixes(pending, prefixes map[common.Address]types.Transactions) map[common.Address]types.Transactions {
+	for account, txs := range prefixes {
+		last := txs[len(txs)-1].Nonce()
+		remaining := pending[account]
+		for len(remaining) > 0 && remaining[0].Nonce() <= last {
+			remaining = remaining[1:]
+		}
+		if len(remaining) == 0 {
+			delete(pending, account)
+		} else {
+			pending[account] = remaining
+		}
+	}
+	return pending
+}
@@ -0,0 +1,33 @@
+package miner
+
+import (
+	"context"
+
+	"github.com/autonity/autonity/core/state"
+	"github.com/autonity/autonity/core/types"
+)
+
+// pendingReady returns the channel closed once the first pending snapshot is taken.
+// The caller must hold w.snapshotMu.
+func (w *worker) pendingReady() chan struct{} {
+	if w.snapshotReady == nil {
+		w.snapshotReady = make(chan struct{})
+	}
+	return w.snapshotReady
+}
+
+// awaitPending returns the pending block and state, waiting for the first pending
+// block to be built if needed.
+func (w *worker) awaitPending(ctx context.Context) (*types.Block, *state.StateDB, error) {
+	w.snapshotMu.Lock()
+	ready := w.pendingReady()
+	w.snapshotMu.Unlock()
+
+	select {
+	case <-ready:
+		block, state := w.pending()
+		return block, state, nil
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+}
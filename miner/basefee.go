@@ -0,0 +1,29 @@
+package miner
+
+import (
+	"math/big"
+
+	"github.com/autonity/autonity/consensus/misc"
+	"github.com/autonity/autonity/core/types"
+)
+
+// BaseFeeCalculator returns the base fee of the block built on the given parent
+// header. It allows experimenting with alternative fee markets without touching the
+// rest of the miner. The blocks built with a base fee departing from the protocol
+// formula are only valid on chains whose consensus rules agree with it.
+type BaseFeeCalculator func(parent *types.Header) *big.Int
+
+func (w *worker) setBaseFeeCalculator(calculator BaseFeeCalculator) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.baseFeeCalculator = calculator
+}
+
+// baseFee returns the base fee of the block built on the parent, using the custom
+// calculator if any and the EIP-1559 formula otherwise. It assumes the lock held.
+func (w *worker) baseFee(parent *types.Header) *big.Int {
+	if w.baseFeeCalculator != nil {
+		return w.baseFeeCalculator(parent)
+	}
+	return misc.CalcBaseFee(w.chainConfig, parent, w.chain)
+}
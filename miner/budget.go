@@ -0,0 +1,55 @@
+package miner
+
+import (
+	"errors"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/types"
+)
+
+// errBudgetExceeded is reported for the transactions left out because the budget
+// of their class is used up.
+var errBudgetExceeded = errors.New("transaction class budget exceeded")
+
+// txBudget limits the gas used per block by a class of transactions, on top of the
+// block gas limit shared by all of them. The class is filled in a dedicated pass,
+// so that its transactions are neither crowded out by the others nor crowd them out
+// beyond the budget. It is meant for the large transactions, such as blob carrying
+// ones.
+type txBudget struct {
+	member func(tx *types.Transaction) bool // whether the transaction belongs to the class
+	limit  uint64                           // gas available to the class per block
+}
+
+// newLargeTxBudget returns the budget of the large transactions, nil if disabled.
+func newLargeTxBudget(config *Config) *txBudget {
+	if config.LargeTxDataSize == 0 || config.LargeTxGasLimit == 0 {
+		return nil
+	}
+	size := config.LargeTxDataSize
+	return &txBudget{
+		member: func(tx *types.Transaction) bool { return uint64(len(tx.Data())) >= size },
+		limit:  config.LargeTxGasLimit,
+	}
+}
+
+// allows returns whether the transaction fits in the budget given the gas already
+// used by the class.
+func (b *txBudget) allows(tx *types.Transaction, used uint64) bool {
+	return used+tx.Gas() <= b.limit
+}
+
+// leading returns, per account, the leading pending transactions of the class.
+func (b *txBudget) leading(pending map[common.Address]types.Transactions) map[common.Address]types.Transactions {
+	members := make(map[common.Address]types.Transactions)
+	for account, txs := range pending {
+		n := 0
+		for n < len(txs) && b.member(txs[n]) {
+			n++
+		}
+		if n > 0 {
+			members[account] = txs[:n]
+		}
+	}
+	return members
+}
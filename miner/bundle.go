@@ -0,0 +1,221 @@
+package miner
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/types"
+)
+
+var (
+	// errEmptyBundle is returned when submitting a bundle without any transaction.
+	errEmptyBundle = errors.New("empty bundle")
+
+	// errInvalidBundle is returned when submitting a bundle which could never be included.
+	errInvalidBundle = errors.New("invalid bundle")
+
+	// errBundleQueueFull is returned when submitting a bundle while maxPendingBundles are
+	// already waiting for inclusion.
+	errBundleQueueFull = errors.New("bundle queue full")
+
+	// errBundleReverted is reported for every member of a bundle which couldn't be
+	// included as a whole.
+	errBundleReverted = errors.New("bundle reverted")
+)
+
+// maxPendingBundles is the maximum number of bundles waiting for inclusion.
+const maxPendingBundles = 64
+
+// bundle is an ordered set of transactions which must be included all together
+// in the given order, or not at all.
+type bundle struct {
+	txs types.Transactions
+}
+
+// tip returns the total tip offered by the bundle on top of the given base fee,
+// assuming each transaction consumes all of its gas.
+func (b *bundle) tip(baseFee *big.Int) *big.Int {
+	total := new(big.Int)
+	for _, tx := range b.txs {
+		tip, err := tx.EffectiveGasTip(baseFee)
+		if err != nil {
+			// the transaction is underpriced, the bundle can't be included.
+			return new(big.Int)
+		}
+		total.Add(total, tip.Mul(tip, new(big.Int).SetUint64(tx.Gas())))
+	}
+	return total
+}
+
+// addBundle queues a bundle of transactions for inclusion in the next sealing blocks.
+func (w *worker) addBundle(txs types.Transactions) error {
+	if len(txs) == 0 {
+		return errEmptyBundle
+	}
+	if err := w.validateBundle(txs); err != nil {
+		return err
+	}
+	cpy := make(types.Transactions, len(txs))
+	copy(cpy, txs)
+
+	w.bundlesMu.Lock()
+	defer w.bundlesMu.Unlock()
+	if len(w.bundles) >= maxPendingBundles {
+		return errBundleQueueFull
+	}
+	w.bundles = append(w.bundles, &bundle{txs: cpy})
+	return nil
+}
+
+// validateBundle refuses a bundle which can't be included on top of the current head: a
+// transaction which isn't properly signed, duplicated or already mined, or more gas than
+// fits in a block.
+func (w *worker) validateBundle(txs types.Transactions) error {
+	head := w.chain.CurrentBlock()
+	state, err := w.chain.StateAt(head.Root())
+	if err != nil {
+		return err
+	}
+	var (
+		signer = types.MakeSigner(w.chainConfig, head.Number())
+		seen   = make(map[common.Hash]struct{}, len(txs))
+		nonces = make(map[common.Address]uint64)
+		gas    uint64
+	)
+	for _, tx := range txs {
+		if _, ok := seen[tx.Hash()]; ok {
+			return fmt.Errorf("%w: duplicate transaction %s", errInvalidBundle, tx.Hash().Hex())
+		}
+		seen[tx.Hash()] = struct{}{}
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			return fmt.Errorf("%w: %v", errInvalidBundle, err)
+		}
+		nonce, ok := nonces[from]
+		if !ok {
+			nonce = state.GetNonce(from)
+		}
+		if tx.Nonce() < nonce {
+			return fmt.Errorf("%w: %w", errInvalidBundle, core.ErrNonceTooLow)
+		}
+		nonces[from] = tx.Nonce() + 1
+		gas += tx.Gas()
+	}
+	if gas > head.GasLimit() {
+		return fmt.Errorf("%w: %d gas above the block gas limit %d", errInvalidBundle, gas, head.GasLimit())
+	}
+	return nil
+}
+
+// pendingBundles returns the queued bundles ordered by decreasing total tip.
+func (w *worker) pendingBundles(baseFee *big.Int) []*bundle {
+	w.bundlesMu.RLock()
+	bundles := make([]*bundle, len(w.bundles))
+	copy(bundles, w.bundles)
+	w.bundlesMu.RUnlock()
+
+	tips := make(map[*bundle]*big.Int, len(bundles))
+	for _, b := range bundles {
+		tips[b] = b.tip(baseFee)
+	}
+	sort.SliceStable(bundles, func(i, j int) bool {
+		return tips[bundles[i]].Cmp(tips[bundles[j]]) > 0
+	})
+	return bundles
+}
+
+// dropBundle removes a bundle from the queue.
+func (w *worker) dropBundle(target *bundle) {
+	w.bundlesMu.Lock()
+	defer w.bundlesMu.Unlock()
+	for i, b := range w.bundles {
+		if b == target {
+			w.bundles = append(w.bundles[:i], w.bundles[i+1:]...)
+			return
+		}
+	}
+}
+
+// dropIncludedBundles removes from the queue the bundles having a transaction included in
+// the given block.
+func (w *worker) dropIncludedBundles(block *types.Block) {
+	w.bundlesMu.Lock()
+	defer w.bundlesMu.Unlock()
+	if len(w.bundles) == 0 {
+		return
+	}
+	included := make(map[common.Hash]struct{}, len(block.Transactions()))
+	for _, tx := range block.Transactions() {
+		included[tx.Hash()] = struct{}{}
+	}
+	bundles := w.bundles[:0]
+	for _, b := range w.bundles {
+		keep := true
+		for _, tx := range b.txs {
+			if _, ok := included[tx.Hash()]; ok {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			bundles = append(bundles, b)
+		}
+	}
+	w.bundles = bundles
+}
+
+// commitBundles applies the queued bundles to the given environment, highest tip first.
+// Each bundle is executed as a unit: if any of its transactions fails or reverts, every
+// change made by the bundle is rolled back and the bundle is dropped from the queue,
+// unless it only missed room in this block.
+func (w *worker) commitBundles(env *environment) {
+	if env.gasPool == nil {
+		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+	}
+	for _, b := range w.pendingBundles(env.header.BaseFee) {
+		if w.pastDeadline(env) {
+			return
+		}
+		err := w.commitBundle(env, b)
+		switch {
+		case err == nil:
+			w.eth.Logger().Debug("Committed transaction bundle", "txs", len(b.txs))
+		case errors.Is(err, core.ErrGasLimitReached):
+			w.eth.Logger().Debug("Transaction bundle doesn't fit in the block", "err", err)
+		default:
+			if env.simulated {
+				continue
+			}
+			w.eth.Logger().Debug("Dropping failed transaction bundle", "err", err)
+			w.dropBundle(b)
+		}
+	}
+}
+
+// commitBundle applies all the transactions of the bundle or none of them.
+func (w *worker) commitBundle(env *environment, b *bundle) error {
+	cp := env.checkpoint()
+	revert := func(err error) error {
+		env.rollback(cp)
+		for _, tx := range b.txs {
+			env.rejected = append(env.rejected, TxRejection{Hash: tx.Hash(), Reason: fmt.Errorf("%w: %w", errBundleReverted, err)})
+		}
+		return err
+	}
+	for _, tx := range b.txs {
+		env.state.Prepare(tx.Hash(), env.tcount)
+		if _, err := w.commitTransaction(env, tx); err != nil {
+			return revert(err)
+		}
+		if receipt := env.receipts[len(env.receipts)-1]; receipt.Status == types.ReceiptStatusFailed {
+			return revert(fmt.Errorf("transaction %s reverted", tx.Hash().Hex()))
+		}
+		env.tcount++
+	}
+	env.release(cp)
+	return nil
+}
@@ -0,0 +1,46 @@
+package miner
+
+import (
+	"sync"
+
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/event"
+)
+
+// headerFeed delivers the chain heads the worker rebuilds on. Unlike event.Feed, a
+// delivery never blocks the worker loop: a subscriber which isn't ready to receive
+// misses it.
+type headerFeed struct {
+	mu          sync.Mutex
+	subscribers []chan<- *types.Header
+}
+
+func (f *headerFeed) subscribe(ch chan<- *types.Header) event.Subscription {
+	f.mu.Lock()
+	f.subscribers = append(f.subscribers, ch)
+	f.mu.Unlock()
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		for i, sub := range f.subscribers {
+			if sub == ch {
+				f.subscribers = append(f.subscribers[:i], f.subscribers[i+1:]...)
+				break
+			}
+		}
+		return nil
+	})
+}
+
+func (f *headerFeed) send(header *types.Header) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, ch := range f.subscribers {
+		select {
+		case ch <- header:
+		default:
+			ChainHeadDroppedMeter.Mark(1)
+		}
+	}
+}
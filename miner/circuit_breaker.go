@@ -0,0 +1,42 @@
+package miner
+
+import "github.com/autonity/autonity/consensus"
+
+// MiningStatus tells whether the node is producing blocks, and if not because of a fault,
+// why.
+type MiningStatus struct {
+	Mining          bool `json:"mining"`          // the miner is running
+	ProposingHalted bool `json:"proposingHalted"` // the consensus engine stopped proposing after its own proposals repeatedly failed verification
+}
+
+// configureCircuitBreaker sets the proposal circuit breaker threshold of the engine, if it
+// has one.
+func configureCircuitBreaker(engine consensus.Engine, threshold int) {
+	breaker, ok := engine.(consensus.ProposalCircuitBreaker)
+	if !ok || threshold == 0 {
+		return
+	}
+	if threshold < 0 {
+		threshold = 0
+	}
+	breaker.SetProposalCircuitBreakerThreshold(threshold)
+}
+
+// MiningStatus returns whether the node is producing blocks. A running miner may still
+// not propose any block if the consensus engine halted proposing, which the operator
+// must investigate and resume with ResetProposalCircuitBreaker.
+func (miner *Miner) MiningStatus() MiningStatus {
+	status := MiningStatus{Mining: miner.Mining()}
+	if breaker, ok := miner.engine.(consensus.ProposalCircuitBreaker); ok {
+		status.ProposingHalted = breaker.ProposingHalted()
+	}
+	return status
+}
+
+// ResetProposalCircuitBreaker resumes proposing after the consensus engine halted it,
+// once the underlying fault is fixed.
+func (miner *Miner) ResetProposalCircuitBreaker() {
+	if breaker, ok := miner.engine.(consensus.ProposalCircuitBreaker); ok {
+		breaker.ResetProposalCircuitBreaker()
+	}
+}
@@ -0,0 +1,53 @@
+package miner
+
+import (
+	"sort"
+
+	"github.com/autonity/autonity/common"
+)
+
+// coinbaseChange switches the fee recipient from the block at the given height on,
+// e.g. once a key rotation is finalized on-chain.
+type coinbaseChange struct {
+	height   uint64
+	coinbase common.Address
+}
+
+// setCoinbaseForHeight schedules a coinbase change. The changes scheduled for the
+// same height apply in the order they were scheduled, the last one winning.
+func (w *worker) setCoinbaseForHeight(height uint64, coinbase common.Address) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	i := sort.Search(len(w.coinbaseChanges), func(i int) bool { return w.coinbaseChanges[i].height > height })
+	w.coinbaseChanges = append(w.coinbaseChanges, coinbaseChange{})
+	copy(w.coinbaseChanges[i+1:], w.coinbaseChanges[i:])
+	w.coinbaseChanges[i] = coinbaseChange{height: height, coinbase: coinbase}
+}
+
+// coinbaseAt returns the coinbase of the block at the given height, the one of the
+// last change due if any. The caller must hold w.mu.
+func (w *worker) coinbaseAt(height uint64) common.Address {
+	coinbase := w.coinbase
+	for _, change := range w.coinbaseChanges {
+		if change.height > height {
+			break
+		}
+		coinbase = change.coinbase
+	}
+	return coinbase
+}
+
+// applyCoinbaseChanges makes the changes due at the given height permanent and
+// returns the coinbase of the block at that height.
+func (w *worker) applyCoinbaseChanges(height uint64) common.Address {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	coinbase := w.coinbaseAt(height)
+	if coinbase != w.coinbase {
+		w.eth.Logger().Info("Switching to scheduled coinbase", "number", height, "old", w.coinbase, "new", coinbase)
+		w.coinbase = coinbase
+	}
+	due := sort.Search(len(w.coinbaseChanges), func(i int) bool { return w.coinbaseChanges[i].height > height })
+	w.coinbaseChanges = w.coinbaseChanges[due:]
+	return coinbase
+}
@@ -0,0 +1,34 @@
+package miner
+
+import (
+	"github.com/autonity/autonity/core/types"
+)
+
+// CommitHook inspects a block fully assembled by the worker before it is submitted
+// for sealing, e.g. to enforce a compliance policy or log its content. The block and
+// its receipts must not be modified. Returning an error vetoes the sealing of the
+// block, the worker builds the next one as usual.
+type CommitHook func(block *types.Block, receipts types.Receipts) error
+
+func (w *worker) setCommitHook(hook CommitHook) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.commitHook = hook
+}
+
+// runCommitHook submits the assembled block to the commit hook, if any.
+func (w *worker) runCommitHook(block *types.Block, receipts types.Receipts) error {
+	w.mu.RLock()
+	hook := w.commitHook
+	w.mu.RUnlock()
+
+	if hook == nil {
+		return nil
+	}
+	// the hook gets its own slice, the receipts being shared with the sealing task
+	if err := hook(block, append(types.Receipts(nil), receipts...)); err != nil {
+		CommitHookVetoMeter.Mark(1)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package miner
+
+import (
+	"time"
+)
+
+// setDeadline bounds the time spent filling the block with transactions, starting
+// now, if an assembly deadline is configured.
+func (w *worker) setDeadline(env *environment) {
+	w.mu.RLock()
+	deadline := w.config.AssemblyDeadline
+	w.mu.RUnlock()
+	if deadline > 0 && env.deadline.IsZero() {
+		env.deadline = time.Now().Add(deadline)
+	}
+}
+
+// pastDeadline reports whether the assembly deadline of the block passed, in which
+// case no further transaction is applied.
+func (w *worker) pastDeadline(env *environment) bool {
+	if env.deadline.IsZero() || time.Now().Before(env.deadline) {
+		return false
+	}
+	if !env.deadlineHit {
+		env.deadlineHit = true
+		AssemblyDeadlineMeter.Mark(1)
+		w.eth.Logger().Debug("Block assembly deadline reached", "number", env.header.Number, "txs", env.tcount)
+	}
+	return true
+}
@@ -0,0 +1,40 @@
+package miner
+
+import (
+	"errors"
+
+	"github.com/autonity/autonity/common"
+)
+
+// errDeniedSender is reported for the transactions left out because their sender
+// is denied.
+var errDeniedSender = errors.New("transaction sender denied")
+
+// senderDenylist is the set of senders whose transactions are never included in
+// the blocks built locally. It's a shorthand for the most common filtering need,
+// excluding known abusive accounts.
+type senderDenylist map[common.Address]struct{}
+
+func newSenderDenylist(senders []common.Address) senderDenylist {
+	if len(senders) == 0 {
+		return nil
+	}
+	denylist := make(senderDenylist, len(senders))
+	for _, sender := range senders {
+		denylist[sender] = struct{}{}
+	}
+	return denylist
+}
+
+// contains returns whether the sender is denied.
+func (d senderDenylist) contains(sender common.Address) bool {
+	_, ok := d[sender]
+	return ok
+}
+
+func (w *worker) setSenderDenylist(senders []common.Address) {
+	denylist := newSenderDenylist(senders)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.deniedSenders = denylist
+}
@@ -0,0 +1,48 @@
+package miner
+
+import (
+	"sync"
+
+	"github.com/autonity/autonity/core/types"
+)
+
+// recentBlockFullnessSize is the number of sealed blocks whose fullness is retained.
+const recentBlockFullnessSize = 128
+
+// blockFullness records the gas used to gas limit ratios of the last blocks sealed
+// locally, in a ring buffer.
+type blockFullness struct {
+	mu     sync.Mutex
+	ratios [recentBlockFullnessSize]float64
+	next   int // Index of the next ratio to be written
+	count  int // Number of ratios recorded, up to recentBlockFullnessSize
+}
+
+// record adds the fullness of a sealed block and updates the block histograms.
+func (f *blockFullness) record(block *types.Block) {
+	SealedBlockTxsHistogram.Update(int64(len(block.Transactions())))
+	SealedBlockGasHistogram.Update(int64(block.GasUsed()))
+
+	var ratio float64
+	if block.GasLimit() > 0 {
+		ratio = float64(block.GasUsed()) / float64(block.GasLimit())
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ratios[f.next] = ratio
+	f.next = (f.next + 1) % recentBlockFullnessSize
+	if f.count < recentBlockFullnessSize {
+		f.count++
+	}
+}
+
+// recent returns the recorded ratios, from the oldest to the latest sealed block.
+func (f *blockFullness) recent() []float64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ratios := make([]float64, 0, f.count)
+	for i := f.next - f.count; i < f.next; i++ {
+		ratios = append(ratios, f.ratios[(i+recentBlockFullnessSize)%recentBlockFullnessSize])
+	}
+	return ratios
+}
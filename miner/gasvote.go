@@ -0,0 +1,83 @@
+package miner
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/params"
+)
+
+// gasVoteWindow is the number of recent blocks whose gas limit votes are aggregated.
+const gasVoteWindow = 32
+
+// gasVoteMarker prefixes the gas limit vote embedded at the end of the block extra
+// data, followed by the voted limit as a big endian uint64.
+var gasVoteMarker = []byte("glv")
+
+var gasVoteSize = len(gasVoteMarker) + 8
+
+// appendGasVote returns the extra data carrying the gas limit vote. The extra data
+// is trimmed if needed to make room for the vote within the maximum size.
+func appendGasVote(extra []byte, limit uint64) []byte {
+	if room := int(params.MaximumExtraDataSize) - gasVoteSize; len(extra) > room {
+		extra = extra[:room]
+	}
+	vote := make([]byte, 0, len(extra)+gasVoteSize)
+	vote = append(vote, extra...)
+	vote = append(vote, gasVoteMarker...)
+	return binary.BigEndian.AppendUint64(vote, limit)
+}
+
+// gasVote returns the gas limit voted for in the extra data, if any.
+func gasVote(extra []byte) (uint64, bool) {
+	if len(extra) < gasVoteSize {
+		return 0, false
+	}
+	vote := extra[len(extra)-gasVoteSize:]
+	if !bytes.HasPrefix(vote, gasVoteMarker) {
+		return 0, false
+	}
+	limit := binary.BigEndian.Uint64(vote[len(gasVoteMarker):])
+	return limit, limit != 0
+}
+
+// votedGasLimit returns the median of the gas limit votes of the recent blocks up
+// to the given one, false if none voted.
+func (w *worker) votedGasLimit(head *types.Header) (uint64, bool) {
+	var votes []uint64
+	for header := head; header != nil && head.Number.Uint64()-header.Number.Uint64() < gasVoteWindow; header = w.chain.GetHeader(header.ParentHash, header.Number.Uint64()-1) {
+		if limit, ok := gasVote(header.Extra); ok {
+			votes = append(votes, limit)
+		}
+		if header.Number.Sign() == 0 {
+			break
+		}
+	}
+	if len(votes) == 0 {
+		return 0, false
+	}
+	sort.Slice(votes, func(i, j int) bool { return votes[i] < votes[j] })
+	return votes[len(votes)/2], true
+}
+
+// gasLimitTarget returns the gas limit the block following the parent strives for,
+// the one voted by the recent blocks if voting is enabled, the gas ceil otherwise.
+// The caller must hold w.mu.
+func (w *worker) gasLimitTarget(parent *types.Header) uint64 {
+	if w.config.GasLimitVote != 0 {
+		if limit, ok := w.votedGasLimit(parent); ok {
+			return limit
+		}
+	}
+	return w.gasCeil(parent.Number.Uint64() + 1)
+}
+
+// setGasLimitVote sets the gas limit voted for in the blocks built, zero disables
+// the voting.
+func (w *worker) setGasLimitVote(limit uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.config.GasLimitVote = limit
+}
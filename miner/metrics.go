@@ -28,6 +28,7 @@ var (
 	SealWorkTimer     = metrics.NewRegisteredTimer("miner/work/seal", nil)     // time to seal block (taskloop, waits for timestamp to be ripe and then submits to consensus engine)
 	CopyWorkTimer     = metrics.NewRegisteredTimer("miner/work/copy", nil)     // time to do task deep copy (see worker ResultLoop()).
 	PersistWorkTimer  = metrics.NewRegisteredTimer("miner/work/persist", nil)  // time to writeBlockAndSetHead
+	SealedHeadTimer   = metrics.NewRegisteredTimer("miner/sealed/head", nil)   // time from sealing a block locally to observing it as chain head
 
 	// instant metrics
 
@@ -38,4 +39,18 @@ var (
 	SealWorkBg     = metrics.NewRegisteredBufferedGauge("miner/work/seal.bg", nil)     // time to seal block (taskloop, waits for timestamp to be ripe and then submits to consensus engine)
 	CopyWorkBg     = metrics.NewRegisteredBufferedGauge("miner/work/copy.bg", nil)     // time to do task deep copy (see worker ResultLoop()).
 	PersistWorkBg  = metrics.NewRegisteredBufferedGauge("miner/work/persist.bg", nil)  // time to writeBlockAndSetHead
+
+	ExtraDataNearLimitMeter = metrics.NewRegisteredMeter("miner/extra/nearlimit", nil)     // extra data set above the warning threshold
+	PendingLogsDroppedMeter = metrics.NewRegisteredMeter("miner/pendinglogs/dropped", nil) // pending logs deliveries missed by slow subscribers
+	PendingLogsTrimmedMeter = metrics.NewRegisteredMeter("miner/pendinglogs/trimmed", nil) // pending logs dropped, oldest first, from the buffer of slow subscribers
+	SealedBlockDroppedMeter = metrics.NewRegisteredMeter("miner/sealed/dropped", nil)      // sealed block deliveries missed by slow subscribers
+	ChainHeadDroppedMeter   = metrics.NewRegisteredMeter("miner/chainhead/dropped", nil)   // chain head deliveries missed by slow subscribers
+	PendingTaskEvictedMeter = metrics.NewRegisteredMeter("miner/pending/evicted", nil)     // sealing tasks dropped with their state to stay within the pending block limit
+	TxResultHitMeter        = metrics.NewRegisteredMeter("miner/txresults/hit", nil)       // transactions replayed from a cached execution
+	TxResultMissMeter       = metrics.NewRegisteredMeter("miner/txresults/miss", nil)      // transactions executed despite the execution cache
+	AssemblyDeadlineMeter   = metrics.NewRegisteredMeter("miner/work/deadline", nil)       // blocks whose filling was cut short by the assembly deadline
+	CommitHookVetoMeter     = metrics.NewRegisteredMeter("miner/commit/veto", nil)         // assembled blocks not sealed as vetoed by the commit hook
+
+	SealedBlockTxsHistogram = metrics.NewRegisteredHistogram("miner/sealed/txs", nil, metrics.NewExpDecaySample(1028, 0.015)) // transactions count of the blocks sealed locally
+	SealedBlockGasHistogram = metrics.NewRegisteredHistogram("miner/sealed/gas", nil, metrics.NewExpDecaySample(1028, 0.015)) // gas used by the blocks sealed locally
 )
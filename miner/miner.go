@@ -18,6 +18,7 @@
 package miner
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 	"sync"
@@ -55,31 +56,67 @@ type Config struct {
 	GasPrice   *big.Int       // Minimum gas price for mining a transaction
 	Recommit   time.Duration  // The time interval for miner to re-create mining work.
 	Noverify   bool           // Disable remote mining solution verification(only useful in ethash).
+
+	AllowZeroCoinbase bool // Build blocks with a zero coinbase if no etherbase is set, e.g. when the protocol contract handles the rewards
+
+	GasLimitStepDivisor uint64 // Bound divisor of the gas limit adjustment toward GasCeil (default = protocol bound divisor)
+	GasLimitVote        uint64 // Gas limit voted for in the extra data, the gas limit then tracks the median vote of the recent blocks (0 = disabled)
+	MaxPendingBlocks    uint64 // Include transactions pending for this number of blocks regardless of their tip (0 = disabled)
+	ReservedSystemGas   uint64 // Gas reserved in each block for the system transactions, see SystemTxSenders
+
+	MinTipBaseFeeFraction float64 // Minimum tip of remote transactions as a fraction of the block base fee (0 = disabled)
+
+	ProduceEmptyBlocks bool          // Build an empty block as soon as the chain is idle for EmptyBlockInterval
+	EmptyBlockInterval time.Duration // Idle time before building an empty block (default = 1s)
+
+	MaxGasPerTx uint64 // Maximum gas limit of a transaction included in a block, the others are left in the pool (0 = unlimited)
+
+	SkipRevertedTxs bool // Leave out the transactions whose execution reverts instead of including them with a failed receipt
+
+	AssemblyDeadline time.Duration // Maximum time spent filling a block with transactions, the block is sealed with those committed so far (0 = unlimited)
+	MaxBlockTxs      int           // Maximum number of transactions included in a block (0 = unlimited)
+
+	LargeTxDataSize uint64 // Minimum data size of the large transactions, filled against their own gas budget (0 = disabled)
+	LargeTxGasLimit uint64 // Gas available to the large transactions in each block (0 = disabled)
+
+	PendingBlockLimit int // Maximum number of sealing blocks retained with their state until sealed (0 = unlimited)
+	TxResultCacheSize int // Number of transaction executions kept to be replayed when rebuilding a block on the same parent (0 = disabled)
+
+	MaxPendingLogSubscribers      int  // Maximum number of pending logs subscribers (0 = unlimited)
+	DropSlowPendingLogSubscribers bool // Evict the subscriber which missed the most deliveries instead of refusing new ones once at the limit
+	MaxBufferedPendingLogs        int  // Maximum number of pending logs buffered per slow subscriber, the oldest dropped first (0 = no buffering, the deliveries are missed)
+
+	SystemTxSenders []common.Address // Trusted senders whose calls to the protocol contracts are system transactions, placed first and allowed the reserved gas
+
+	ProposalCircuitBreakerThreshold int // Consecutive failures to verify our own proposals after which the consensus engine stops proposing (0 = engine default, negative = disabled)
 }
 
 // Miner creates blocks and searches for proof-of-work values.
 type Miner struct {
-	mux     *event.TypeMux
-	worker  *worker
-	eth     Backend
-	engine  consensus.Engine
-	exitCh  chan struct{}
-	startCh chan struct{}
-	stopCh  chan struct{}
+	mux      *event.TypeMux
+	worker   *worker
+	eth      Backend
+	engine   consensus.Engine
+	exitCh   chan struct{}
+	startCh  chan struct{}
+	stopCh   chan struct{}
+	resyncCh chan chan struct{}
 
 	wg sync.WaitGroup
 }
 
 func New(eth Backend, config *Config, chainConfig *params.ChainConfig, mux *event.TypeMux, engine consensus.Engine, isLocalBlock func(header *types.Header) bool) *Miner {
 	miner := &Miner{
-		eth:     eth,
-		mux:     mux,
-		engine:  engine,
-		exitCh:  make(chan struct{}),
-		startCh: make(chan struct{}),
-		stopCh:  make(chan struct{}),
-		worker:  newWorker(config, chainConfig, engine, eth, mux, isLocalBlock, true),
+		eth:      eth,
+		mux:      mux,
+		engine:   engine,
+		exitCh:   make(chan struct{}),
+		startCh:  make(chan struct{}),
+		stopCh:   make(chan struct{}),
+		resyncCh: make(chan chan struct{}),
+		worker:   newWorker(config, chainConfig, engine, eth, mux, isLocalBlock, true),
 	}
+	configureCircuitBreaker(engine, config.ProposalCircuitBreakerThreshold)
 	miner.wg.Add(1)
 	go miner.update()
 	return miner
@@ -112,6 +149,7 @@ func (miner *Miner) update() {
 			}
 			switch ev.Data.(type) {
 			case downloader.StartEvent:
+				miner.setSyncing(true)
 				wasMining := miner.Mining()
 				miner.worker.stop()
 				canStart = false
@@ -125,14 +163,23 @@ func (miner *Miner) update() {
 				if shouldStart {
 					miner.worker.start()
 				}
+				miner.setSyncing(false)
 			case downloader.DoneEvent:
 				canStart = true
 				if shouldStart {
 					miner.worker.start()
 				}
+				miner.setSyncing(false)
 				// Stop reacting to downloader events
 				events.Unsubscribe()
 			}
+		case done := <-miner.resyncCh:
+			// Follow the downloader events again until the requested resync completes
+			if events.Closed() {
+				events = miner.mux.Subscribe(downloader.StartEvent{}, downloader.DoneEvent{}, downloader.FailedEvent{})
+				dlEventCh = events.Chan()
+			}
+			close(done)
 		case <-miner.startCh:
 			if canStart {
 				miner.worker.start()
@@ -148,12 +195,34 @@ func (miner *Miner) update() {
 	}
 }
 
+// setSyncing notifies the consensus engine, if it cares, that the chain is being synced.
+func (miner *Miner) setSyncing(syncing bool) {
+	if engine, ok := miner.engine.(consensus.SyncAware); ok {
+		engine.SetSyncing(syncing)
+	}
+}
+
 // Start starts the miner mining, unless it has been paused by the downloader
 // during sync, in which case it will start mining once the sync has completed.
 func (miner *Miner) Start() {
 	miner.startCh <- struct{}{}
 }
 
+// Resync makes the miner pause during the next sync again, as it does during the
+// initial one. It is called before the consensus engine forces a resync.
+func (miner *Miner) Resync() {
+	done := make(chan struct{})
+	select {
+	case miner.resyncCh <- done:
+	case <-miner.exitCh:
+		return
+	}
+	select {
+	case <-done:
+	case <-miner.exitCh:
+	}
+}
+
 func (miner *Miner) Stop() {
 	miner.stopCh <- struct{}{}
 }
@@ -174,17 +243,46 @@ func (miner *Miner) Hashrate() uint64 {
 	return 0
 }
 
+// extraDataWarnSize is the extra data size above which operators are warned that
+// little room is left should the protocol embed additional bytes.
+const extraDataWarnSize = params.MaximumExtraDataSize * 4 / 5
+
 func (miner *Miner) SetExtra(extra []byte) error {
 	if uint64(len(extra)) > params.MaximumExtraDataSize {
 		return fmt.Errorf("extra exceeds max length. %d > %v", len(extra), params.MaximumExtraDataSize)
 	}
+	if uint64(len(extra)) > extraDataWarnSize {
+		ExtraDataNearLimitMeter.Mark(1)
+		miner.eth.Logger().Warn("Miner extra data close to limit", "size", len(extra), "threshold", extraDataWarnSize, "limit", params.MaximumExtraDataSize)
+	}
 	miner.worker.setExtra(extra)
 	return nil
 }
 
-// SetRecommitInterval sets the interval for sealing work resubmitting.
-func (miner *Miner) SetRecommitInterval(interval time.Duration) {
-	miner.worker.setRecommitInterval(interval)
+// SetRecommitInterval sets the interval for sealing work resubmitting. Intervals
+// below one second are raised to it, an error is returned if it is not positive.
+func (miner *Miner) SetRecommitInterval(interval time.Duration) error {
+	return miner.worker.setRecommitInterval(interval)
+}
+
+// SetAssemblyDeadline bounds the time spent filling a block with transactions,
+// the block is sealed with those committed so far. Zero removes the bound.
+func (miner *Miner) SetAssemblyDeadline(deadline time.Duration) {
+	miner.worker.setAssemblyDeadline(deadline)
+}
+
+// SetMaxBlockTxs bounds the number of transactions included in a block. Zero
+// removes the bound.
+func (miner *Miner) SetMaxBlockTxs(max int) {
+	miner.worker.setMaxBlockTxs(max)
+}
+
+// SetBuildProfile sets the recommit interval, the assembly deadline and the maximum
+// number of transactions per block at once from a preset trading the block latency
+// for its fullness, see BuildProfile. The parameters can still be adjusted
+// individually afterwards.
+func (miner *Miner) SetBuildProfile(profile BuildProfile) error {
+	return miner.worker.setBuildProfile(profile)
 }
 
 // Pending returns the currently pending block and associated state.
@@ -192,6 +290,13 @@ func (miner *Miner) Pending() (*types.Block, *state.StateDB) {
 	return miner.worker.pending()
 }
 
+// AwaitPending returns the pending block and state like Pending, but waits for the
+// first pending block to be built instead of returning nil, e.g. right after startup.
+// It returns the context error if the context is done first.
+func (miner *Miner) AwaitPending(ctx context.Context) (*types.Block, *state.StateDB, error) {
+	return miner.worker.awaitPending(ctx)
+}
+
 // PendingBlock returns the currently pending block.
 //
 // Note, to access both the pending block and the pending state
@@ -212,6 +317,92 @@ func (miner *Miner) SetGasCeil(ceil uint64) {
 	miner.worker.setGasCeil(ceil)
 }
 
+// SetPendingBlockLimit bounds the number of blocks retained with their state while
+// being sealed. Frequent recommits leave a block behind each, the oldest ones are
+// evicted beyond the limit. Zero removes the bound.
+func (miner *Miner) SetPendingBlockLimit(limit int) {
+	miner.worker.setPendingBlockLimit(limit)
+}
+
+// SetTxPrioritizer sets the order in which the pending transactions are included
+// in the sealing blocks. Passing nil restores the default tip based order.
+func (miner *Miner) SetTxPrioritizer(prioritizer TxPrioritizer) {
+	miner.worker.setTxPrioritizer(prioritizer)
+}
+
+// SetSystemTxProvider sets the provider of the protocol transactions placed at the
+// top of every block built, before any transaction of the pool. An error of the
+// provider aborts the build. Passing nil disables it.
+func (miner *Miner) SetSystemTxProvider(provider SystemTxProvider) {
+	miner.worker.setSystemTxProvider(provider)
+}
+
+// SetSystemTxSenders sets the trusted senders, such as the local oracle and node
+// accounts, whose calls to the protocol contracts are system transactions: placed
+// first in the blocks and allowed the reserved system gas. The calls of any other
+// sender are user transactions. Passing an empty list leaves only the transactions
+// of the system transaction provider as system ones.
+func (miner *Miner) SetSystemTxSenders(senders []common.Address) {
+	miner.worker.setSystemTxSenders(senders)
+}
+
+// SetRewardSplitter sets the redistribution of the reward earned by the coinbase
+// in each block built, the split transfers are appended to the block. Passing nil
+// leaves the whole reward to the coinbase.
+func (miner *Miner) SetRewardSplitter(splitter RewardSplitter) {
+	miner.worker.setRewardSplitter(splitter)
+}
+
+// SetCommitHook sets the hook inspecting every block assembled before it is sealed,
+// with its receipts. An error of the hook vetoes the sealing of the block. Passing
+// nil disables it.
+func (miner *Miner) SetCommitHook(hook CommitHook) {
+	miner.worker.setCommitHook(hook)
+}
+
+// SetSenderDenylist excludes the transactions of the given senders from the blocks
+// built by this node. They are left in the pool, to be included by the others.
+// Passing an empty list clears it.
+func (miner *Miner) SetSenderDenylist(senders []common.Address) {
+	miner.worker.setSenderDenylist(senders)
+}
+
+// SetPriorityAccounts places the transactions of the given senders at the front of
+// the blocks built by this node, after the system transactions, regardless of their
+// tip, e.g. to guarantee the timely inclusion of an oracle feeder. Passing an empty
+// list clears them.
+func (miner *Miner) SetPriorityAccounts(accounts []common.Address) {
+	miner.worker.setPriorityAccounts(accounts)
+}
+
+// SetBaseFeeCalculator replaces the EIP-1559 base fee formula of the blocks built,
+// for experimenting with alternative fee markets. Passing nil restores the default.
+func (miner *Miner) SetBaseFeeCalculator(calculator BaseFeeCalculator) {
+	miner.worker.setBaseFeeCalculator(calculator)
+}
+
+// SetCoinbaseForHeight schedules a change of the fee recipient, applied from the
+// block built at the given height on. Several changes can be scheduled, they apply
+// in height order.
+func (miner *Miner) SetCoinbaseForHeight(height uint64, addr common.Address) {
+	miner.worker.setCoinbaseForHeight(height, addr)
+}
+
+// SetGasLimitVote sets the gas limit voted for in the extra data of the blocks
+// built. The gas limit then moves toward the median vote of the recent blocks
+// instead of the gas ceil. Zero disables the voting.
+func (miner *Miner) SetGasLimitVote(limit uint64) {
+	miner.worker.setGasLimitVote(limit)
+}
+
+// SetGasCeilWithRamp moves the gaslimit to strive for linearly from its current
+// value to the target over the given number of blocks, so that network-wide changes
+// don't translate into sudden jumps. The gas limit itself still moves by at most
+// the protocol's max delta per block.
+func (miner *Miner) SetGasCeilWithRamp(target uint64, blocks uint64) {
+	miner.worker.setGasCeilWithRamp(target, blocks)
+}
+
 // EnablePreseal turns on the preseal mining feature. It's enabled by default.
 // Note this function shouldn't be exposed to API, it's unnecessary for users
 // (miners) to actually know the underlying detail. It's only for outside project
@@ -232,11 +423,103 @@ func (miner *Miner) DisablePreseal() {
 // GetSealingBlock retrieves a sealing block based on the given parameters.
 // The returned block is not sealed but all other fields should be filled.
 func (miner *Miner) GetSealingBlock(parent common.Hash, timestamp uint64, coinbase common.Address, random common.Hash) (*types.Block, error) {
-	return miner.worker.getSealingBlock(parent, timestamp, coinbase, random)
+	return miner.GetSealingBlockWithParams(BuildParams{
+		Parent:    parent,
+		Timestamp: timestamp,
+		Coinbase:  coinbase,
+		Random:    random,
+	})
+}
+
+// GetSealingBlockWithParams retrieves a sealing block based on the given parameters,
+// which can also replace the pool content by given transactions or leave it out.
+// The returned block is not sealed but all other fields should be filled.
+func (miner *Miner) GetSealingBlockWithParams(params BuildParams) (*types.Block, error) {
+	return miner.worker.getSealingBlock(&params)
+}
+
+// BuildBlock assembles a block based on the given parameters. Alongside with the
+// block, the result reports the transactions which were dropped and why, the gas
+// used, the final state root and the time spent building it.
+// The returned block is not sealed but all other fields should be filled.
+func (miner *Miner) BuildBlock(params BuildParams) (*BuildResult, error) {
+	return miner.worker.buildBlock(&params)
+}
+
+// Simulate dry-runs the assembly of the block the miner would seal next from the
+// current transaction pool content. The block is neither sealed nor proposed and the
+// pending block is left untouched.
+func (miner *Miner) Simulate() (*types.Block, types.Receipts, error) {
+	return miner.worker.simulate()
+}
+
+// PendingStateAt returns the state of a pending-like block built on the given base
+// block from the pool content, for what-if queries against a recent block rather than
+// the head. The base state is regenerated if needed, an error is returned if it is
+// unavailable. The pending block is left untouched.
+func (miner *Miner) PendingStateAt(base common.Hash) (*state.StateDB, error) {
+	return miner.worker.pendingStateAt(base)
+}
+
+// SimulateTx applies the transaction against the pending state, as if it was the next
+// transaction of the pending block, and returns its outcome without including it. The
+// pending state may differ from the latest committed one, e.g. for the nonces and the
+// balances of the accounts with pending transactions.
+func (miner *Miner) SimulateTx(tx *types.Transaction) (*SimResult, error) {
+	return miner.worker.simulateTx(tx)
+}
+
+// TxSelectionReport builds a block on the given parent, the latest head if empty,
+// and reports for each transaction of the pool whether it was included and why not
+// if it wasn't. The block is only simulated, the pending block is left untouched.
+func (miner *Miner) TxSelectionReport(parent common.Hash) (*SelectionReport, error) {
+	return miner.worker.txSelectionReport(parent)
+}
+
+// ResealPending submits the pending block for sealing again without rebuilding it,
+// for instance once an external signer recovered from a failure. It fails if the
+// block doesn't extend the current head anymore.
+func (miner *Miner) ResealPending() (*types.Block, error) {
+	return miner.worker.resealPending()
+}
+
+// SendBundle queues an ordered set of transactions which must be included all together
+// in a block, or not at all. Bundles are prioritized over the transaction pool content
+// by their total tip and remain queued until they get mined or fail, the bundles which
+// could never be included are refused and the queue is capped.
+func (miner *Miner) SendBundle(txs types.Transactions) error {
+	return miner.worker.addBundle(txs)
 }
 
 // SubscribePendingLogs starts delivering logs from pending transactions
-// to the given channel.
+// to the given channel. Deliveries are skipped while the channel is full, unless
+// buffering is configured in which case the logs are delivered later and the
+// oldest dropped beyond the buffer size. The subscription fails once it is refused
+// or evicted because of the configured maximum number of subscribers.
 func (miner *Miner) SubscribePendingLogs(ch chan<- []*types.Log) event.Subscription {
 	return miner.worker.pendingLogsFeed.Subscribe(ch)
 }
+
+// SubscribeSealedBlock starts delivering the blocks sealed locally along with their
+// receipts and assembly stats, before their import into the chain. It is the
+// earliest notification of a new block, but the import may still fail. Deliveries
+// are skipped while the channel is full, it should be buffered.
+func (miner *Miner) SubscribeSealedBlock(ch chan<- *SealedBlock) event.Subscription {
+	return miner.worker.sealedBlockFeed.subscribe(ch)
+}
+
+// RecentBlockFullness returns the gas used to gas limit ratios of the last blocks
+// sealed locally, at most 128, from the oldest to the latest. Consistently high
+// ratios hint at a demand exceeding the block capacity.
+func (miner *Miner) RecentBlockFullness() []float64 {
+	return miner.worker.fullness.recent()
+}
+
+// SubscribeChainHead starts delivering the headers of the new chain heads the
+// miner rebuilds its sealing work on, once the rebuild has been triggered.
+// Unlike the blockchain head event, the notification follows the miner's own
+// view of the chain. The headers are dropped for a subscriber whose channel is
+// full, it should be buffered.
+func (miner *Miner) SubscribeChainHead(ch chan<- *types.Header) event.Subscription {
+	return miner.worker.chainHeadFeed.subscribe(ch)
+}
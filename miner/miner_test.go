@@ -18,7 +18,9 @@
 package miner
 
 import (
+	"bytes"
 	"math/big"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -26,6 +28,7 @@ import (
 	"github.com/autonity/autonity/log"
 
 	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus"
 	"github.com/autonity/autonity/consensus/ethash"
 	"github.com/autonity/autonity/core"
 	"github.com/autonity/autonity/core/rawdb"
@@ -35,6 +38,7 @@ import (
 	"github.com/autonity/autonity/eth/downloader"
 	"github.com/autonity/autonity/ethdb/memorydb"
 	"github.com/autonity/autonity/event"
+	"github.com/autonity/autonity/metrics"
 	"github.com/autonity/autonity/params"
 	"github.com/autonity/autonity/trie"
 )
@@ -121,6 +125,67 @@ func TestMiner(t *testing.T) {
 	waitForMiningState(t, miner, true)
 }
 
+// syncAwareEngine records the sync notifications of the miner.
+type syncAwareEngine struct {
+	consensus.Engine
+	syncing chan bool
+}
+
+func (e *syncAwareEngine) SetSyncing(syncing bool) {
+	e.syncing <- syncing
+}
+
+func TestMinerNotifiesSyncing(t *testing.T) {
+	miner, mux := createMiner(t)
+	defer miner.Close()
+	engine := &syncAwareEngine{Engine: miner.engine, syncing: make(chan bool, 1)}
+	miner.engine = engine
+	// the update loop listens to the downloader events once it handled the start
+	miner.Start()
+	waitForMiningState(t, miner, true)
+
+	for _, ev := range []struct {
+		event   interface{}
+		syncing bool
+	}{
+		{downloader.StartEvent{}, true},
+		{downloader.FailedEvent{}, false},
+		{downloader.StartEvent{}, true},
+		{downloader.DoneEvent{}, false},
+	} {
+		mux.Post(ev.event)
+		select {
+		case syncing := <-engine.syncing:
+			if syncing != ev.syncing {
+				t.Fatalf("%T: syncing mismatch: have %v, want %v", ev.event, syncing, ev.syncing)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%T: engine not notified", ev.event)
+		}
+	}
+}
+
+func TestMinerResync(t *testing.T) {
+	miner, mux := createMiner(t)
+	miner.Start()
+	waitForMiningState(t, miner, true)
+	mux.Post(downloader.StartEvent{})
+	waitForMiningState(t, miner, false)
+	mux.Post(downloader.DoneEvent{})
+	waitForMiningState(t, miner, true)
+
+	// a sync requested by the consensus engine pauses the miner like the initial one
+	miner.Resync()
+	mux.Post(downloader.StartEvent{})
+	waitForMiningState(t, miner, false)
+	mux.Post(downloader.DoneEvent{})
+	waitForMiningState(t, miner, true)
+
+	// and the later syncs are ignored again
+	mux.Post(downloader.StartEvent{})
+	waitForMiningState(t, miner, true)
+}
+
 // TestMinerDownloaderFirstFails tests that mining is only
 // permitted to run indefinitely once the downloader sees a DoneEvent (success).
 // An initial FailedEvent should allow mining to stop on a subsequent
@@ -211,6 +276,48 @@ func TestCloseMiner(t *testing.T) {
 	waitForMiningState(t, miner, false)
 }
 
+func TestSetExtraNearLimit(t *testing.T) {
+	miner, _ := createMiner(t)
+	defer miner.Close()
+
+	enabled := metrics.Enabled
+	metrics.Enabled = true
+	meter := ExtraDataNearLimitMeter
+	ExtraDataNearLimitMeter = metrics.NewMeter()
+	handler := log.Root().GetHandler()
+	var warnings int32
+	log.Root().SetHandler(log.FuncHandler(func(r *log.Record) error {
+		if r.Lvl == log.LvlWarn && r.Msg == "Miner extra data close to limit" {
+			atomic.AddInt32(&warnings, 1)
+		}
+		return nil
+	}))
+	defer func() {
+		log.Root().SetHandler(handler)
+		ExtraDataNearLimitMeter.Stop()
+		ExtraDataNearLimitMeter = meter
+		metrics.Enabled = enabled
+	}()
+
+	// At the threshold the extra data is silently accepted.
+	if err := miner.SetExtra(make([]byte, extraDataWarnSize)); err != nil {
+		t.Fatalf("extra data at threshold rejected: %v", err)
+	}
+	if atomic.LoadInt32(&warnings) != 0 || ExtraDataNearLimitMeter.Count() != 0 {
+		t.Fatalf("unexpected warning at threshold: warnings %d, meter %d", warnings, ExtraDataNearLimitMeter.Count())
+	}
+	// Past the threshold it is still accepted but with a warning.
+	if err := miner.SetExtra(make([]byte, extraDataWarnSize+1)); err != nil {
+		t.Fatalf("extra data past threshold rejected: %v", err)
+	}
+	if atomic.LoadInt32(&warnings) != 1 || ExtraDataNearLimitMeter.Count() != 1 {
+		t.Fatalf("missing warning past threshold: warnings %d, meter %d", warnings, ExtraDataNearLimitMeter.Count())
+	}
+	if !bytes.Equal(miner.worker.extra, make([]byte, extraDataWarnSize+1)) {
+		t.Fatalf("extra data not set")
+	}
+}
+
 // waitForMiningState waits until either
 // * the desired mining state was reached
 // * a timeout was reached which fails the test
@@ -264,3 +371,56 @@ func createMiner(t *testing.T) (*Miner, *event.TypeMux) {
 	// Create Miner
 	return New(backend, &config, chainConfig, mux, engine, isLocalBlock), mux
 }
+
+// circuitBreakerEngine is a consensus engine with a proposal circuit breaker.
+type circuitBreakerEngine struct {
+	consensus.Engine
+	threshold int
+	halted    bool
+}
+
+func (e *circuitBreakerEngine) SetProposalCircuitBreakerThreshold(threshold int) {
+	e.threshold = threshold
+}
+
+func (e *circuitBreakerEngine) ProposingHalted() bool {
+	return e.halted
+}
+
+func (e *circuitBreakerEngine) ResetProposalCircuitBreaker() {
+	e.halted = false
+}
+
+func TestConfigureCircuitBreaker(t *testing.T) {
+	for _, tt := range []struct {
+		configured, want int
+	}{
+		{configured: 0, want: 5}, // engine default kept
+		{configured: 3, want: 3},
+		{configured: -1, want: 0}, // disabled
+	} {
+		engine := &circuitBreakerEngine{threshold: 5}
+		configureCircuitBreaker(engine, tt.configured)
+		if engine.threshold != tt.want {
+			t.Fatalf("configured %d: threshold mismatch: have %d, want %d", tt.configured, engine.threshold, tt.want)
+		}
+	}
+}
+
+func TestMiningStatusProposingHalted(t *testing.T) {
+	miner, _ := createMiner(t)
+	defer miner.Close()
+	if status := miner.MiningStatus(); status.ProposingHalted {
+		t.Fatalf("proposing reported halted without circuit breaker")
+	}
+
+	engine := &circuitBreakerEngine{Engine: miner.engine, halted: true}
+	miner.engine = engine
+	if status := miner.MiningStatus(); status.Mining || !status.ProposingHalted {
+		t.Fatalf("status mismatch: have %+v", status)
+	}
+	miner.ResetProposalCircuitBreaker()
+	if status := miner.MiningStatus(); status.ProposingHalted {
+		t.Fatalf("proposing still halted after reset")
+	}
+}
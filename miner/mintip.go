@@ -0,0 +1,54 @@
+package miner
+
+import (
+	"math/big"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/types"
+)
+
+// minTip returns the minimum effective tip of the remote transactions for a block with
+// the given base fee, nil if the floor doesn't track the base fee.
+func (w *worker) minTip(baseFee *big.Int) *big.Int {
+	if w.config.MinTipBaseFeeFraction <= 0 || baseFee == nil {
+		return nil
+	}
+	tip, _ := new(big.Float).Mul(new(big.Float).SetInt(baseFee), big.NewFloat(w.config.MinTipBaseFeeFraction)).Int(nil)
+	return tip
+}
+
+// withMinTip drops, per account, the transactions paying less than the minimum tip
+// along with all their successors.
+func withMinTip(pending map[common.Address]types.Transactions, baseFee, minTip *big.Int) map[common.Address]types.Transactions {
+	for account, txs := range pending {
+		n := 0
+		for n < len(txs) && txs[n].EffectiveGasTipIntCmp(minTip, baseFee) >= 0 {
+			n++
+		}
+		if n == 0 {
+			delete(pending, account)
+		} else {
+			pending[account] = txs[:n]
+		}
+	}
+	return pending
+}
+
+// withRemoteMinTip applies the minimum tip to the transactions of the accounts which
+// aren't local only.
+func withRemoteMinTip(pending map[common.Address]types.Transactions, locals []common.Address, baseFee, minTip *big.Int) map[common.Address]types.Transactions {
+	remotes := make(map[common.Address]types.Transactions, len(pending))
+	for account, txs := range pending {
+		remotes[account] = txs
+	}
+	for _, account := range locals {
+		delete(remotes, account)
+	}
+	for account := range remotes {
+		delete(pending, account)
+	}
+	for account, txs := range withMinTip(remotes, baseFee, minTip) {
+		pending[account] = txs
+	}
+	return pending
+}
@@ -0,0 +1,44 @@
+package miner
+
+import (
+	"github.com/autonity/autonity/common"
+)
+
+// setPendingBlockLimit bounds the number of sealing tasks retained with their state
+// until sealed, the oldest ones being evicted beyond. Zero removes the bound.
+func (w *worker) setPendingBlockLimit(limit int) {
+	w.pendingMu.Lock()
+	defer w.pendingMu.Unlock()
+	w.pendingLimit = limit
+	w.evictPendingTasks()
+}
+
+// addPendingTask retains the task being sealed until its result arrives.
+func (w *worker) addPendingTask(sealHash common.Hash, task *task) {
+	w.pendingMu.Lock()
+	defer w.pendingMu.Unlock()
+	w.pendingTasks[sealHash] = task
+	w.evictPendingTasks()
+}
+
+// evictPendingTasks drops the oldest tasks above the limit. The sealing of an
+// evicted task can't complete, but with frequent recommits it is superseded by a
+// newer one anyway. It must be called with the pending lock held.
+func (w *worker) evictPendingTasks() {
+	if w.pendingLimit <= 0 {
+		return
+	}
+	for len(w.pendingTasks) > w.pendingLimit {
+		var (
+			oldest     common.Hash
+			oldestTask *task
+		)
+		for h, t := range w.pendingTasks {
+			if oldestTask == nil || t.createdAt.Before(oldestTask.createdAt) {
+				oldest, oldestTask = h, t
+			}
+		}
+		delete(w.pendingTasks, oldest)
+		PendingTaskEvictedMeter.Mark(1)
+	}
+}
@@ -0,0 +1,28 @@
+package miner
+
+import (
+	"time"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/state"
+)
+
+// pendingStateAt builds a block on the given base from the pool content, the way the
+// pending block is built on the head, and returns its state. The block is only
+// simulated, the worker state is left untouched.
+func (w *worker) pendingStateAt(base common.Hash) (*state.StateDB, error) {
+	w.mu.RLock()
+	coinbase := w.coinbase
+	w.mu.RUnlock()
+
+	result, err := w.getWork(&generateParams{
+		timestamp:  uint64(time.Now().Unix()),
+		parentHash: base,
+		coinbase:   coinbase,
+		simulate:   true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.state, nil
+}
@@ -0,0 +1,144 @@
+package miner
+
+import (
+	"container/heap"
+	"math/big"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/types"
+)
+
+// TxPrioritizer defines the order in which the worker tries to include the candidate
+// transactions. The nonce ordering of the transactions of an account always prevails,
+// the prioritizer only arbitrates between the next transactions of each account.
+type TxPrioritizer interface {
+	// Less reports whether a must be included before b. The effective gas tip of the
+	// transactions can be computed with the base fee of the block being built, and
+	// their arrival time is available through FirstSeen.
+	Less(a, b *types.Transaction, baseFee *big.Int) bool
+}
+
+// TipPrioritizer is the default TxPrioritizer, it favours the highest effective tip
+// and falls back to the arrival time for deterministic ordering.
+type TipPrioritizer struct{}
+
+func (TipPrioritizer) Less(a, b *types.Transaction, baseFee *big.Int) bool {
+	cmp := a.EffectiveGasTipValue(baseFee).Cmp(b.EffectiveGasTipValue(baseFee))
+	if cmp == 0 {
+		return a.FirstSeen().Before(b.FirstSeen())
+	}
+	return cmp > 0
+}
+
+// orderedTransactions is a set of transactions retrieved in inclusion order.
+type orderedTransactions interface {
+	// Peek returns the next transaction to include.
+	Peek() *types.Transaction
+	// Shift replaces the next transaction with the following one from the same account.
+	Shift()
+	// Pop removes the next transaction and all the following ones from the same account.
+	Pop()
+}
+
+// prioritizedHeads is a heap of the next transaction of each account.
+type prioritizedHeads struct {
+	txs         types.Transactions
+	prioritizer TxPrioritizer
+	baseFee     *big.Int
+}
+
+func (h *prioritizedHeads) Len() int { return len(h.txs) }
+func (h *prioritizedHeads) Less(i, j int) bool {
+	return h.prioritizer.Less(h.txs[i], h.txs[j], h.baseFee)
+}
+func (h *prioritizedHeads) Swap(i, j int) { h.txs[i], h.txs[j] = h.txs[j], h.txs[i] }
+
+func (h *prioritizedHeads) Push(x interface{}) {
+	h.txs = append(h.txs, x.(*types.Transaction))
+}
+
+func (h *prioritizedHeads) Pop() interface{} {
+	n := len(h.txs)
+	x := h.txs[n-1]
+	h.txs = h.txs[:n-1]
+	return x
+}
+
+// prioritizedTransactions orders transactions according to a TxPrioritizer while
+// honouring the nonce ordering of each account.
+type prioritizedTransactions struct {
+	txs     map[common.Address]types.Transactions // Per account nonce-sorted list of transactions
+	heads   *prioritizedHeads                     // Next transaction for each unique account
+	signer  types.Signer
+	baseFee *big.Int
+}
+
+// newPrioritizedTransactions creates the ordered set out of the given per account
+// nonce-sorted transactions, the map is reowned. Transactions whose fee cap doesn't
+// cover the base fee are discarded with the following ones from the same account.
+func newPrioritizedTransactions(signer types.Signer, txs map[common.Address]types.Transactions, baseFee *big.Int, prioritizer TxPrioritizer) *prioritizedTransactions {
+	heads := &prioritizedHeads{
+		txs:         make(types.Transactions, 0, len(txs)),
+		prioritizer: prioritizer,
+		baseFee:     baseFee,
+	}
+	for from, accTxs := range txs {
+		acc, _ := types.Sender(signer, accTxs[0])
+		if _, err := accTxs[0].EffectiveGasTip(baseFee); acc != from || err != nil {
+			delete(txs, from)
+			continue
+		}
+		heads.txs = append(heads.txs, accTxs[0])
+		txs[from] = accTxs[1:]
+	}
+	heap.Init(heads)
+	return &prioritizedTransactions{
+		txs:     txs,
+		heads:   heads,
+		signer:  signer,
+		baseFee: baseFee,
+	}
+}
+
+func (t *prioritizedTransactions) Peek() *types.Transaction {
+	if t.heads.Len() == 0 {
+		return nil
+	}
+	return t.heads.txs[0]
+}
+
+func (t *prioritizedTransactions) Shift() {
+	acc, _ := types.Sender(t.signer, t.heads.txs[0])
+	if txs, ok := t.txs[acc]; ok && len(txs) > 0 {
+		if _, err := txs[0].EffectiveGasTip(t.baseFee); err == nil {
+			t.heads.txs[0], t.txs[acc] = txs[0], txs[1:]
+			heap.Fix(t.heads, 0)
+			return
+		}
+	}
+	heap.Pop(t.heads)
+}
+
+func (t *prioritizedTransactions) Pop() {
+	heap.Pop(t.heads)
+}
+
+// orderTransactions returns the given transactions in the order of the configured
+// prioritizer, the map is reowned.
+func (w *worker) orderTransactions(env *environment, txs map[common.Address]types.Transactions) orderedTransactions {
+	w.mu.RLock()
+	prioritizer := w.prioritizer
+	w.mu.RUnlock()
+	if prioritizer == nil {
+		return types.NewTransactionsByPriceAndNonce(env.signer, txs, env.header.BaseFee)
+	}
+	return newPrioritizedTransactions(env.signer, txs, env.header.BaseFee, prioritizer)
+}
+
+// setTxPrioritizer sets the order of the transactions inclusion, nil restores the
+// default tip based one.
+func (w *worker) setTxPrioritizer(prioritizer TxPrioritizer) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.prioritizer = prioritizer
+}
@@ -0,0 +1,26 @@
+package miner
+
+import (
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/types"
+)
+
+func (w *worker) setPriorityAccounts(accounts []common.Address) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.priorityAccounts = append([]common.Address(nil), accounts...)
+}
+
+// priorityTransactions returns the pending transactions of the priority accounts,
+// they are included before any other regardless of their tip.
+func (w *worker) priorityTransactions(pending map[common.Address]types.Transactions) map[common.Address]types.Transactions {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	priority := make(map[common.Address]types.Transactions)
+	for _, account := range w.priorityAccounts {
+		if txs := pending[account]; len(txs) > 0 {
+			priority[account] = txs
+		}
+	}
+	return priority
+}
@@ -0,0 +1,76 @@
+package miner
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrUnknownBuildProfile is returned when setting a build profile which isn't one of the presets.
+var ErrUnknownBuildProfile = errors.New("unknown build profile")
+
+// BuildProfile is a preset of the block building parameters trading the block
+// latency for its fullness.
+type BuildProfile uint8
+
+const (
+	// LowLatency rebuilds the block every second and seals it after at most
+	// 100ms of filling and 500 transactions, for blocks ready as soon as possible.
+	LowLatency BuildProfile = iota
+	// Balanced rebuilds the block every 2s and seals it after at most 500ms of
+	// filling and 2000 transactions.
+	Balanced
+	// MaxFullness rebuilds the block every 3s and fills it without time nor
+	// transaction count limit, for blocks as full as the pool allows.
+	MaxFullness
+)
+
+// buildParams are the parameters set by a build profile.
+type buildParams struct {
+	recommit         time.Duration
+	assemblyDeadline time.Duration // 0 = unlimited
+	maxBlockTxs      int           // 0 = unlimited
+}
+
+var buildProfiles = map[BuildProfile]buildParams{
+	LowLatency:  {recommit: time.Second, assemblyDeadline: 100 * time.Millisecond, maxBlockTxs: 500},
+	Balanced:    {recommit: 2 * time.Second, assemblyDeadline: 500 * time.Millisecond, maxBlockTxs: 2000},
+	MaxFullness: {recommit: 3 * time.Second},
+}
+
+func (p BuildProfile) String() string {
+	switch p {
+	case LowLatency:
+		return "LowLatency"
+	case Balanced:
+		return "Balanced"
+	case MaxFullness:
+		return "MaxFullness"
+	default:
+		return fmt.Sprintf("BuildProfile(%d)", uint8(p))
+	}
+}
+
+func (w *worker) setBuildProfile(profile BuildProfile) error {
+	params, ok := buildProfiles[profile]
+	if !ok {
+		return fmt.Errorf("%w: %v", ErrUnknownBuildProfile, profile)
+	}
+	w.mu.Lock()
+	w.config.AssemblyDeadline = params.assemblyDeadline
+	w.config.MaxBlockTxs = params.maxBlockTxs
+	w.mu.Unlock()
+	return w.setRecommitInterval(params.recommit)
+}
+
+func (w *worker) setAssemblyDeadline(deadline time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.config.AssemblyDeadline = deadline
+}
+
+func (w *worker) setMaxBlockTxs(max int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.config.MaxBlockTxs = max
+}
@@ -0,0 +1,52 @@
+package miner
+
+import (
+	"time"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/metrics"
+)
+
+// sealedBlock is a block handed to the consensus engine, waiting to become the chain head.
+type sealedBlock struct {
+	number uint64
+	at     time.Time
+}
+
+// recordSealed remembers when the block with the given seal hash was sealed, to measure
+// its delay to become the chain head. The seal hash is used as the committed block
+// differs from the sealed one by its seal.
+func (w *worker) recordSealed(sealHash common.Hash, number uint64) {
+	if !metrics.Enabled {
+		return
+	}
+	w.sealedMu.Lock()
+	defer w.sealedMu.Unlock()
+	if w.sealed == nil {
+		w.sealed = make(map[common.Hash]sealedBlock)
+	}
+	// a resealed block keeps its first sealing time
+	if _, ok := w.sealed[sealHash]; !ok {
+		w.sealed[sealHash] = sealedBlock{number: number, at: time.Now()}
+	}
+}
+
+// observeHead measures the delay to become the chain head of the new head if it was
+// sealed locally. The blocks sealed for the heights up to the head's are forgotten,
+// they can't become the head anymore.
+func (w *worker) observeHead(head *types.Block) {
+	if !metrics.Enabled {
+		return
+	}
+	w.sealedMu.Lock()
+	defer w.sealedMu.Unlock()
+	if sealed, ok := w.sealed[w.engine.SealHash(head.Header())]; ok {
+		SealedHeadTimer.UpdateSince(sealed.at)
+	}
+	for hash, sealed := range w.sealed {
+		if sealed.number <= head.NumberU64() {
+			delete(w.sealed, hash)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package miner
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/autonity/autonity/core/types"
+)
+
+var (
+	// errNoPendingBlock is returned when resealing while no block was submitted for sealing yet.
+	errNoPendingBlock = errors.New("no pending block to reseal")
+
+	// errStalePendingBlock is returned when resealing a pending block which isn't
+	// built on the current head anymore.
+	errStalePendingBlock = errors.New("pending block not built on the current head")
+)
+
+// setSealingTask records the last task submitted for sealing.
+func (w *worker) setSealingTask(task *task) {
+	w.pendingMu.Lock()
+	defer w.pendingMu.Unlock()
+	w.sealing = task
+}
+
+// resealPending submits the last block assembled for sealing again, without
+// rebuilding it, for instance once the signer recovered from a failure. The block
+// must still extend the current head.
+func (w *worker) resealPending() (*types.Block, error) {
+	w.pendingMu.RLock()
+	sealing := w.sealing
+	w.pendingMu.RUnlock()
+	if sealing == nil {
+		return nil, errNoPendingBlock
+	}
+	block := sealing.block
+	if head := w.chain.CurrentBlock(); block.ParentHash() != head.Hash() {
+		return nil, fmt.Errorf("%w: block %d built on %x, head is %d %x", errStalePendingBlock, block.NumberU64(), block.ParentHash(), head.NumberU64(), head.Hash())
+	}
+	// The state is copied as the block could get sealed twice.
+	task := &task{receipts: sealing.receipts, state: sealing.state.Copy(), block: block, createdAt: time.Now(), elapsed: sealing.elapsed, reseal: true}
+	select {
+	case w.taskCh <- task:
+		w.eth.Logger().Info("Resealing pending block", "number", block.Number(), "sealhash", w.engine.SealHash(block.Header()))
+		return block, nil
+	case <-w.exitCh:
+		return nil, errors.New("miner closed")
+	}
+}
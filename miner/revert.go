@@ -0,0 +1,72 @@
+package miner
+
+import (
+	"errors"
+
+	"github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/types"
+)
+
+// errTxReverted is reported for the transactions left out because their execution
+// reverted while the worker is configured to skip them.
+var errTxReverted = errors.New("transaction reverted")
+
+// envCheckpoint is a point of the block assembly the environment can be rolled
+// back to, across any number of transactions.
+type envCheckpoint struct {
+	snapshot int
+	gasPool  core.GasPool
+	gasUsed  uint64
+	txCount  int
+	tcount   int
+	sysGas   uint64
+	largeGas uint64
+}
+
+// checkpoint records the environment to be able to roll back the transactions
+// applied next. The state journal is retained across them until the checkpoint
+// is released or rolled back to, which relies on the state being finalised
+// rather than hashed between transactions, as it is from Byzantium.
+func (env *environment) checkpoint() *envCheckpoint {
+	env.state.RetainJournal()
+	return &envCheckpoint{
+		snapshot: env.state.Snapshot(),
+		gasPool:  *env.gasPool,
+		gasUsed:  env.header.GasUsed,
+		txCount:  len(env.txs),
+		tcount:   env.tcount,
+		sysGas:   env.systemGasUsed,
+		largeGas: env.largeGasUsed,
+	}
+}
+
+// release keeps the changes made since the checkpoint.
+func (env *environment) release(*envCheckpoint) {
+	env.state.ReleaseJournal()
+}
+
+// rollback reverts the state and every counter of the environment to the checkpoint.
+func (env *environment) rollback(cp *envCheckpoint) {
+	env.state.RevertToSnapshot(cp.snapshot)
+	env.state.ReleaseJournal()
+	*env.gasPool = cp.gasPool
+	env.header.GasUsed = cp.gasUsed
+	env.txs = env.txs[:cp.txCount]
+	env.receipts = env.receipts[:cp.txCount]
+	env.tcount = cp.tcount
+	env.systemGasUsed = cp.sysGas
+	env.largeGasUsed = cp.largeGas
+}
+
+// applySucceedingTransaction applies the transaction like applyTransaction, but
+// rolls it back if its execution reverts.
+func (w *worker) applySucceedingTransaction(env *environment, tx *types.Transaction, system bool) ([]*types.Log, error) {
+	cp := env.checkpoint()
+	logs, err := w.applyTransaction(env, tx, system)
+	if err != nil || env.receipts[len(env.receipts)-1].Status == types.ReceiptStatusSuccessful {
+		env.release(cp)
+		return logs, err
+	}
+	env.rollback(cp)
+	return nil, errTxReverted
+}
@@ -0,0 +1,86 @@
+package miner
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/types"
+)
+
+// errRewardOverspent is returned when the reward split transfers more than the
+// coinbase earned in the block.
+var errRewardOverspent = errors.New("reward split exceeds the block reward")
+
+// RewardSplitter redistributes the reward earned by the coinbase in the block being
+// built. It returns transfers from the coinbase account, starting at the given nonce
+// and signed by the operator, which are appended to the block. As regular
+// transactions they don't affect the validity of the block for the other nodes, and
+// they can't move more than the reward, gas included, so that no value is created.
+type RewardSplitter func(header *types.Header, reward *big.Int, nonce uint64) ([]*types.Transaction, error)
+
+func (w *worker) setRewardSplitter(splitter RewardSplitter) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.rewardSplitter = splitter
+}
+
+// blockReward returns the balance earned by the coinbase since the beginning of
+// the block.
+func (env *environment) blockReward() *big.Int {
+	reward := new(big.Int).Sub(env.state.GetBalance(env.coinbase), env.coinbaseBalance)
+	if reward.Sign() < 0 {
+		return new(big.Int)
+	}
+	return reward
+}
+
+// splitReward appends the reward split transfers to the block. The split is all or
+// nothing, the reward is left to the coinbase if any transfer can't be included.
+func (w *worker) splitReward(env *environment) error {
+	w.mu.RLock()
+	splitter := w.rewardSplitter
+	w.mu.RUnlock()
+	if splitter == nil || env.coinbase == (common.Address{}) {
+		return nil
+	}
+	reward := env.blockReward()
+	if reward.Sign() == 0 {
+		return nil
+	}
+	txs, err := splitter(env.header, new(big.Int).Set(reward), env.state.GetNonce(env.coinbase))
+	if err != nil {
+		return fmt.Errorf("reward splitter: %w", err)
+	}
+	spent := new(big.Int)
+	for _, tx := range txs {
+		from, err := types.Sender(env.signer, tx)
+		if err != nil {
+			return fmt.Errorf("reward split transaction %s: %w", tx.Hash(), err)
+		}
+		if from != env.coinbase {
+			return fmt.Errorf("reward split transaction %s not sent by the coinbase %s", tx.Hash(), env.coinbase)
+		}
+		spent.Add(spent, tx.Cost())
+	}
+	if spent.Cmp(reward) > 0 {
+		return fmt.Errorf("%w: have %v, want at most %v", errRewardOverspent, spent, reward)
+	}
+
+	if env.gasPool == nil {
+		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+	}
+	cp := env.checkpoint()
+	for _, tx := range txs {
+		env.state.Prepare(tx.Hash(), env.tcount)
+		if _, err := w.commitTransaction(env, tx); err != nil {
+			env.rollback(cp)
+			return fmt.Errorf("reward split transaction %s: %w", tx.Hash(), err)
+		}
+		env.tcount++
+	}
+	env.release(cp)
+	return nil
+}
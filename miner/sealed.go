@@ -0,0 +1,67 @@
+package miner
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/event"
+)
+
+// SealedBlock is a block sealed locally, delivered before its import into the chain.
+type SealedBlock struct {
+	Block        *types.Block
+	Receipts     types.Receipts
+	GasUsed      uint64
+	Fees         *big.Int      // tips paid to the coinbase, in wei
+	AssemblyTime time.Duration // time spent assembling the block
+	SealTime     time.Duration // time from submitting the block for sealing to its sealing
+}
+
+// sealedBlockFeed delivers the sealed blocks. Unlike event.Feed, a delivery never
+// blocks the seal path: a subscriber which isn't ready to receive misses it.
+type sealedBlockFeed struct {
+	mu          sync.Mutex
+	subscribers []chan<- *SealedBlock
+}
+
+func (f *sealedBlockFeed) subscribe(ch chan<- *SealedBlock) event.Subscription {
+	f.mu.Lock()
+	f.subscribers = append(f.subscribers, ch)
+	f.mu.Unlock()
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		for i, sub := range f.subscribers {
+			if sub == ch {
+				f.subscribers = append(f.subscribers[:i], f.subscribers[i+1:]...)
+				break
+			}
+		}
+		return nil
+	})
+}
+
+func (f *sealedBlockFeed) send(sealed *SealedBlock) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, ch := range f.subscribers {
+		select {
+		case ch <- sealed:
+		default:
+			SealedBlockDroppedMeter.Mark(1)
+		}
+	}
+}
+
+// blockFees returns the tips paid to the coinbase by the transactions of the block, in wei.
+func blockFees(block *types.Block, receipts []*types.Receipt) *big.Int {
+	fees := new(big.Int)
+	for i, tx := range block.Transactions() {
+		tip, _ := tx.EffectiveGasTip(block.BaseFee())
+		fees.Add(fees, new(big.Int).Mul(new(big.Int).SetUint64(receipts[i].GasUsed), tip))
+	}
+	return fees
+}
@@ -0,0 +1,154 @@
+package miner
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/types"
+)
+
+// ExclusionReason tells why a candidate transaction was left out of the block.
+type ExclusionReason string
+
+const (
+	ExcludedLowTip       ExclusionReason = "low tip"       // the tip is below the base fee or the minimum tip
+	ExcludedNonceGap     ExclusionReason = "nonce gap"     // a transaction with a lower nonce of the sender is missing
+	ExcludedGasExhausted ExclusionReason = "gas exhausted" // no gas left in the block or in the budget of its class
+	ExcludedFiltered     ExclusionReason = "filtered"      // the sender is denied by this node
+	ExcludedFailed       ExclusionReason = "failed"        // the execution failed, see the error
+)
+
+// TxSelection reports whether a candidate transaction was included in the block,
+// and why not if it wasn't.
+type TxSelection struct {
+	Hash     common.Hash     `json:"hash"`
+	From     common.Address  `json:"from"`
+	Nonce    uint64          `json:"nonce"`
+	Included bool            `json:"included"`
+	Reason   ExclusionReason `json:"reason,omitempty"`
+	Error    string          `json:"error,omitempty"` // the execution error, if any
+}
+
+// SelectionReport describes the selection of the transactions of the pool in a
+// block built on the given parent, sorted by sender and nonce.
+type SelectionReport struct {
+	Parent       common.Hash   `json:"parent"`
+	Number       uint64        `json:"number"`
+	BaseFee      *big.Int      `json:"baseFee"`
+	GasUsed      uint64        `json:"gasUsed"`
+	Transactions []TxSelection `json:"transactions"`
+}
+
+// exclusionReason classifies the error a transaction was rejected with.
+func exclusionReason(err error) ExclusionReason {
+	switch {
+	case errors.Is(err, core.ErrGasLimitReached), errors.Is(err, errBudgetExceeded):
+		return ExcludedGasExhausted
+	case errors.Is(err, errDeniedSender):
+		return ExcludedFiltered
+	case errors.Is(err, core.ErrNonceTooHigh), errors.Is(err, core.ErrNonceTooLow):
+		return ExcludedNonceGap
+	default:
+		return ExcludedFailed
+	}
+}
+
+// txSelectionReport builds a block on the given parent, the latest head if empty,
+// from the pool content and reports the outcome for each transaction of the pool.
+// The block is only simulated, the worker state is left untouched.
+func (w *worker) txSelectionReport(parent common.Hash) (*SelectionReport, error) {
+	w.mu.RLock()
+	coinbase, denied := w.coinbase, w.deniedSenders
+	w.mu.RUnlock()
+
+	pool := w.eth.TxPool()
+	pending, queued := pool.Content()
+	result, err := w.getWork(&generateParams{
+		timestamp:  uint64(time.Now().Unix()),
+		parentHash: parent,
+		coinbase:   coinbase,
+		simulate:   true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	block := result.Block
+	report := &SelectionReport{
+		Parent:  block.ParentHash(),
+		Number:  block.NumberU64(),
+		BaseFee: block.BaseFee(),
+		GasUsed: result.GasUsed,
+	}
+
+	included := make(map[common.Hash]bool, len(block.Transactions()))
+	for _, tx := range block.Transactions() {
+		included[tx.Hash()] = true
+	}
+	rejected := make(map[common.Hash]error, len(result.Rejected))
+	for _, rejection := range result.Rejected {
+		rejected[rejection.Hash] = rejection.Reason
+	}
+	locals := make(map[common.Address]bool)
+	for _, account := range pool.Locals() {
+		locals[account] = true
+	}
+	// the remote transactions must pay the pool and miner minimum tips, if any
+	baseFee, poolTip, minTip := block.BaseFee(), pool.GasPrice(), w.minTip(block.BaseFee())
+	lowTip := func(account common.Address, tx *types.Transaction) bool {
+		if baseFee != nil && tx.GasFeeCapIntCmp(baseFee) < 0 {
+			return true
+		}
+		if locals[account] {
+			return false
+		}
+		return tx.EffectiveGasTipIntCmp(poolTip, baseFee) < 0 || (minTip != nil && tx.EffectiveGasTipIntCmp(minTip, baseFee) < 0)
+	}
+
+	accounts := make([]common.Address, 0, len(pending)+len(queued))
+	for account := range pending {
+		accounts = append(accounts, account)
+	}
+	for account := range queued {
+		if _, ok := pending[account]; !ok {
+			accounts = append(accounts, account)
+		}
+	}
+	sort.Slice(accounts, func(i, j int) bool { return bytes.Compare(accounts[i][:], accounts[j][:]) < 0 })
+
+	for _, account := range accounts {
+		// the pending transactions are executable, the queued ones follow a nonce gap
+		gap := false
+		for _, tx := range pending[account] {
+			selection := TxSelection{Hash: tx.Hash(), From: account, Nonce: tx.Nonce(), Included: included[tx.Hash()]}
+			if !selection.Included {
+				switch err, ok := rejected[tx.Hash()]; {
+				case ok:
+					selection.Reason = exclusionReason(err)
+					if selection.Reason == ExcludedFailed {
+						selection.Error = err.Error()
+					}
+				case denied.contains(account):
+					selection.Reason = ExcludedFiltered
+				case gap:
+					selection.Reason = ExcludedNonceGap
+				case lowTip(account, tx):
+					selection.Reason = ExcludedLowTip
+				default:
+					// never tried, the block was full before its turn came
+					selection.Reason = ExcludedGasExhausted
+				}
+				gap = true
+			}
+			report.Transactions = append(report.Transactions, selection)
+		}
+		for _, tx := range queued[account] {
+			report.Transactions = append(report.Transactions, TxSelection{Hash: tx.Hash(), From: account, Nonce: tx.Nonce(), Reason: ExcludedNonceGap})
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,57 @@
+package miner
+
+import (
+	"errors"
+
+	"github.com/autonity/autonity/accounts/abi"
+	"github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/core/vm"
+)
+
+// errNoPendingState is returned when simulating a transaction before the first
+// pending block is built.
+var errNoPendingState = errors.New("no pending state to simulate against")
+
+// SimResult is the outcome of a transaction simulated against the pending state.
+type SimResult struct {
+	GasUsed      uint64       // Gas consumed by the transaction
+	Failed       bool         // Whether the execution failed, e.g. reverted
+	RevertReason string       // Reason given by the reverting contract, if any
+	ReturnData   []byte       // Data returned or the revert data
+	Logs         []*types.Log // Logs emitted by the transaction
+}
+
+// simulateTx applies the transaction on a copy of the pending state, right after
+// the transactions of the pending block, and reports its outcome. The pending state
+// is left untouched.
+func (w *worker) simulateTx(tx *types.Transaction) (*SimResult, error) {
+	block, statedb := w.pending()
+	if block == nil || statedb == nil {
+		return nil, errNoPendingState
+	}
+	header := block.Header()
+	msg, err := tx.AsMessage(types.MakeSigner(w.chainConfig, header.Number), header.BaseFee)
+	if err != nil {
+		return nil, err
+	}
+	statedb.Prepare(tx.Hash(), len(block.Transactions()))
+	evm := vm.NewEVM(core.NewEVMBlockContext(header, w.chain, &header.Coinbase), core.NewEVMTxContext(msg), statedb, w.chainConfig, *w.chain.GetVMConfig())
+	result, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(header.GasLimit))
+	if err != nil {
+		return nil, err
+	}
+	sim := &SimResult{
+		GasUsed:    result.UsedGas,
+		Failed:     result.Failed(),
+		ReturnData: result.Return(),
+		Logs:       statedb.GetLogs(tx.Hash(), block.Hash()),
+	}
+	if revert := result.Revert(); len(revert) > 0 {
+		sim.ReturnData = revert
+		if reason, err := abi.UnpackRevert(revert); err == nil {
+			sim.RevertReason = reason
+		}
+	}
+	return sim, nil
+}
@@ -0,0 +1,173 @@
+package miner
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/event"
+)
+
+var (
+	// ErrTooManySubscribers is reported by the pending logs subscriptions refused
+	// because the maximum number of subscribers is reached.
+	ErrTooManySubscribers = errors.New("too many pending logs subscribers")
+
+	// ErrSubscriberEvicted is reported by the pending logs subscriptions dropped to
+	// make room for a new subscriber because they missed the most deliveries.
+	ErrSubscriberEvicted = errors.New("slow pending logs subscriber evicted")
+)
+
+// logsSubscriber is a pending logs subscriber along with the number of deliveries
+// it missed because its channel was full, or of logs dropped from its buffer.
+type logsSubscriber struct {
+	ch      chan<- []*types.Log
+	dropped uint64
+	evict   chan struct{}
+
+	buffered []*types.Log  // Logs waiting for the subscriber to be ready, if buffering
+	ready    chan struct{} // Signals logs added to the buffer
+}
+
+// logsFeed delivers the pending logs to a bounded number of subscribers. Unlike
+// event.Feed, a delivery never blocks: a subscriber which isn't ready to receive
+// either misses it or, if buffering, gets the logs later from a bounded buffer,
+// so that a slow consumer can't back up the worker nor exhaust its memory.
+type logsFeed struct {
+	max         int  // Maximum number of subscribers (0 = unlimited)
+	dropSlowest bool // Evict the slowest subscriber instead of refusing new ones once at the limit
+	maxBuffered int  // Maximum number of logs buffered per subscriber, the oldest dropped first (0 = no buffering)
+
+	mu          sync.Mutex
+	subscribers []*logsSubscriber
+}
+
+func newLogsFeed(max int, dropSlowest bool, maxBuffered int) *logsFeed {
+	return &logsFeed{max: max, dropSlowest: dropSlowest, maxBuffered: maxBuffered}
+}
+
+// Subscribe adds a channel to the feed. Once the maximum number of subscribers is
+// reached, the returned subscription either fails with ErrTooManySubscribers or
+// the subscriber which missed the most deliveries is evicted to make room.
+func (f *logsFeed) Subscribe(ch chan<- []*types.Log) event.Subscription {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.max > 0 && len(f.subscribers) >= f.max {
+		if !f.dropSlowest {
+			return event.NewSubscription(func(<-chan struct{}) error {
+				return ErrTooManySubscribers
+			})
+		}
+		f.evictSlowest()
+	}
+	sub := &logsSubscriber{ch: ch, evict: make(chan struct{}), ready: make(chan struct{}, 1)}
+	f.subscribers = append(f.subscribers, sub)
+
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		if f.maxBuffered > 0 {
+			return f.deliverBuffered(sub, quit)
+		}
+		select {
+		case <-quit:
+			f.remove(sub)
+			return nil
+		case <-sub.evict:
+			return ErrSubscriberEvicted
+		}
+	})
+}
+
+// Send delivers the logs to every subscriber ready to receive them, or buffers
+// them if buffering, and returns the number of subscribers reached.
+func (f *logsFeed) Send(logs []*types.Log) (nsent int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, sub := range f.subscribers {
+		if f.maxBuffered > 0 {
+			f.buffer(sub, logs)
+			nsent++
+			continue
+		}
+		select {
+		case sub.ch <- logs:
+			nsent++
+		default:
+			sub.dropped++
+			PendingLogsDroppedMeter.Mark(1)
+		}
+	}
+	return nsent
+}
+
+// buffer adds the logs to the subscriber buffer, dropping the oldest ones beyond
+// the maximum. It must be called with the lock held.
+func (f *logsFeed) buffer(sub *logsSubscriber, logs []*types.Log) {
+	sub.buffered = append(sub.buffered, logs...)
+	if over := len(sub.buffered) - f.maxBuffered; over > 0 {
+		sub.buffered = append([]*types.Log(nil), sub.buffered[over:]...)
+		sub.dropped += uint64(over)
+		PendingLogsTrimmedMeter.Mark(int64(over))
+	}
+	select {
+	case sub.ready <- struct{}{}:
+	default:
+	}
+}
+
+// deliverBuffered sends the buffered logs to the subscriber as they come, in a
+// single batch for all the logs buffered while the subscriber wasn't ready. At
+// most maxBuffered logs are buffered on top of the batch being delivered.
+func (f *logsFeed) deliverBuffered(sub *logsSubscriber, quit <-chan struct{}) error {
+	for {
+		select {
+		case <-sub.ready:
+		case <-quit:
+			f.remove(sub)
+			return nil
+		case <-sub.evict:
+			return ErrSubscriberEvicted
+		}
+		f.mu.Lock()
+		logs := sub.buffered
+		sub.buffered = nil
+		f.mu.Unlock()
+		if len(logs) == 0 {
+			continue
+		}
+		select {
+		case sub.ch <- logs:
+		case <-quit:
+			f.remove(sub)
+			return nil
+		case <-sub.evict:
+			return ErrSubscriberEvicted
+		}
+	}
+}
+
+// evictSlowest drops the subscriber which missed the most deliveries, the oldest
+// one among equals. It must be called with the lock held.
+func (f *logsFeed) evictSlowest() {
+	slowest := 0
+	for i, sub := range f.subscribers {
+		if sub.dropped > f.subscribers[slowest].dropped {
+			slowest = i
+		}
+	}
+	close(f.subscribers[slowest].evict)
+	f.subscribers = append(f.subscribers[:slowest], f.subscribers[slowest+1:]...)
+}
+
+func (f *logsFeed) remove(sub *logsSubscriber) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, s := range f.subscribers {
+		if s == sub {
+			f.subscribers = append(f.subscribers[:i], f.subscribers[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package miner
+
+import (
+	"fmt"
+
+	"github.com/autonity/autonity/autonity"
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/state"
+	"github.com/autonity/autonity/core/types"
+)
+
+// SystemTxProvider returns the protocol transactions, such as a randomness beacon or
+// an oracle update, to place at the top of the block being built on the given
+// header and state. An error aborts the build.
+type SystemTxProvider func(header *types.Header, state *state.StateDB) ([]*types.Transaction, error)
+
+// systemContracts are the protocol contracts, the transactions sent to them by a
+// trusted system sender, such as oracle votes or accountability events, are system
+// transactions.
+var systemContracts = map[common.Address]struct{}{
+	autonity.AutonityContractAddress:       {},
+	autonity.AccountabilityContractAddress: {},
+	autonity.OracleContractAddress:         {},
+	autonity.ACUContractAddress:            {},
+	autonity.SupplyControlContractAddress:  {},
+	autonity.StabilizationContractAddress:  {},
+}
+
+// isSystemCall returns true if the transaction calls a protocol contract.
+func isSystemCall(tx *types.Transaction) bool {
+	if tx.To() == nil {
+		return false
+	}
+	_, ok := systemContracts[*tx.To()]
+	return ok
+}
+
+// systemSenderSet holds the trusted senders of system transactions.
+type systemSenderSet map[common.Address]struct{}
+
+func (w *worker) setSystemTxSenders(senders []common.Address) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.systemSenders = make(systemSenderSet, len(senders))
+	for _, sender := range senders {
+		w.systemSenders[sender] = struct{}{}
+	}
+}
+
+// isSystemTx returns true if the transaction is sent by a trusted system sender to a
+// protocol contract. The same calls from any other account, such as bonding or NTN
+// transfers, are user transactions.
+func (w *worker) isSystemTx(signer types.Signer, tx *types.Transaction) bool {
+	if !isSystemCall(tx) {
+		return false
+	}
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return false
+	}
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	_, ok := w.systemSenders[from]
+	return ok
+}
+
+// systemTransactions returns, per trusted system sender, the leading system
+// transactions of the pending ones.
+func (w *worker) systemTransactions(pending map[common.Address]types.Transactions) map[common.Address]types.Transactions {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	system := make(map[common.Address]types.Transactions)
+	for account := range w.systemSenders {
+		txs := pending[account]
+		n := 0
+		for n < len(txs) && isSystemCall(txs[n]) {
+			n++
+		}
+		if n > 0 {
+			system[account] = txs[:n]
+		}
+	}
+	return system
+}
+
+// userGasAvailable returns the gas left to user transactions, once the gas reserved
+// for the system transactions not included yet is set aside.
+func (w *worker) userGasAvailable(env *environment) uint64 {
+	reserved := w.config.ReservedSystemGas
+	if env.systemGasUsed >= reserved {
+		reserved = 0
+	} else {
+		reserved -= env.systemGasUsed
+	}
+	if env.gasPool.Gas() <= reserved {
+		return 0
+	}
+	return env.gasPool.Gas() - reserved
+}
+
+func (w *worker) setSystemTxProvider(provider SystemTxProvider) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.systemTxProvider = provider
+}
+
+// commitProvidedTransactions includes the transactions of the system transaction
+// provider, if any, in the given order. They can use the reserved gas and must all
+// be applied successfully.
+func (w *worker) commitProvidedTransactions(env *environment) error {
+	w.mu.RLock()
+	provider := w.systemTxProvider
+	w.mu.RUnlock()
+	if provider == nil {
+		return nil
+	}
+	txs, err := provider(env.header, env.state)
+	if err != nil {
+		return fmt.Errorf("system transaction provider: %w", err)
+	}
+	if env.gasPool == nil {
+		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+	}
+	for _, tx := range txs {
+		env.state.Prepare(tx.Hash(), env.tcount)
+		if _, err := w.applyTransaction(env, tx, true); err != nil {
+			return fmt.Errorf("system transaction %s: %w", tx.Hash(), err)
+		}
+		env.tcount++
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+package miner
+
+import (
+	"math/big"
+
+	"github.com/autonity/autonity/autonity"
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/lru"
+	"github.com/autonity/autonity/core/state"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/rlp"
+)
+
+// txResultCache memoizes the execution of the transactions, so that the frequent
+// rebuilds of the sealing block on the same parent don't execute them over and
+// over again.
+//
+// The execution of a transaction only depends on the block context and on the
+// state it accesses, which is known from the access list once executed. A result
+// is then kept with the values of the accessed state before the execution, and
+// replayed as long as the state still holds these values, whatever the
+// transactions applied before. The result is recorded on the second execution of
+// a transaction, the first one telling which state to watch.
+type txResultCache struct {
+	results *lru.Cache[txResultKey, *txResult]
+}
+
+type txResultKey struct {
+	context common.Hash // block context the transaction executes in, see blockContext
+	tx      common.Hash
+}
+
+// txResult is the outcome of a transaction execution, alongside with the state it
+// depends on.
+type txResult struct {
+	accessed types.AccessList // state accessed by the last execution
+
+	// set once the values of the state accessed are known before the execution
+	pre     stateValues
+	post    stateValues
+	credits map[common.Address]*big.Int // fees credited to the fee recipients not in the accessed state
+	gasUsed uint64
+	status  uint64
+	logs    []*types.Log
+}
+
+// accountValues holds the values of an account and of its storage slots.
+type accountValues struct {
+	exists   bool
+	empty    bool
+	balance  *big.Int
+	nonce    uint64
+	codeHash common.Hash
+	storage  map[common.Hash]common.Hash
+}
+
+type stateValues map[common.Address]*accountValues
+
+func newTxResultCache(size int) *txResultCache {
+	if size <= 0 {
+		return nil
+	}
+	return &txResultCache{results: lru.NewCache[txResultKey, *txResult](size)}
+}
+
+// blockContext hashes the header fields the execution of a transaction depends on.
+func blockContext(env *environment) common.Hash {
+	enc, _ := rlp.EncodeToBytes([]interface{}{
+		env.header.ParentHash,
+		env.header.Number,
+		env.header.Time,
+		env.coinbase,
+		env.header.GasLimit,
+		env.header.BaseFee,
+		env.header.Difficulty,
+		env.header.MixDigest,
+	})
+	return crypto.Keccak256Hash(enc)
+}
+
+// feeRecipients returns the accounts credited with the transaction fees.
+func feeRecipients(env *environment) []common.Address {
+	if env.coinbase == autonity.AutonityContractAddress {
+		return []common.Address{env.coinbase}
+	}
+	return []common.Address{env.coinbase, autonity.AutonityContractAddress}
+}
+
+// readState returns the current values of the given state.
+func readState(db *state.StateDB, list types.AccessList) stateValues {
+	values := make(stateValues, len(list))
+	for _, tuple := range list {
+		account := &accountValues{exists: db.Exist(tuple.Address), storage: make(map[common.Hash]common.Hash, len(tuple.StorageKeys))}
+		if account.exists {
+			account.empty = db.Empty(tuple.Address)
+			account.balance = new(big.Int).Set(db.GetBalance(tuple.Address))
+			account.nonce = db.GetNonce(tuple.Address)
+			account.codeHash = db.GetCodeHash(tuple.Address)
+		}
+		for _, key := range tuple.StorageKeys {
+			account.storage[key] = db.GetState(tuple.Address, key)
+		}
+		values[tuple.Address] = account
+	}
+	return values
+}
+
+// matches returns whether the state still holds the values.
+func (v stateValues) matches(db *state.StateDB) bool {
+	for addr, account := range v {
+		if db.Exist(addr) != account.exists {
+			return false
+		}
+		if account.exists && (db.GetNonce(addr) != account.nonce || db.GetBalance(addr).Cmp(account.balance) != 0 || db.GetCodeHash(addr) != account.codeHash) {
+			return false
+		}
+		for key, value := range account.storage {
+			if db.GetState(addr, key) != value {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// covers returns whether the values include all the given state.
+func (v stateValues) covers(list types.AccessList) bool {
+	for _, tuple := range list {
+		account, ok := v[tuple.Address]
+		if !ok {
+			return false
+		}
+		for _, key := range tuple.StorageKeys {
+			if _, ok := account.storage[key]; !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// replayable returns whether the changes between the values can be applied with
+// plain balance, nonce and storage updates. Accounts created, destroyed, becoming
+// empty or not, and code changes are left to the execution.
+func replayable(pre, post stateValues) bool {
+	for addr, after := range post {
+		before := pre[addr]
+		if before.exists != after.exists {
+			return false
+		}
+		if after.exists && (before.empty != after.empty || before.codeHash != after.codeHash) {
+			return false
+		}
+	}
+	return true
+}
+
+// txExecution tracks a transaction execution to record its result.
+type txExecution struct {
+	key      txResultKey
+	pre      stateValues
+	balances map[common.Address]*big.Int // balances of the fee recipients before the execution
+}
+
+// replay applies the cached result of the transaction if the state it depends on
+// is unchanged, returning its receipt. Otherwise, it returns nil and the tracker
+// of the execution to be recorded once done.
+func (c *txResultCache) replay(env *environment, tx *types.Transaction) (*types.Receipt, *txExecution) {
+	exec := &txExecution{key: txResultKey{context: blockContext(env), tx: tx.Hash()}, balances: make(map[common.Address]*big.Int)}
+	for _, addr := range feeRecipients(env) {
+		exec.balances[addr] = env.state.GetBalance(addr)
+	}
+	result, ok := c.results.Get(exec.key)
+	if !ok {
+		TxResultMissMeter.Mark(1)
+		return nil, exec
+	}
+	if result.pre == nil || env.gasPool.Gas() < tx.Gas() || !result.pre.matches(env.state) {
+		TxResultMissMeter.Mark(1)
+		exec.pre = readState(env.state, result.accessed)
+		return nil, exec
+	}
+	TxResultHitMeter.Mark(1)
+
+	// Mirror core.ApplyTransaction, the block hash being taken before the gas update.
+	blockHash := env.header.Hash()
+	env.gasPool.SubGas(result.gasUsed)
+	for addr, after := range result.post {
+		if !after.exists {
+			continue
+		}
+		if env.state.GetNonce(addr) != after.nonce {
+			env.state.SetNonce(addr, after.nonce)
+		}
+		if env.state.GetBalance(addr).Cmp(after.balance) != 0 {
+			env.state.SetBalance(addr, new(big.Int).Set(after.balance))
+		}
+		for key, value := range after.storage {
+			env.state.SetState(addr, key, value)
+		}
+	}
+	for addr, credit := range result.credits {
+		env.state.AddBalance(addr, credit)
+	}
+	for _, log := range result.logs {
+		env.state.AddLog(&types.Log{
+			Address:     log.Address,
+			Topics:      log.Topics,
+			Data:        log.Data,
+			BlockNumber: env.header.Number.Uint64(),
+		})
+	}
+	env.state.Finalise(true)
+	env.header.GasUsed += result.gasUsed
+
+	receipt := &types.Receipt{
+		Type:              tx.Type(),
+		Status:            result.status,
+		CumulativeGasUsed: env.header.GasUsed,
+		TxHash:            tx.Hash(),
+		GasUsed:           result.gasUsed,
+		Logs:              env.state.GetLogs(tx.Hash(), blockHash),
+		BlockHash:         blockHash,
+		BlockNumber:       env.header.Number,
+		TransactionIndex:  uint(env.state.TxIndex()),
+	}
+	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+	return receipt, nil
+}
+
+// record keeps the result of the successful execution of the transaction.
+func (c *txResultCache) record(env *environment, exec *txExecution, receipt *types.Receipt) {
+	accessed := env.state.AccessList()
+	result := &txResult{accessed: accessed}
+	if exec.pre != nil && exec.pre.covers(accessed) {
+		if post := readState(env.state, accessed); replayable(exec.pre, post) {
+			result.pre, result.post = exec.pre, post
+			result.credits = make(map[common.Address]*big.Int)
+			for addr, balance := range exec.balances {
+				if _, ok := post[addr]; !ok {
+					result.credits[addr] = new(big.Int).Sub(env.state.GetBalance(addr), balance)
+				}
+			}
+			result.gasUsed, result.status = receipt.GasUsed, receipt.Status
+			for _, log := range receipt.Logs {
+				result.logs = append(result.logs, &types.Log{Address: log.Address, Topics: log.Topics, Data: log.Data})
+			}
+		}
+	}
+	c.results.Add(exec.key, result)
+}
@@ -30,7 +30,6 @@ import (
 
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/consensus"
-	"github.com/autonity/autonity/consensus/misc"
 	"github.com/autonity/autonity/core"
 	"github.com/autonity/autonity/core/state"
 	"github.com/autonity/autonity/core/types"
@@ -69,6 +68,10 @@ const (
 	// any newly arrived transactions.
 	maxRecommitInterval = 15 * time.Second
 
+	// defaultEmptyBlockInterval is the idle time after which an empty block is built
+	// when the production of empty blocks is enabled without an explicit interval.
+	defaultEmptyBlockInterval = 1 * time.Second
+
 	// intervalAdjustRatio is the impact a single interval adjustment has on sealing work
 	// resubmitting interval.
 	intervalAdjustRatio = 0.1
@@ -81,6 +84,22 @@ const (
 	staleThreshold = 7
 )
 
+var (
+	// ErrParentStateUnavailable is returned when building on a parent block whose
+	// state is neither live nor recoverable, e.g. because it was pruned.
+	ErrParentStateUnavailable = errors.New("parent state unavailable")
+
+	// ErrInvalidRecommitInterval is returned when setting a recommit interval which
+	// is not positive.
+	ErrInvalidRecommitInterval = errors.New("recommit interval must be positive")
+
+	// errReplayProtectedTx is reported for replay protected transactions seen before the EIP155 fork.
+	errReplayProtectedTx = errors.New("replay protected transaction before eip155")
+
+	// errTxGasTooHigh is reported for transactions whose gas limit exceeds Config.MaxGasPerTx.
+	errTxGasTooHigh = errors.New("transaction gas limit above the per transaction maximum")
+)
+
 // environment is the worker's current environment and holds all
 // information of the sealing block generation.
 type environment struct {
@@ -97,6 +116,30 @@ type environment struct {
 	txs      []*types.Transaction
 	receipts []*types.Receipt
 	uncles   map[common.Hash]*types.Header
+	rejected []TxRejection // transactions dropped during the assembly, with the reason why
+
+	systemGasUsed   uint64   // gas used by system transactions, counted toward the reserved system gas
+	largeGasUsed    uint64   // gas used by large transactions, counted toward their budget
+	coinbaseBalance *big.Int // balance of the coinbase before the block, to compute its reward
+	simulated       bool     // the block is only simulated, the worker state must be left untouched
+
+	excluded map[common.Hash]struct{} // pool transactions left out of the block, nil if none
+
+	deadline    time.Time // time after which no further pool transaction is applied, zero if unbounded
+	deadlineHit bool      // the deadline cut the filling short
+}
+
+// exclude leaves the given pool transactions out of the block.
+func (env *environment) exclude(hashes ...common.Hash) {
+	if len(hashes) == 0 {
+		return
+	}
+	if env.excluded == nil {
+		env.excluded = make(map[common.Hash]struct{}, len(hashes))
+	}
+	for _, hash := range hashes {
+		env.excluded[hash] = struct{}{}
+	}
 }
 
 // copy creates a deep copy of environment.
@@ -110,7 +153,13 @@ func (env *environment) copy() *environment {
 		coinbase:  env.coinbase,
 		header:    types.CopyHeader(env.header),
 		receipts:  copyReceipts(env.receipts),
+
+		systemGasUsed:   env.systemGasUsed,
+		largeGasUsed:    env.largeGasUsed,
+		coinbaseBalance: env.coinbaseBalance,
 	}
+	cpy.rejected = make([]TxRejection, len(env.rejected))
+	copy(cpy.rejected, env.rejected)
 	if env.gasPool != nil {
 		gasPool := *env.gasPool
 		cpy.gasPool = &gasPool
@@ -151,6 +200,8 @@ type task struct {
 	state     *state.StateDB
 	block     *types.Block
 	createdAt time.Time
+	elapsed   time.Duration // time spent assembling the block
+	reseal    bool          // submitted again by ResealPending, the duplicate check is skipped
 }
 
 const (
@@ -170,7 +221,7 @@ type newWorkReq struct {
 type getWorkReq struct {
 	params *generateParams
 	err    error
-	result chan *types.Block
+	result chan *BuildResult
 }
 
 // intervalAdjust represents a resubmitting interval adjustment.
@@ -188,8 +239,14 @@ type worker struct {
 	eth         Backend
 	chain       *core.BlockChain
 
+	gasLimitStepDivisor uint64         // Sanitized bound divisor of the gas limit adjustment toward the ceiling
+	largeTxBudget       *txBudget      // Gas budget of the large transactions, nil if disabled
+	txResults           *txResultCache // Executions of the transactions replayed across rebuilds, nil if disabled
+
 	// Feeds
-	pendingLogsFeed event.Feed
+	pendingLogsFeed *logsFeed
+	chainHeadFeed   headerFeed // Heads the worker rebuilt its sealing work on
+	sealedBlockFeed sealedBlockFeed
 
 	// Subscriptions
 	mux          *event.TypeMux
@@ -206,6 +263,7 @@ type worker struct {
 	taskCh             chan *task
 	resultCh           chan *types.Block
 	startCh            chan struct{}
+	candidateReqCh     chan uint64 // Heights the consensus engine asks a candidate block for
 	exitCh             chan struct{}
 	resubmitIntervalCh chan time.Duration
 	resubmitAdjustCh   chan *intervalAdjust
@@ -216,17 +274,41 @@ type worker struct {
 	localUncles  map[common.Hash]*types.Block // A set of side blocks generated locally as the possible uncle blocks.
 	remoteUncles map[common.Hash]*types.Block // A set of side blocks as the possible uncle blocks.
 
-	mu       sync.RWMutex // The lock used to protect the coinbase and extra fields
-	coinbase common.Address
-	extra    []byte
+	// Block building settings changeable at runtime, guarded by mu
+	mu                sync.RWMutex
+	coinbase          common.Address
+	coinbaseChanges   []coinbaseChange // Scheduled coinbase changes, sorted by height
+	extra             []byte
+	prioritizer       TxPrioritizer     // Custom transaction inclusion order, nil for the default tip based one
+	systemTxProvider  SystemTxProvider  // Protocol transactions placed at the top of each block, nil if none
+	systemSenders     systemSenderSet   // Trusted senders whose calls to the protocol contracts are system transactions
+	rewardSplitter    RewardSplitter    // Redistribution of the coinbase reward at the end of each block, nil if none
+	commitHook        CommitHook        // Inspection of the assembled blocks before sealing, nil if none
+	gasCeilRamp       *gasCeilRamp      // Gradual change of the gas ceil in progress, nil if none
+	deniedSenders     senderDenylist    // Senders whose transactions are never included, nil if none
+	priorityAccounts  []common.Address  // Senders whose transactions are included first regardless of their tip
+	baseFeeCalculator BaseFeeCalculator // Base fee of the blocks built, nil for the EIP-1559 formula
 
 	pendingMu    sync.RWMutex
 	pendingTasks map[common.Hash]*task
+	pendingLimit int   // Maximum number of pending tasks retained, 0 = unlimited
+	sealing      *task // Last task submitted for sealing, see ResealPending
+
+	bundlesMu sync.RWMutex // The lock used to protect the bundle queue
+	bundles   []*bundle    // Transaction bundles waiting for inclusion
+
+	txFirstSeen map[common.Hash]uint64 // Block height at which each pending transaction was first seen
+
+	sealedMu sync.Mutex                  // The lock used to protect the sealed blocks below
+	sealed   map[common.Hash]sealedBlock // Blocks sealed locally waiting to become the chain head, by seal hash
+
+	fullness blockFullness // Gas used to gas limit ratios of the last blocks sealed locally
 
 	snapshotMu       sync.RWMutex // The lock used to protect the snapshots below
 	snapshotBlock    *types.Block
 	snapshotReceipts types.Receipts
 	snapshotState    *state.StateDB
+	snapshotReady    chan struct{} // Closed once the first snapshot is taken, see pendingReady
 
 	// atomic status counters
 	running int32 // The indicator whether the consensus engine is running or not.
@@ -247,6 +329,7 @@ type worker struct {
 	skipSealHook func(*task) bool                   // Method to decide whether skipping the sealing.
 	fullTaskHook func()                             // Method to call before pushing the full sealing task.
 	resubmitHook func(time.Duration, time.Duration) // Method to call upon updating resubmitting interval.
+	applyTxHook  func(*types.Transaction)           // Method to call before applying a pool transaction.
 }
 
 func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus.Engine, eth Backend, mux *event.TypeMux, isLocalBlock func(header *types.Header) bool, init bool) *worker {
@@ -262,6 +345,10 @@ func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus
 		localUncles:        make(map[common.Hash]*types.Block),
 		remoteUncles:       make(map[common.Hash]*types.Block),
 		pendingTasks:       make(map[common.Hash]*task),
+		pendingLimit:       config.PendingBlockLimit,
+		pendingLogsFeed:    newLogsFeed(config.MaxPendingLogSubscribers, config.DropSlowPendingLogSubscribers, config.MaxBufferedPendingLogs),
+		largeTxBudget:      newLargeTxBudget(config),
+		txResults:          newTxResultCache(config.TxResultCacheSize),
 		txsCh:              make(chan core.NewTxsEvent, txChanSize),
 		chainHeadCh:        make(chan core.ChainHeadEvent, chainHeadChanSize),
 		chainSideCh:        make(chan core.ChainSideEvent, chainSideChanSize),
@@ -271,21 +358,36 @@ func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus
 		resultCh:           make(chan *types.Block, resultQueueSize),
 		exitCh:             make(chan struct{}),
 		startCh:            make(chan struct{}, 1),
+		candidateReqCh:     make(chan uint64, 1),
 		resubmitIntervalCh: make(chan time.Duration),
 		resubmitAdjustCh:   make(chan *intervalAdjust, resubmitAdjustChanSize),
 	}
+	// Build a candidate block on demand when the consensus engine supports asking for one.
+	if requester, ok := engine.(consensus.CandidateRequester); ok {
+		requester.SetCandidateRequestHandler(worker.requestCandidate)
+	}
 	// Subscribe NewTxsEvent for tx pool
 	worker.txsSub = eth.TxPool().SubscribeNewTxsEvent(worker.txsCh)
 	// Subscribe events for blockchain
 	worker.chainHeadSub = eth.BlockChain().SubscribeChainHeadEvent(worker.chainHeadCh)
 	worker.chainSideSub = eth.BlockChain().SubscribeChainSideEvent(worker.chainSideCh)
 
+	worker.setSystemTxSenders(config.SystemTxSenders)
+
 	// Sanitize recommit interval if the user-specified one is too short.
 	recommit := worker.config.Recommit
 	if recommit < minRecommitInterval {
 		eth.Logger().Warn("Sanitizing miner recommit interval", "provided", recommit, "updated", minRecommitInterval)
 		recommit = minRecommitInterval
 	}
+	// Sanitize the gas limit step divisor, a lower value would exceed the protocol's max per-block delta.
+	worker.gasLimitStepDivisor = worker.config.GasLimitStepDivisor
+	if worker.gasLimitStepDivisor == 0 {
+		worker.gasLimitStepDivisor = params.GasLimitBoundDivisor
+	} else if worker.gasLimitStepDivisor < params.GasLimitBoundDivisor {
+		eth.Logger().Warn("Sanitizing miner gas limit step divisor", "provided", worker.gasLimitStepDivisor, "updated", params.GasLimitBoundDivisor)
+		worker.gasLimitStepDivisor = params.GasLimitBoundDivisor
+	}
 
 	worker.wg.Add(4)
 	go worker.mainLoop()
@@ -304,6 +406,52 @@ func (w *worker) setGasCeil(ceil uint64) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 	w.config.GasCeil = ceil
+	w.gasCeilRamp = nil
+}
+
+// gasCeilRamp linearly moves the gas ceil from one value to another over a number
+// of blocks following a start block.
+type gasCeilRamp struct {
+	from, to uint64
+	start    uint64
+	blocks   uint64
+}
+
+// setGasCeilWithRamp moves the gas ceil from its current value to the target one
+// linearly over the given number of blocks, starting with the next block.
+func (w *worker) setGasCeilWithRamp(target uint64, blocks uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if blocks == 0 {
+		w.config.GasCeil = target
+		w.gasCeilRamp = nil
+		return
+	}
+	head := w.chain.CurrentBlock().NumberU64()
+	w.gasCeilRamp = &gasCeilRamp{
+		from:   w.gasCeil(head),
+		to:     target,
+		start:  head,
+		blocks: blocks,
+	}
+	w.config.GasCeil = target
+}
+
+// gasCeil returns the gas ceil of the given block. The gas limit still moves toward
+// it within the protocol's per-block bound. The caller must hold w.mu.
+func (w *worker) gasCeil(number uint64) uint64 {
+	ramp := w.gasCeilRamp
+	if ramp == nil || number >= ramp.start+ramp.blocks {
+		return w.config.GasCeil
+	}
+	if number <= ramp.start {
+		return ramp.from
+	}
+	step := number - ramp.start
+	if ramp.to >= ramp.from {
+		return ramp.from + (ramp.to-ramp.from)*step/ramp.blocks
+	}
+	return ramp.from - (ramp.from-ramp.to)*step/ramp.blocks
 }
 
 // setExtra sets the content used to initialize the block extra field.
@@ -313,12 +461,17 @@ func (w *worker) setExtra(extra []byte) {
 	w.extra = extra
 }
 
-// setRecommitInterval updates the interval for miner sealing work recommitting.
-func (w *worker) setRecommitInterval(interval time.Duration) {
+// setRecommitInterval updates the interval for miner sealing work recommitting, the
+// intervals below minRecommitInterval are raised to it.
+func (w *worker) setRecommitInterval(interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("%w: %v", ErrInvalidRecommitInterval, interval)
+	}
 	select {
 	case w.resubmitIntervalCh <- interval:
 	case <-w.exitCh:
 	}
+	return nil
 }
 
 // disablePreseal disables pre-sealing feature
@@ -378,6 +531,15 @@ func (w *worker) stop() {
 	}
 }
 
+// requestCandidate asks for a new sealing block for the given height, the request
+// is dropped if one is already queued.
+func (w *worker) requestCandidate(height uint64) {
+	select {
+	case w.candidateReqCh <- height:
+	default:
+	}
+}
+
 // isRunning returns an indicator whether worker is running or not.
 func (w *worker) isRunning() bool {
 	return atomic.LoadInt32(&w.running) == 1
@@ -426,6 +588,19 @@ func (w *worker) newWorkLoop(recommit time.Duration) {
 	defer timer.Stop()
 	<-timer.C // discard the initial tick
 
+	// emptyTimer rebuilds the sealing block once the chain has been idle for the
+	// empty block interval, it is only armed if empty blocks production is enabled.
+	var emptyInterval time.Duration
+	if w.config.ProduceEmptyBlocks {
+		emptyInterval = w.config.EmptyBlockInterval
+		if emptyInterval <= 0 {
+			emptyInterval = defaultEmptyBlockInterval
+		}
+	}
+	emptyTimer := time.NewTimer(0)
+	defer emptyTimer.Stop()
+	<-emptyTimer.C // discard the initial tick
+
 	// commit aborts in-flight transaction execution with given signal and resubmits a new one.
 	commit := func(noempty bool, s int32) {
 		if interrupt != nil {
@@ -438,6 +613,9 @@ func (w *worker) newWorkLoop(recommit time.Duration) {
 			return
 		}
 		timer.Reset(recommit)
+		if emptyInterval > 0 {
+			emptyTimer.Reset(emptyInterval)
+		}
 		atomic.StoreInt32(&w.newTxs, 0)
 	}
 	// clearPending cleans the stale pending tasks.
@@ -458,13 +636,24 @@ func (w *worker) newWorkLoop(recommit time.Duration) {
 			timestamp = time.Now().Unix()
 			commit(false, commitInterruptNewHead)
 
+		case height := <-w.candidateReqCh:
+			// The consensus engine is proposer without candidate, build one straight away
+			// unless the chain moved on meanwhile.
+			if w.isRunning() && w.chain.CurrentBlock().NumberU64()+1 == height {
+				timestamp = time.Now().Unix()
+				commit(false, commitInterruptResubmit)
+			}
+
 		case head := <-w.chainHeadCh:
+			w.observeHead(head.Block)
+			w.dropIncludedBundles(head.Block)
 			clearPending(head.Block.NumberU64())
 			timestamp = time.Now().Unix()
 			if h, ok := w.engine.(consensus.Handler); ok {
 				h.NewChainHead()
 			}
 			commit(false, commitInterruptNewHead)
+			w.chainHeadFeed.send(head.Block.Header())
 
 		case <-timer.C:
 			// If sealing is running resubmit a new work cycle periodically to pull in
@@ -478,6 +667,18 @@ func (w *worker) newWorkLoop(recommit time.Duration) {
 				commit(true, commitInterruptResubmit)
 			}
 
+		case <-emptyTimer.C:
+			// Build an empty block promptly if no transaction is waiting for inclusion,
+			// the recommit cycles take care of the pending ones otherwise.
+			if w.isRunning() {
+				if pending, _ := w.eth.TxPool().Stats(); pending == 0 {
+					timestamp = time.Now().Unix()
+					commit(false, commitInterruptResubmit)
+					continue
+				}
+			}
+			emptyTimer.Reset(emptyInterval)
+
 		case interval := <-w.resubmitIntervalCh:
 			// Adjust resubmit interval explicitly by user.
 			if interval < minRecommitInterval {
@@ -537,12 +738,12 @@ func (w *worker) mainLoop() {
 			w.commitWork(req.interrupt, req.noempty, req.timestamp)
 
 		case req := <-w.getWorkCh:
-			block, err := w.generateWork(req.params)
+			result, err := w.generateWork(req.params)
 			if err != nil {
 				req.err = err
 				req.result <- nil
 			} else {
-				req.result <- block
+				req.result <- result
 			}
 
 		case ev := <-w.chainSideCh:
@@ -598,7 +799,7 @@ func (w *worker) mainLoop() {
 					acc, _ := types.Sender(w.current.signer, tx)
 					txs[acc] = append(txs[acc], tx)
 				}
-				txset := types.NewTransactionsByPriceAndNonce(w.current.signer, txs, w.current.header.BaseFee)
+				txset := w.orderTransactions(w.current, txs)
 				tcount := w.current.tcount
 				w.commitTransactions(w.current, txset, nil)
 
@@ -647,20 +848,19 @@ func (w *worker) taskLoop() {
 			}
 			// Reject duplicate sealing work due to resubmitting.
 			sealHash := w.engine.SealHash(task.block.Header())
-			if sealHash == prev {
+			if sealHash == prev && !task.reseal {
 				continue
 			}
 			// Interrupt previous sealing operation
 			interrupt()
 			stopCh, prev = make(chan struct{}), sealHash
+			w.setSealingTask(task)
 
 			if w.skipSealHook != nil && w.skipSealHook(task) {
 				continue
 			}
 			w.eth.Logger().Debug("New block Seal request", "hash", w.engine.SealHash(task.block.Header()))
-			w.pendingMu.Lock()
-			w.pendingTasks[sealHash] = task
-			w.pendingMu.Unlock()
+			w.addPendingTask(sealHash, task)
 
 			sealStart := time.Now()
 			if err := w.engine.Seal(w.chain, task.block, w.resultCh, stopCh); err != nil {
@@ -668,6 +868,8 @@ func (w *worker) taskLoop() {
 				w.pendingMu.Lock()
 				delete(w.pendingTasks, sealHash)
 				w.pendingMu.Unlock()
+			} else {
+				w.recordSealed(sealHash, task.block.NumberU64())
 			}
 
 			if metrics.Enabled {
@@ -741,6 +943,16 @@ func (w *worker) resultLoop() {
 				CopyWorkBg.Add(now.Sub(copyStart).Nanoseconds())
 			}
 
+			w.fullness.record(block)
+			w.sealedBlockFeed.send(&SealedBlock{
+				Block:        block,
+				Receipts:     receipts,
+				GasUsed:      block.GasUsed(),
+				Fees:         blockFees(block, receipts),
+				AssemblyTime: task.elapsed,
+				SealTime:     time.Since(task.createdAt),
+			})
+
 			// Commit block and state to database.
 			persistStart := time.Now()
 			_, err := w.chain.WriteBlockAndSetHead(block, receipts, logs, task.state, true)
@@ -771,17 +983,18 @@ func (w *worker) makeEnv(parent *types.Block, header *types.Header, coinbase com
 	// the miner to speed block sealing up a bit.
 	state, err := w.chain.StateAt(parent.Root())
 	if err != nil {
-		// Note since the sealing block can be created upon the arbitrary parent
-		// block, but the state of parent block may already be pruned, so the necessary
-		// state recovery is needed here in the future.
+		// The sealing block can be created upon an arbitrary parent block, which is
+		// not necessarily the current head, so its state may not be live anymore.
+		// Try to regenerate it by re-executing the blocks from the closest available state.
 		//
 		// The maximum acceptable reorg depth can be limited by the finalised block
 		// somehow. TODO(rjl493456442) fix the hard-coded number here later.
 		state, err = w.eth.StateAtBlock(parent, 1024, nil, false, false)
-		w.eth.Logger().Warn("Recovered mining state", "root", parent.Root(), "err", err)
-	}
-	if err != nil {
-		return nil, err
+		if err != nil {
+			w.eth.Logger().Warn("Failed to recover mining state", "number", parent.Number(), "root", parent.Root(), "err", err)
+			return nil, fmt.Errorf("%w: block %d (%s): %v", ErrParentStateUnavailable, parent.NumberU64(), parent.Hash().Hex(), err)
+		}
+		w.eth.Logger().Info("Recovered mining state", "number", parent.Number(), "root", parent.Root())
 	}
 	state.StartPrefetcher("miner")
 
@@ -795,6 +1008,8 @@ func (w *worker) makeEnv(parent *types.Block, header *types.Header, coinbase com
 		header:    header,
 		uncles:    make(map[common.Hash]*types.Header),
 	}
+	// the balance is copied as the state object is updated in place
+	env.coinbaseBalance = new(big.Int).Set(state.GetBalance(coinbase))
 	// when 08 is processed ancestors contain 07 (quick block)
 	for _, ancestor := range w.chain.GetBlocksFromHash(parent.Hash(), 7) {
 		for _, uncle := range ancestor.Uncles() {
@@ -841,29 +1056,82 @@ func (w *worker) updateSnapshot(env *environment) {
 	)
 	w.snapshotReceipts = copyReceipts(env.receipts)
 	w.snapshotState = env.state.Copy()
+
+	select {
+	case <-w.pendingReady():
+	default:
+		close(w.snapshotReady)
+	}
 }
 
 func (w *worker) commitTransaction(env *environment, tx *types.Transaction) ([]*types.Log, error) {
-	snap := env.state.Snapshot()
+	if w.applyTxHook != nil {
+		w.applyTxHook(tx)
+	}
+	if w.config.SkipRevertedTxs {
+		return w.applySucceedingTransaction(env, tx, w.isSystemTx(env.signer, tx))
+	}
+	return w.applyTransaction(env, tx, w.isSystemTx(env.signer, tx))
+}
 
-	receipt, err := core.ApplyTransaction(w.chainConfig, w.chain, &env.coinbase, env.gasPool, env.state, env.header, tx, &env.header.GasUsed, *w.chain.GetVMConfig())
-	if err != nil {
-		env.state.RevertToSnapshot(snap)
-		return nil, err
+// applyTransaction applies the transaction on top of the environment, system
+// transactions being allowed to use the reserved gas.
+func (w *worker) applyTransaction(env *environment, tx *types.Transaction, system bool) ([]*types.Log, error) {
+	// User transactions can't use the gas reserved for system transactions
+	if !system && w.config.ReservedSystemGas > 0 && w.userGasAvailable(env) < tx.Gas() {
+		return nil, core.ErrGasLimitReached
+	}
+	// Large transactions can't use more than their own budget
+	large := w.largeTxBudget != nil && w.largeTxBudget.member(tx)
+	if large && !w.largeTxBudget.allows(tx, env.largeGasUsed) {
+		return nil, errBudgetExceeded
+	}
+	// The cached executions rely on the access list to know the state they depend on
+	var (
+		receipt *types.Receipt
+		exec    *txExecution
+	)
+	if w.txResults != nil && w.chainConfig.IsBerlin(env.header.Number) {
+		receipt, exec = w.txResults.replay(env, tx)
+	}
+	if receipt == nil {
+		snap := env.state.Snapshot()
+
+		var err error
+		receipt, err = core.ApplyTransaction(w.chainConfig, w.chain, &env.coinbase, env.gasPool, env.state, env.header, tx, &env.header.GasUsed, *w.chain.GetVMConfig())
+		if err != nil {
+			env.state.RevertToSnapshot(snap)
+			return nil, err
+		}
+		if exec != nil {
+			w.txResults.record(env, exec, receipt)
+		}
 	}
 	env.txs = append(env.txs, tx)
 	env.receipts = append(env.receipts, receipt)
+	if system {
+		env.systemGasUsed += receipt.GasUsed
+	}
+	if large {
+		env.largeGasUsed += receipt.GasUsed
+	}
 
 	return receipt.Logs, nil
 }
 
-func (w *worker) commitTransactions(env *environment, txs *types.TransactionsByPriceAndNonce, interrupt *int32) bool {
+func (w *worker) commitTransactions(env *environment, txs orderedTransactions, interrupt *int32) bool {
 	gasLimit := env.header.GasLimit
 	if env.gasPool == nil {
 		env.gasPool = new(core.GasPool).AddGas(gasLimit)
 	}
 	var coalescedLogs []*types.Log
 
+	w.mu.RLock()
+	denied := w.deniedSenders
+	maxGasPerTx := w.config.MaxGasPerTx
+	maxTxs := w.config.MaxBlockTxs
+	w.mu.RUnlock()
+
 	for {
 		// In the following three cases, we will interrupt the execution of the transaction.
 		// (1) new head block event arrival, the interrupt signal is 1
@@ -885,6 +1153,14 @@ func (w *worker) commitTransactions(env *environment, txs *types.TransactionsByP
 			}
 			return atomic.LoadInt32(interrupt) == commitInterruptNewHead
 		}
+		// Once the assembly deadline passed the block is sealed with what was committed so far
+		if w.pastDeadline(env) {
+			break
+		}
+		if maxTxs > 0 && env.tcount >= maxTxs {
+			w.eth.Logger().Trace("Maximum transaction count reached", "txs", env.tcount)
+			break
+		}
 		// If we don't have enough gas for any further transactions then we're done
 		if env.gasPool.Gas() < params.TxGas {
 			w.eth.Logger().Trace("Not enough gas for further transactions", "have", env.gasPool, "want", params.TxGas)
@@ -900,10 +1176,34 @@ func (w *worker) commitTransactions(env *environment, txs *types.TransactionsByP
 		//
 		// We use the eip155 signer regardless of the current hf.
 		from, _ := types.Sender(env.signer, tx)
+		// Skip the excluded transactions, the following ones of the account may still apply
+		// if the excluded one was included otherwise.
+		if _, ok := env.excluded[tx.Hash()]; ok {
+			txs.Shift()
+			continue
+		}
+		// Skip the denied senders, their transactions are left in the pool.
+		if denied.contains(from) {
+			w.eth.Logger().Trace("Skipping transaction of denied sender", "sender", from, "hash", tx.Hash())
+			env.rejected = append(env.rejected, TxRejection{Hash: tx.Hash(), Reason: errDeniedSender})
+
+			txs.Pop()
+			continue
+		}
+		// Skip the transactions over the gas cap, a single one could monopolize the block.
+		// They are left in the pool.
+		if maxGasPerTx != 0 && tx.Gas() > maxGasPerTx {
+			w.eth.Logger().Trace("Skipping transaction over the gas cap", "hash", tx.Hash(), "gas", tx.Gas(), "cap", maxGasPerTx)
+			env.rejected = append(env.rejected, TxRejection{Hash: tx.Hash(), Reason: errTxGasTooHigh})
+
+			txs.Pop()
+			continue
+		}
 		// Check whether the tx is replay protected. If we're not in the EIP155 hf
 		// phase, start ignoring the sender until we do.
 		if tx.Protected() && !w.chainConfig.IsEIP155(env.header.Number) {
 			w.eth.Logger().Trace("Ignoring reply protected transaction", "hash", tx.Hash(), "eip155", w.chainConfig.EIP155Block)
+			env.rejected = append(env.rejected, TxRejection{Hash: tx.Hash(), Reason: errReplayProtectedTx})
 
 			txs.Pop()
 			continue
@@ -912,12 +1212,25 @@ func (w *worker) commitTransactions(env *environment, txs *types.TransactionsByP
 		env.state.Prepare(tx.Hash(), env.tcount)
 
 		logs, err := w.commitTransaction(env, tx)
+		if err != nil {
+			env.rejected = append(env.rejected, TxRejection{Hash: tx.Hash(), Reason: err})
+		}
 		switch {
 		case errors.Is(err, core.ErrGasLimitReached):
 			// Pop the current out-of-gas transaction without shifting in the next from the account
 			w.eth.Logger().Trace("Gas limit exceeded for current block", "sender", from)
 			txs.Pop()
 
+		case errors.Is(err, errBudgetExceeded):
+			// Pop the transaction over the budget of its class without shifting in the next from the account
+			w.eth.Logger().Trace("Transaction class budget exceeded", "sender", from, "hash", tx.Hash())
+			txs.Pop()
+
+		case errors.Is(err, errTxReverted):
+			// Pop the reverted transaction, the next from the account would have a nonce gap
+			w.eth.Logger().Trace("Skipping reverted transaction", "sender", from, "hash", tx.Hash())
+			txs.Pop()
+
 		case errors.Is(err, core.ErrNonceTooLow):
 			// New head notification data race between the transaction pool and miner, shift
 			w.eth.Logger().Trace("Skipping transaction with low nonce", "sender", from, "nonce", tx.Nonce())
@@ -947,7 +1260,7 @@ func (w *worker) commitTransactions(env *environment, txs *types.TransactionsByP
 		}
 	}
 
-	if !w.isRunning() && len(coalescedLogs) > 0 {
+	if !w.isRunning() && !env.simulated && len(coalescedLogs) > 0 {
 		// We don't push the pendingLogsEvent while we are sealing. The reason is that
 		// when we are sealing, the worker will regenerate a sealing block every 3 seconds.
 		// In order to avoid pushing the repeated pendingLog, we disable the pending log pushing.
@@ -970,15 +1283,50 @@ func (w *worker) commitTransactions(env *environment, txs *types.TransactionsByP
 	return false
 }
 
+// TxRejection describes a transaction which was dropped during the block assembly.
+type TxRejection struct {
+	Hash   common.Hash
+	Reason error
+}
+
+// BuildParams wraps the parameters of an external block building request.
+type BuildParams struct {
+	Parent    common.Hash        // Parent block hash, empty means the latest chain head
+	Timestamp uint64             // The timestamp of the block, must be greater than the parent one
+	Coinbase  common.Address     // The fee recipient address for including transaction
+	Random    common.Hash        // The randomness value of the block, optional
+	Txs       types.Transactions // Transactions to include in this order instead of the pool content, optional
+	NoTxs     bool               // Flag whether the block is built without any transaction of the pool
+	FillPool  bool               // Flag whether the pool content follows the given Txs
+	Exclude   []common.Hash      // Pool transactions left out of the block, e.g. already part of a bundle
+}
+
+// BuildResult wraps an assembled block with the diagnostics gathered while building it.
+type BuildResult struct {
+	Block     *types.Block
+	Receipts  types.Receipts
+	Rejected  []TxRejection // transactions dropped from the block and the reason why
+	GasUsed   uint64
+	StateRoot common.Hash
+	Elapsed   time.Duration // time spent assembling the block
+
+	state *state.StateDB // state after the block, see pendingStateAt
+}
+
 // generateParams wraps various of settings for generating sealing task.
 type generateParams struct {
-	timestamp  uint64         // The timstamp for sealing task
-	forceTime  bool           // Flag whether the given timestamp is immutable or not
-	parentHash common.Hash    // Parent block hash, empty means the latest chain head
-	coinbase   common.Address // The fee recipient address for including transaction
-	random     common.Hash    // The randomness generated by beacon chain, empty before the merge
-	noUncle    bool           // Flag whether the uncle block inclusion is allowed
-	noExtra    bool           // Flag whether the extra field assignment is allowed
+	timestamp  uint64             // The timstamp for sealing task
+	forceTime  bool               // Flag whether the given timestamp is immutable or not
+	parentHash common.Hash        // Parent block hash, empty means the latest chain head
+	coinbase   common.Address     // The fee recipient address for including transaction
+	random     common.Hash        // The randomness generated by beacon chain, empty before the merge
+	noUncle    bool               // Flag whether the uncle block inclusion is allowed
+	noExtra    bool               // Flag whether the extra field assignment is allowed
+	simulate   bool               // Flag whether the block is only simulated
+	txs        types.Transactions // Transactions to include instead of the pool content, nil for the pool
+	noTxs      bool               // Flag whether the pool content is left out
+	fillPool   bool               // Flag whether the pool content follows the given transactions
+	exclude    []common.Hash      // Pool transactions left out of the block
 }
 
 // prepareWork constructs the sealing task according to the given parameters,
@@ -1021,23 +1369,26 @@ func (w *worker) prepareWork(genParams *generateParams) (*environment, error) {
 	header := &types.Header{
 		ParentHash: parent.Hash(),
 		Number:     num.Add(num, common.Big1),
-		GasLimit:   core.CalcGasLimit(parent.GasLimit(), w.config.GasCeil),
+		GasLimit:   core.CalcGasLimitWithDivisor(parent.GasLimit(), w.gasLimitTarget(parent.Header()), w.gasLimitStepDivisor),
 		Time:       timestamp,
 		Coinbase:   genParams.coinbase,
 	}
 	if !genParams.noExtra && len(w.extra) != 0 {
 		header.Extra = w.extra
 	}
+	if !genParams.noExtra && w.config.GasLimitVote != 0 {
+		header.Extra = appendGasVote(w.extra, w.config.GasLimitVote)
+	}
 	// Set the randomness field from the beacon chain if it's available.
 	if genParams.random != (common.Hash{}) {
 		header.MixDigest = genParams.random
 	}
 	// Set baseFee and GasLimit if we are on an EIP-1559 chain
 	if w.chainConfig.IsLondon(header.Number) {
-		header.BaseFee = misc.CalcBaseFee(w.chainConfig, parent.Header(), w.chain)
+		header.BaseFee = w.baseFee(parent.Header())
 		if !w.chainConfig.IsLondon(parent.Number()) {
 			parentGasLimit := parent.GasLimit() * params.ElasticityMultiplier
-			header.GasLimit = core.CalcGasLimit(parentGasLimit, w.config.GasCeil)
+			header.GasLimit = core.CalcGasLimitWithDivisor(parentGasLimit, w.gasLimitTarget(parent.Header()), w.gasLimitStepDivisor)
 		}
 	}
 	// Run the consensus preparation with the default or customized consensus engine.
@@ -1053,6 +1404,7 @@ func (w *worker) prepareWork(genParams *generateParams) (*environment, error) {
 		w.eth.Logger().Error("Failed to create sealing context", "err", err)
 		return nil, err
 	}
+	env.simulated = genParams.simulate
 	// Accumulate the uncles for the sealing work only if it's allowed.
 	if !genParams.noUncle && w.chainConfig.Ethash != nil {
 		commitUncles := func(blocks map[common.Hash]*types.Block) {
@@ -1075,12 +1427,67 @@ func (w *worker) prepareWork(genParams *generateParams) (*environment, error) {
 }
 
 // fillTransactions retrieves the pending transactions from the txpool and fills them
-// into the given sealing block. The transaction selection and ordering strategy can
-// be customized with the plugin in the future.
-func (w *worker) fillTransactions(interrupt *int32, env *environment) {
+// into the given sealing block, after the transactions of the system transaction
+// provider. The transaction selection and ordering strategy can be customized with
+// the plugin in the future. An error is only returned if the provider failed.
+func (w *worker) fillTransactions(interrupt *int32, env *environment) error {
+	w.setDeadline(env)
+	// The provided protocol transactions always come first.
+	if err := w.commitProvidedTransactions(env); err != nil {
+		return err
+	}
+	pending := w.eth.TxPool().Pending(true)
+
+	// System transactions from the pool are placed next, they can use the reserved gas.
+	system := w.systemTransactions(pending)
+	if len(system) > 0 {
+		pending = withoutPrefixes(pending, system)
+		txs := w.orderTransactions(env, system)
+		if w.commitTransactions(env, txs, interrupt) {
+			return nil
+		}
+	}
+
+	// The transactions of the priority accounts are placed next, regardless of their tip.
+	priority := w.priorityTransactions(pending)
+	if len(priority) > 0 {
+		pending = withoutPrefixes(pending, priority)
+		txs := w.orderTransactions(env, priority)
+		if w.commitTransactions(env, txs, interrupt) {
+			return nil
+		}
+	}
+
+	// Bundles are applied next as they are all-or-nothing and the most sensitive to ordering.
+	w.commitBundles(env)
+
+	// Transactions starved for too long are included first, regardless of their tip.
+	if w.config.MaxPendingBlocks > 0 {
+		if aged := w.agedTransactions(withoutPrefixes(withoutPrefixes(w.eth.TxPool().Pending(false), system), priority), env.header.Number.Uint64(), !env.simulated); len(aged) > 0 {
+			pending = withoutPrefixes(pending, aged)
+			txs := w.orderTransactions(env, aged)
+			if w.commitTransactions(env, txs, interrupt) {
+				return nil
+			}
+		}
+	}
+
+	// Large transactions are filled against their own budget before the regular ones take the remaining gas.
+	if w.largeTxBudget != nil {
+		if large := w.largeTxBudget.leading(pending); len(large) > 0 {
+			pending = withoutPrefixes(pending, large)
+			if minTip := w.minTip(env.header.BaseFee); minTip != nil {
+				large = withRemoteMinTip(large, w.eth.TxPool().Locals(), env.header.BaseFee, minTip)
+			}
+			txs := w.orderTransactions(env, large)
+			if w.commitTransactions(env, txs, interrupt) {
+				return nil
+			}
+		}
+	}
+
 	// Split the pending transactions into locals and remotes
 	// Fill the block with all available pending transactions.
-	pending := w.eth.TxPool().Pending(true)
 	localTxs, remoteTxs := make(map[common.Address]types.Transactions), pending
 	for _, account := range w.eth.TxPool().Locals() {
 		if txs := remoteTxs[account]; len(txs) > 0 {
@@ -1089,29 +1496,80 @@ func (w *worker) fillTransactions(interrupt *int32, env *environment) {
 		}
 	}
 	if len(localTxs) > 0 {
-		txs := types.NewTransactionsByPriceAndNonce(env.signer, localTxs, env.header.BaseFee)
+		txs := w.orderTransactions(env, localTxs)
 		if w.commitTransactions(env, txs, interrupt) {
-			return
+			return nil
 		}
 	}
+	// The tip floor tracks the base fee, like the static gas price floor it doesn't apply to locals.
+	if minTip := w.minTip(env.header.BaseFee); minTip != nil {
+		remoteTxs = withMinTip(remoteTxs, env.header.BaseFee, minTip)
+	}
 	if len(remoteTxs) > 0 {
-		txs := types.NewTransactionsByPriceAndNonce(env.signer, remoteTxs, env.header.BaseFee)
+		txs := w.orderTransactions(env, remoteTxs)
 		if w.commitTransactions(env, txs, interrupt) {
-			return
+			return nil
+		}
+	}
+	return nil
+}
+
+// commitGivenTransactions applies the given transactions in order instead of the
+// pool content. The failing ones are left out and reported as rejected.
+func (w *worker) commitGivenTransactions(env *environment, txs types.Transactions) {
+	if env.gasPool == nil {
+		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+	}
+	for _, tx := range txs {
+		env.state.Prepare(tx.Hash(), env.tcount)
+		if _, err := w.commitTransaction(env, tx); err != nil {
+			w.eth.Logger().Debug("Given transaction failed", "hash", tx.Hash(), "err", err)
+			env.rejected = append(env.rejected, TxRejection{Hash: tx.Hash(), Reason: err})
+			continue
 		}
+		env.tcount++
 	}
 }
 
 // generateWork generates a sealing block based on the given parameters.
-func (w *worker) generateWork(params *generateParams) (*types.Block, error) {
+func (w *worker) generateWork(params *generateParams) (*BuildResult, error) {
+	start := time.Now()
 	work, err := w.prepareWork(params)
 	if err != nil {
 		return nil, err
 	}
 	defer work.discard()
 
-	w.fillTransactions(nil, work)
-	return w.engine.FinalizeAndAssemble(w.chain, work.header, work.state, work.txs, work.unclelist(), &work.receipts)
+	fillPool := !params.noTxs && (params.txs == nil || params.fillPool)
+	if !params.noTxs && params.txs != nil {
+		w.commitGivenTransactions(work, params.txs)
+	}
+	if fillPool {
+		// the given transactions are never included twice
+		work.exclude(params.exclude...)
+		for _, tx := range params.txs {
+			work.exclude(tx.Hash())
+		}
+		if err := w.fillTransactions(nil, work); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.splitReward(work); err != nil {
+		w.eth.Logger().Warn("Failed to split block reward", "err", err)
+	}
+	block, err := w.engine.FinalizeAndAssemble(w.chain, work.header, work.state, work.txs, work.unclelist(), &work.receipts)
+	if err != nil {
+		return nil, err
+	}
+	return &BuildResult{
+		Block:     block,
+		Receipts:  work.receipts,
+		Rejected:  work.rejected,
+		GasUsed:   block.GasUsed(),
+		StateRoot: block.Root(),
+		Elapsed:   time.Since(start),
+		state:     work.state,
+	}, nil
 }
 
 // commitWork generates several new sealing tasks based on the parent block
@@ -1122,11 +1580,12 @@ func (w *worker) commitWork(interrupt *int32, noempty bool, timestamp int64) {
 	// Set the coinbase if the worker is running or it's required
 	var coinbase common.Address
 	if w.isRunning() {
-		if w.coinbase == (common.Address{}) {
+		// Use the preset address as the fee recipient, switching it if scheduled
+		coinbase = w.applyCoinbaseChanges(w.chain.CurrentBlock().NumberU64() + 1)
+		if coinbase == (common.Address{}) && !w.config.AllowZeroCoinbase {
 			w.eth.Logger().Error("Refusing to mine without etherbase")
 			return
 		}
-		coinbase = w.coinbase // Use the preset address as the fee recipient
 	}
 	work, err := w.prepareWork(&generateParams{
 		timestamp: uint64(timestamp),
@@ -1149,7 +1608,14 @@ func (w *worker) commitWork(interrupt *int32, noempty bool, timestamp int64) {
 
 	fillTxStart := time.Now()
 	// Fill pending transactions from the txpool
-	w.fillTransactions(interrupt, work)
+	if err := w.fillTransactions(interrupt, work); err != nil {
+		w.eth.Logger().Error("Failed to fill sealing block", "err", err)
+		work.discard()
+		return
+	}
+	if err := w.splitReward(work); err != nil {
+		w.eth.Logger().Warn("Failed to split block reward", "err", err)
+	}
 	if metrics.Enabled {
 		now := time.Now()
 		FillWorkTimer.Update(now.Sub(fillTxStart))
@@ -1197,17 +1663,20 @@ func (w *worker) commit(env *environment, interval func(), update bool, start ti
 		}
 		// If we're post merge, just ignore
 
-		select {
-		case w.taskCh <- &task{receipts: env.receipts, state: env.state, block: block, createdAt: time.Now()}:
-			w.eth.Logger().Info("Preparing new block proposal", "number", block.Number(), "sealhash", w.engine.SealHash(block.Header()),
-				"uncles", len(env.uncles), "txs", env.tcount,
-				"gas", block.GasUsed(), "fees", totalFees(block, env.receipts),
-				"elapsed", common.PrettyDuration(time.Since(start))) // Consider moving that to DEBUG level
-
-		case <-w.exitCh:
-			w.eth.Logger().Info("Worker has exited")
+		if err := w.runCommitHook(block, env.receipts); err != nil {
+			w.eth.Logger().Warn("Block sealing vetoed by the commit hook", "number", block.Number(), "sealhash", w.engine.SealHash(block.Header()), "err", err)
+		} else {
+			select {
+			case w.taskCh <- &task{receipts: env.receipts, state: env.state, block: block, createdAt: time.Now(), elapsed: time.Since(start)}:
+				w.eth.Logger().Info("Preparing new block proposal", "number", block.Number(), "sealhash", w.engine.SealHash(block.Header()),
+					"uncles", len(env.uncles), "txs", env.tcount,
+					"gas", block.GasUsed(), "fees", totalFees(block, env.receipts),
+					"elapsed", common.PrettyDuration(time.Since(start))) // Consider moving that to DEBUG level
+
+			case <-w.exitCh:
+				w.eth.Logger().Info("Worker has exited")
+			}
 		}
-
 	}
 	if update {
 		w.updateSnapshot(env)
@@ -1216,26 +1685,63 @@ func (w *worker) commit(env *environment, interval func(), update bool, start ti
 }
 
 // getSealingBlock generates the sealing block based on the given parameters.
-func (w *worker) getSealingBlock(parent common.Hash, timestamp uint64, coinbase common.Address, random common.Hash) (*types.Block, error) {
+func (w *worker) getSealingBlock(params *BuildParams) (*types.Block, error) {
+	result, err := w.buildBlock(params)
+	if err != nil {
+		return nil, err
+	}
+	return result.Block, nil
+}
+
+// buildBlock generates a sealing block based on the given parameters and returns
+// it alongside with the diagnostics gathered during its assembly.
+func (w *worker) buildBlock(params *BuildParams) (*BuildResult, error) {
+	return w.getWork(&generateParams{
+		timestamp:  params.Timestamp,
+		forceTime:  true,
+		parentHash: params.Parent,
+		coinbase:   params.Coinbase,
+		random:     params.Random,
+		noUncle:    true,
+		noExtra:    true,
+		txs:        params.Txs,
+		noTxs:      params.NoTxs,
+		fillPool:   params.FillPool,
+		exclude:    params.Exclude,
+	})
+}
+
+// simulate builds the block the worker would seal next from the current pool content,
+// without sealing it nor affecting the pending block or any other worker state.
+func (w *worker) simulate() (*types.Block, types.Receipts, error) {
+	w.mu.RLock()
+	coinbase := w.coinbaseAt(w.chain.CurrentBlock().NumberU64() + 1)
+	w.mu.RUnlock()
+
+	result, err := w.getWork(&generateParams{
+		timestamp: uint64(time.Now().Unix()),
+		coinbase:  coinbase,
+		simulate:  true,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return result.Block, result.Receipts, nil
+}
+
+// getWork generates a block in the main loop based on the given parameters.
+func (w *worker) getWork(params *generateParams) (*BuildResult, error) {
 	req := &getWorkReq{
-		params: &generateParams{
-			timestamp:  timestamp,
-			forceTime:  true,
-			parentHash: parent,
-			coinbase:   coinbase,
-			random:     random,
-			noUncle:    true,
-			noExtra:    true,
-		},
-		result: make(chan *types.Block, 1),
+		params: params,
+		result: make(chan *BuildResult, 1),
 	}
 	select {
 	case w.getWorkCh <- req:
-		block := <-req.result
-		if block == nil {
+		result := <-req.result
+		if result == nil {
 			return nil, req.err
 		}
-		return block, nil
+		return result, nil
 	case <-w.exitCh:
 		return nil, errors.New("miner closed")
 	}
@@ -1268,10 +1774,6 @@ func (w *worker) postSideBlock(event core.ChainSideEvent) {
 
 // totalFees computes total consumed miner fees in ETH. Block transactions and receipts have to have the same order.
 func totalFees(block *types.Block, receipts []*types.Receipt) *big.Float {
-	feesWei := new(big.Int)
-	for i, tx := range block.Transactions() {
-		minerFee, _ := tx.EffectiveGasTip(block.BaseFee())
-		feesWei.Add(feesWei, new(big.Int).Mul(new(big.Int).SetUint64(receipts[i].GasUsed), minerFee))
-	}
+	feesWei := blockFees(block, receipts)
 	return new(big.Float).Quo(new(big.Float).SetInt(feesWei), new(big.Float).SetInt(big.NewInt(params.Ether)))
 }
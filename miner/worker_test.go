@@ -17,6 +17,10 @@
 package miner
 
 import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"errors"
 	"github.com/autonity/autonity/accounts/abi/bind/backends"
 	tendermintcore "github.com/autonity/autonity/consensus/tendermint/core"
 	"github.com/autonity/autonity/core/state"
@@ -25,15 +29,18 @@ import (
 	"math/big"
 	"math/rand"
 	"os"
+	"reflect"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	tendermintBackend "github.com/autonity/autonity/consensus/tendermint/backend"
 
+	"github.com/autonity/autonity/autonity"
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/consensus"
 	"github.com/autonity/autonity/consensus/ethash"
+	"github.com/autonity/autonity/consensus/misc"
 	"github.com/autonity/autonity/core"
 	"github.com/autonity/autonity/core/rawdb"
 	"github.com/autonity/autonity/core/types"
@@ -41,7 +48,9 @@ import (
 	"github.com/autonity/autonity/crypto"
 	"github.com/autonity/autonity/ethdb"
 	"github.com/autonity/autonity/event"
+	"github.com/autonity/autonity/metrics"
 	"github.com/autonity/autonity/params"
+	"github.com/autonity/autonity/params/generated"
 )
 
 const (
@@ -112,11 +121,11 @@ func (b *testWorkerBackend) Logger() log.Logger {
 	return log.Root()
 }
 
-func newTestWorkerBackend(t *testing.T, chainConfig *params.ChainConfig, engine consensus.Engine, db ethdb.Database, n int) *testWorkerBackend {
+func newTestWorkerBackend(t testing.TB, chainConfig *params.ChainConfig, engine consensus.Engine, db ethdb.Database, n int) *testWorkerBackend {
 	var gspec = core.Genesis{
 		Config:     chainConfig,
 		BaseFee:    big.NewInt(params.InitialBaseFee),
-		Alloc:      core.GenesisAlloc{testBankAddress: {Balance: testBankFunds}},
+		Alloc:      core.GenesisAlloc{testBankAddress: {Balance: testBankFunds}, testUserAddress: {Balance: testBankFunds}},
 		Difficulty: big.NewInt(0),
 	}
 
@@ -206,6 +215,58 @@ func newTestWorker(t *testing.T, chainConfig *params.ChainConfig, engine consens
 	return w, backend
 }
 
+// newTestBuildWorker creates a worker with the given configuration, testConfig
+// if nil, on top of a new ethash chain with an empty pool. The worker and its
+// engine are closed once the test is done.
+func newTestBuildWorker(t testing.TB, config *Config) (*worker, *testWorkerBackend) {
+	if config == nil {
+		config = testConfig
+	}
+	engine := ethash.NewFaker()
+	t.Cleanup(func() { engine.Close() })
+	b := newTestWorkerBackend(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	w := newWorker(config, ethashChainConfig, engine, b, new(event.TypeMux), nil, false)
+	t.Cleanup(w.close)
+	return w, b
+}
+
+// newTestTransfer returns a transfer of 1000 wei signed by the given key.
+func newTestTransfer(key *ecdsa.PrivateKey, nonce uint64, to common.Address, gasPrice *big.Int) *types.Transaction {
+	tx, _ := types.SignTx(types.NewTransaction(nonce, to, big.NewInt(1000), params.TxGas, gasPrice, nil), types.LatestSigner(ethashChainConfig), key)
+	return tx
+}
+
+// addRemotes adds the transactions to the pool, failing the test if any is refused.
+func (b *testWorkerBackend) addRemotes(t testing.TB, txs ...*types.Transaction) {
+	t.Helper()
+	for _, err := range b.txPool.AddRemotesSync(txs) {
+		if err != nil {
+			t.Fatalf("failed to add transaction: %v", err)
+		}
+	}
+}
+
+// prepareTestWork prepares the work for a block on top of the head, discarded
+// once the test is done.
+func prepareTestWork(t testing.TB, w *worker) *environment {
+	t.Helper()
+	parent := w.chain.CurrentBlock()
+	env, err := w.prepareWork(&generateParams{timestamp: parent.Time() + 1, coinbase: testUserAddress})
+	if err != nil {
+		t.Fatalf("failed to prepare work: %v", err)
+	}
+	t.Cleanup(env.discard)
+	return env
+}
+
+// buildTestBlock fills a block on top of the head with the pool transactions.
+func buildTestBlock(t testing.TB, w *worker) *environment {
+	t.Helper()
+	env := prepareTestWork(t, w)
+	w.fillTransactions(nil, env)
+	return env
+}
+
 func TestGenerateBlockAndImportEthash(t *testing.T) {
 	testGenerateBlockAndImport(t, false)
 }
@@ -548,3 +609,2610 @@ func testAdjustInterval(t *testing.T, chainConfig *params.ChainConfig, engine co
 		t.Error("interval reset timeout")
 	}
 }
+
+func TestSetRecommitInterval(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, _ := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	updated := make(chan time.Duration, 1)
+	w.resubmitHook = func(minInterval time.Duration, recommitInterval time.Duration) {
+		updated <- recommitInterval
+	}
+	for _, tt := range []struct {
+		interval time.Duration
+		want     time.Duration
+		err      bool
+	}{
+		{interval: 3 * time.Second, want: 3 * time.Second},
+		{interval: 100 * time.Millisecond, want: minRecommitInterval},
+		{interval: 0, err: true},
+		{interval: -time.Second, err: true},
+	} {
+		err := w.setRecommitInterval(tt.interval)
+		if tt.err {
+			if !errors.Is(err, ErrInvalidRecommitInterval) {
+				t.Fatalf("interval %v: error mismatch: have %v, want %v", tt.interval, err, ErrInvalidRecommitInterval)
+			}
+			select {
+			case have := <-updated:
+				t.Fatalf("interval %v: recommit interval updated to %v", tt.interval, have)
+			case <-time.After(100 * time.Millisecond):
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("interval %v: unexpected error: %v", tt.interval, err)
+		}
+		select {
+		case have := <-updated:
+			if have != tt.want {
+				t.Fatalf("interval %v: recommit interval mismatch: have %v, want %v", tt.interval, have, tt.want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("interval %v: recommit interval not updated", tt.interval)
+		}
+	}
+}
+
+func TestBuildBlockDiagnostics(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, b := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	// PUSH1 0 PUSH1 0 REVERT: the contract creation is included but reverts.
+	revertTx, _ := types.SignTx(types.NewContractCreation(b.txPool.Nonce(testBankAddress), big.NewInt(0), testGas, new(big.Int).SetUint64(params.InitialBaseFee*2), common.FromHex("0x60006000fd")), types.HomesteadSigner{}, testBankKey)
+	if err := b.txPool.AddLocal(revertTx); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+
+	parent := b.chain.CurrentBlock()
+	result, err := w.buildBlock(&BuildParams{
+		Parent:    parent.Hash(),
+		Timestamp: parent.Time() + 1,
+		Coinbase:  testUserAddress,
+	})
+	if err != nil {
+		t.Fatalf("failed to build block: %v", err)
+	}
+	if have, want := len(result.Block.Transactions()), 2; have != want {
+		t.Fatalf("transaction count mismatch: have %d, want %d", have, want)
+	}
+	if result.Receipts[1].Status != types.ReceiptStatusFailed {
+		t.Fatalf("expected reverting transaction receipt to be failed")
+	}
+	if result.GasUsed != result.Block.GasUsed() || result.StateRoot != result.Block.Root() {
+		t.Fatalf("diagnostics mismatch with the assembled block")
+	}
+	if len(result.Rejected) != 0 {
+		t.Fatalf("unexpected rejected transactions: %v", result.Rejected)
+	}
+
+	// A transaction with a nonce gap is dropped and reported.
+	env, err := w.prepareWork(&generateParams{timestamp: parent.Time() + 1, coinbase: testUserAddress})
+	if err != nil {
+		t.Fatalf("failed to prepare work: %v", err)
+	}
+	defer env.discard()
+	gapTx, _ := types.SignTx(types.NewTransaction(10, testUserAddress, big.NewInt(1000), params.TxGas, new(big.Int).SetUint64(params.InitialBaseFee*2), nil), types.HomesteadSigner{}, testBankKey)
+	txs := types.NewTransactionsByPriceAndNonce(env.signer, map[common.Address]types.Transactions{testBankAddress: {gapTx}}, env.header.BaseFee)
+	w.commitTransactions(env, txs, nil)
+	if len(env.rejected) != 1 || env.rejected[0].Hash != gapTx.Hash() || !errors.Is(env.rejected[0].Reason, core.ErrNonceTooHigh) {
+		t.Fatalf("rejection mismatch: have %v", env.rejected)
+	}
+}
+
+func TestBuildBlockOnAncestor(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	db := rawdb.NewMemoryDatabase()
+	w, b := newTestWorker(t, ethashChainConfig, engine, db, 4)
+	defer w.close()
+
+	head := b.chain.CurrentBlock()
+	ancestor := b.chain.GetBlockByNumber(head.NumberU64() - 2)
+	result, err := w.buildBlock(&BuildParams{
+		Parent:    ancestor.Hash(),
+		Timestamp: head.Time() + 1,
+		Coinbase:  testUserAddress,
+	})
+	if err != nil {
+		t.Fatalf("failed to build on ancestor: %v", err)
+	}
+	if result.Block.ParentHash() != ancestor.Hash() || result.Block.NumberU64() != ancestor.NumberU64()+1 {
+		t.Fatalf("block not built on the requested ancestor")
+	}
+
+	// Prune the ancestor state, building on top of it must fail.
+	pruned := b.chain.GetBlockByNumber(head.NumberU64() - 1)
+	rawdb.DeleteTrieNode(db, pruned.Root())
+	_, err = w.buildBlock(&BuildParams{
+		Parent:    pruned.Hash(),
+		Timestamp: head.Time() + 1,
+		Coinbase:  testUserAddress,
+	})
+	if !errors.Is(err, ErrParentStateUnavailable) {
+		t.Fatalf("expected %v, got %v", ErrParentStateUnavailable, err)
+	}
+}
+
+func TestGetSealingBlockWithParams(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, b := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+	miner := &Miner{worker: w}
+
+	parent := b.chain.CurrentBlock()
+	random := common.HexToHash("0x01")
+	positional, err := miner.GetSealingBlock(parent.Hash(), parent.Time()+1, testUserAddress, random)
+	if err != nil {
+		t.Fatalf("failed to build block: %v", err)
+	}
+	args := BuildParams{Parent: parent.Hash(), Timestamp: parent.Time() + 1, Coinbase: testUserAddress, Random: random}
+	block, err := miner.GetSealingBlockWithParams(args)
+	if err != nil {
+		t.Fatalf("failed to build block: %v", err)
+	}
+	if block.Hash() != positional.Hash() {
+		t.Fatalf("block mismatch: have %x, want %x", block.Hash(), positional.Hash())
+	}
+	if len(block.Transactions()) == 0 {
+		t.Fatal("pool transactions left out")
+	}
+
+	// The pool content is left out.
+	args.NoTxs = true
+	if block, err = miner.GetSealingBlockWithParams(args); err != nil {
+		t.Fatalf("failed to build block: %v", err)
+	}
+	if len(block.Transactions()) != 0 {
+		t.Fatalf("transaction count mismatch: have %d, want 0", len(block.Transactions()))
+	}
+
+	// The given transactions replace the pool content, the failing ones are dropped.
+	signer := types.LatestSigner(ethashChainConfig)
+	given, _ := types.SignTx(types.NewTransaction(0, testBankAddress, big.NewInt(1000), params.TxGas, big.NewInt(2*params.InitialBaseFee), nil), signer, testUserKey)
+	gap, _ := types.SignTx(types.NewTransaction(5, testBankAddress, big.NewInt(1000), params.TxGas, big.NewInt(2*params.InitialBaseFee), nil), signer, testUserKey)
+	args.NoTxs, args.Txs = false, types.Transactions{given, gap}
+	if block, err = miner.GetSealingBlockWithParams(args); err != nil {
+		t.Fatalf("failed to build block: %v", err)
+	}
+	if len(block.Transactions()) != 1 || block.Transactions()[0].Hash() != given.Hash() {
+		t.Fatalf("transactions mismatch: have %d, want the given one only", len(block.Transactions()))
+	}
+}
+
+func TestGetSealingBlockExclude(t *testing.T) {
+	w, b := newTestBuildWorker(t, nil)
+	miner := &Miner{worker: w}
+
+	var bank, user types.Transactions
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		bank = append(bank, newTestTransfer(testBankKey, nonce, testUserAddress, big.NewInt(2*params.InitialBaseFee)))
+		user = append(user, newTestTransfer(testUserKey, nonce, testBankAddress, big.NewInt(2*params.InitialBaseFee)))
+	}
+	b.addRemotes(t, append(append(types.Transactions{}, bank...), user...)...)
+	parent := b.chain.CurrentBlock()
+	build := func(args BuildParams) map[common.Hash]int {
+		t.Helper()
+		args.Parent, args.Timestamp, args.Coinbase = parent.Hash(), parent.Time()+1, testUserAddress
+		block, err := miner.GetSealingBlockWithParams(args)
+		if err != nil {
+			t.Fatalf("failed to build block: %v", err)
+		}
+		included := make(map[common.Hash]int)
+		for i, tx := range block.Transactions() {
+			included[tx.Hash()] = i
+		}
+		return included
+	}
+
+	// the excluded transaction is left out, along with the following ones of its account
+	included := build(BuildParams{Exclude: []common.Hash{bank[1].Hash()}})
+	if len(included) != 4 {
+		t.Fatalf("included transactions mismatch: have %d, want 4", len(included))
+	}
+	for _, tx := range []*types.Transaction{bank[1], bank[2]} {
+		if _, ok := included[tx.Hash()]; ok {
+			t.Fatalf("transaction %x included despite its exclusion", tx.Hash())
+		}
+	}
+
+	// the given transactions come first, the pool fill skips them and the excluded ones
+	included = build(BuildParams{Txs: types.Transactions{bank[0], bank[1]}, FillPool: true, Exclude: []common.Hash{user[0].Hash()}})
+	if len(included) != 3 {
+		t.Fatalf("included transactions mismatch: have %d, want 3", len(included))
+	}
+	for i, tx := range []*types.Transaction{bank[0], bank[1], bank[2]} {
+		if pos, ok := included[tx.Hash()]; !ok || pos != i {
+			t.Fatalf("transaction %d position mismatch: have %d (included %v), want %d", i, pos, ok, i)
+		}
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	config := *testConfig
+	config.MaxPendingBlocks = 10
+	config.Etherbase = testBankAddress
+	w, b := newTestBuildWorker(t, &config)
+	b.txPool.AddLocals(pendingTxs)
+
+	block, receipts, err := w.simulate()
+	if err != nil {
+		t.Fatalf("failed to simulate block: %v", err)
+	}
+	if len(block.Transactions()) == 0 || len(receipts) != len(block.Transactions()) {
+		t.Fatalf("simulated block content mismatch: %d txs, %d receipts", len(block.Transactions()), len(receipts))
+	}
+	// the worker state is left untouched
+	if pending, _ := w.pendingBlockAndReceipts(); pending != nil {
+		t.Fatalf("pending block set by the simulation")
+	}
+	if len(w.txFirstSeen) != 0 {
+		t.Fatalf("pending transactions recorded by the simulation")
+	}
+
+	// a real build from the same pool produces the same block content
+	tasks := make(chan *task, 1)
+	w.newTaskHook = func(task *task) {
+		if len(task.block.Transactions()) > 0 {
+			select {
+			case tasks <- task:
+			default:
+			}
+		}
+	}
+	w.skipSealHook = func(task *task) bool { return true }
+	w.start()
+	var real *task
+	select {
+	case real = <-tasks:
+	case <-time.After(3 * time.Second):
+		t.Fatal("sealing task timeout")
+	}
+	if block.NumberU64() != real.block.NumberU64() || block.ParentHash() != real.block.ParentHash() || block.Coinbase() != real.block.Coinbase() {
+		t.Fatalf("simulated block header mismatch")
+	}
+	if block.GasUsed() != real.block.GasUsed() || len(block.Transactions()) != len(real.block.Transactions()) {
+		t.Fatalf("simulated block content mismatch: have %d txs using %d gas, want %d txs using %d gas",
+			len(block.Transactions()), block.GasUsed(), len(real.block.Transactions()), real.block.GasUsed())
+	}
+	for i, tx := range block.Transactions() {
+		if tx.Hash() != real.block.Transactions()[i].Hash() {
+			t.Fatalf("transaction %d mismatch", i)
+		}
+		if receipts[i].Status != real.receipts[i].Status || receipts[i].GasUsed != real.receipts[i].GasUsed {
+			t.Fatalf("receipt %d mismatch", i)
+		}
+	}
+}
+
+func TestGasLimitStepDivisor(t *testing.T) {
+	for _, tc := range []struct {
+		divisor uint64
+		want    uint64 // effective divisor after sanitization
+	}{
+		{0, params.GasLimitBoundDivisor},
+		{params.GasLimitBoundDivisor / 2, params.GasLimitBoundDivisor},
+		{4 * params.GasLimitBoundDivisor, 4 * params.GasLimitBoundDivisor},
+	} {
+		config := *testConfig
+		config.GasCeil = 2 * params.GenesisGasLimit
+		config.GasLimitStepDivisor = tc.divisor
+		w, b := newTestBuildWorker(t, &config)
+
+		parent := b.chain.CurrentBlock()
+		env := prepareTestWork(t, w)
+		if have, want := env.header.GasLimit, parent.GasLimit()+parent.GasLimit()/tc.want-1; have != want {
+			t.Errorf("divisor %d: gas limit mismatch: have %d, want %d", tc.divisor, have, want)
+		}
+	}
+}
+
+func TestGasCeilWithRamp(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, b := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+	head := b.chain.CurrentBlock().NumberU64()
+
+	ceil := func(number uint64) uint64 {
+		w.mu.RLock()
+		defer w.mu.RUnlock()
+		return w.gasCeil(number)
+	}
+
+	w.setGasCeil(8_000_000)
+	w.setGasCeilWithRamp(16_000_000, 4)
+	for i, want := range []uint64{8_000_000, 10_000_000, 12_000_000, 14_000_000, 16_000_000, 16_000_000, 16_000_000} {
+		if have := ceil(head + uint64(i)); have != want {
+			t.Errorf("ramp up, block %d: gas ceil mismatch: have %d, want %d", i, have, want)
+		}
+	}
+	// the gas limit still moves toward the ramped ceil within the protocol bound
+	parent := b.chain.CurrentBlock()
+	env, err := w.prepareWork(&generateParams{timestamp: parent.Time() + 1, coinbase: testUserAddress})
+	if err != nil {
+		t.Fatalf("failed to prepare work: %v", err)
+	}
+	if have, want := env.header.GasLimit, core.CalcGasLimit(parent.GasLimit(), 10_000_000); have != want {
+		t.Errorf("gas limit mismatch: have %d, want %d", have, want)
+	}
+	env.discard()
+
+	// a direct change cancels the ramp in progress, ramps down start from the current ceil
+	w.setGasCeil(16_000_000)
+	w.setGasCeilWithRamp(4_000_000, 2)
+	for i, want := range []uint64{16_000_000, 10_000_000, 4_000_000, 4_000_000} {
+		if have := ceil(head + uint64(i)); have != want {
+			t.Errorf("ramp down, block %d: gas ceil mismatch: have %d, want %d", i, have, want)
+		}
+	}
+}
+
+func TestSubscribeChainHead(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, b := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	heads := make(chan *types.Header, 1)
+	sub := w.chainHeadFeed.subscribe(heads)
+	defer sub.Unsubscribe()
+	// a subscriber which isn't ready to receive doesn't block the worker
+	slow := w.chainHeadFeed.subscribe(make(chan *types.Header))
+	defer slow.Unsubscribe()
+
+	head := b.chain.CurrentBlock()
+	w.chainHeadCh <- core.ChainHeadEvent{Block: head}
+	select {
+	case header := <-heads:
+		if header.Hash() != head.Hash() {
+			t.Fatalf("head mismatch: have %x, want %x", header.Hash(), head.Hash())
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("chain head not delivered")
+	}
+}
+
+func TestProduceEmptyBlocks(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		enabled bool
+	}{{"disabled", false}, {"enabled", true}} {
+		t.Run(tt.name, func(t *testing.T) {
+			config := *testConfig
+			config.ProduceEmptyBlocks = tt.enabled
+			config.EmptyBlockInterval = 100 * time.Millisecond
+			w, _ := newTestBuildWorker(t, &config)
+
+			var empty int32
+			started := make(chan struct{}, 1)
+			w.newTaskHook = func(task *task) {
+				if len(task.block.Transactions()) == 0 {
+					atomic.AddInt32(&empty, 1)
+				}
+				select {
+				case started <- struct{}{}:
+				default:
+				}
+			}
+			w.skipSealHook = func(task *task) bool { return true }
+			w.start()
+
+			select {
+			case <-started:
+			case <-time.After(3 * time.Second):
+				t.Fatal("initial task timeout")
+			}
+			// Let the initial commit settle, the recommit interval is far longer than the wait.
+			time.Sleep(50 * time.Millisecond)
+			before := atomic.LoadInt32(&empty)
+			time.Sleep(5 * config.EmptyBlockInterval)
+			after := atomic.LoadInt32(&empty)
+
+			if tt.enabled && after <= before {
+				t.Errorf("no empty block produced on idle chain: have %d tasks, want more than %d", after, before)
+			}
+			if !tt.enabled && after != before {
+				t.Errorf("empty blocks produced while disabled: have %d tasks, want %d", after, before)
+			}
+		})
+	}
+}
+
+func TestRequestCandidate(t *testing.T) {
+	w, b := newTestBuildWorker(t, nil)
+
+	tasks := make(chan *task, 10)
+	w.newTaskHook = func(task *task) { tasks <- task }
+	w.skipSealHook = func(task *task) bool { return true }
+	w.start()
+
+	select {
+	case <-tasks:
+	case <-time.After(3 * time.Second):
+		t.Fatal("initial task timeout")
+	}
+
+	// a request for a height the chain isn't at is ignored
+	head := b.chain.CurrentBlock().NumberU64()
+	w.requestCandidate(head + 2)
+	select {
+	case <-tasks:
+		t.Fatal("candidate built for a stale request")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	w.requestCandidate(head + 1)
+	select {
+	case task := <-tasks:
+		if task.block.NumberU64() != head+1 {
+			t.Fatalf("candidate number mismatch: have %d, want %d", task.block.NumberU64(), head+1)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no candidate built on request")
+	}
+}
+
+func TestMaxGasPerTx(t *testing.T) {
+	config := *testConfig
+	config.MaxGasPerTx = 50000
+	w, b := newTestBuildWorker(t, &config)
+
+	oversized, _ := types.SignTx(types.NewTransaction(0, testUserAddress, big.NewInt(1000), config.MaxGasPerTx+1, big.NewInt(10*params.InitialBaseFee), nil), types.LatestSigner(ethashChainConfig), testBankKey)
+	txs := []*types.Transaction{oversized}
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		txs = append(txs, newTestTransfer(testUserKey, nonce, testBankAddress, big.NewInt(params.InitialBaseFee)))
+	}
+	b.addRemotes(t, txs...)
+	env := buildTestBlock(t, w)
+
+	// the oversized transaction is skipped, the smaller ones fill the block
+	if len(env.txs) != 3 {
+		t.Fatalf("included transactions mismatch: have %d, want 3", len(env.txs))
+	}
+	for _, tx := range env.txs {
+		if tx.Hash() == oversized.Hash() {
+			t.Fatal("oversized transaction included")
+		}
+	}
+	if len(env.rejected) != 1 || !errors.Is(env.rejected[0].Reason, errTxGasTooHigh) || env.rejected[0].Hash != oversized.Hash() {
+		t.Fatalf("rejections mismatch: have %v, want the oversized transaction", env.rejected)
+	}
+	if pending, _ := b.txPool.Stats(); pending != len(txs) {
+		t.Fatalf("pending transactions mismatch: have %d, want %d", pending, len(txs))
+	}
+}
+
+func TestAllowZeroCoinbase(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		allow bool
+	}{{"zero coinbase refused", false}, {"zero coinbase allowed", true}} {
+		t.Run(tt.name, func(t *testing.T) {
+			config := *testConfig
+			config.Etherbase = common.Address{}
+			config.AllowZeroCoinbase = tt.allow
+			w, _ := newTestBuildWorker(t, &config)
+
+			taskCh := make(chan *task, 1)
+			w.newTaskHook = func(task *task) {
+				select {
+				case taskCh <- task:
+				default:
+				}
+			}
+			w.skipSealHook = func(task *task) bool { return true }
+			w.start()
+
+			select {
+			case task := <-taskCh:
+				if !tt.allow {
+					t.Fatal("block built with a zero coinbase while not allowed")
+				}
+				if coinbase := task.block.Coinbase(); coinbase != (common.Address{}) {
+					t.Fatalf("coinbase mismatch: have %x, want zero", coinbase)
+				}
+			case <-time.After(500 * time.Millisecond):
+				if tt.allow {
+					t.Fatal("no block built with a zero coinbase while allowed")
+				}
+			}
+		})
+	}
+}
+
+func TestSenderDenylist(t *testing.T) {
+	w, b := newTestBuildWorker(t, nil)
+
+	var txs []*types.Transaction
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		bank := newTestTransfer(testBankKey, nonce, testUserAddress, big.NewInt(10*params.InitialBaseFee))
+		user := newTestTransfer(testUserKey, nonce, testBankAddress, big.NewInt(params.InitialBaseFee))
+		txs = append(txs, bank, user)
+	}
+	b.addRemotes(t, txs...)
+	signer := types.LatestSigner(ethashChainConfig)
+	senders := func(env *environment) map[common.Address]int {
+		count := make(map[common.Address]int)
+		for _, tx := range env.txs {
+			from, _ := types.Sender(signer, tx)
+			count[from]++
+		}
+		return count
+	}
+
+	// the transactions of the denied sender are left out, the others are included as usual
+	w.setSenderDenylist([]common.Address{testBankAddress})
+	env := buildTestBlock(t, w)
+	if count := senders(env); count[testBankAddress] != 0 || count[testUserAddress] != 2 {
+		t.Fatalf("included transactions mismatch: have %d from the denied sender and %d from the other, want 0 and 2", count[testBankAddress], count[testUserAddress])
+	}
+	if len(env.rejected) != 1 || !errors.Is(env.rejected[0].Reason, errDeniedSender) || env.rejected[0].Hash != txs[0].Hash() {
+		t.Fatalf("rejections mismatch: have %v, want the first transaction of the denied sender", env.rejected)
+	}
+	if pending, _ := b.txPool.Stats(); pending != len(txs) {
+		t.Fatalf("pending transactions mismatch: have %d, want %d", pending, len(txs))
+	}
+
+	// clearing the denylist includes them again
+	w.setSenderDenylist(nil)
+	env = buildTestBlock(t, w)
+	if count := senders(env); count[testBankAddress] != 2 || count[testUserAddress] != 2 {
+		t.Fatalf("included transactions mismatch: have %v, want 2 from each sender", count)
+	}
+}
+
+func TestMinTipBaseFeeFraction(t *testing.T) {
+	config := *testConfig
+	config.MinTipBaseFeeFraction = 0.5
+	w, b := newTestBuildWorker(t, &config)
+
+	baseFee := big.NewInt(params.InitialBaseFee)
+	lowTip := newTestTransfer(testUserKey, 0, testBankAddress, big.NewInt(params.InitialBaseFee*11/10))
+	highTip := newTestTransfer(testBankKey, 0, testUserAddress, big.NewInt(2*params.InitialBaseFee))
+	b.addRemotes(t, lowTip, highTip)
+
+	// share returns the given share of the initial base fee
+	share := func(num, den int64) *big.Int {
+		return new(big.Int).Div(new(big.Int).Mul(baseFee, big.NewInt(num)), big.NewInt(den))
+	}
+	tests := []struct {
+		baseFee *big.Int
+		minTip  *big.Int
+		want    []*types.Transaction
+	}{
+		// floor at half the base fee, only the high tip one pays enough
+		{baseFee, share(1, 2), []*types.Transaction{highTip}},
+		// the floor drops with the base fee, both pay enough
+		{share(1, 4), share(1, 8), []*types.Transaction{highTip, lowTip}},
+		// the floor rises above both tips
+		{share(3, 2), share(3, 4), nil},
+	}
+	for i, tt := range tests {
+		if minTip := w.minTip(tt.baseFee); minTip.Cmp(tt.minTip) != 0 {
+			t.Errorf("block %d: min tip mismatch: have %v, want %v", i, minTip, tt.minTip)
+		}
+		env := prepareTestWork(t, w)
+		env.header.BaseFee = tt.baseFee
+		w.fillTransactions(nil, env)
+		if len(env.txs) != len(tt.want) {
+			t.Fatalf("block %d: included transactions mismatch: have %d, want %d", i, len(env.txs), len(tt.want))
+		}
+		for j, tx := range env.txs {
+			if tx.Hash() != tt.want[j].Hash() {
+				t.Errorf("block %d: transaction %d mismatch", i, j)
+			}
+		}
+	}
+
+	// disabled without a fraction
+	w.config.MinTipBaseFeeFraction = 0
+	if minTip := w.minTip(baseFee); minTip != nil {
+		t.Errorf("min tip set while disabled: %v", minTip)
+	}
+}
+
+func TestMaxPendingBlocks(t *testing.T) {
+	lowTip := newTestTransfer(testUserKey, 0, testBankAddress, big.NewInt(params.InitialBaseFee+1))
+	highTip := newTestTransfer(testBankKey, 0, testUserAddress, big.NewInt(2*params.InitialBaseFee))
+
+	for _, maxPending := range []uint64{0, 2} {
+		config := *testConfig
+		config.MaxPendingBlocks = maxPending
+		w, b := newTestBuildWorker(t, &config)
+
+		// Blocks only fit a single transaction and the low tip one is always competing
+		// against a more recent high tip one, it is starved unless it ages out.
+		parent := b.chain.CurrentBlock()
+		for i, tx := range []*types.Transaction{lowTip, highTip, nil} {
+			if tx != nil {
+				b.addRemotes(t, tx)
+			}
+			env := prepareTestWork(t, w)
+			env.header.Number.SetUint64(parent.NumberU64() + 1 + uint64(i))
+			if i == 0 {
+				// first block is full
+				env.gasPool = new(core.GasPool)
+			} else {
+				env.gasPool = new(core.GasPool).AddGas(params.TxGas)
+			}
+			w.fillTransactions(nil, env)
+			if i > 0 {
+				want := highTip
+				if maxPending > 0 && uint64(i) >= maxPending {
+					want = lowTip
+				}
+				if len(env.txs) != 1 || env.txs[0].Hash() != want.Hash() {
+					t.Errorf("max pending %d, block %d: wrong transaction included", maxPending, i)
+				}
+			}
+		}
+	}
+}
+
+func TestAssemblyDeadline(t *testing.T) {
+	enabled := metrics.Enabled
+	metrics.Enabled = true
+	meter := AssemblyDeadlineMeter
+	AssemblyDeadlineMeter = metrics.NewMeter()
+	defer func() {
+		metrics.Enabled = enabled
+		AssemblyDeadlineMeter = meter
+	}()
+
+	config := *testConfig
+	config.AssemblyDeadline = 100 * time.Millisecond
+	w, b := newTestBuildWorker(t, &config)
+	// an artificially slow state, each transaction takes a fraction of the deadline to apply
+	w.applyTxHook = func(*types.Transaction) { time.Sleep(30 * time.Millisecond) }
+
+	var txs types.Transactions
+	for nonce := uint64(0); nonce < 10; nonce++ {
+		txs = append(txs, newTestTransfer(testBankKey, nonce, testUserAddress, big.NewInt(10*params.InitialBaseFee)))
+	}
+	b.addRemotes(t, txs...)
+	parent := b.chain.CurrentBlock()
+	start := time.Now()
+	block, err := (&Miner{worker: w}).GetSealingBlockWithParams(BuildParams{Parent: parent.Hash(), Timestamp: parent.Time() + 1, Coinbase: testUserAddress})
+	if err != nil {
+		t.Fatalf("failed to build block: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("assembly overran the deadline: took %v", elapsed)
+	}
+	// the transactions committed before the deadline are kept, in nonce order
+	included := block.Transactions()
+	if len(included) == 0 || len(included) == len(txs) {
+		t.Fatalf("included transactions mismatch: have %d, want a partial block", len(included))
+	}
+	for i, tx := range included {
+		if tx.Hash() != txs[i].Hash() {
+			t.Fatalf("transaction %d mismatch: have %x, want %x", i, tx.Hash(), txs[i].Hash())
+		}
+	}
+	if AssemblyDeadlineMeter.Count() != 1 {
+		t.Fatalf("deadline meter mismatch: have %d, want 1", AssemblyDeadlineMeter.Count())
+	}
+	// the partial block is valid
+	if _, err := b.chain.InsertChain(types.Blocks{block}); err != nil {
+		t.Fatalf("failed to import the partial block: %v", err)
+	}
+}
+
+func TestPriorityAccounts(t *testing.T) {
+	w, b := newTestBuildWorker(t, nil)
+
+	var (
+		txs      []*types.Transaction
+		priority []*types.Transaction
+	)
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		highTip := newTestTransfer(testBankKey, nonce, testUserAddress, big.NewInt(10*params.InitialBaseFee))
+		lowTip := newTestTransfer(testUserKey, nonce, testBankAddress, big.NewInt(params.InitialBaseFee+1))
+		txs = append(txs, highTip, lowTip)
+		priority = append(priority, lowTip)
+	}
+	b.addRemotes(t, txs...)
+
+	// the low tip transactions of the priority account precede the higher tip ones, in nonce order
+	w.setPriorityAccounts([]common.Address{testUserAddress})
+	env := buildTestBlock(t, w)
+	if len(env.txs) != len(txs) {
+		t.Fatalf("included transactions mismatch: have %d, want %d", len(env.txs), len(txs))
+	}
+	for i, tx := range priority {
+		if env.txs[i].Hash() != tx.Hash() {
+			t.Fatalf("transaction %d mismatch: have %x, want the priority account's %x", i, env.txs[i].Hash(), tx.Hash())
+		}
+	}
+	if len(env.rejected) != 0 {
+		t.Fatalf("rejections mismatch: have %v, want none", env.rejected)
+	}
+
+	// clearing the priority accounts restores the tip based order
+	w.setPriorityAccounts(nil)
+	env = buildTestBlock(t, w)
+	if from, _ := types.Sender(types.LatestSigner(ethashChainConfig), env.txs[0]); from != testBankAddress {
+		t.Fatalf("first transaction sender mismatch: have %x, want the highest tip %x", from, testBankAddress)
+	}
+}
+
+func TestAwaitPending(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, _ := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+	miner := &Miner{worker: w}
+
+	// nothing was built yet, the wait ends with the context
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if block, state, err := miner.AwaitPending(ctx); !errors.Is(err, context.DeadlineExceeded) || block != nil || state != nil {
+		t.Fatalf("await result mismatch: have %v %v %v, want the context error", block, state, err)
+	}
+
+	// the wait ends once the first pending block is built
+	type result struct {
+		err   error
+		built bool
+	}
+	done := make(chan result, 1)
+	go func() {
+		block, state, err := miner.AwaitPending(context.Background())
+		done <- result{err: err, built: block != nil && state != nil}
+	}()
+	select {
+	case <-done:
+		t.Fatal("await returned before the pending block was built")
+	case <-time.After(50 * time.Millisecond):
+	}
+	w.start()
+	select {
+	case res := <-done:
+		if res.err != nil || !res.built {
+			t.Fatalf("await result mismatch: have built %v and error %v, want the pending block", res.built, res.err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("await didn't return once the pending block was built")
+	}
+
+	// later calls return at once
+	block, state, err := miner.AwaitPending(context.Background())
+	if err != nil || block == nil || state == nil {
+		t.Fatalf("await result mismatch: have %v %v %v, want the pending block", block, state, err)
+	}
+}
+
+func TestBaseFeeCalculator(t *testing.T) {
+	w, b := newTestBuildWorker(t, nil)
+
+	parent := b.chain.CurrentBlock()
+	baseFee := func() *big.Int {
+		return prepareTestWork(t, w).header.BaseFee
+	}
+
+	// the default follows the EIP-1559 formula
+	if have, want := baseFee(), misc.CalcBaseFee(ethashChainConfig, parent.Header(), b.chain); have.Cmp(want) != 0 {
+		t.Fatalf("default base fee mismatch: have %v, want %v", have, want)
+	}
+
+	// a custom calculator is given the parent header
+	var seen *types.Header
+	w.setBaseFeeCalculator(func(parent *types.Header) *big.Int {
+		seen = parent
+		return big.NewInt(42)
+	})
+	if have := baseFee(); have.Cmp(big.NewInt(42)) != 0 {
+		t.Fatalf("custom base fee mismatch: have %v, want 42", have)
+	}
+	if seen == nil || seen.Hash() != parent.Hash() {
+		t.Fatal("calculator not given the parent header")
+	}
+
+	// clearing it restores the default
+	w.setBaseFeeCalculator(nil)
+	if have, want := baseFee(), misc.CalcBaseFee(ethashChainConfig, parent.Header(), b.chain); have.Cmp(want) != 0 {
+		t.Fatalf("restored base fee mismatch: have %v, want %v", have, want)
+	}
+}
+
+func TestLargeTxBudget(t *testing.T) {
+	const (
+		largeSize = 100
+		largeGas  = params.TxGas + largeSize*params.TxDataZeroGas
+	)
+	signer := types.LatestSigner(ethashChainConfig)
+	newTxs := func(large bool, n int, tip int64, key *ecdsa.PrivateKey) []*types.Transaction {
+		var txs []*types.Transaction
+		for nonce := uint64(0); nonce < uint64(n); nonce++ {
+			gas, data := params.TxGas, []byte(nil)
+			if large {
+				gas, data = largeGas, make([]byte, largeSize)
+			}
+			tx, _ := types.SignTx(types.NewTransaction(nonce, testUserAddress, big.NewInt(1000), gas, big.NewInt(params.InitialBaseFee+tip), data), signer, key)
+			txs = append(txs, tx)
+		}
+		return txs
+	}
+	count := func(env *environment) (large, regular int) {
+		for _, tx := range env.txs {
+			if len(tx.Data()) >= largeSize {
+				large++
+			} else {
+				regular++
+			}
+		}
+		return large, regular
+	}
+
+	tests := []struct {
+		name             string
+		large, regular   []*types.Transaction
+		gas              uint64
+		wantLarge, wantR int
+	}{
+		{
+			// high tip large transactions are capped by their budget
+			name:      "large transactions don't crowd out regular ones",
+			large:     newTxs(true, 5, 10*params.InitialBaseFee, testBankKey),
+			regular:   newTxs(false, 3, 1, testUserKey),
+			gas:       10 * largeGas,
+			wantLarge: 2,
+			wantR:     3,
+		},
+		{
+			// high tip regular transactions can't take the gas of the large ones
+			name:      "regular transactions don't crowd out large ones",
+			large:     newTxs(true, 2, 1, testBankKey),
+			regular:   newTxs(false, 5, 10*params.InitialBaseFee, testUserKey),
+			gas:       2*largeGas + 2*params.TxGas,
+			wantLarge: 2,
+			wantR:     2,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := *testConfig
+			config.LargeTxDataSize = largeSize
+			config.LargeTxGasLimit = 2 * largeGas
+			w, b := newTestBuildWorker(t, &config)
+
+			b.addRemotes(t, append(test.large, test.regular...)...)
+			env := prepareTestWork(t, w)
+			env.gasPool = new(core.GasPool).AddGas(test.gas)
+			w.fillTransactions(nil, env)
+
+			if large, regular := count(env); large != test.wantLarge || regular != test.wantR {
+				t.Fatalf("included transactions mismatch: have %d large and %d regular, want %d and %d", large, regular, test.wantLarge, test.wantR)
+			}
+			if env.largeGasUsed > config.LargeTxGasLimit {
+				t.Fatalf("large transactions budget exceeded: have %d, want at most %d", env.largeGasUsed, config.LargeTxGasLimit)
+			}
+		})
+	}
+}
+
+func TestLargeTxBudgetRejection(t *testing.T) {
+	budget := &txBudget{member: func(tx *types.Transaction) bool { return len(tx.Data()) > 0 }, limit: 2 * params.TxGas}
+	tx := types.NewTransaction(0, testUserAddress, big.NewInt(0), params.TxGas, big.NewInt(1), []byte{1})
+	if !budget.allows(tx, params.TxGas) {
+		t.Fatal("transaction filling the budget refused")
+	}
+	if budget.allows(tx, params.TxGas+1) {
+		t.Fatal("transaction over the budget allowed")
+	}
+
+	w, _ := newTestBuildWorker(t, nil)
+	w.largeTxBudget = budget
+
+	env := prepareTestWork(t, w)
+	env.largeGasUsed = budget.limit
+	signed, _ := types.SignTx(types.NewTransaction(0, testUserAddress, big.NewInt(0), params.TxGas+params.TxDataNonZeroGasEIP2028, big.NewInt(2*params.InitialBaseFee), []byte{1}), types.LatestSigner(ethashChainConfig), testBankKey)
+	if _, err := w.commitTransaction(env, signed); !errors.Is(err, errBudgetExceeded) {
+		t.Fatalf("error mismatch: have %v, want %v", err, errBudgetExceeded)
+	}
+}
+
+func TestCoinbaseForHeight(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, _ := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	initial := w.coinbase
+	first, second, third := common.HexToAddress("0x0100"), common.HexToAddress("0x0200"), common.HexToAddress("0x0300")
+	// scheduled out of order, the last one scheduled for a height wins
+	w.setCoinbaseForHeight(3, second)
+	w.setCoinbaseForHeight(2, first)
+	w.setCoinbaseForHeight(3, third)
+
+	for height, want := range map[uint64]common.Address{1: initial, 2: first, 3: third, 4: third} {
+		if have := w.coinbaseAt(height); have != want {
+			t.Errorf("coinbase at %d mismatch: have %x, want %x", height, have, want)
+		}
+	}
+	if have := w.applyCoinbaseChanges(1); have != initial || w.coinbase != initial || len(w.coinbaseChanges) != 3 {
+		t.Fatalf("change applied before its height: coinbase %x, %d changes left", have, len(w.coinbaseChanges))
+	}
+	if have := w.applyCoinbaseChanges(2); have != first || w.coinbase != first || len(w.coinbaseChanges) != 2 {
+		t.Fatalf("change not applied at its height: coinbase %x, %d changes left", have, len(w.coinbaseChanges))
+	}
+	if have := w.applyCoinbaseChanges(3); have != third || w.coinbase != third || len(w.coinbaseChanges) != 0 {
+		t.Fatalf("changes not applied in order: coinbase %x, %d changes left", have, len(w.coinbaseChanges))
+	}
+}
+
+func TestCoinbaseForHeightSwitchesBlocks(t *testing.T) {
+	scheduled := common.HexToAddress("0x0100")
+	for head := 0; head < 3; head++ {
+		engine := ethash.NewFaker()
+		w, _ := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), head)
+		initial := w.coinbase
+		w.setCoinbaseForHeight(2, scheduled)
+
+		block, _, err := w.simulate()
+		if err != nil {
+			t.Fatalf("failed to simulate block: %v", err)
+		}
+		want := initial
+		if block.NumberU64() >= 2 {
+			want = scheduled
+		}
+		if block.Coinbase() != want {
+			t.Errorf("block %d coinbase mismatch: have %x, want %x", block.NumberU64(), block.Coinbase(), want)
+		}
+		w.close()
+		engine.Close()
+	}
+}
+
+func TestCommitHook(t *testing.T) {
+	enabled := metrics.Enabled
+	metrics.Enabled = true
+	meter := CommitHookVetoMeter
+	CommitHookVetoMeter = metrics.NewMeter()
+	defer func() {
+		metrics.Enabled = enabled
+		CommitHookVetoMeter = meter
+	}()
+
+	run := func(t *testing.T, veto error) (inspected chan *types.Block, tasks chan *task) {
+		engine := ethash.NewFaker()
+		t.Cleanup(func() { engine.Close() })
+		w, b := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+		t.Cleanup(w.close)
+
+		inspected, tasks = make(chan *types.Block, 16), make(chan *task, 16)
+		w.setCommitHook(func(block *types.Block, receipts types.Receipts) error {
+			if len(receipts) != len(block.Transactions()) {
+				t.Errorf("receipts mismatch: have %d, want %d", len(receipts), len(block.Transactions()))
+			}
+			inspected <- block
+			return veto
+		})
+		w.newTaskHook = func(task *task) { tasks <- task }
+		w.skipSealHook = func(*task) bool { return true }
+		w.fullTaskHook = func() { time.Sleep(100 * time.Millisecond) }
+		b.txPool.AddLocal(b.newRandomTx(true))
+		w.start()
+		return inspected, tasks
+	}
+
+	t.Run("passing hook, block is sealed", func(t *testing.T) {
+		inspected, tasks := run(t, nil)
+		var block *types.Block
+		select {
+		case block = <-inspected:
+		case <-time.After(3 * time.Second):
+			t.Fatal("block not inspected")
+		}
+		select {
+		case task := <-tasks:
+			if task.block.Hash() != block.Hash() {
+				t.Fatalf("sealed block mismatch: have %x, want %x", task.block.Hash(), block.Hash())
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatal("inspected block not submitted for sealing")
+		}
+	})
+
+	t.Run("vetoing hook, sealing is aborted", func(t *testing.T) {
+		CommitHookVetoMeter = metrics.NewMeter()
+		inspected, tasks := run(t, errors.New("policy violation"))
+		select {
+		case <-inspected:
+		case <-time.After(3 * time.Second):
+			t.Fatal("block not inspected")
+		}
+		select {
+		case <-tasks:
+			t.Fatal("vetoed block submitted for sealing")
+		case <-time.After(500 * time.Millisecond):
+		}
+		if CommitHookVetoMeter.Count() == 0 {
+			t.Fatal("veto not metered")
+		}
+	})
+}
+
+func TestBlockFullness(t *testing.T) {
+	enabled := metrics.Enabled
+	metrics.Enabled = true
+	txsHistogram, gasHistogram := SealedBlockTxsHistogram, SealedBlockGasHistogram
+	SealedBlockTxsHistogram = metrics.NewHistogram(metrics.NewUniformSample(100))
+	SealedBlockGasHistogram = metrics.NewHistogram(metrics.NewUniformSample(100))
+	defer func() {
+		metrics.Enabled = enabled
+		SealedBlockTxsHistogram, SealedBlockGasHistogram = txsHistogram, gasHistogram
+	}()
+
+	newBlock := func(gasUsed, gasLimit uint64, txs int) *types.Block {
+		header := &types.Header{Number: big.NewInt(1), GasUsed: gasUsed, GasLimit: gasLimit}
+		return types.NewBlockWithHeader(header).WithBody(make([]*types.Transaction, txs), nil)
+	}
+
+	var f blockFullness
+	if ratios := f.recent(); len(ratios) != 0 {
+		t.Fatalf("fullness reported before any block: %v", ratios)
+	}
+	f.record(newBlock(0, 8_000_000, 0))
+	f.record(newBlock(2_000_000, 8_000_000, 5))
+	f.record(newBlock(8_000_000, 8_000_000, 40))
+	f.record(newBlock(0, 0, 0))
+	want := []float64{0, 0.25, 1, 0}
+	ratios := f.recent()
+	if len(ratios) != len(want) {
+		t.Fatalf("ratios count mismatch: have %d, want %d", len(ratios), len(want))
+	}
+	for i := range want {
+		if ratios[i] != want[i] {
+			t.Fatalf("ratio %d mismatch: have %v, want %v", i, ratios[i], want[i])
+		}
+	}
+	if count, max := SealedBlockTxsHistogram.Count(), SealedBlockTxsHistogram.Max(); count != 4 || max != 40 {
+		t.Fatalf("txs histogram mismatch: have count %d max %d, want count 4 max 40", count, max)
+	}
+	if sum := SealedBlockGasHistogram.Sum(); sum != 10_000_000 {
+		t.Fatalf("gas histogram sum mismatch: have %d, want %d", sum, 10_000_000)
+	}
+
+	// only the latest blocks are retained once the buffer wraps around
+	for i := 1; i <= recentBlockFullnessSize+10; i++ {
+		f.record(newBlock(uint64(i), 1000, 0))
+	}
+	ratios = f.recent()
+	if len(ratios) != recentBlockFullnessSize {
+		t.Fatalf("ratios count mismatch: have %d, want %d", len(ratios), recentBlockFullnessSize)
+	}
+	for i, ratio := range ratios {
+		if want := float64(i+11) / 1000; ratio != want {
+			t.Fatalf("ratio %d mismatch: have %v, want %v", i, ratio, want)
+		}
+	}
+}
+
+func TestRecentBlockFullness(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, _ := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+	miner := &Miner{worker: w}
+
+	sealedCh := make(chan *SealedBlock, 1)
+	sub := miner.SubscribeSealedBlock(sealedCh)
+	defer sub.Unsubscribe()
+
+	w.start()
+	var sealed *SealedBlock
+	select {
+	case sealed = <-sealedCh:
+	case <-time.After(3 * time.Second):
+		t.Fatal("sealed block not delivered")
+	}
+	block := sealed.Block
+	ratios := miner.RecentBlockFullness()
+	if len(ratios) == 0 {
+		t.Fatal("fullness of the sealed block not recorded")
+	}
+	if want := float64(block.GasUsed()) / float64(block.GasLimit()); ratios[0] != want || want <= 0 {
+		t.Fatalf("fullness mismatch: have %v, want %v", ratios[0], want)
+	}
+}
+
+func TestGasVoteEncoding(t *testing.T) {
+	extra := []byte("autonity")
+	encoded := appendGasVote(extra, 30_000_000)
+	if !bytes.HasPrefix(encoded, extra) {
+		t.Fatalf("extra data lost: %x", encoded)
+	}
+	if limit, ok := gasVote(encoded); !ok || limit != 30_000_000 {
+		t.Fatalf("vote mismatch: have %d (%v), want %d", limit, ok, 30_000_000)
+	}
+	if _, ok := gasVote(extra); ok {
+		t.Fatal("vote found in extra data without marker")
+	}
+
+	// a full extra data is trimmed to leave room for the vote
+	full := bytes.Repeat([]byte{0xff}, int(params.MaximumExtraDataSize))
+	encoded = appendGasVote(full, 1)
+	if uint64(len(encoded)) != params.MaximumExtraDataSize {
+		t.Fatalf("extra data size mismatch: have %d, want %d", len(encoded), params.MaximumExtraDataSize)
+	}
+	if limit, ok := gasVote(encoded); !ok || limit != 1 {
+		t.Fatalf("vote mismatch: have %d (%v), want 1", limit, ok)
+	}
+}
+
+func TestGasLimitVoting(t *testing.T) {
+	config := *testConfig
+	config.GasLimitVote = 20_000_000
+	w, b := newTestBuildWorker(t, &config)
+
+	// without any vote in the recent blocks, the gas limit moves toward the gas ceil
+	genesis := b.chain.CurrentBlock()
+	env := prepareTestWork(t, w)
+	if limit, ok := gasVote(env.header.Extra); !ok || limit != config.GasLimitVote {
+		t.Fatalf("vote marker mismatch: have %d (%v), want %d", limit, ok, config.GasLimitVote)
+	}
+	if want := core.CalcGasLimitWithDivisor(genesis.GasLimit(), config.GasCeil, w.gasLimitStepDivisor); env.header.GasLimit != want {
+		t.Fatalf("gas limit mismatch without votes: have %d, want %d", env.header.GasLimit, want)
+	}
+
+	// the gas limit tracks the median vote of the recent blocks
+	votes := []uint64{2 * genesis.GasLimit(), 3 * genesis.GasLimit(), genesis.GasLimit() / 2}
+	blocks, _ := core.GenerateChain(ethashChainConfig, genesis, w.engine, b.db, len(votes), func(i int, gen *core.BlockGen) {
+		gen.SetExtra(appendGasVote(nil, votes[i]))
+	})
+	if _, err := b.chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	head := b.chain.CurrentBlock()
+	if limit, ok := w.votedGasLimit(head.Header()); !ok || limit != votes[0] {
+		t.Fatalf("voted gas limit mismatch: have %d (%v), want %d", limit, ok, votes[0])
+	}
+	env = prepareTestWork(t, w)
+	if want := core.CalcGasLimitWithDivisor(head.GasLimit(), votes[0], w.gasLimitStepDivisor); env.header.GasLimit != want || want <= head.GasLimit() {
+		t.Fatalf("gas limit mismatch with votes: have %d, want %d above %d", env.header.GasLimit, want, head.GasLimit())
+	}
+
+	// the votes are ignored once the voting is disabled
+	w.setGasLimitVote(0)
+	env = prepareTestWork(t, w)
+	if _, ok := gasVote(env.header.Extra); ok {
+		t.Fatal("vote marker emitted with the voting disabled")
+	}
+	if want := core.CalcGasLimitWithDivisor(head.GasLimit(), config.GasCeil, w.gasLimitStepDivisor); env.header.GasLimit != want {
+		t.Fatalf("gas limit mismatch with the voting disabled: have %d, want %d", env.header.GasLimit, want)
+	}
+}
+
+func TestGasLimitVotingLondonTransition(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	db := rawdb.NewMemoryDatabase()
+	b := newTestWorkerBackend(t, ethashChainConfig, engine, db, 0)
+	// the worker sees the chain built so far as pre-London
+	chainConfig := *ethashChainConfig
+	chainConfig.LondonBlock, chainConfig.ArrowGlacierBlock = big.NewInt(4), nil
+	config := *testConfig
+	config.GasLimitVote = 20_000_000
+	w := newWorker(&config, &chainConfig, engine, b, new(event.TypeMux), nil, false)
+	defer w.close()
+
+	genesis := b.chain.CurrentBlock()
+	votes := []uint64{2 * genesis.GasLimit(), 3 * genesis.GasLimit(), genesis.GasLimit() / 2}
+	blocks, _ := core.GenerateChain(ethashChainConfig, genesis, engine, db, len(votes), func(i int, gen *core.BlockGen) {
+		gen.SetExtra(appendGasVote(nil, votes[i]))
+	})
+	if _, err := b.chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+
+	// the first London block still strives for the voted gas limit, the elasticity aside
+	head := b.chain.CurrentBlock()
+	env := prepareTestWork(t, w)
+	if !chainConfig.IsLondon(env.header.Number) || chainConfig.IsLondon(head.Number()) {
+		t.Fatalf("block %d isn't the London transition", env.header.Number)
+	}
+	want := core.CalcGasLimitWithDivisor(head.GasLimit()*params.ElasticityMultiplier, votes[0], w.gasLimitStepDivisor)
+	if env.header.GasLimit != want {
+		t.Fatalf("gas limit mismatch at the London transition: have %d, want %d", env.header.GasLimit, want)
+	}
+}
+
+func TestPendingStateAt(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	b := newTestWorkerBackend(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 3)
+	w := newWorker(testConfig, ethashChainConfig, engine, b, new(event.TypeMux), nil, false)
+	defer w.close()
+	miner := &Miner{worker: w}
+
+	recipient := common.HexToAddress("0xdeadbeef")
+	tx := newTestTransfer(testBankKey, 0, recipient, big.NewInt(10*params.InitialBaseFee))
+	b.addRemotes(t, tx)
+
+	head := b.chain.CurrentBlock()
+	ancestor := b.chain.GetBlockByNumber(1)
+	for _, base := range []*types.Block{head, ancestor} {
+		statedb, err := miner.PendingStateAt(base.Hash())
+		if err != nil {
+			t.Fatalf("block %d: failed to build pending state: %v", base.NumberU64(), err)
+		}
+		// the pool transaction is applied on top of the base
+		if balance := statedb.GetBalance(recipient); balance.Cmp(big.NewInt(1000)) != 0 {
+			t.Fatalf("block %d: recipient balance mismatch: have %v, want 1000", base.NumberU64(), balance)
+		}
+		if nonce := statedb.GetNonce(testBankAddress); nonce != 1 {
+			t.Fatalf("block %d: sender nonce mismatch: have %d, want 1", base.NumberU64(), nonce)
+		}
+		// the block rewards of the base chain are kept
+		baseState, err := b.chain.StateAt(base.Root())
+		if err != nil {
+			t.Fatalf("block %d: failed to get base state: %v", base.NumberU64(), err)
+		}
+		paid := new(big.Int).Add(big.NewInt(1000), new(big.Int).Mul(tx.GasFeeCap(), new(big.Int).SetUint64(params.TxGas)))
+		if balance, min := statedb.GetBalance(testBankAddress), new(big.Int).Sub(baseState.GetBalance(testBankAddress), paid); balance.Cmp(min) < 0 {
+			t.Fatalf("block %d: sender balance mismatch: have %v, want at least %v", base.NumberU64(), balance, min)
+		}
+	}
+	if _, err := miner.PendingStateAt(common.HexToHash("0x01")); err == nil {
+		t.Fatal("pending state built on an unknown base")
+	}
+}
+
+func TestPendingBlockLimit(t *testing.T) {
+	enabled := metrics.Enabled
+	metrics.Enabled = true
+	meter := PendingTaskEvictedMeter
+	PendingTaskEvictedMeter = metrics.NewMeter()
+	defer func() {
+		PendingTaskEvictedMeter.Stop()
+		PendingTaskEvictedMeter = meter
+		metrics.Enabled = enabled
+	}()
+
+	config := *testConfig
+	config.PendingBlockLimit = 3
+	w, _ := newTestBuildWorker(t, &config)
+
+	// every recommit leaves a task behind
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		w.addPendingTask(common.BigToHash(big.NewInt(int64(i))), &task{
+			block:     types.NewBlockWithHeader(&types.Header{Number: common.Big1}),
+			createdAt: start.Add(time.Duration(i) * time.Second),
+		})
+		if len(w.pendingTasks) > config.PendingBlockLimit {
+			t.Fatalf("retained tasks above the limit: have %d, want at most %d", len(w.pendingTasks), config.PendingBlockLimit)
+		}
+	}
+	check := func(want ...int64) {
+		t.Helper()
+		if len(w.pendingTasks) != len(want) {
+			t.Fatalf("retained tasks mismatch: have %d, want %d", len(w.pendingTasks), len(want))
+		}
+		for _, i := range want {
+			if _, ok := w.pendingTasks[common.BigToHash(big.NewInt(i))]; !ok {
+				t.Fatalf("task %d evicted, the oldest ones should be", i)
+			}
+		}
+	}
+	check(2, 3, 4)
+	if evicted := PendingTaskEvictedMeter.Count(); evicted != 2 {
+		t.Fatalf("evicted tasks mismatch: have %d, want 2", evicted)
+	}
+
+	// lowering the limit evicts straight away
+	w.setPendingBlockLimit(1)
+	check(4)
+	if evicted := PendingTaskEvictedMeter.Count(); evicted != 4 {
+		t.Fatalf("evicted tasks mismatch: have %d, want 4", evicted)
+	}
+}
+
+// lowTipPrioritizer favours the cheapest transactions.
+type lowTipPrioritizer struct{}
+
+func (lowTipPrioritizer) Less(a, b *types.Transaction, baseFee *big.Int) bool {
+	return a.EffectiveGasTipValue(baseFee).Cmp(b.EffectiveGasTipValue(baseFee)) < 0
+}
+
+func TestTxPrioritizer(t *testing.T) {
+	lowTip := newTestTransfer(testUserKey, 0, testBankAddress, big.NewInt(params.InitialBaseFee+1))
+	highTip := newTestTransfer(testBankKey, 0, testUserAddress, big.NewInt(2*params.InitialBaseFee))
+	nextHighTip := newTestTransfer(testBankKey, 1, testUserAddress, big.NewInt(2*params.InitialBaseFee))
+
+	tests := []struct {
+		prioritizer TxPrioritizer
+		want        []*types.Transaction
+	}{
+		{nil, []*types.Transaction{highTip, nextHighTip, lowTip}},
+		{TipPrioritizer{}, []*types.Transaction{highTip, nextHighTip, lowTip}},
+		{lowTipPrioritizer{}, []*types.Transaction{lowTip, highTip, nextHighTip}},
+	}
+	for i, tt := range tests {
+		w, b := newTestBuildWorker(t, nil)
+		w.setTxPrioritizer(tt.prioritizer)
+
+		b.addRemotes(t, lowTip, highTip, nextHighTip)
+		env := prepareTestWork(t, w)
+		env.gasPool = new(core.GasPool).AddGas(3 * params.TxGas)
+		w.fillTransactions(nil, env)
+		if len(env.txs) != len(tt.want) {
+			t.Fatalf("test %d: included transactions mismatch: have %d, want %d", i, len(env.txs), len(tt.want))
+		}
+		for j, tx := range env.txs {
+			if tx.Hash() != tt.want[j].Hash() {
+				t.Errorf("test %d: transaction %d mismatch: have %x, want %x", i, j, tx.Hash(), tt.want[j].Hash())
+			}
+		}
+	}
+}
+
+func TestSetBuildProfile(t *testing.T) {
+	config := *testConfig
+	w, _ := newTestBuildWorker(t, &config)
+	miner := &Miner{worker: w}
+
+	updated := make(chan time.Duration, 1)
+	w.resubmitHook = func(minInterval time.Duration, recommitInterval time.Duration) {
+		updated <- recommitInterval
+	}
+	for _, tt := range []struct {
+		profile     BuildProfile
+		recommit    time.Duration
+		deadline    time.Duration
+		maxBlockTxs int
+	}{
+		{profile: LowLatency, recommit: time.Second, deadline: 100 * time.Millisecond, maxBlockTxs: 500},
+		{profile: Balanced, recommit: 2 * time.Second, deadline: 500 * time.Millisecond, maxBlockTxs: 2000},
+		{profile: MaxFullness, recommit: 3 * time.Second},
+	} {
+		if err := miner.SetBuildProfile(tt.profile); err != nil {
+			t.Fatalf("%v: failed to set the profile: %v", tt.profile, err)
+		}
+		select {
+		case have := <-updated:
+			if have != tt.recommit {
+				t.Fatalf("%v: recommit interval mismatch: have %v, want %v", tt.profile, have, tt.recommit)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%v: recommit interval not updated", tt.profile)
+		}
+		w.mu.RLock()
+		deadline, maxBlockTxs := w.config.AssemblyDeadline, w.config.MaxBlockTxs
+		w.mu.RUnlock()
+		if deadline != tt.deadline {
+			t.Fatalf("%v: assembly deadline mismatch: have %v, want %v", tt.profile, deadline, tt.deadline)
+		}
+		if maxBlockTxs != tt.maxBlockTxs {
+			t.Fatalf("%v: maximum transactions mismatch: have %d, want %d", tt.profile, maxBlockTxs, tt.maxBlockTxs)
+		}
+	}
+
+	// unknown profiles change nothing
+	if err := miner.SetBuildProfile(MaxFullness + 1); !errors.Is(err, ErrUnknownBuildProfile) {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrUnknownBuildProfile)
+	}
+	select {
+	case have := <-updated:
+		t.Fatalf("recommit interval updated to %v", have)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestMaxBlockTxs(t *testing.T) {
+	config := *testConfig
+	w, b := newTestBuildWorker(t, &config)
+	miner := &Miner{worker: w}
+
+	txs := make([]*types.Transaction, 5)
+	for i := range txs {
+		txs[i] = b.newRandomTx(false)
+		if errs := b.txPool.AddLocals(txs[i : i+1]); errs[0] != nil {
+			t.Fatalf("failed to add transaction %d: %v", i, errs[0])
+		}
+	}
+	for _, max := range []int{2, 0} {
+		miner.SetMaxBlockTxs(max)
+		block, _, err := w.simulate()
+		if err != nil {
+			t.Fatalf("failed to simulate block: %v", err)
+		}
+		want := len(txs)
+		if max > 0 {
+			want = max
+		}
+		if have := len(block.Transactions()); have != want {
+			t.Fatalf("max %d: transactions mismatch: have %d, want %d", max, have, want)
+		}
+	}
+}
+
+func TestSealedHeadDelay(t *testing.T) {
+	enabled := metrics.Enabled
+	metrics.Enabled = true
+	timer := SealedHeadTimer
+	SealedHeadTimer = metrics.NewTimer()
+	defer func() {
+		SealedHeadTimer.Stop()
+		SealedHeadTimer = timer
+		metrics.Enabled = enabled
+	}()
+
+	w, _ := newTestBuildWorker(t, nil)
+
+	sealed := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), Time: 1})
+	other := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), Time: 2})
+	w.recordSealed(w.engine.SealHash(sealed.Header()), 1)
+	w.recordSealed(w.engine.SealHash(other.Header()), 1)
+
+	// the delay is measured once the sealed block is observed as chain head
+	const delay = 50 * time.Millisecond
+	time.Sleep(delay)
+	w.chainHeadCh <- core.ChainHeadEvent{Block: sealed}
+	deadline := time.Now().Add(3 * time.Second)
+	for SealedHeadTimer.Count() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("sealed block delay not measured")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if count, min := SealedHeadTimer.Count(), time.Duration(SealedHeadTimer.Min()); count != 1 || min < delay {
+		t.Fatalf("measured delay mismatch: have %d measures, shortest %v, want 1 of at least %v", count, min, delay)
+	}
+
+	// the competing block of the same height is forgotten
+	w.sealedMu.Lock()
+	defer w.sealedMu.Unlock()
+	if len(w.sealed) != 0 {
+		t.Fatalf("sealed blocks retained: have %d, want 0", len(w.sealed))
+	}
+}
+
+func TestResealPending(t *testing.T) {
+	// newSealingWorker returns a worker whose first sealing attempt fails, and a
+	// channel notified of every attempt.
+	newSealingWorker := func(t *testing.T) (*worker, *testWorkerBackend, chan *task) {
+		w, b := newTestBuildWorker(t, nil)
+
+		var attempts int32
+		sealed := make(chan *task, 2)
+		w.skipSealHook = func(task *task) bool {
+			sealed <- task
+			return atomic.AddInt32(&attempts, 1) == 1
+		}
+		return w, b, sealed
+	}
+	// submit assembles a block on the head and submits it for sealing.
+	submit := func(t *testing.T, w *worker, sealed chan *task) *types.Block {
+		env := prepareTestWork(t, w)
+		block, err := w.engine.FinalizeAndAssemble(w.chain, env.header, env.state, env.txs, nil, &env.receipts)
+		if err != nil {
+			t.Fatalf("failed to assemble block: %v", err)
+		}
+		w.taskCh <- &task{receipts: env.receipts, state: env.state, block: block, createdAt: time.Now()}
+		<-sealed
+		return block
+	}
+
+	t.Run("nothing to reseal", func(t *testing.T) {
+		w, _, _ := newSealingWorker(t)
+		if _, err := w.resealPending(); !errors.Is(err, errNoPendingBlock) {
+			t.Fatalf("error mismatch: have %v, want %v", err, errNoPendingBlock)
+		}
+	})
+
+	t.Run("pending block resealed", func(t *testing.T) {
+		w, b, sealed := newSealingWorker(t)
+		block := submit(t, w, sealed)
+
+		resealed, err := w.resealPending()
+		if err != nil {
+			t.Fatalf("failed to reseal: %v", err)
+		}
+		if resealed.Hash() != block.Hash() {
+			t.Fatalf("resealed block mismatch: have %x, want %x", resealed.Hash(), block.Hash())
+		}
+		// the very same block is sealed, despite the duplicate check
+		if task := <-sealed; task.block.Hash() != block.Hash() {
+			t.Fatalf("sealed block mismatch: have %x, want %x", task.block.Hash(), block.Hash())
+		}
+		for deadline := time.Now().Add(5 * time.Second); b.chain.CurrentBlock().NumberU64() != 1; {
+			if time.Now().After(deadline) {
+				t.Fatal("resealed block not imported")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if head := b.chain.CurrentBlock(); w.engine.SealHash(head.Header()) != w.engine.SealHash(block.Header()) {
+			t.Fatalf("imported block mismatch: have %x, want the resealed one", head.Hash())
+		}
+	})
+
+	t.Run("stale pending block", func(t *testing.T) {
+		w, b, sealed := newSealingWorker(t)
+		submit(t, w, sealed)
+
+		// a competing block becomes the head meanwhile
+		if _, err := b.chain.InsertChain(types.Blocks{b.uncleBlock}); err != nil {
+			t.Fatalf("failed to insert block: %v", err)
+		}
+		if _, err := w.resealPending(); !errors.Is(err, errStalePendingBlock) {
+			t.Fatalf("error mismatch: have %v, want %v", err, errStalePendingBlock)
+		}
+		select {
+		case <-sealed:
+			t.Fatal("stale block submitted for sealing")
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+}
+
+func TestSkipRevertedTxs(t *testing.T) {
+	price := big.NewInt(2 * params.InitialBaseFee)
+	// PUSH1 0 PUSH1 0 REVERT
+	reverting, _ := types.SignTx(types.NewContractCreation(0, big.NewInt(0), testGas, price, common.FromHex("0x60006000fd")), types.HomesteadSigner{}, testBankKey)
+	followUp := newTestTransfer(testBankKey, 1, testUserAddress, price)
+	transfer := newTestTransfer(testUserKey, 0, testBankAddress, price)
+
+	commit := func(t *testing.T, skip bool) *environment {
+		config := *testConfig
+		config.SkipRevertedTxs = skip
+		w, _ := newTestBuildWorker(t, &config)
+
+		env := prepareTestWork(t, w)
+		txs := types.NewTransactionsByPriceAndNonce(env.signer, map[common.Address]types.Transactions{
+			testBankAddress: {reverting, followUp},
+			testUserAddress: {transfer},
+		}, env.header.BaseFee)
+		w.commitTransactions(env, txs, nil)
+		return env
+	}
+
+	t.Run("reverted transactions are included by default", func(t *testing.T) {
+		env := commit(t, false)
+		if len(env.txs) != 3 || len(env.receipts) != 3 || len(env.rejected) != 0 {
+			t.Fatalf("transaction count mismatch: have %d included, %d rejected", len(env.txs), len(env.rejected))
+		}
+		for i, tx := range env.txs {
+			if tx.Hash() != reverting.Hash() {
+				continue
+			}
+			if receipt := env.receipts[i]; receipt.Status != types.ReceiptStatusFailed || receipt.GasUsed == 0 {
+				t.Fatalf("reverted transaction receipt mismatch: status %d, gas used %d", receipt.Status, receipt.GasUsed)
+			}
+		}
+		if env.state.GetNonce(testBankAddress) != 2 {
+			t.Fatalf("reverted transaction not charged: nonce %d", env.state.GetNonce(testBankAddress))
+		}
+	})
+
+	t.Run("reverted transactions are skipped", func(t *testing.T) {
+		env := commit(t, true)
+		if len(env.txs) != 1 || env.txs[0].Hash() != transfer.Hash() {
+			t.Fatalf("included transactions mismatch: have %d", len(env.txs))
+		}
+		if len(env.receipts) != 1 || env.receipts[0].Status != types.ReceiptStatusSuccessful {
+			t.Fatalf("receipts mismatch")
+		}
+		if env.header.GasUsed != params.TxGas || env.gasPool.Gas() != env.header.GasLimit-params.TxGas {
+			t.Fatalf("gas accounting mismatch: used %d, pool %d", env.header.GasUsed, env.gasPool.Gas())
+		}
+		if env.state.GetNonce(testBankAddress) != 0 || env.state.GetBalance(testBankAddress).Cmp(testBankFunds) <= 0 {
+			t.Fatalf("reverted transaction not rolled back")
+		}
+		if len(env.rejected) != 1 || env.rejected[0].Hash != reverting.Hash() || !errors.Is(env.rejected[0].Reason, errTxReverted) {
+			t.Fatalf("rejections mismatch: have %v", env.rejected)
+		}
+	})
+}
+
+func TestRewardSplitter(t *testing.T) {
+	w, b := newTestBuildWorker(t, nil)
+
+	// The coinbase earns the tips of the pool transactions.
+	var txs []*types.Transaction
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		txs = append(txs, newTestTransfer(testBankKey, nonce, testBankAddress, big.NewInt(10*params.InitialBaseFee)))
+	}
+	b.addRemotes(t, txs...)
+
+	signer := types.LatestSigner(ethashChainConfig)
+	operator, staking := common.HexToAddress("0x0100"), common.HexToAddress("0x0200")
+	var splitReward *big.Int
+	// a quarter of the reward goes to the operator and to the staking contract each
+	w.setRewardSplitter(func(header *types.Header, reward *big.Int, nonce uint64) ([]*types.Transaction, error) {
+		splitReward = reward
+		share := new(big.Int).Div(reward, big.NewInt(4))
+		var split []*types.Transaction
+		for i, to := range []common.Address{operator, staking} {
+			tx, _ := types.SignTx(types.NewTransaction(nonce+uint64(i), to, share, params.TxGas, header.BaseFee, nil), signer, testUserKey)
+			split = append(split, tx)
+		}
+		return split, nil
+	})
+
+	env := buildTestBlock(t, w)
+	earned := new(big.Int).Set(env.state.GetBalance(testUserAddress))
+	if err := w.splitReward(env); err != nil {
+		t.Fatalf("failed to split reward: %v", err)
+	}
+
+	if reward := new(big.Int).Sub(earned, env.coinbaseBalance); splitReward == nil || splitReward.Cmp(reward) != 0 {
+		t.Fatalf("split reward mismatch: have %v, want %v", splitReward, reward)
+	}
+	if len(env.txs) != len(txs)+2 {
+		t.Fatalf("transactions count mismatch: have %d, want %d", len(env.txs), len(txs)+2)
+	}
+	share := new(big.Int).Div(splitReward, big.NewInt(4))
+	for _, addr := range []common.Address{operator, staking} {
+		if have := env.state.GetBalance(addr); have.Cmp(share) != 0 {
+			t.Errorf("share of %s mismatch: have %v, want %v", addr, have, share)
+		}
+	}
+	// the split only moves the earned value, less the burnt base fee of the transfers
+	burnt := new(big.Int).Mul(env.header.BaseFee, new(big.Int).SetUint64(2*params.TxGas))
+	total := new(big.Int).Add(env.state.GetBalance(testUserAddress), burnt)
+	total.Add(total, env.state.GetBalance(operator))
+	total.Add(total, env.state.GetBalance(staking))
+	if total.Cmp(earned) != 0 {
+		t.Fatalf("total balance mismatch: have %v, want %v", total, earned)
+	}
+}
+
+func TestRewardSplitterOverspend(t *testing.T) {
+	w, b := newTestBuildWorker(t, nil)
+
+	b.addRemotes(t, newTestTransfer(testBankKey, 0, testBankAddress, big.NewInt(10*params.InitialBaseFee)))
+	// the whole reward is transferred, leaving nothing for the gas
+	signer := types.LatestSigner(ethashChainConfig)
+	w.setRewardSplitter(func(header *types.Header, reward *big.Int, nonce uint64) ([]*types.Transaction, error) {
+		tx, _ := types.SignTx(types.NewTransaction(nonce, common.HexToAddress("0x0100"), reward, params.TxGas, header.BaseFee, nil), signer, testUserKey)
+		return []*types.Transaction{tx}, nil
+	})
+
+	env := buildTestBlock(t, w)
+	balance := new(big.Int).Set(env.state.GetBalance(testUserAddress))
+
+	if err := w.splitReward(env); !errors.Is(err, errRewardOverspent) {
+		t.Fatalf("error mismatch: have %v, want %v", err, errRewardOverspent)
+	}
+	if len(env.txs) != 1 {
+		t.Fatalf("transactions count mismatch: have %d, want 1", len(env.txs))
+	}
+	if have := env.state.GetBalance(testUserAddress); have.Cmp(balance) != 0 {
+		t.Fatalf("coinbase balance mismatch: have %v, want %v", have, balance)
+	}
+}
+
+func TestSubscribeSealedBlock(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, b := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+	miner := &Miner{worker: w}
+
+	sealedCh := make(chan *SealedBlock, 1)
+	sub := miner.SubscribeSealedBlock(sealedCh)
+	defer sub.Unsubscribe()
+	// a subscriber never receiving doesn't stall the seal path
+	stalled := miner.SubscribeSealedBlock(make(chan *SealedBlock))
+	defer stalled.Unsubscribe()
+
+	w.start()
+	var sealed *SealedBlock
+	select {
+	case sealed = <-sealedCh:
+	case <-time.After(3 * time.Second):
+		t.Fatal("sealed block not delivered")
+	}
+	block := sealed.Block
+	if block.NumberU64() != 1 {
+		t.Fatalf("block number mismatch: have %d, want 1", block.NumberU64())
+	}
+	if len(sealed.Receipts) != len(block.Transactions()) || len(block.Transactions()) == 0 {
+		t.Fatalf("receipts mismatch: have %d for %d transactions", len(sealed.Receipts), len(block.Transactions()))
+	}
+	for i, receipt := range sealed.Receipts {
+		if receipt.BlockHash != block.Hash() || receipt.TxHash != block.Transactions()[i].Hash() {
+			t.Fatalf("receipt %d doesn't match the sealed block", i)
+		}
+	}
+	if sealed.GasUsed != block.GasUsed() {
+		t.Fatalf("gas used mismatch: have %d, want %d", sealed.GasUsed, block.GasUsed())
+	}
+	if fees := blockFees(block, sealed.Receipts); sealed.Fees.Cmp(fees) != 0 || fees.Sign() <= 0 {
+		t.Fatalf("fees mismatch: have %v, want %v", sealed.Fees, fees)
+	}
+	if sealed.AssemblyTime <= 0 {
+		t.Fatalf("assembly time not reported")
+	}
+
+	// the sealed block is imported regardless of the stalled subscriber
+	deadline := time.Now().Add(3 * time.Second)
+	for !b.chain.HasBlock(block.Hash(), block.NumberU64()) {
+		if time.Now().After(deadline) {
+			t.Fatal("sealed block not imported")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestTxSelectionReport(t *testing.T) {
+	const largeSize = 100
+	signer := types.LatestSigner(ethashChainConfig)
+	newTx := func(nonce uint64, feeCap int64, size int, key *ecdsa.PrivateKey) *types.Transaction {
+		tx, _ := types.SignTx(types.NewTransaction(nonce, common.HexToAddress("0x0100"), big.NewInt(1000), params.TxGas+uint64(size)*params.TxDataZeroGas, big.NewInt(feeCap), make([]byte, size)), signer, key)
+		return tx
+	}
+	report := func(t *testing.T, config *Config, setup func(w *worker), txs []*types.Transaction) map[common.Hash]TxSelection {
+		w, b := newTestBuildWorker(t, config)
+		if setup != nil {
+			setup(w)
+		}
+		b.addRemotes(t, txs...)
+		report, err := w.txSelectionReport(common.Hash{})
+		if err != nil {
+			t.Fatalf("failed to report the selection: %v", err)
+		}
+		if report.Parent != b.chain.CurrentBlock().Hash() {
+			t.Fatalf("parent mismatch: have %x, want the head %x", report.Parent, b.chain.CurrentBlock().Hash())
+		}
+		if len(report.Transactions) != len(txs) {
+			t.Fatalf("reported transactions count mismatch: have %d, want %d", len(report.Transactions), len(txs))
+		}
+		selections := make(map[common.Hash]TxSelection)
+		for _, selection := range report.Transactions {
+			selections[selection.Hash] = selection
+		}
+		return selections
+	}
+	check := func(t *testing.T, selections map[common.Hash]TxSelection, tx *types.Transaction, included bool, reason ExclusionReason) {
+		t.Helper()
+		selection, ok := selections[tx.Hash()]
+		if !ok {
+			t.Fatalf("transaction %d not reported", tx.Nonce())
+		}
+		if selection.Included != included || selection.Reason != reason {
+			t.Errorf("transaction %d selection mismatch: have included %v reason %q, want %v %q", tx.Nonce(), selection.Included, selection.Reason, included, reason)
+		}
+	}
+
+	t.Run("low tip and nonce gaps", func(t *testing.T) {
+		config := *testConfig
+		config.MinTipBaseFeeFraction = 0.5
+		var (
+			included = newTx(0, 2*params.InitialBaseFee, 0, testBankKey)
+			lowTip   = newTx(1, params.InitialBaseFee*11/10, 0, testBankKey)
+			afterLow = newTx(2, 2*params.InitialBaseFee, 0, testBankKey)
+			queued   = newTx(5, 2*params.InitialBaseFee, 0, testBankKey)
+		)
+		selections := report(t, &config, nil, []*types.Transaction{included, lowTip, afterLow, queued})
+		check(t, selections, included, true, "")
+		check(t, selections, lowTip, false, ExcludedLowTip)
+		check(t, selections, afterLow, false, ExcludedNonceGap)
+		check(t, selections, queued, false, ExcludedNonceGap)
+	})
+
+	t.Run("gas exhausted and filtered", func(t *testing.T) {
+		largeGas := params.TxGas + largeSize*params.TxDataZeroGas
+		config := *testConfig
+		config.LargeTxDataSize = largeSize
+		config.LargeTxGasLimit = largeGas
+		var (
+			included  = newTx(0, 2*params.InitialBaseFee, largeSize, testBankKey)
+			overLimit = newTx(1, 2*params.InitialBaseFee, largeSize, testBankKey)
+			denied    = newTx(0, 2*params.InitialBaseFee, 0, testUserKey)
+			denied2   = newTx(1, 2*params.InitialBaseFee, 0, testUserKey)
+		)
+		deny := func(w *worker) { w.setSenderDenylist([]common.Address{testUserAddress}) }
+		selections := report(t, &config, deny, []*types.Transaction{included, overLimit, denied, denied2})
+		check(t, selections, included, true, "")
+		check(t, selections, overLimit, false, ExcludedGasExhausted)
+		check(t, selections, denied, false, ExcludedFiltered)
+		check(t, selections, denied2, false, ExcludedFiltered)
+	})
+}
+
+// revertingCode returns an init code reverting with the given reason, encoded as
+// Error(string).
+func revertingCode(reason string) []byte {
+	payload := append(crypto.Keccak256([]byte("Error(string)"))[:4], common.LeftPadBytes(big.NewInt(32).Bytes(), 32)...)
+	payload = append(payload, common.LeftPadBytes(big.NewInt(int64(len(reason))).Bytes(), 32)...)
+	payload = append(payload, common.RightPadBytes([]byte(reason), 32)...)
+	// PUSH1 len PUSH1 12 PUSH1 0 CODECOPY PUSH1 len PUSH1 0 REVERT, followed by the payload
+	code := []byte{0x60, byte(len(payload)), 0x60, 12, 0x60, 0, 0x39, 0x60, byte(len(payload)), 0x60, 0, 0xfd}
+	return append(code, payload...)
+}
+
+// waitPendingTx waits until the transaction is included in the pending block and
+// returns its receipt.
+func waitPendingTx(t *testing.T, w *worker, tx *types.Transaction) *types.Receipt {
+	t.Helper()
+	for deadline := time.Now().Add(3 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		block, receipts := w.pendingBlockAndReceipts()
+		if block == nil {
+			continue
+		}
+		for i, included := range block.Transactions() {
+			if included.Hash() == tx.Hash() {
+				return receipts[i]
+			}
+		}
+	}
+	t.Fatalf("transaction not included in the pending block")
+	return nil
+}
+
+func TestSimulateTx(t *testing.T) {
+	w, b := newTestBuildWorker(t, nil)
+
+	if _, err := w.simulateTx(pendingTxs[0]); err != errNoPendingState {
+		t.Fatalf("simulation without pending state: have %v, want %v", err, errNoPendingState)
+	}
+	// the pending block is built by the worker, then extended with the new pool transactions
+	w.startCh <- struct{}{}
+	if err := b.txPool.AddLocal(pendingTxs[0]); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	waitPendingTx(t, w, pendingTxs[0])
+
+	// the pending state is ahead of the committed one
+	_, pendingState := w.pending()
+	nonce := pendingState.GetNonce(testBankAddress)
+	if nonce == 0 {
+		t.Fatalf("pending nonce not ahead of the committed one")
+	}
+	signer := types.LatestSigner(ethashChainConfig)
+	price := big.NewInt(2 * params.InitialBaseFee)
+
+	t.Run("simulated transaction matches the mined one", func(t *testing.T) {
+		// PUSH1 0 PUSH1 0 LOG0 STOP
+		tx, _ := types.SignTx(types.NewContractCreation(nonce, big.NewInt(0), testGas, price, common.FromHex("0x60006000a000")), signer, testBankKey)
+		sim, err := w.simulateTx(tx)
+		if err != nil {
+			t.Fatalf("failed to simulate: %v", err)
+		}
+		if sim.Failed || len(sim.Logs) != 1 {
+			t.Fatalf("simulation mismatch: failed %v, %d logs", sim.Failed, len(sim.Logs))
+		}
+		// the simulation left the pending state untouched
+		if _, state := w.pending(); state.GetNonce(testBankAddress) != nonce {
+			t.Fatalf("pending state modified by the simulation")
+		}
+
+		if err := b.txPool.AddLocal(tx); err != nil {
+			t.Fatalf("failed to add transaction: %v", err)
+		}
+		receipt := waitPendingTx(t, w, tx)
+		if receipt.GasUsed != sim.GasUsed || receipt.Status != types.ReceiptStatusSuccessful || len(receipt.Logs) != len(sim.Logs) {
+			t.Fatalf("mined transaction mismatch: gas %d/%d, status %d, %d/%d logs", receipt.GasUsed, sim.GasUsed, receipt.Status, len(receipt.Logs), len(sim.Logs))
+		}
+		if receipt.Logs[0].Address != sim.Logs[0].Address {
+			t.Fatalf("log address mismatch: have %x, want %x", sim.Logs[0].Address, receipt.Logs[0].Address)
+		}
+		nonce++
+	})
+
+	t.Run("reverting transaction reports its reason", func(t *testing.T) {
+		tx, _ := types.SignTx(types.NewContractCreation(nonce, big.NewInt(0), testGas, price, revertingCode("not allowed")), signer, testBankKey)
+		sim, err := w.simulateTx(tx)
+		if err != nil {
+			t.Fatalf("failed to simulate: %v", err)
+		}
+		if !sim.Failed || sim.RevertReason != "not allowed" || len(sim.Logs) != 0 || len(sim.ReturnData) == 0 {
+			t.Fatalf("simulation mismatch: failed %v, reason %q", sim.Failed, sim.RevertReason)
+		}
+
+		if err := b.txPool.AddLocal(tx); err != nil {
+			t.Fatalf("failed to add transaction: %v", err)
+		}
+		receipt := waitPendingTx(t, w, tx)
+		if receipt.Status != types.ReceiptStatusFailed || receipt.GasUsed != sim.GasUsed {
+			t.Fatalf("mined transaction mismatch: status %d, gas %d/%d", receipt.Status, receipt.GasUsed, sim.GasUsed)
+		}
+	})
+
+	t.Run("invalid nonce refused", func(t *testing.T) {
+		tx := newTestTransfer(testBankKey, 0, testUserAddress, price)
+		if _, err := w.simulateTx(tx); err == nil {
+			t.Fatalf("transaction with a used nonce simulated")
+		}
+	})
+}
+
+func TestReservedSystemGas(t *testing.T) {
+	config := *testConfig
+	config.ReservedSystemGas = 2 * params.TxGas
+	config.SystemTxSenders = []common.Address{testUserAddress}
+	w, b := newTestBuildWorker(t, &config)
+
+	// Fill the mempool with high tip user transactions, and a low tip system one.
+	var txs []*types.Transaction
+	for nonce := uint64(0); nonce < 10; nonce++ {
+		txs = append(txs, newTestTransfer(testBankKey, nonce, testUserAddress, big.NewInt(2*params.InitialBaseFee)))
+	}
+	systemTx, _ := types.SignTx(types.NewTransaction(0, autonity.OracleContractAddress, big.NewInt(0), params.TxGas, big.NewInt(params.InitialBaseFee+1), nil), types.LatestSigner(ethashChainConfig), testUserKey)
+	b.addRemotes(t, append(txs, systemTx)...)
+
+	env := prepareTestWork(t, w)
+	env.gasPool = new(core.GasPool).AddGas(5 * params.TxGas)
+	w.fillTransactions(nil, env)
+
+	if len(env.txs) == 0 || env.txs[0].Hash() != systemTx.Hash() {
+		t.Fatalf("system transaction not placed first")
+	}
+	// the unused reserved gas is kept for late system transactions
+	if have, want := len(env.txs)-1, 3; have != want {
+		t.Fatalf("user transactions count mismatch: have %d, want %d", have, want)
+	}
+	if env.systemGasUsed != params.TxGas {
+		t.Fatalf("system gas used mismatch: have %d, want %d", env.systemGasUsed, params.TxGas)
+	}
+	if have, want := env.gasPool.Gas(), params.TxGas; have != want {
+		t.Fatalf("remaining gas mismatch: have %d, want %d", have, want)
+	}
+}
+
+func TestIsSystemTx(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, _ := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+	w.setSystemTxSenders([]common.Address{testUserAddress})
+
+	signer := types.LatestSigner(ethashChainConfig)
+	newTx := func(to common.Address, key *ecdsa.PrivateKey) *types.Transaction {
+		tx, _ := types.SignTx(types.NewTransaction(0, to, big.NewInt(0), params.TxGas, big.NewInt(params.InitialBaseFee), nil), signer, key)
+		return tx
+	}
+	for _, tt := range []struct {
+		name   string
+		tx     *types.Transaction
+		system bool
+	}{
+		{name: "trusted sender calling a protocol contract", tx: newTx(autonity.OracleContractAddress, testUserKey), system: true},
+		{name: "trusted sender calling another account", tx: newTx(testBankAddress, testUserKey)},
+		// bonding, NTN transfers or oracle calls of any user get no priority nor reserved gas
+		{name: "untrusted sender calling a protocol contract", tx: newTx(autonity.AutonityContractAddress, testBankKey)},
+	} {
+		if have := w.isSystemTx(signer, tt.tx); have != tt.system {
+			t.Fatalf("%s: system mismatch: have %v, want %v", tt.name, have, tt.system)
+		}
+	}
+	pending := map[common.Address]types.Transactions{
+		testUserAddress: {newTx(autonity.OracleContractAddress, testUserKey)},
+		testBankAddress: {newTx(autonity.AutonityContractAddress, testBankKey)},
+	}
+	if system := w.systemTransactions(pending); len(system) != 1 || len(system[testUserAddress]) != 1 {
+		t.Fatalf("system transactions mismatch: have %v", system)
+	}
+}
+
+func TestSystemTxProvider(t *testing.T) {
+	w, b := newTestBuildWorker(t, nil)
+
+	// The pool transactions offer a much higher tip than the provided ones.
+	var txs []*types.Transaction
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		txs = append(txs, newTestTransfer(testBankKey, nonce, testUserAddress, big.NewInt(10*params.InitialBaseFee)))
+	}
+	b.addRemotes(t, txs...)
+
+	signer := types.LatestSigner(ethashChainConfig)
+	var provided []*types.Transaction
+	w.setSystemTxProvider(func(header *types.Header, state *state.StateDB) ([]*types.Transaction, error) {
+		provided = provided[:0]
+		nonce := state.GetNonce(testUserAddress)
+		for i := uint64(0); i < 2; i++ {
+			tx, _ := types.SignTx(types.NewTransaction(nonce+i, autonity.OracleContractAddress, big.NewInt(0), params.TxGas, header.BaseFee, nil), signer, testUserKey)
+			provided = append(provided, tx)
+		}
+		return provided, nil
+	})
+
+	parent := b.chain.CurrentBlock()
+	result, err := w.generateWork(&generateParams{timestamp: parent.Time() + 1, coinbase: testUserAddress})
+	if err != nil {
+		t.Fatalf("failed to generate work: %v", err)
+	}
+	included := result.Block.Transactions()
+	if len(included) != len(provided)+len(txs) {
+		t.Fatalf("transactions count mismatch: have %d, want %d", len(included), len(provided)+len(txs))
+	}
+	for i, tx := range provided {
+		if included[i].Hash() != tx.Hash() {
+			t.Fatalf("provided transaction %d not placed at the top of the block", i)
+		}
+	}
+
+	// a nil provider is a no-op
+	w.setSystemTxProvider(nil)
+	result, err = w.generateWork(&generateParams{timestamp: parent.Time() + 1, coinbase: testUserAddress})
+	if err != nil {
+		t.Fatalf("failed to generate work: %v", err)
+	}
+	if have := len(result.Block.Transactions()); have != len(txs) {
+		t.Fatalf("transactions count mismatch: have %d, want %d", have, len(txs))
+	}
+}
+
+func TestSystemTxProviderError(t *testing.T) {
+	w, b := newTestBuildWorker(t, nil)
+
+	errProvider := errors.New("beacon unavailable")
+	w.setSystemTxProvider(func(*types.Header, *state.StateDB) ([]*types.Transaction, error) {
+		return nil, errProvider
+	})
+	parent := b.chain.CurrentBlock()
+	if _, err := w.generateWork(&generateParams{timestamp: parent.Time() + 1, coinbase: testUserAddress}); !errors.Is(err, errProvider) {
+		t.Fatalf("error mismatch: have %v, want %v", err, errProvider)
+	}
+
+	// a provided transaction which can't be applied aborts the build as well
+	signer := types.LatestSigner(ethashChainConfig)
+	w.setSystemTxProvider(func(header *types.Header, state *state.StateDB) ([]*types.Transaction, error) {
+		tx, _ := types.SignTx(types.NewTransaction(state.GetNonce(testUserAddress)+1, autonity.OracleContractAddress, big.NewInt(0), params.TxGas, header.BaseFee, nil), signer, testUserKey)
+		return []*types.Transaction{tx}, nil
+	})
+	if _, err := w.generateWork(&generateParams{timestamp: parent.Time() + 1, coinbase: testUserAddress}); !errors.Is(err, core.ErrNonceTooHigh) {
+		t.Fatalf("error mismatch: have %v, want %v", err, core.ErrNonceTooHigh)
+	}
+}
+
+func newBundleTx(nonce uint64, tip int64, data []byte) *types.Transaction {
+	var tx *types.Transaction
+	price := new(big.Int).Add(big.NewInt(params.InitialBaseFee), big.NewInt(tip))
+	if data != nil {
+		tx, _ = types.SignTx(types.NewContractCreation(nonce, big.NewInt(0), testGas, price, data), types.HomesteadSigner{}, testBankKey)
+	} else {
+		tx, _ = types.SignTx(types.NewTransaction(nonce, testUserAddress, big.NewInt(1000), params.TxGas, price, nil), types.HomesteadSigner{}, testBankKey)
+	}
+	return tx
+}
+
+func TestCommitBundles(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, _ := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	t.Run("all transactions succeed, bundle is included", func(t *testing.T) {
+		w.bundles = nil
+		bundleTxs := types.Transactions{newBundleTx(0, 1, nil), newBundleTx(1, 1, nil)}
+		if err := w.addBundle(bundleTxs); err != nil {
+			t.Fatalf("failed to add bundle: %v", err)
+		}
+		env := prepareTestWork(t, w)
+
+		w.commitBundles(env)
+		if len(env.txs) != 2 || env.txs[0].Hash() != bundleTxs[0].Hash() || env.txs[1].Hash() != bundleTxs[1].Hash() {
+			t.Fatalf("bundle not included in order")
+		}
+		if env.header.GasUsed != 2*params.TxGas || env.tcount != 2 {
+			t.Fatalf("gas used or tx count mismatch: have %d/%d", env.header.GasUsed, env.tcount)
+		}
+	})
+
+	t.Run("one transaction reverts, whole bundle is rolled back", func(t *testing.T) {
+		w.bundles = nil
+		// PUSH1 0 PUSH1 0 REVERT
+		bundleTxs := types.Transactions{newBundleTx(0, 1, nil), newBundleTx(1, 1, common.FromHex("0x60006000fd"))}
+		if err := w.addBundle(bundleTxs); err != nil {
+			t.Fatalf("failed to add bundle: %v", err)
+		}
+		// The reverting contract creation counts toward the large transactions budget
+		w.largeTxBudget = &txBudget{member: func(tx *types.Transaction) bool { return len(tx.Data()) > 0 }, limit: testGas}
+		defer func() { w.largeTxBudget = nil }()
+		env := prepareTestWork(t, w)
+		balance := env.state.GetBalance(testBankAddress)
+
+		w.commitBundles(env)
+		if len(env.txs) != 0 || len(env.receipts) != 0 || env.header.GasUsed != 0 || env.tcount != 0 || env.largeGasUsed != 0 {
+			t.Fatalf("bundle not rolled back")
+		}
+		if env.gasPool.Gas() != env.header.GasLimit {
+			t.Fatalf("gas pool not restored: have %d, want %d", env.gasPool.Gas(), env.header.GasLimit)
+		}
+		if env.state.GetBalance(testBankAddress).Cmp(balance) != 0 || env.state.GetNonce(testBankAddress) != 0 {
+			t.Fatalf("state not reverted")
+		}
+		if len(env.rejected) != 2 || !errors.Is(env.rejected[0].Reason, errBundleReverted) {
+			t.Fatalf("rejections mismatch: have %v", env.rejected)
+		}
+		if len(w.bundles) != 0 {
+			t.Fatalf("reverted bundle should have been dropped")
+		}
+	})
+
+	t.Run("stale bundle is dropped", func(t *testing.T) {
+		w.bundles = nil
+		if err := w.addBundle(types.Transactions{newBundleTx(0, 1, nil)}); err != nil {
+			t.Fatalf("failed to add bundle: %v", err)
+		}
+		env := prepareTestWork(t, w)
+		env.state.SetNonce(testBankAddress, 1)
+
+		w.commitBundles(env)
+		if len(env.txs) != 0 || len(w.bundles) != 0 {
+			t.Fatalf("stale bundle should have been dropped")
+		}
+		if !errors.Is(env.rejected[0].Reason, core.ErrNonceTooLow) {
+			t.Fatalf("unexpected rejection reason: %v", env.rejected[0].Reason)
+		}
+	})
+
+	t.Run("bundles are ordered by total tip", func(t *testing.T) {
+		w.bundles = nil
+		low, high := types.Transactions{newBundleTx(0, 1, nil)}, types.Transactions{newBundleTx(0, 100, nil)}
+		if err := w.addBundle(low); err != nil {
+			t.Fatalf("failed to add bundle: %v", err)
+		}
+		if err := w.addBundle(high); err != nil {
+			t.Fatalf("failed to add bundle: %v", err)
+		}
+		bundles := w.pendingBundles(big.NewInt(params.InitialBaseFee))
+		if bundles[0].txs[0].Hash() != high[0].Hash() {
+			t.Fatalf("highest tip bundle should come first")
+		}
+	})
+
+	t.Run("included bundle is dropped", func(t *testing.T) {
+		w.bundles = nil
+		bundleTxs := types.Transactions{newBundleTx(0, 1, nil)}
+		if err := w.addBundle(bundleTxs); err != nil {
+			t.Fatalf("failed to add bundle: %v", err)
+		}
+		w.dropIncludedBundles(types.NewBlockWithHeader(&types.Header{}).WithBody(bundleTxs, nil))
+		if len(w.bundles) != 0 {
+			t.Fatalf("included bundle should have been dropped")
+		}
+	})
+
+	if err := w.addBundle(nil); !errors.Is(err, errEmptyBundle) {
+		t.Fatalf("expected %v, got %v", errEmptyBundle, err)
+	}
+}
+
+func TestAddBundleValidation(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, b := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	unsigned := types.NewTransaction(0, testUserAddress, big.NewInt(1000), params.TxGas, big.NewInt(params.InitialBaseFee), nil)
+	tooMuchGas, _ := types.SignTx(types.NewTransaction(0, testUserAddress, big.NewInt(1000), b.chain.CurrentBlock().GasLimit()+1, big.NewInt(params.InitialBaseFee), nil), types.HomesteadSigner{}, testBankKey)
+	for name, txs := range map[string]types.Transactions{
+		"unsigned transaction":  {unsigned},
+		"duplicate transaction": {newBundleTx(0, 1, nil), newBundleTx(0, 1, nil)},
+		"nonce going backwards": {newBundleTx(1, 1, nil), newBundleTx(0, 1, nil)},
+		"gas above block limit": {tooMuchGas},
+	} {
+		if err := w.addBundle(txs); !errors.Is(err, errInvalidBundle) {
+			t.Fatalf("%s: expected %v, got %v", name, errInvalidBundle, err)
+		}
+	}
+
+	w.bundles = nil
+	for i := 0; i < maxPendingBundles; i++ {
+		if err := w.addBundle(types.Transactions{newBundleTx(0, int64(i), nil)}); err != nil {
+			t.Fatalf("failed to add bundle %d: %v", i, err)
+		}
+	}
+	if err := w.addBundle(types.Transactions{newBundleTx(0, 1000, nil)}); !errors.Is(err, errBundleQueueFull) {
+		t.Fatalf("expected %v, got %v", errBundleQueueFull, err)
+	}
+}
+
+func TestLogsFeedMaxSubscribers(t *testing.T) {
+	feed := newLogsFeed(2, false, 0)
+
+	ch1, ch2, ch3 := make(chan []*types.Log, 1), make(chan []*types.Log, 1), make(chan []*types.Log, 1)
+	sub1 := feed.Subscribe(ch1)
+	defer sub1.Unsubscribe()
+	sub2 := feed.Subscribe(ch2)
+
+	refused := feed.Subscribe(ch3)
+	select {
+	case err := <-refused.Err():
+		if err != ErrTooManySubscribers {
+			t.Fatalf("subscription error mismatch: have %v, want %v", err, ErrTooManySubscribers)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("subscription above the limit not refused")
+	}
+	if nsent := feed.Send([]*types.Log{}); nsent != 2 {
+		t.Fatalf("delivered to %d subscribers, want 2", nsent)
+	}
+
+	// unsubscribing makes room for a new subscriber
+	sub2.Unsubscribe()
+	sub3 := feed.Subscribe(ch3)
+	defer sub3.Unsubscribe()
+	select {
+	case err := <-sub3.Err():
+		t.Fatalf("subscription failed: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	<-ch1
+	if nsent := feed.Send([]*types.Log{}); nsent != 2 {
+		t.Fatalf("delivered to %d subscribers, want 2", nsent)
+	}
+}
+
+func TestLogsFeedDropSlowest(t *testing.T) {
+	enabled := metrics.Enabled
+	metrics.Enabled = true
+	meter := PendingLogsDroppedMeter
+	PendingLogsDroppedMeter = metrics.NewMeter()
+	defer func() {
+		PendingLogsDroppedMeter.Stop()
+		PendingLogsDroppedMeter = meter
+		metrics.Enabled = enabled
+	}()
+
+	feed := newLogsFeed(2, true, 0)
+
+	fast, slow := make(chan []*types.Log, 1), make(chan []*types.Log)
+	fastSub := feed.Subscribe(fast)
+	defer fastSub.Unsubscribe()
+	slowSub := feed.Subscribe(slow)
+
+	// the slow consumer never reads, it misses every delivery without blocking the feed
+	for i := 0; i < 3; i++ {
+		done := make(chan int)
+		go func() { done <- feed.Send([]*types.Log{}) }()
+		select {
+		case nsent := <-done:
+			if nsent != 1 {
+				t.Fatalf("delivered to %d subscribers, want 1", nsent)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("send blocked by a slow subscriber")
+		}
+		<-fast
+	}
+	if dropped := PendingLogsDroppedMeter.Count(); dropped != 3 {
+		t.Fatalf("dropped deliveries mismatch: have %d, want 3", dropped)
+	}
+
+	// a new subscriber evicts the slow one
+	newSub := feed.Subscribe(make(chan []*types.Log, 1))
+	defer newSub.Unsubscribe()
+	select {
+	case err := <-slowSub.Err():
+		if err != ErrSubscriberEvicted {
+			t.Fatalf("subscription error mismatch: have %v, want %v", err, ErrSubscriberEvicted)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("slow subscriber not evicted")
+	}
+	select {
+	case err := <-fastSub.Err():
+		t.Fatalf("fast subscription failed: %v", err)
+	case <-newSub.Err():
+		t.Fatal("new subscription failed")
+	default:
+	}
+	if nsent := feed.Send([]*types.Log{}); nsent != 2 {
+		t.Fatalf("delivered to %d subscribers, want 2", nsent)
+	}
+}
+
+func TestLogsFeedBufferCap(t *testing.T) {
+	enabled := metrics.Enabled
+	metrics.Enabled = true
+	meter := PendingLogsTrimmedMeter
+	PendingLogsTrimmedMeter = metrics.NewMeter()
+	defer func() {
+		PendingLogsTrimmedMeter.Stop()
+		PendingLogsTrimmedMeter = meter
+		metrics.Enabled = enabled
+	}()
+
+	const maxBuffered = 5
+	feed := newLogsFeed(0, false, maxBuffered)
+	slow := make(chan []*types.Log)
+	sub := feed.Subscribe(slow)
+	defer sub.Unsubscribe()
+
+	next := uint(0)
+	send := func(n int) {
+		logs := make([]*types.Log, n)
+		for i := range logs {
+			logs[i] = &types.Log{Index: next}
+			next++
+		}
+		done := make(chan int)
+		go func() { done <- feed.Send(logs) }()
+		select {
+		case nsent := <-done:
+			if nsent != 1 {
+				t.Fatalf("delivered to %d subscribers, want 1", nsent)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("send blocked by a slow subscriber")
+		}
+	}
+	buffered := func() int {
+		feed.mu.Lock()
+		defer feed.mu.Unlock()
+		return len(feed.subscribers[0].buffered)
+	}
+	receive := func(from, to uint) {
+		select {
+		case logs := <-slow:
+			if len(logs) != int(to-from) {
+				t.Fatalf("delivered logs mismatch: have %d, want %d", len(logs), to-from)
+			}
+			for i, log := range logs {
+				if log.Index != from+uint(i) {
+					t.Fatalf("log %d mismatch: have index %d, want %d", i, log.Index, from+uint(i))
+				}
+			}
+		case <-time.After(time.Second):
+			t.Fatal("buffered logs not delivered")
+		}
+	}
+
+	// the first batch is taken for delivery while the subscriber isn't reading
+	send(3)
+	deadline := time.Now().Add(time.Second)
+	for buffered() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("first batch not taken for delivery")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	// the next logs are buffered up to the cap, the oldest being dropped
+	for _, n := range []int{3, 3, 3, 1} {
+		send(n)
+		if n := buffered(); n > maxBuffered {
+			t.Fatalf("buffered logs above the cap: have %d, want at most %d", n, maxBuffered)
+		}
+	}
+	if trimmed := PendingLogsTrimmedMeter.Count(); trimmed != 5 {
+		t.Fatalf("dropped logs mismatch: have %d, want 5", trimmed)
+	}
+	receive(0, 3)
+	receive(8, 13)
+
+	// the subscriber catching up gets the logs as they come
+	send(2)
+	receive(13, 15)
+}
+
+var (
+	// cacheTestContract hashes its memory 2000 times, stores and logs the result.
+	cacheTestContract = common.HexToAddress("0x0c0c")
+	cacheTestCode     = common.FromHex("6107d05b6020600020600052600190038060035760005160005560206000a000")
+	// cacheTestDestructible self-destructs to the benefit of its caller.
+	cacheTestDestructible = common.HexToAddress("0x0d0d")
+	cacheTestDestructCode = common.FromHex("33ff")
+)
+
+type txCacheTest struct {
+	w   *worker
+	b   *testWorkerBackend
+	txs []*types.Transaction
+}
+
+func newTxCacheTest(t testing.TB, size int) *txCacheTest {
+	var txs []*types.Transaction
+	for nonce := uint64(0); nonce < 5; nonce++ {
+		txs = append(txs, cacheTestTx(nonce, &cacheTestContract, nil, nil))
+	}
+	return newTxCacheTestWith(t, size, txs)
+}
+
+// cacheTestTx returns a transaction of the bank, a contract creation if to is nil.
+func cacheTestTx(nonce uint64, to *common.Address, value *big.Int, data []byte) *types.Transaction {
+	if value == nil {
+		value = new(big.Int)
+	}
+	price := big.NewInt(2 * params.InitialBaseFee)
+	tx := types.NewContractCreation(nonce, value, 300000, price, data)
+	if to != nil {
+		tx = types.NewTransaction(nonce, *to, value, 300000, price, data)
+	}
+	tx, _ = types.SignTx(tx, types.LatestSigner(ethashChainConfig), testBankKey)
+	return tx
+}
+
+func newTxCacheTestWith(t testing.TB, size int, txs []*types.Transaction) *txCacheTest {
+	config := *testConfig
+	config.TxResultCacheSize = size
+	w, b := newTestBuildWorker(t, &config)
+	return &txCacheTest{w: w, b: b, txs: txs}
+}
+
+// build applies the transactions on top of the head, modify being applied to the
+// state beforehand. It returns the state root and the receipts of the block.
+func (c *txCacheTest) build(t testing.TB, timestamp uint64, modify func(env *environment)) (common.Hash, []*types.Receipt) {
+	env, err := c.w.prepareWork(&generateParams{timestamp: timestamp, coinbase: testUserAddress})
+	if err != nil {
+		t.Fatalf("failed to prepare work: %v", err)
+	}
+	defer env.discard()
+	env.state.SetCode(cacheTestContract, cacheTestCode)
+	env.state.SetCode(cacheTestDestructible, cacheTestDestructCode)
+	// the oracle accepts any vote, for its fee to be refunded
+	env.state.SetCode(autonity.OracleContractAddress, []byte{0x00})
+	if modify != nil {
+		modify(env)
+	}
+	env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+	for _, tx := range c.txs {
+		env.state.Prepare(tx.Hash(), env.tcount)
+		if _, err := c.w.commitTransaction(env, tx); err != nil {
+			t.Fatalf("failed to commit transaction: %v", err)
+		}
+		env.tcount++
+	}
+	return env.state.IntermediateRoot(true), env.receipts
+}
+
+func swapTxResultMeters(t testing.TB) {
+	enabled := metrics.Enabled
+	metrics.Enabled = true
+	hit, miss := TxResultHitMeter, TxResultMissMeter
+	TxResultHitMeter, TxResultMissMeter = metrics.NewMeter(), metrics.NewMeter()
+	t.Cleanup(func() {
+		TxResultHitMeter.Stop()
+		TxResultMissMeter.Stop()
+		TxResultHitMeter, TxResultMissMeter = hit, miss
+		metrics.Enabled = enabled
+	})
+}
+
+func TestTxResultCache(t *testing.T) {
+	swapTxResultMeters(t)
+
+	uncached, cached := newTxCacheTest(t, 0), newTxCacheTest(t, 64)
+	timestamp := uncached.b.chain.CurrentBlock().Time() + 1
+	check := func(modify func(env *environment), wantHits int64) {
+		t.Helper()
+		hits := TxResultHitMeter.Count()
+		wantRoot, wantReceipts := uncached.build(t, timestamp, modify)
+		root, receipts := cached.build(t, timestamp, modify)
+		if root != wantRoot {
+			t.Fatalf("state root mismatch: have %x, want %x", root, wantRoot)
+		}
+		if !reflect.DeepEqual(receipts, wantReceipts) {
+			t.Fatalf("receipts mismatch:\nhave %+v\nwant %+v", receipts, wantReceipts)
+		}
+		if have := TxResultHitMeter.Count() - hits; have != wantHits {
+			t.Fatalf("replayed transactions mismatch: have %d, want %d", have, wantHits)
+		}
+	}
+	// the first execution tells the state to watch, the second one records the result
+	check(nil, 0)
+	check(nil, 0)
+	check(nil, int64(len(cached.txs)))
+
+	// the fees credited to the coinbase aren't a dependency of the transactions
+	check(func(env *environment) {
+		env.state.AddBalance(testUserAddress, big.NewInt(1))
+	}, int64(len(cached.txs)))
+	// the stored hash changes the gas used, hence the fees paid by the following transactions
+	check(func(env *environment) {
+		env.state.SetState(cacheTestContract, common.Hash{}, common.HexToHash("0x01"))
+	}, 0)
+	check(func(env *environment) {
+		env.state.AddBalance(testBankAddress, big.NewInt(1))
+	}, 0)
+	// the results don't apply to another block context
+	hits := TxResultHitMeter.Count()
+	if cached.build(t, timestamp+1, nil); TxResultHitMeter.Count() != hits {
+		t.Fatal("transactions replayed in another block context")
+	}
+}
+
+// TestTxResultCacheDifferential checks the blocks built with the cached results
+// against the ones built executing every transaction, for the executions whose
+// changes go beyond plain balance, nonce and storage updates.
+func TestTxResultCacheDifferential(t *testing.T) {
+	vote := append(common.CopyBytes(generated.OracleAbi.Methods["vote"].ID), make([]byte, 32)...)
+	tests := []struct {
+		name string
+		txs  []*types.Transaction
+	}{
+		{"contract creation", []*types.Transaction{
+			cacheTestTx(0, nil, nil, common.FromHex("6001600055")),
+			cacheTestTx(1, &cacheTestContract, nil, nil),
+			cacheTestTx(2, nil, big.NewInt(1), common.FromHex("6002600055")),
+		}},
+		{"self-destruct", []*types.Transaction{
+			cacheTestTx(0, &cacheTestContract, nil, nil),
+			cacheTestTx(1, &cacheTestDestructible, big.NewInt(1000), nil),
+			cacheTestTx(2, &cacheTestDestructible, big.NewInt(1000), nil),
+		}},
+		{"oracle vote fee refund", []*types.Transaction{
+			cacheTestTx(0, &autonity.OracleContractAddress, nil, vote),
+			cacheTestTx(1, &cacheTestContract, nil, nil),
+			cacheTestTx(2, &autonity.OracleContractAddress, nil, vote),
+		}},
+		{"autonity contract", []*types.Transaction{
+			cacheTestTx(0, &cacheTestContract, nil, nil),
+			cacheTestTx(1, &autonity.AutonityContractAddress, big.NewInt(1000), nil),
+			cacheTestTx(2, &autonity.AutonityContractAddress, nil, []byte{0x01}),
+			cacheTestTx(3, &cacheTestContract, nil, nil),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			swapTxResultMeters(t)
+			uncached, cached := newTxCacheTestWith(t, 0, tt.txs), newTxCacheTestWith(t, 64, tt.txs)
+			timestamp := uncached.b.chain.CurrentBlock().Time() + 1
+			modifications := []func(env *environment){
+				nil,
+				nil,
+				nil,
+				func(env *environment) { env.state.AddBalance(testUserAddress, big.NewInt(1)) },
+				func(env *environment) { env.state.AddBalance(autonity.AutonityContractAddress, big.NewInt(1)) },
+				func(env *environment) { env.state.SetState(cacheTestContract, common.Hash{}, common.HexToHash("0x01")) },
+				nil,
+			}
+			for i, modify := range modifications {
+				wantRoot, wantReceipts := uncached.build(t, timestamp, modify)
+				root, receipts := cached.build(t, timestamp, modify)
+				if root != wantRoot {
+					t.Fatalf("build %d: state root mismatch: have %x, want %x", i, root, wantRoot)
+				}
+				if !reflect.DeepEqual(receipts, wantReceipts) {
+					t.Fatalf("build %d: receipts mismatch:\nhave %+v\nwant %+v", i, receipts, wantReceipts)
+				}
+			}
+			// the transactions the changes of which can't be replayed are executed, not the others
+			if TxResultHitMeter.Count() == 0 {
+				t.Fatal("no transaction replayed")
+			}
+		})
+	}
+}
+
+func BenchmarkTxResultCache(b *testing.B) {
+	for _, bench := range []struct {
+		name string
+		size int
+	}{{"disabled", 0}, {"enabled", 64}} {
+		b.Run(bench.name, func(b *testing.B) {
+			swapTxResultMeters(b)
+			test := newTxCacheTest(b, bench.size)
+			timestamp := test.b.chain.CurrentBlock().Time() + 1
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				test.build(b, timestamp, nil)
+			}
+			b.StopTimer()
+			if bench.size > 0 {
+				b.ReportMetric(float64(TxResultMissMeter.Count())/float64(b.N), "executions/op")
+			} else {
+				b.ReportMetric(float64(len(test.txs)), "executions/op")
+			}
+		})
+	}
+}